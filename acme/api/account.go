@@ -1,9 +1,11 @@
 package api
 
 import (
+	"crypto"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/go-acme/lego/v4/acme"
 )
@@ -73,6 +75,27 @@ func (a *AccountService) Update(accountURL string, req acme.Account) (acme.Accou
 	return account, nil
 }
 
+// UpdateEAB Updates an account with a new External Account Binding.
+func (a *AccountService) UpdateEAB(accountURL string, req acme.Account, kid, hmacEncoded string) (acme.Account, error) {
+	if accountURL == "" {
+		return acme.Account{}, errors.New("account[updateEAB]: empty URL")
+	}
+
+	hmac, err := base64.RawURLEncoding.DecodeString(hmacEncoded)
+	if err != nil {
+		return acme.Account{}, fmt.Errorf("acme: could not decode hmac key: %w", err)
+	}
+
+	eabJWS, err := a.core.signEABContent(accountURL, kid, hmac)
+	if err != nil {
+		return acme.Account{}, fmt.Errorf("acme: error signing eab content: %w", err)
+	}
+
+	req.ExternalAccountBinding = eabJWS
+
+	return a.Update(accountURL, req)
+}
+
 // Deactivate Deactivates an account.
 func (a *AccountService) Deactivate(accountURL string) error {
 	if accountURL == "" {
@@ -83,3 +106,37 @@ func (a *AccountService) Deactivate(accountURL string) error {
 	_, err := a.core.post(accountURL, req, nil)
 	return err
 }
+
+// KeyChange Changes the key of the account.
+// On success, the new key is used to sign the following requests.
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.3.5
+func (a *AccountService) KeyChange(newKey crypto.Signer) error {
+	if newKey == nil {
+		return errors.New("account[keyChange]: the new key cannot be nil")
+	}
+
+	uri := a.core.GetDirectory().KeyChangeURL
+	if uri == "" {
+		return errors.New("account[keyChange]: the server doesn't support the key change")
+	}
+
+	innerJWS, err := a.core.jws.SignKeyChange(uri, newKey)
+	if err != nil {
+		return fmt.Errorf("account[keyChange]: %w", err)
+	}
+
+	resp, err := a.core.retrievablePost(uri, []byte(innerJWS.FullSerialize()), nil, false)
+	if err != nil {
+		var problem *acme.ProblemDetails
+		// The status of the problem document can be missing: the status of the response is used.
+		if errors.As(err, &problem) && (problem.HTTPStatus == http.StatusConflict || resp != nil && resp.StatusCode == http.StatusConflict) {
+			return &acme.KeyInUseError{ProblemDetails: problem, AccountURL: getLocation(resp)}
+		}
+
+		return err
+	}
+
+	a.core.jws.SetPrivateKey(newKey)
+
+	return nil
+}
@@ -2,11 +2,13 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"crypto"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -15,15 +17,27 @@ import (
 	"github.com/go-acme/lego/v4/acme/api/internal/secure"
 	"github.com/go-acme/lego/v4/acme/api/internal/sender"
 	"github.com/go-acme/lego/v4/log"
+	jose "github.com/go-jose/go-jose/v3"
 )
 
+// maxNonceRetries the maximum number of times a request is sent again after a "badNonce" error.
+// The server provides a fresh nonce with the "badNonce" error, it's used by the next attempt.
+const maxNonceRetries = 5
+
 // Core ACME/LE core API.
 type Core struct {
-	doer         *sender.Doer
-	nonceManager *nonces.Manager
-	jws          *secure.JWS
-	directory    acme.Directory
-	HTTPClient   *http.Client
+	doer        *sender.Doer
+	nonceSource NonceSource
+	jws         *secure.JWS
+	directory   acme.Directory
+	HTTPClient  *http.Client
+
+	// nonceRetries the number of requests sent again after a "badNonce" error.
+	// It's shared by the copies of the core (see WithContext).
+	nonceRetries *atomic.Int64
+
+	// ctx the context of the requests (see WithContext).
+	ctx context.Context
 
 	common         service // Reuse a single struct instead of allocating one for each service on the heap.
 	Accounts       *AccountService
@@ -33,31 +47,129 @@ type Core struct {
 	Orders         *OrderService
 }
 
+// Option configures the creation of a Core.
+type Option func(*options)
+
+type options struct {
+	userAgentSuffix string
+	nonceSource     NonceSource
+}
+
+// NonceSource provides the anti-replay nonces of the signed requests.
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-6.5
+type NonceSource interface {
+	Nonce() (string, error)
+}
+
+// NonceReceiver is implemented by the nonce sources which collect the nonces provided by the responses of the server
+// (the "Replay-Nonce" header).
+type NonceReceiver interface {
+	Push(nonce string)
+}
+
+// WithUserAgentSuffix sets a suffix appended to the User-Agent of the requests sent to the ACME server.
+// The suffix doesn't replace the default User-Agent, it allows an operator to identify an application.
+func WithUserAgentSuffix(suffix string) Option {
+	return func(o *options) {
+		o.userAgentSuffix = suffix
+	}
+}
+
+// WithNonceSource sets the source of the nonces of the signed requests (e.g. tests, CAs with unusual nonce behavior).
+// If the source implements NonceReceiver, it receives the nonces provided by the responses of the server.
+// By default, the nonces are collected from the responses, or fetched from the newNonce endpoint.
+func WithNonceSource(source NonceSource) Option {
+	return func(o *options) {
+		o.nonceSource = source
+	}
+}
+
 // New Creates a new Core.
-func New(httpClient *http.Client, userAgent, caDirURL, kid string, privateKey crypto.PrivateKey) (*Core, error) {
+func New(httpClient *http.Client, userAgent, caDirURL, kid string, privateKey crypto.PrivateKey, opts ...Option) (*Core, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	doer := sender.NewDoer(httpClient, userAgent)
+	doer.SetUserAgentSuffix(o.userAgentSuffix)
 
 	dir, err := getDirectory(doer, caDirURL)
 	if err != nil {
 		return nil, err
 	}
 
-	nonceManager := nonces.NewManager(doer, dir.NewNonceURL)
-
-	jws := secure.NewJWS(privateKey, kid, nonceManager)
+	var nonceSource NonceSource = nonces.NewManager(doer, dir.NewNonceURL)
+	if o.nonceSource != nil {
+		nonceSource = o.nonceSource
+	}
 
-	c := &Core{doer: doer, nonceManager: nonceManager, jws: jws, directory: dir, HTTPClient: httpClient}
+	jws := secure.NewJWS(privateKey, kid, nonceSource)
 
-	c.common.core = c
-	c.Accounts = (*AccountService)(&c.common)
-	c.Authorizations = (*AuthorizationService)(&c.common)
-	c.Certificates = (*CertificateService)(&c.common)
-	c.Challenges = (*ChallengeService)(&c.common)
-	c.Orders = (*OrderService)(&c.common)
+	c := &Core{doer: doer, nonceSource: nonceSource, jws: jws, directory: dir, HTTPClient: httpClient, nonceRetries: &atomic.Int64{}}
+	c.initServices()
 
 	return c, nil
 }
 
+// WithContext returns a copy of the core whose requests are bound to the context:
+// the pending requests (including the fetch of the nonces) and the retries are aborted when the context is canceled.
+// The copy shares the account, the nonces, and the directory of the core.
+func (a *Core) WithContext(ctx context.Context) *Core {
+	doer := a.doer.WithContext(ctx)
+
+	// a custom nonce source (WithNonceSource) can't be bound to the context.
+	nonceSource := a.nonceSource
+	if manager, ok := a.nonceSource.(*nonces.Manager); ok {
+		nonceSource = manager.WithDoer(doer)
+	}
+
+	c := &Core{
+		doer:         doer,
+		nonceSource:  nonceSource,
+		jws:          a.jws.WithNonceSource(nonceSource),
+		directory:    a.directory,
+		HTTPClient:   a.HTTPClient,
+		nonceRetries: a.nonceRetries,
+		ctx:          ctx,
+	}
+	c.initServices()
+
+	return c
+}
+
+func (a *Core) initServices() {
+	a.common.core = a
+	a.Accounts = (*AccountService)(&a.common)
+	a.Authorizations = (*AuthorizationService)(&a.common)
+	a.Certificates = (*CertificateService)(&a.common)
+	a.Challenges = (*ChallengeService)(&a.common)
+	a.Orders = (*OrderService)(&a.common)
+}
+
+// context returns the context of the requests.
+func (a *Core) context() context.Context {
+	if a.ctx == nil {
+		return context.Background()
+	}
+
+	return a.ctx
+}
+
+// RequestInfo describes an HTTP request sent to the ACME server, and its response.
+// The sensitive headers (e.g. Authorization, Cookie) are redacted.
+type RequestInfo = sender.RequestInfo
+
+// RequestHook is called after each HTTP request sent to the ACME server.
+type RequestHook = sender.Hook
+
+// SetRequestHook sets a hook called after each HTTP request sent to the ACME server.
+// The hook is called synchronously: it must not block.
+// Note: the request used by New to get the directory is not observed.
+func (a *Core) SetRequestHook(hook RequestHook) {
+	a.doer.SetHook(hook)
+}
+
 // post performs an HTTP POST request and parses the response body as JSON,
 // into the provided respBody object.
 func (a *Core) post(uri string, reqBody, response interface{}) (*http.Response, error) {
@@ -66,26 +178,50 @@ func (a *Core) post(uri string, reqBody, response interface{}) (*http.Response,
 		return nil, errors.New("failed to marshal message")
 	}
 
-	return a.retrievablePost(uri, content, response)
+	return a.retrievablePost(uri, content, response, false)
+}
+
+// postWithKey performs an HTTP POST request signed with the given key instead of the account key,
+// and parses the response body as JSON, into the provided respBody object.
+func (a *Core) postWithKey(uri string, reqBody, response interface{}, privateKey crypto.PrivateKey) (*http.Response, error) {
+	content, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, errors.New("failed to marshal message")
+	}
+
+	sign := func(uri string, content []byte) (*jose.JSONWebSignature, error) {
+		return a.jws.SignContentWithKey(uri, content, privateKey)
+	}
+
+	return a.retrievableSignedPost(uri, content, response, false, sign)
 }
 
 // postAsGet performs an HTTP POST ("POST-as-GET") request.
 // https://www.rfc-editor.org/rfc/rfc8555.html#section-6.3
 func (a *Core) postAsGet(uri string, response interface{}) (*http.Response, error) {
-	return a.retrievablePost(uri, []byte{}, response)
+	return a.retrievablePost(uri, []byte{}, response, true)
+}
+
+// retrievablePost sends a signed POST request, it's sent again after a "badNonce" error,
+// and after a transient network error if the request is idempotent (POST-as-GET).
+func (a *Core) retrievablePost(uri string, content []byte, response interface{}, idempotent bool) (*http.Response, error) {
+	return a.retrievableSignedPost(uri, content, response, idempotent, a.jws.SignContent)
 }
 
-func (a *Core) retrievablePost(uri string, content []byte, response interface{}) (*http.Response, error) {
-	// during tests, allow to support ~90% of bad nonce with a minimum of attempts.
+// signFunc signs the content of a request.
+type signFunc func(uri string, content []byte) (*jose.JSONWebSignature, error)
+
+func (a *Core) retrievableSignedPost(uri string, content []byte, response interface{}, idempotent bool, sign signFunc) (*http.Response, error) {
 	bo := backoff.NewExponentialBackOff()
 	bo.InitialInterval = 200 * time.Millisecond
 	bo.MaxInterval = 5 * time.Second
 	bo.MaxElapsedTime = 20 * time.Second
+	bo.Reset()
 
 	var resp *http.Response
 	operation := func() error {
 		var err error
-		resp, err = a.signedPost(uri, content, response)
+		resp, err = a.signedPost(uri, content, response, sign)
 		if err != nil {
 			// Retry if the nonce was invalidated
 			var e *acme.NonceError
@@ -93,6 +229,11 @@ func (a *Core) retrievablePost(uri string, content []byte, response interface{})
 				return err
 			}
 
+			// The request is signed again, with a new nonce.
+			if idempotent && sender.IsTransientError(a.context(), err) {
+				return err
+			}
+
 			return backoff.Permanent(err)
 		}
 
@@ -100,10 +241,16 @@ func (a *Core) retrievablePost(uri string, content []byte, response interface{})
 	}
 
 	notify := func(err error, duration time.Duration) {
+		var e *acme.NonceError
+		if errors.As(err, &e) {
+			a.nonceRetries.Add(1)
+		}
+
 		log.Infof("retry due to: %v", err)
 	}
 
-	err := backoff.RetryNotify(operation, bo, notify)
+	// The number of retries is bounded to avoid a loop when the server rejects all the nonces.
+	err := backoff.RetryNotify(operation, backoff.WithContext(backoff.WithMaxRetries(bo, maxNonceRetries), a.context()), notify)
 	if err != nil {
 		return resp, err
 	}
@@ -111,9 +258,14 @@ func (a *Core) retrievablePost(uri string, content []byte, response interface{})
 	return resp, nil
 }
 
-func (a *Core) signedPost(uri string, content []byte, response interface{}) (*http.Response, error) {
-	signedContent, err := a.jws.SignContent(uri, content)
+func (a *Core) signedPost(uri string, content []byte, response interface{}, sign signFunc) (*http.Response, error) {
+	signedContent, err := sign(uri, content)
 	if err != nil {
+		// go-jose doesn't wrap the error of the nonce source (e.g. the fetch of a nonce aborted by the context).
+		if ctxErr := a.context().Err(); ctxErr != nil {
+			return nil, fmt.Errorf("failed to post JWS message: failed to sign content: %w", ctxErr)
+		}
+
 		return nil, fmt.Errorf("failed to post JWS message: failed to sign content: %w", err)
 	}
 
@@ -123,8 +275,8 @@ func (a *Core) signedPost(uri string, content []byte, response interface{}) (*ht
 
 	// nonceErr is ignored to keep the root error.
 	nonce, nonceErr := nonces.GetFromResponse(resp)
-	if nonceErr == nil {
-		a.nonceManager.Push(nonce)
+	if receiver, ok := a.nonceSource.(NonceReceiver); ok && nonceErr == nil {
+		receiver.Push(nonce)
 	}
 
 	return resp, err
@@ -148,6 +300,11 @@ func (a *Core) GetDirectory() acme.Directory {
 	return a.directory
 }
 
+// NonceRetries returns the number of requests sent again after a "badNonce" error, since the creation of the Core.
+func (a *Core) NonceRetries() int64 {
+	return a.nonceRetries.Load()
+}
+
 func getDirectory(do *sender.Doer, caDirURL string) (acme.Directory, error) {
 	var dir acme.Directory
 	if _, err := do.Get(caDirURL, &dir); err != nil {
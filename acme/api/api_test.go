@@ -0,0 +1,277 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCore_retrievablePost_badNonce(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	var nonces []string
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		nonce, err := readNonce(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		nonces = append(nonces, nonce)
+
+		if len(nonces) == 1 {
+			writeBadNonce(w, "fresh")
+			return
+		}
+
+		err = tester.WriteJSONResponse(w, acme.Order{Status: acme.StatusValid})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	order, err := core.Orders.New([]string{"example.com"})
+	require.NoError(t, err)
+
+	assert.Equal(t, acme.StatusValid, order.Status)
+
+	// the nonce provided with the badNonce error is used by the retry.
+	assert.Equal(t, []string{"12345", "fresh"}, nonces)
+
+	assert.EqualValues(t, 1, core.NonceRetries())
+}
+
+func TestCore_nonceSource(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	var nonces []string
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		nonce, err := readNonce(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		nonces = append(nonces, nonce)
+
+		w.Header().Set("Replay-Nonce", "from-server")
+
+		err = tester.WriteJSONResponse(w, acme.Order{Status: acme.StatusValid})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	source := &stubNonceSource{}
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey, WithNonceSource(source))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = core.Orders.New([]string{"example.com"})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"stub-1", "stub-2"}, nonces)
+	assert.Equal(t, []string{"from-server", "from-server"}, source.pushed)
+}
+
+func TestCore_WithContext_nonce(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	unblock := make(chan struct{})
+	t.Cleanup(func() { close(unblock) })
+
+	mux.HandleFunc("/dir", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Directory{
+			NewNonceURL:   server.URL + "/nonce",
+			NewAccountURL: server.URL + "/account",
+			NewOrderURL:   server.URL + "/newOrder",
+			RevokeCertURL: server.URL + "/revokeCert",
+			KeyChangeURL:  server.URL + "/keyChange",
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	// the server doesn't provide nonces.
+	mux.HandleFunc("/nonce", func(_ http.ResponseWriter, _ *http.Request) {
+		<-unblock
+	})
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := New(http.DefaultClient, "lego-test", server.URL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	_, err = core.WithContext(ctx).Orders.New([]string{"example.com"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestCore_retrievablePost_badNonce_maxRetries(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	var attempts int
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		writeBadNonce(w, "stale")
+	})
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	_, err = core.Orders.New([]string{"example.com"})
+	require.Error(t, err)
+
+	var nonceErr *acme.NonceError
+	assert.True(t, errors.As(err, &nonceErr))
+
+	assert.Equal(t, maxNonceRetries+1, attempts)
+}
+
+func TestCore_retrievablePost_transientError(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	var orderAttempts, newOrderAttempts int
+
+	mux.HandleFunc("/order/1", func(w http.ResponseWriter, _ *http.Request) {
+		orderAttempts++
+
+		if orderAttempts == 1 {
+			resetConnection(t, w)
+			return
+		}
+
+		err := tester.WriteJSONResponse(w, acme.Order{Status: acme.StatusValid})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		newOrderAttempts++
+
+		resetConnection(t, w)
+	})
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	// POST-as-GET: sent again.
+	order, err := core.Orders.Get(apiURL + "/order/1")
+	require.NoError(t, err)
+
+	assert.Equal(t, acme.StatusValid, order.Status)
+	assert.Equal(t, 2, orderAttempts)
+
+	// POST: not sent again.
+	_, err = core.Orders.New([]string{"example.com"})
+	require.Error(t, err)
+
+	assert.Equal(t, 1, newOrderAttempts)
+}
+
+// resetConnection closes the connection without response.
+func resetConnection(t *testing.T, w http.ResponseWriter) {
+	t.Helper()
+
+	conn, _, err := w.(http.Hijacker).Hijack()
+	require.NoError(t, err)
+
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		// sends a RST instead of a FIN.
+		_ = tcpConn.SetLinger(0)
+	}
+
+	_ = conn.Close()
+}
+
+func writeBadNonce(w http.ResponseWriter, nonce string) {
+	w.Header().Set("Replay-Nonce", nonce)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	// the status of the problem document is omitted on purpose.
+	_, _ = w.Write([]byte(`{"type":"` + acme.BadNonceErr + `","detail":"JWS has an invalid anti-replay nonce"}`))
+}
+
+func readNonce(r *http.Request) (string, error) {
+	reqBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+
+	jws, err := jose.ParseSigned(string(reqBody))
+	if err != nil {
+		return "", err
+	}
+
+	return jws.Signatures[0].Protected.Nonce, nil
+}
+
+// stubNonceSource provides predictable nonces, and records the nonces provided by the server.
+type stubNonceSource struct {
+	count  int
+	pushed []string
+}
+
+func (s *stubNonceSource) Nonce() (string, error) {
+	s.count++
+	return fmt.Sprintf("stub-%d", s.count), nil
+}
+
+func (s *stubNonceSource) Push(nonce string) {
+	s.pushed = append(s.pushed, nonce)
+}
@@ -10,16 +10,25 @@ type AuthorizationService service
 
 // Get Gets an authorization.
 func (c *AuthorizationService) Get(authzURL string) (acme.Authorization, error) {
+	authz, err := c.GetExtended(authzURL)
+	if err != nil {
+		return acme.Authorization{}, err
+	}
+	return authz.Authorization, nil
+}
+
+// GetExtended Gets an authorization, with the value of the `Retry-After` header of the response.
+func (c *AuthorizationService) GetExtended(authzURL string) (acme.ExtendedAuthorization, error) {
 	if authzURL == "" {
-		return acme.Authorization{}, errors.New("authorization[get]: empty URL")
+		return acme.ExtendedAuthorization{}, errors.New("authorization[get]: empty URL")
 	}
 
 	var authz acme.Authorization
-	_, err := c.core.postAsGet(authzURL, &authz)
+	resp, err := c.core.postAsGet(authzURL, &authz)
 	if err != nil {
-		return acme.Authorization{}, err
+		return acme.ExtendedAuthorization{}, err
 	}
-	return authz, nil
+	return acme.ExtendedAuthorization{Authorization: authz, RetryAfter: getRetryAfter(resp)}, nil
 }
 
 // Deactivate Deactivates an authorization.
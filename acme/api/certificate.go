@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
@@ -61,6 +62,17 @@ func (c *CertificateService) Revoke(req acme.RevokeCertMessage) error {
 	return err
 }
 
+// RevokeWithKey Revokes a certificate, the request is signed with the private key of the certificate instead of the account key.
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.6
+func (c *CertificateService) RevokeWithKey(req acme.RevokeCertMessage, privateKey crypto.PrivateKey) error {
+	if privateKey == nil {
+		return errors.New("certificate[revoke]: the private key cannot be nil")
+	}
+
+	_, err := c.core.postWithKey(c.core.GetDirectory().RevokeCertURL, req, nil, privateKey)
+	return err
+}
+
 // get Returns the certificate and the "up" link.
 func (c *CertificateService) get(certURL string, bundle bool) (*acme.RawCertificate, http.Header, error) {
 	if certURL == "" {
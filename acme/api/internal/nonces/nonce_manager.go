@@ -13,7 +13,12 @@ import (
 type Manager struct {
 	do       *sender.Doer
 	nonceURL string
-	nonces   []string
+	pool     *pool
+}
+
+// pool the nonces collected from the responses, shared by the copies of a Manager (see WithDoer).
+type pool struct {
+	nonces []string
 	sync.Mutex
 }
 
@@ -22,28 +27,39 @@ func NewManager(do *sender.Doer, nonceURL string) *Manager {
 	return &Manager{
 		do:       do,
 		nonceURL: nonceURL,
+		pool:     &pool{},
+	}
+}
+
+// WithDoer returns a copy of the manager fetching the nonces with the doer (e.g. a doer bound to a context).
+// The copy shares the nonces of the manager.
+func (n *Manager) WithDoer(do *sender.Doer) *Manager {
+	return &Manager{
+		do:       do,
+		nonceURL: n.nonceURL,
+		pool:     n.pool,
 	}
 }
 
 // Pop Pops a nonce.
 func (n *Manager) Pop() (string, bool) {
-	n.Lock()
-	defer n.Unlock()
+	n.pool.Lock()
+	defer n.pool.Unlock()
 
-	if len(n.nonces) == 0 {
+	if len(n.pool.nonces) == 0 {
 		return "", false
 	}
 
-	nonce := n.nonces[len(n.nonces)-1]
-	n.nonces = n.nonces[:len(n.nonces)-1]
+	nonce := n.pool.nonces[len(n.pool.nonces)-1]
+	n.pool.nonces = n.pool.nonces[:len(n.pool.nonces)-1]
 	return nonce, true
 }
 
 // Push Pushes a nonce.
 func (n *Manager) Push(nonce string) {
-	n.Lock()
-	defer n.Unlock()
-	n.nonces = append(n.nonces, nonce)
+	n.pool.Lock()
+	defer n.pool.Unlock()
+	n.pool.nonces = append(n.pool.nonces, nonce)
 }
 
 // Nonce implement jose.NonceSource.
@@ -1,6 +1,7 @@
 package nonces
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -9,6 +10,8 @@ import (
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/acme/api/internal/sender"
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNotHoldingLockWhileMakingHTTPRequests(t *testing.T) {
@@ -53,3 +56,36 @@ func TestNotHoldingLockWhileMakingHTTPRequests(t *testing.T) {
 		t.Fatal("JWS is probably holding a lock while making HTTP request")
 	}
 }
+
+func TestManager_WithDoer(t *testing.T) {
+	unblock := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-unblock
+		w.Header().Set("Replay-Nonce", "12345")
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(unblock) })
+
+	manager := NewManager(sender.NewDoer(http.DefaultClient, "lego-test"), server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	bound := manager.WithDoer(sender.NewDoer(http.DefaultClient, "lego-test").WithContext(ctx))
+
+	// the nonces are shared.
+	bound.Push("pushed")
+
+	nonce, err := manager.Nonce()
+	require.NoError(t, err)
+	assert.Equal(t, "pushed", nonce)
+
+	// the fetch of a nonce is aborted with the context of the doer.
+	start := time.Now()
+
+	_, err = bound.Nonce()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
@@ -3,28 +3,43 @@ package secure
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rsa"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 
-	"github.com/go-acme/lego/v4/acme/api/internal/nonces"
 	jose "github.com/go-jose/go-jose/v3"
 )
 
 // JWS Represents a JWS.
 type JWS struct {
+	*account
+	nonces jose.NonceSource
+}
+
+// account the key of the account, shared by the copies of a JWS (see WithNonceSource).
+type account struct {
 	privKey crypto.PrivateKey
 	kid     string // Key identifier
-	nonces  *nonces.Manager
 }
 
 // NewJWS Create a new JWS.
-func NewJWS(privateKey crypto.PrivateKey, kid string, nonceManager *nonces.Manager) *JWS {
+func NewJWS(privateKey crypto.PrivateKey, kid string, nonceSource jose.NonceSource) *JWS {
 	return &JWS{
-		privKey: privateKey,
-		nonces:  nonceManager,
-		kid:     kid,
+		account: &account{privKey: privateKey, kid: kid},
+		nonces:  nonceSource,
+	}
+}
+
+// WithNonceSource returns a copy of the JWS using the nonce source.
+// The copy shares the key and the key identifier of the JWS.
+func (j *JWS) WithNonceSource(nonceSource jose.NonceSource) *JWS {
+	return &JWS{
+		account: j.account,
+		nonces:  nonceSource,
 	}
 }
 
@@ -33,23 +48,26 @@ func (j *JWS) SetKid(kid string) {
 	j.kid = kid
 }
 
+// SetPrivateKey Sets the private key used to sign the contents (e.g. after a key change).
+func (j *JWS) SetPrivateKey(privateKey crypto.PrivateKey) {
+	j.privKey = privateKey
+}
+
 // SignContent Signs a content with the JWS.
 func (j *JWS) SignContent(url string, content []byte) (*jose.JSONWebSignature, error) {
-	var alg jose.SignatureAlgorithm
-	switch k := j.privKey.(type) {
-	case *rsa.PrivateKey:
-		alg = jose.RS256
-	case *ecdsa.PrivateKey:
-		if k.Curve == elliptic.P256() {
-			alg = jose.ES256
-		} else if k.Curve == elliptic.P384() {
-			alg = jose.ES384
-		}
-	}
+	return j.signContent(url, content, j.privKey, j.kid)
+}
+
+// SignContentWithKey Signs a content with the given key instead of the account key (e.g. the key of a certificate).
+// The JWS contains the public key (jwk) instead of the key identifier (kid).
+func (j *JWS) SignContentWithKey(url string, content []byte, privateKey crypto.PrivateKey) (*jose.JSONWebSignature, error) {
+	return j.signContent(url, content, privateKey, "")
+}
 
+func (j *JWS) signContent(url string, content []byte, privateKey crypto.PrivateKey, kid string) (*jose.JSONWebSignature, error) {
 	signKey := jose.SigningKey{
-		Algorithm: alg,
-		Key:       jose.JSONWebKey{Key: j.privKey, KeyID: j.kid},
+		Algorithm: signatureAlgorithm(privateKey),
+		Key:       jose.JSONWebKey{Key: privateKey, KeyID: kid},
 	}
 
 	options := jose.SignerOptions{
@@ -59,7 +77,7 @@ func (j *JWS) SignContent(url string, content []byte) (*jose.JSONWebSignature, e
 		},
 	}
 
-	if j.kid == "" {
+	if kid == "" {
 		options.EmbedJWK = true
 	}
 
@@ -75,6 +93,51 @@ func (j *JWS) SignContent(url string, content []byte) (*jose.JSONWebSignature, e
 	return signed, nil
 }
 
+// SignKeyChange Signs the inner JWS of a key change with the new key.
+// The payload contains the account URL (key identifier) and the current public key.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.3.5
+func (j *JWS) SignKeyChange(url string, newKey crypto.PrivateKey) (*jose.JSONWebSignature, error) {
+	if j.kid == "" {
+		return nil, errors.New("missing key identifier (account URL)")
+	}
+
+	oldKey := &jose.JSONWebKey{Key: j.privKey}
+
+	payload := struct {
+		Account string          `json:"account"`
+		OldKey  jose.JSONWebKey `json:"oldKey"`
+	}{
+		Account: j.kid,
+		OldKey:  oldKey.Public(),
+	}
+
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal key change content: %w", err)
+	}
+
+	// The inner JWS has no nonce, and embeds the new key.
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: signatureAlgorithm(newKey), Key: newKey},
+		&jose.SignerOptions{
+			EmbedJWK: true,
+			ExtraHeaders: map[jose.HeaderKey]interface{}{
+				"url": url,
+			},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key change jose signer: %w", err)
+	}
+
+	signed, err := signer.Sign(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign key change content: %w", err)
+	}
+
+	return signed, nil
+}
+
 // SignEABContent Signs an external account binding content with the JWS.
 func (j *JWS) SignEABContent(url, kid string, hmac []byte) (*jose.JSONWebSignature, error) {
 	jwk := jose.JSONWebKey{Key: j.privKey}
@@ -113,6 +176,8 @@ func (j *JWS) GetKeyAuthorization(token string) (string, error) {
 		publicKey = k.Public()
 	case *rsa.PrivateKey:
 		publicKey = k.Public()
+	case ed25519.PrivateKey:
+		publicKey = k.Public()
 	}
 
 	// Generate the Key Authorization for the challenge
@@ -128,3 +193,24 @@ func (j *JWS) GetKeyAuthorization(token string) (string, error) {
 
 	return token + "." + keyThumb, nil
 }
+
+// signatureAlgorithm returns the signature algorithm to use with the private key.
+func signatureAlgorithm(privateKey crypto.PrivateKey) jose.SignatureAlgorithm {
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return jose.RS256
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jose.ES256
+		case elliptic.P384():
+			return jose.ES384
+		case elliptic.P521():
+			return jose.ES512
+		}
+	case ed25519.PrivateKey:
+		return jose.EdDSA
+	}
+
+	return ""
+}
@@ -1,6 +1,12 @@
 package secure
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,6 +16,9 @@ import (
 	"github.com/go-acme/lego/v4/acme/api/internal/nonces"
 	"github.com/go-acme/lego/v4/acme/api/internal/sender"
 	"github.com/go-acme/lego/v4/platform/tester"
+	jose "github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNotHoldingLockWhileMakingHTTPRequests(t *testing.T) {
@@ -54,3 +63,75 @@ func TestNotHoldingLockWhileMakingHTTPRequests(t *testing.T) {
 		t.Fatal("JWS is probably holding a lock while making HTTP request")
 	}
 }
+
+func TestJWS_SignContent(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		generate func() (crypto.Signer, error)
+		expected jose.SignatureAlgorithm
+	}{
+		{
+			desc:     "RSA",
+			generate: func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 2048) },
+			expected: jose.RS256,
+		},
+		{
+			desc:     "P256",
+			generate: func() (crypto.Signer, error) { return ecdsa.GenerateKey(elliptic.P256(), rand.Reader) },
+			expected: jose.ES256,
+		},
+		{
+			desc:     "P384",
+			generate: func() (crypto.Signer, error) { return ecdsa.GenerateKey(elliptic.P384(), rand.Reader) },
+			expected: jose.ES384,
+		},
+		{
+			desc:     "P521",
+			generate: func() (crypto.Signer, error) { return ecdsa.GenerateKey(elliptic.P521(), rand.Reader) },
+			expected: jose.ES512,
+		},
+		{
+			desc: "Ed25519",
+			generate: func() (crypto.Signer, error) {
+				_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+				return privateKey, err
+			},
+			expected: jose.EdDSA,
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Replay-Nonce", "12345")
+	}))
+	t.Cleanup(server.Close)
+
+	doer := sender.NewDoer(http.DefaultClient, "lego-test")
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			privateKey, err := test.generate()
+			require.NoError(t, err)
+
+			j := NewJWS(privateKey, "", nonces.NewManager(doer, server.URL))
+
+			content, err := j.SignContent("https://example.com/acme/new-order", []byte(`{"foo":"bar"}`))
+			require.NoError(t, err)
+
+			signed, err := jose.ParseSigned(content.FullSerialize())
+			require.NoError(t, err)
+
+			require.Len(t, signed.Signatures, 1)
+			assert.Equal(t, string(test.expected), signed.Signatures[0].Header.Algorithm)
+
+			payload, err := signed.Verify(privateKey.Public())
+			require.NoError(t, err)
+
+			assert.Equal(t, `{"foo":"bar"}`, string(payload))
+
+			keyAuth, err := j.GetKeyAuthorization("token")
+			require.NoError(t, err)
+
+			assert.Regexp(t, `^token\.[\w-]{43}$`, keyAuth)
+		})
+	}
+}
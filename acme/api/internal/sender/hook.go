@@ -0,0 +1,72 @@
+package sender
+
+import (
+	"net/http"
+	"time"
+)
+
+// redacted is the value of the redacted headers.
+const redacted = "REDACTED"
+
+// sensitiveHeaders the headers redacted before calling a Hook.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// RequestInfo describes an HTTP request sent to the ACME server, and its response.
+// The sensitive headers are redacted.
+type RequestInfo struct {
+	Method         string
+	URL            string
+	RequestHeader  http.Header
+	StatusCode     int // 0 if the request failed.
+	ResponseHeader http.Header
+	Duration       time.Duration
+	Err            error // the transport error, if any.
+}
+
+// Hook is called after each HTTP request sent to the ACME server.
+type Hook func(info RequestInfo)
+
+// SetHook sets the hook called after each HTTP request.
+func (d *Doer) SetHook(hook Hook) {
+	d.hook = hook
+}
+
+func (d *Doer) callHook(req *http.Request, resp *http.Response, start time.Time, err error) {
+	if d.hook == nil {
+		return
+	}
+
+	info := RequestInfo{
+		Method:        req.Method,
+		URL:           req.URL.String(),
+		RequestHeader: redactHeader(req.Header),
+		Duration:      time.Since(start),
+		Err:           err,
+	}
+
+	if resp != nil {
+		info.StatusCode = resp.StatusCode
+		info.ResponseHeader = redactHeader(resp.Header)
+	}
+
+	d.hook(info)
+}
+
+func redactHeader(header http.Header) http.Header {
+	h := header.Clone()
+
+	for _, name := range sensitiveHeaders {
+		if _, ok := h[name]; ok {
+			h.Set(name, redacted)
+		}
+	}
+
+	return h
+}
@@ -1,14 +1,18 @@
 package sender
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"runtime"
 	"strings"
+	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/log"
 )
 
 type RequestOption func(*http.Request) error
@@ -21,8 +25,11 @@ func contentType(ct string) RequestOption {
 }
 
 type Doer struct {
-	httpClient *http.Client
-	userAgent  string
+	httpClient      *http.Client
+	userAgent       string
+	userAgentSuffix string
+	hook            Hook
+	ctx             context.Context
 }
 
 // NewDoer Creates a new Doer.
@@ -33,6 +40,14 @@ func NewDoer(client *http.Client, userAgent string) *Doer {
 	}
 }
 
+// WithContext returns a copy of the Doer whose requests are bound to the context.
+func (d *Doer) WithContext(ctx context.Context) *Doer {
+	dc := *d
+	dc.ctx = ctx
+
+	return &dc
+}
+
 // Get performs a GET request with a proper User-Agent string.
 // If "response" is not provided, callers should close resp.Body when done reading from it.
 func (d *Doer) Get(url string, response interface{}) (*http.Response, error) {
@@ -67,7 +82,12 @@ func (d *Doer) Post(url string, body io.Reader, bodyType string, response interf
 }
 
 func (d *Doer) newRequest(method, uri string, body io.Reader, opts ...RequestOption) (*http.Request, error) {
-	req, err := http.NewRequest(method, uri, body)
+	ctx := d.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, uri, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -85,11 +105,15 @@ func (d *Doer) newRequest(method, uri string, body io.Reader, opts ...RequestOpt
 }
 
 func (d *Doer) do(req *http.Request, response interface{}) (*http.Response, error) {
-	resp, err := d.httpClient.Do(req)
+	start := time.Now()
+
+	resp, err := d.send(req)
 	if err != nil {
 		return nil, err
 	}
 
+	log.Debugf("acme: %s %s: %s (%s)", req.Method, req.URL, resp.Status, time.Since(start))
+
 	if err = checkError(req, resp); err != nil {
 		return resp, err
 	}
@@ -111,9 +135,62 @@ func (d *Doer) do(req *http.Request, response interface{}) (*http.Response, erro
 	return resp, nil
 }
 
+// send sends the request.
+// The idempotent requests (GET and HEAD) are sent again, with a backoff, after a transient network error.
+func (d *Doer) send(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return d.sendOnce(req)
+	}
+
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = transientRetryInterval
+	bo.MaxElapsedTime = 0
+	bo.Reset()
+
+	var resp *http.Response
+
+	operation := func() error {
+		var err error
+		resp, err = d.sendOnce(req)
+		if err != nil && IsTransientError(req.Context(), err) {
+			return err
+		}
+
+		return backoff.Permanent(err)
+	}
+
+	notify := func(err error, _ time.Duration) {
+		log.Infof("retry due to: %v", err)
+	}
+
+	b := backoff.WithContext(backoff.WithMaxRetries(bo, MaxTransientRetries), req.Context())
+
+	err := backoff.RetryNotify(operation, b, notify)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (d *Doer) sendOnce(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	resp, err := d.httpClient.Do(req)
+
+	d.callHook(req, resp, start, err)
+
+	return resp, err
+}
+
+// SetUserAgentSuffix sets a suffix appended to the User-Agent string.
+func (d *Doer) SetUserAgentSuffix(suffix string) {
+	d.userAgentSuffix = suffix
+}
+
 // formatUserAgent builds and returns the User-Agent string to use in requests.
 func (d *Doer) formatUserAgent() string {
-	ua := fmt.Sprintf("%s %s (%s; %s; %s)", d.userAgent, ourUserAgent, ourUserAgentComment, runtime.GOOS, runtime.GOARCH)
+	ua := fmt.Sprintf("%s %s (%s; %s; %s) %s", d.userAgent, ourUserAgent, ourUserAgentComment, runtime.GOOS, runtime.GOARCH, d.userAgentSuffix)
 	return strings.TrimSpace(ua)
 }
 
@@ -134,7 +211,8 @@ func checkError(req *http.Request, resp *http.Response) error {
 		errorDetails.URL = req.URL.String()
 
 		// Check for errors we handle specifically
-		if errorDetails.HTTPStatus == http.StatusBadRequest && errorDetails.Type == acme.BadNonceErr {
+		// The status of the problem document can be missing: the status of the response is used.
+		if errorDetails.Type == acme.BadNonceErr && (errorDetails.HTTPStatus == http.StatusBadRequest || (errorDetails.HTTPStatus == 0 && resp.StatusCode == http.StatusBadRequest)) {
 			return &acme.NonceError{ProblemDetails: errorDetails}
 		}
 
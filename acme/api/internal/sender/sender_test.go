@@ -1,15 +1,29 @@
 package sender
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
 
+	"github.com/go-acme/lego/v4/acme"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+func init() {
+	// keeps the tests of the transient errors fast.
+	transientRetryInterval = 10 * time.Millisecond
+}
+
 func TestDo_UserAgentOnAllHTTPMethod(t *testing.T) {
 	var ua, method string
 	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
@@ -65,3 +79,314 @@ func TestDo_CustomUserAgent(t *testing.T) {
 	}
 	assert.Len(t, strings.Split(ua, " "), 5)
 }
+
+func TestDo_UserAgentSuffix(t *testing.T) {
+	var ua string
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		ua = r.Header.Get("User-Agent")
+	}))
+	t.Cleanup(server.Close)
+
+	doer := NewDoer(http.DefaultClient, "MyApp/1.2.3")
+	doer.SetUserAgentSuffix("operator/42")
+
+	_, err := doer.Get(server.URL, nil)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(ua, "MyApp/1.2.3 "+ourUserAgent+" ("), "User-Agent: %s", ua)
+	assert.True(t, strings.HasSuffix(ua, ") operator/42"), "User-Agent: %s", ua)
+}
+
+func TestDo_hook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("Replay-Nonce", "12345")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(server.Close)
+
+	var infos []RequestInfo
+
+	doer := NewDoer(http.DefaultClient, "")
+	doer.SetHook(func(info RequestInfo) {
+		infos = append(infos, info)
+	})
+
+	req, err := doer.newRequest(http.MethodPost, server.URL, strings.NewReader("{}"), contentType("application/jose+json"))
+	require.NoError(t, err)
+
+	req.Header.Set("Authorization", "Bearer secret")
+
+	_, err = doer.do(req, nil)
+	require.NoError(t, err)
+
+	require.Len(t, infos, 1)
+
+	info := infos[0]
+	assert.Equal(t, http.MethodPost, info.Method)
+	assert.Equal(t, server.URL, info.URL)
+	assert.Equal(t, http.StatusCreated, info.StatusCode)
+	assert.NoError(t, info.Err)
+
+	assert.Equal(t, "REDACTED", info.RequestHeader.Get("Authorization"))
+	assert.Equal(t, "application/jose+json", info.RequestHeader.Get("Content-Type"))
+	assert.Equal(t, "REDACTED", info.ResponseHeader.Get("Set-Cookie"))
+	assert.Equal(t, "12345", info.ResponseHeader.Get("Replay-Nonce"))
+
+	// the headers of the request must not be modified.
+	assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+}
+
+func TestDo_problemDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{
+  "type": "urn:ietf:params:acme:error:rejectedIdentifier",
+  "detail": "Some of the identifiers requested were rejected",
+  "status": 403,
+  "subproblems": [
+    {
+      "type": "urn:ietf:params:acme:error:malformed",
+      "detail": "Invalid underscore in DNS name \"_example.org\"",
+      "identifier": {"type": "dns", "value": "_example.org"}
+    },
+    {
+      "type": "urn:ietf:params:acme:error:rejectedIdentifier",
+      "detail": "This CA will not issue for \"example.net\"",
+      "identifier": {"type": "dns", "value": "example.net"}
+    }
+  ]
+}`))
+	}))
+	t.Cleanup(server.Close)
+
+	doer := NewDoer(server.Client(), "")
+
+	_, err := doer.Post(server.URL, strings.NewReader("{}"), "application/jose+json", nil)
+	require.Error(t, err)
+
+	var problem *acme.ProblemDetails
+	require.ErrorAs(t, err, &problem)
+
+	assert.Equal(t, "urn:ietf:params:acme:error:rejectedIdentifier", problem.Type)
+	assert.Equal(t, "Some of the identifiers requested were rejected", problem.Detail)
+	assert.Equal(t, http.StatusForbidden, problem.HTTPStatus)
+	assert.Equal(t, http.MethodPost, problem.Method)
+
+	expected := []acme.SubProblem{
+		{
+			Type:       acme.MalformedErr,
+			Detail:     `Invalid underscore in DNS name "_example.org"`,
+			Identifier: acme.Identifier{Type: "dns", Value: "_example.org"},
+		},
+		{
+			Type:       "urn:ietf:params:acme:error:rejectedIdentifier",
+			Detail:     `This CA will not issue for "example.net"`,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.net"},
+		},
+	}
+	assert.Equal(t, expected, problem.SubProblems)
+
+	assert.True(t, problem.HasSubProblem(acme.MalformedErr))
+	assert.False(t, problem.HasSubProblem(acme.BadCSRErr))
+
+	var retryable acme.RetryableError
+	require.ErrorAs(t, err, &retryable)
+	assert.False(t, retryable.Retryable())
+}
+
+func TestDo_problemDetails_nonce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type":"urn:ietf:params:acme:error:badNonce","detail":"JWS has an invalid anti-replay nonce","status":400}`))
+	}))
+	t.Cleanup(server.Close)
+
+	doer := NewDoer(server.Client(), "")
+
+	_, err := doer.Post(server.URL, strings.NewReader("{}"), "application/jose+json", nil)
+	require.Error(t, err)
+
+	var nonceErr *acme.NonceError
+	require.ErrorAs(t, err, &nonceErr)
+
+	// the problem document is reachable through the typed error.
+	var problem *acme.ProblemDetails
+	require.True(t, errors.As(err, &problem))
+	assert.Equal(t, acme.BadNonceErr, problem.Type)
+
+	assert.True(t, nonceErr.Retryable())
+}
+
+func TestDo_transientError(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		call             func(doer *Doer, u string) (*http.Response, error)
+		expectedAttempts int
+		expectedErr      bool
+	}{
+		{
+			desc: "GET",
+			call: func(doer *Doer, u string) (*http.Response, error) {
+				return doer.Get(u, nil)
+			},
+			expectedAttempts: 2,
+		},
+		{
+			desc: "HEAD",
+			call: func(doer *Doer, u string) (*http.Response, error) {
+				return doer.Head(u)
+			},
+			expectedAttempts: 2,
+		},
+		{
+			desc: "POST",
+			call: func(doer *Doer, u string) (*http.Response, error) {
+				return doer.Post(u, strings.NewReader("falalalala"), "text/plain", nil)
+			},
+			expectedAttempts: 1,
+			expectedErr:      true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			var attempts atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if attempts.Add(1) == 1 {
+					resetConnection(t, w)
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+			t.Cleanup(server.Close)
+
+			doer := NewDoer(server.Client(), "")
+
+			resp, err := test.call(doer, server.URL)
+
+			assert.Equal(t, test.expectedAttempts, int(attempts.Load()))
+
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestDo_transientError_maxRetries(t *testing.T) {
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+
+		resetConnection(t, w)
+	}))
+	t.Cleanup(server.Close)
+
+	doer := NewDoer(server.Client(), "")
+
+	_, err := doer.Get(server.URL, nil)
+	require.Error(t, err)
+
+	assert.Equal(t, MaxTransientRetries+1, int(attempts.Load()))
+}
+
+func TestIsTransientError(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		desc     string
+		ctx      context.Context
+		err      error
+		expected assert.BoolAssertionFunc
+	}{
+		{
+			desc:     "nil",
+			ctx:      context.Background(),
+			expected: assert.False,
+		},
+		{
+			desc:     "connection reset",
+			ctx:      context.Background(),
+			err:      &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}},
+			expected: assert.True,
+		},
+		{
+			desc:     "unexpected EOF",
+			ctx:      context.Background(),
+			err:      &url.Error{Op: "Get", URL: "https://example.com", Err: io.EOF},
+			expected: assert.True,
+		},
+		{
+			desc:     "timeout",
+			ctx:      context.Background(),
+			err:      &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{Op: "dial", Err: timeoutError{}}},
+			expected: assert.True,
+		},
+		{
+			desc:     "connection refused",
+			ctx:      context.Background(),
+			err:      &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}},
+			expected: assert.False,
+		},
+		{
+			desc:     "DNS error",
+			ctx:      context.Background(),
+			err:      &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}}},
+			expected: assert.False,
+		},
+		{
+			desc:     "ACME error",
+			ctx:      context.Background(),
+			err:      &acme.ProblemDetails{Type: acme.BadNonceErr},
+			expected: assert.False,
+		},
+		{
+			desc:     "canceled context",
+			ctx:      canceled,
+			err:      &url.Error{Op: "Get", URL: "https://example.com", Err: context.Canceled},
+			expected: assert.False,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.expected(t, IsTransientError(test.ctx, test.err))
+		})
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// resetConnection closes the connection without response.
+func resetConnection(t *testing.T, w http.ResponseWriter) {
+	t.Helper()
+
+	conn, _, err := w.(http.Hijacker).Hijack()
+	require.NoError(t, err)
+
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		// sends a RST instead of a FIN.
+		_ = tcpConn.SetLinger(0)
+	}
+
+	_ = conn.Close()
+}
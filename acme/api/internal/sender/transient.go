@@ -0,0 +1,38 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+)
+
+// MaxTransientRetries the maximum number of times an idempotent request is sent again after a transient network error.
+const MaxTransientRetries = 3
+
+// transientRetryInterval the initial interval between two attempts after a transient network error.
+var transientRetryInterval = 500 * time.Millisecond
+
+// IsTransientError returns true if the error is a transient network error:
+// a timeout (e.g. TLS handshake timeout), a connection reset, a broken pipe, or an unexpected EOF.
+// The ACME errors, and the errors caused by the cancellation of the context, are not transient.
+func IsTransientError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	// e.g. the TLS handshake timeout, the dial timeout.
+	// The other network errors (e.g. connection refused, DNS errors, TLS failures) are not transient.
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
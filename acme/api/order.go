@@ -3,21 +3,76 @@ package api
 import (
 	"encoding/base64"
 	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-acme/lego/v4/acme"
 )
 
+// OrderOptions the options of a new order.
+type OrderOptions struct {
+	// ReplacesCertID the ARI certificate identifier of the certificate replaced by the order.
+	// - https://datatracker.ietf.org/doc/draft-ietf-acme-ari/
+	ReplacesCertID string
+
+	// Profile the name of the certificate profile to use.
+	// The profile must be advertised by the ACME server in the directory meta profiles.
+	// - https://datatracker.ietf.org/doc/draft-aaron-acme-profiles/
+	Profile string
+
+	// AutoRenewal the Short-Term Automatic Renewal (STAR) settings of the order,
+	// the ACME server must advertise the auto-renewal capabilities in the directory meta.
+	// - https://www.rfc-editor.org/rfc/rfc8739.html
+	AutoRenewal *acme.AutoRenewal
+}
+
 type OrderService service
 
 // New Creates a new order.
 func (o *OrderService) New(domains []string) (acme.ExtendedOrder, error) {
+	return o.NewWithOptions(domains, nil)
+}
+
+// NewWithOptions Creates a new order with options.
+func (o *OrderService) NewWithOptions(domains []string, opts *OrderOptions) (acme.ExtendedOrder, error) {
 	var identifiers []acme.Identifier
 	for _, domain := range domains {
+		// https://www.rfc-editor.org/rfc/rfc8738.html
+		if ip := net.ParseIP(domain); ip != nil {
+			identifiers = append(identifiers, acme.Identifier{Type: "ip", Value: ip.String()})
+			continue
+		}
+
 		identifiers = append(identifiers, acme.Identifier{Type: "dns", Value: domain})
 	}
 
 	orderReq := acme.Order{Identifiers: identifiers}
 
+	if opts != nil {
+		orderReq.Replaces = opts.ReplacesCertID
+
+		if opts.Profile != "" {
+			err := o.checkProfile(opts.Profile)
+			if err != nil {
+				return acme.ExtendedOrder{}, err
+			}
+
+			orderReq.Profile = opts.Profile
+		}
+
+		if opts.AutoRenewal != nil {
+			err := o.checkAutoRenewal(opts.AutoRenewal)
+			if err != nil {
+				return acme.ExtendedOrder{}, err
+			}
+
+			orderReq.AutoRenewal = opts.AutoRenewal
+		}
+	}
+
 	var order acme.Order
 	resp, err := o.core.post(o.core.GetDirectory().NewOrderURL, orderReq, &order)
 	if err != nil {
@@ -30,6 +85,64 @@ func (o *OrderService) New(domains []string) (acme.ExtendedOrder, error) {
 	}, nil
 }
 
+// checkProfile checks that the profile is advertised by the ACME server.
+func (o *OrderService) checkProfile(profile string) error {
+	profiles := o.core.GetDirectory().Meta.Profiles
+	if len(profiles) == 0 {
+		return fmt.Errorf("order[new]: the profile %q is not available: the ACME server doesn't advertise any profile", profile)
+	}
+
+	if _, ok := profiles[profile]; ok {
+		return nil
+	}
+
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return fmt.Errorf("order[new]: the profile %q is not available, the profiles offered by the ACME server are: %s", profile, strings.Join(names, ", "))
+}
+
+// checkAutoRenewal checks that the auto-renewal settings are allowed by the capabilities of the ACME server.
+func (o *OrderService) checkAutoRenewal(autoRenewal *acme.AutoRenewal) error {
+	meta := o.core.GetDirectory().Meta.AutoRenewal
+	if meta == nil {
+		return errors.New("order[new]: the ACME server doesn't support the auto-renewal of the certificates (STAR)")
+	}
+
+	if autoRenewal.Lifetime < meta.MinLifetime {
+		return fmt.Errorf("order[new]: the auto-renewal lifetime (%ds) is lower than the minimum lifetime of the ACME server (%ds)",
+			autoRenewal.Lifetime, meta.MinLifetime)
+	}
+
+	if autoRenewal.AllowCertificateGet && !meta.AllowCertificateGet {
+		return errors.New("order[new]: the ACME server doesn't allow the retrieval of the auto-renewed certificates with GET requests")
+	}
+
+	end, err := time.Parse(time.RFC3339, autoRenewal.EndDate)
+	if err != nil {
+		return fmt.Errorf("order[new]: invalid auto-renewal end date: %w", err)
+	}
+
+	start := time.Now()
+	if autoRenewal.StartDate != "" {
+		start, err = time.Parse(time.RFC3339, autoRenewal.StartDate)
+		if err != nil {
+			return fmt.Errorf("order[new]: invalid auto-renewal start date: %w", err)
+		}
+	}
+
+	if meta.MaxDuration > 0 && end.Sub(start) > time.Duration(meta.MaxDuration)*time.Second {
+		return fmt.Errorf("order[new]: the auto-renewal duration (%s) is greater than the maximum duration of the ACME server (%ds)",
+			end.Sub(start).Round(time.Second), meta.MaxDuration)
+	}
+
+	return nil
+}
+
 // Get Gets an order.
 func (o *OrderService) Get(orderURL string) (acme.ExtendedOrder, error) {
 	if orderURL == "" {
@@ -37,12 +150,12 @@ func (o *OrderService) Get(orderURL string) (acme.ExtendedOrder, error) {
 	}
 
 	var order acme.Order
-	_, err := o.core.postAsGet(orderURL, &order)
+	resp, err := o.core.postAsGet(orderURL, &order)
 	if err != nil {
 		return acme.ExtendedOrder{}, err
 	}
 
-	return acme.ExtendedOrder{Order: order}, nil
+	return acme.ExtendedOrder{Order: order, RetryAfter: getRetryAfter(resp)}, nil
 }
 
 // UpdateForCSR Updates an order for a CSR.
@@ -52,7 +165,7 @@ func (o *OrderService) UpdateForCSR(orderURL string, csr []byte) (acme.ExtendedO
 	}
 
 	var order acme.Order
-	_, err := o.core.post(orderURL, csrMsg, &order)
+	resp, err := o.core.post(orderURL, csrMsg, &order)
 	if err != nil {
 		return acme.ExtendedOrder{}, err
 	}
@@ -61,5 +174,26 @@ func (o *OrderService) UpdateForCSR(orderURL string, csr []byte) (acme.ExtendedO
 		return acme.ExtendedOrder{}, order.Error
 	}
 
-	return acme.ExtendedOrder{Order: order}, nil
+	return acme.ExtendedOrder{Order: order, RetryAfter: getRetryAfter(resp)}, nil
+}
+
+// Cancel Cancels the auto-renewal of a STAR order: the server stops issuing new certificates for the order.
+// - https://www.rfc-editor.org/rfc/rfc8739.html#section-3.1.2
+func (o *OrderService) Cancel(orderURL string) (acme.ExtendedOrder, error) {
+	if orderURL == "" {
+		return acme.ExtendedOrder{}, errors.New("order[cancel]: empty URL")
+	}
+
+	// only the status is sent: the identifiers of the order cannot be updated.
+	cancelReq := struct {
+		Status string `json:"status"`
+	}{Status: acme.StatusCanceled}
+
+	var order acme.Order
+	_, err := o.core.post(orderURL, cancelReq, &order)
+	if err != nil {
+		return acme.ExtendedOrder{}, err
+	}
+
+	return acme.ExtendedOrder{Order: order, Location: orderURL}, nil
 }
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/platform/tester"
@@ -66,6 +68,389 @@ func TestOrderService_New(t *testing.T) {
 	assert.Equal(t, expected, order)
 }
 
+func TestOrderService_NewWithOptions(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := readSignedBody(r, privateKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		order := acme.Order{}
+		err = json.Unmarshal(body, &order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusValid,
+			Identifiers: order.Identifiers,
+			Replaces:    order.Replaces,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	order, err := core.Orders.NewWithOptions([]string{"example.com"}, &OrderOptions{ReplacesCertID: "aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE"})
+	require.NoError(t, err)
+
+	expected := acme.ExtendedOrder{
+		Order: acme.Order{
+			Status:      "valid",
+			Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}},
+			Replaces:    "aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE",
+		},
+	}
+	assert.Equal(t, expected, order)
+}
+
+func TestOrderService_New_ipIdentifiers(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	var identifiers []acme.Identifier
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		body, err := readSignedBody(r, privateKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		order := acme.Order{}
+		err = json.Unmarshal(body, &order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		identifiers = order.Identifiers
+
+		err = tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusPending,
+			Identifiers: order.Identifiers,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	_, err = core.Orders.New([]string{"example.com", "192.0.2.1", "2001:DB8:0:0::1"})
+	require.NoError(t, err)
+
+	expected := []acme.Identifier{
+		{Type: "dns", Value: "example.com"},
+		{Type: "ip", Value: "192.0.2.1"},
+		{Type: "ip", Value: "2001:db8::1"},
+	}
+	assert.Equal(t, expected, identifiers)
+}
+
+func TestOrderService_New_wildcardOnly(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	var identifiers []acme.Identifier
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		body, err := readSignedBody(r, privateKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		order := acme.Order{}
+		err = json.Unmarshal(body, &order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		identifiers = order.Identifiers
+
+		err = tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusPending,
+			Identifiers: order.Identifiers,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	_, err = core.Orders.New([]string{"*.example.com"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []acme.Identifier{{Type: "dns", Value: "*.example.com"}}, identifiers)
+}
+
+func TestOrderService_NewWithOptions_profile(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/dir", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Directory{
+			NewNonceURL:   server.URL + "/nonce",
+			NewAccountURL: server.URL + "/account",
+			NewOrderURL:   server.URL + "/newOrder",
+			Meta: acme.Meta{
+				Profiles: map[string]string{
+					"classic":    "The profile you're accustomed to",
+					"shortlived": "A short-lived certificate profile",
+				},
+			},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/nonce", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Replay-Nonce", "12345")
+	})
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		body, err := readSignedBody(r, privateKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		order := acme.Order{}
+		err = json.Unmarshal(body, &order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusPending,
+			Identifiers: order.Identifiers,
+			Profile:     order.Profile,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := New(http.DefaultClient, "lego-test", server.URL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	order, err := core.Orders.NewWithOptions([]string{"example.com"}, &OrderOptions{Profile: "shortlived"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "shortlived", order.Profile)
+
+	_, err = core.Orders.NewWithOptions([]string{"example.com"}, &OrderOptions{Profile: "tlsserver"})
+	require.EqualError(t, err, `order[new]: the profile "tlsserver" is not available, the profiles offered by the ACME server are: classic, shortlived`)
+}
+
+func TestOrderService_NewWithOptions_profileNotSupported(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	_, err = core.Orders.NewWithOptions([]string{"example.com"}, &OrderOptions{Profile: "shortlived"})
+	require.EqualError(t, err, `order[new]: the profile "shortlived" is not available: the ACME server doesn't advertise any profile`)
+}
+
+func TestOrderService_NewWithOptions_autoRenewal(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/dir", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Directory{
+			NewNonceURL:   server.URL + "/nonce",
+			NewAccountURL: server.URL + "/account",
+			NewOrderURL:   server.URL + "/newOrder",
+			Meta: acme.Meta{
+				AutoRenewal: &acme.AutoRenewalMeta{MinLifetime: 86400, MaxDuration: 31536000},
+			},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/nonce", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Replay-Nonce", "12345")
+	})
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		body, err := readSignedBody(r, privateKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		order := acme.Order{}
+		err = json.Unmarshal(body, &order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusPending,
+			Identifiers: order.Identifiers,
+			AutoRenewal: order.AutoRenewal,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := New(http.DefaultClient, "lego-test", server.URL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	endDate := time.Now().Add(30 * 24 * time.Hour).UTC().Format(time.RFC3339)
+
+	testCases := []struct {
+		desc        string
+		autoRenewal *acme.AutoRenewal
+		expectedErr string
+	}{
+		{
+			desc:        "valid",
+			autoRenewal: &acme.AutoRenewal{EndDate: endDate, Lifetime: 4 * 86400},
+		},
+		{
+			desc:        "lifetime lower than the minimum",
+			autoRenewal: &acme.AutoRenewal{EndDate: endDate, Lifetime: 3600},
+			expectedErr: "order[new]: the auto-renewal lifetime (3600s) is lower than the minimum lifetime of the ACME server (86400s)",
+		},
+		{
+			desc:        "duration greater than the maximum",
+			autoRenewal: &acme.AutoRenewal{StartDate: "2030-01-01T00:00:00Z", EndDate: "2032-01-01T00:00:00Z", Lifetime: 86400},
+			expectedErr: "order[new]: the auto-renewal duration (17520h0m0s) is greater than the maximum duration of the ACME server (31536000s)",
+		},
+		{
+			desc:        "GET not allowed",
+			autoRenewal: &acme.AutoRenewal{EndDate: endDate, Lifetime: 86400, AllowCertificateGet: true},
+			expectedErr: "order[new]: the ACME server doesn't allow the retrieval of the auto-renewed certificates with GET requests",
+		},
+		{
+			desc:        "invalid end date",
+			autoRenewal: &acme.AutoRenewal{EndDate: "tomorrow", Lifetime: 86400},
+			expectedErr: `order[new]: invalid auto-renewal end date: parsing time "tomorrow" as "2006-01-02T15:04:05Z07:00": cannot parse "tomorrow" as "2006"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			order, err := core.Orders.NewWithOptions([]string{"example.com"}, &OrderOptions{AutoRenewal: test.autoRenewal})
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, test.autoRenewal, order.AutoRenewal)
+		})
+	}
+}
+
+func TestOrderService_NewWithOptions_autoRenewalNotSupported(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	_, err = core.Orders.NewWithOptions([]string{"example.com"}, &OrderOptions{AutoRenewal: &acme.AutoRenewal{Lifetime: 86400}})
+	require.EqualError(t, err, "order[new]: the ACME server doesn't support the auto-renewal of the certificates (STAR)")
+}
+
+func TestOrderService_Cancel(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	mux.HandleFunc("/order/1", func(w http.ResponseWriter, r *http.Request) {
+		body, err := readSignedBody(r, privateKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if string(body) != `{"status":"canceled"}` {
+			http.Error(w, "unexpected body: "+string(body), http.StatusBadRequest)
+			return
+		}
+
+		err = tester.WriteJSONResponse(w, acme.Order{Status: acme.StatusCanceled})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	order, err := core.Orders.Cancel(apiURL + "/order/1")
+	require.NoError(t, err)
+
+	assert.Equal(t, acme.StatusCanceled, order.Status)
+	assert.Equal(t, apiURL+"/order/1", order.Location)
+}
+
 func readSignedBody(r *http.Request, privateKey *rsa.PrivateKey) ([]byte, error) {
 	reqBody, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// ErrNoARI is returned when the server does not advertise a renewal info endpoint.
+var ErrNoARI = errors.New("renewalInfo[get]: server does not advertise a renewal info endpoint")
+
+// GetRenewalInfo gets the renewal information of a certificate from the renewalInfo endpoint.
+// Callers should close resp.Body when done reading from it.
+//
+// The endpoint is part of a draft specification, not all ACME servers implement it:
+// ErrNoARI is returned if the server does not advertise a renewal info endpoint.
+// - https://datatracker.ietf.org/doc/draft-ietf-acme-ari/
+func (c *CertificateService) GetRenewalInfo(certID string) (*http.Response, error) {
+	if c.core.directory.RenewalInfo == "" {
+		return nil, ErrNoARI
+	}
+
+	if certID == "" {
+		return nil, errors.New("renewalInfo[get]: 'certID' cannot be empty")
+	}
+
+	endpoint, err := url.JoinPath(c.core.directory.RenewalInfo, certID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.core.doer.Get(endpoint, nil)
+}
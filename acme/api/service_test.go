@@ -3,6 +3,7 @@ package api
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -54,3 +55,55 @@ func Test_getLink(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRetryAfter(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected time.Duration
+		ok       bool
+		delta    time.Duration
+	}{
+		{
+			desc:     "seconds",
+			value:    "120",
+			expected: 2 * time.Minute,
+			ok:       true,
+		},
+		{
+			desc:     "HTTP date",
+			value:    time.Now().Add(time.Hour).UTC().Format(http.TimeFormat),
+			expected: time.Hour,
+			ok:       true,
+			delta:    2 * time.Second,
+		},
+		{
+			desc:  "HTTP date in the past",
+			value: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat),
+			ok:    true,
+		},
+		{
+			desc:  "negative seconds",
+			value: "-1",
+		},
+		{
+			desc:  "invalid",
+			value: "soon",
+		},
+		{
+			desc: "empty",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			d, ok := ParseRetryAfter(test.value)
+
+			assert.Equal(t, test.ok, ok)
+			assert.InDelta(t, test.expected, d, float64(test.delta))
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 // ACME status values of Account, Order, Authorization and Challenge objects.
 // See https://www.rfc-editor.org/rfc/rfc8555.html#section-7.1.6 for details.
 const (
+	StatusCanceled    = "canceled"
 	StatusDeactivated = "deactivated"
 	StatusExpired     = "expired"
 	StatusInvalid     = "invalid"
@@ -46,6 +47,11 @@ type Directory struct {
 	RevokeCertURL string `json:"revokeCert"`
 	KeyChangeURL  string `json:"keyChange"`
 	Meta          Meta   `json:"meta"`
+
+	// renewalInfo (optional, string):
+	// The URL of the ACME Renewal Information (ARI) endpoint.
+	// - https://datatracker.ietf.org/doc/draft-ietf-acme-ari/
+	RenewalInfo string `json:"renewalInfo"`
 }
 
 // Meta the ACME meta object (related to Directory).
@@ -72,6 +78,58 @@ type Meta struct {
 	// then the CA requires that all new- account requests include an "externalAccountBinding" field
 	// associating the new account with an external account.
 	ExternalAccountRequired bool `json:"externalAccountRequired"`
+
+	// profiles (optional, object):
+	// A map of the certificate profiles supported by the ACME server,
+	// the keys are the names of the profiles and the values are their descriptions.
+	// - https://datatracker.ietf.org/doc/draft-aaron-acme-profiles/
+	Profiles map[string]string `json:"profiles,omitempty"`
+
+	// auto-renewal (optional, object):
+	// The Short-Term Automatic Renewal (STAR) capabilities of the ACME server.
+	// - https://www.rfc-editor.org/rfc/rfc8739.html#section-3.1.1
+	AutoRenewal *AutoRenewalMeta `json:"auto-renewal,omitempty"`
+}
+
+// AutoRenewalMeta the STAR capabilities of the ACME server.
+// - https://www.rfc-editor.org/rfc/rfc8739.html#section-3.1.1
+type AutoRenewalMeta struct {
+	// min-lifetime (required, integer):
+	// The minimum acceptable value for the auto-renewal lifetime, in seconds.
+	MinLifetime int `json:"min-lifetime"`
+
+	// max-duration (required, integer):
+	// The maximum delta between the auto-renewal end-date and start-date, in seconds.
+	MaxDuration int `json:"max-duration"`
+
+	// allow-certificate-get (optional, boolean):
+	// If true, the server allows the retrieval of the STAR certificates with unauthenticated GET requests.
+	AllowCertificateGet bool `json:"allow-certificate-get,omitempty"`
+}
+
+// AutoRenewal the STAR auto-renewal object of an order.
+// - https://www.rfc-editor.org/rfc/rfc8739.html#section-3.1.1
+type AutoRenewal struct {
+	// start-date (optional, string):
+	// The earliest date of validity of the first certificate issued, in RFC 3339 format.
+	// By default, the date of the finalization of the order.
+	StartDate string `json:"start-date,omitempty"`
+
+	// end-date (required, string):
+	// The latest date of validity of the last certificate issued, in RFC 3339 format.
+	EndDate string `json:"end-date"`
+
+	// lifetime (required, integer):
+	// The maximum validity period of each certificate, in seconds.
+	Lifetime int `json:"lifetime"`
+
+	// lifetime-adjust (optional, integer):
+	// The amount of "left pad" added to each certificate, in seconds.
+	LifetimeAdjust int `json:"lifetime-adjust,omitempty"`
+
+	// allow-certificate-get (optional, boolean):
+	// If true, the STAR certificates can be retrieved with unauthenticated GET requests.
+	AllowCertificateGet bool `json:"allow-certificate-get,omitempty"`
 }
 
 // ExtendedAccount a extended Account.
@@ -126,6 +184,9 @@ type ExtendedOrder struct {
 
 	// The order URL, contains the value of the response header `Location`
 	Location string `json:"-"`
+
+	// Contains the value of the response header `Retry-After`
+	RetryAfter string `json:"-"`
 }
 
 // Order the ACME order Object.
@@ -179,6 +240,26 @@ type Order struct {
 	// certificate (optional, string):
 	// A URL for the certificate that has been issued in response to this order
 	Certificate string `json:"certificate,omitempty"`
+
+	// replaces (optional, string):
+	// The ARI certificate identifier (see draft-ietf-acme-ari) of the certificate being replaced by this order.
+	// - https://datatracker.ietf.org/doc/draft-ietf-acme-ari/
+	Replaces string `json:"replaces,omitempty"`
+
+	// profile (optional, string):
+	// The name of the certificate profile (advertised in the directory meta profiles) selected for this order.
+	// - https://datatracker.ietf.org/doc/draft-aaron-acme-profiles/
+	Profile string `json:"profile,omitempty"`
+
+	// auto-renewal (optional, object):
+	// The STAR auto-renewal settings of the order.
+	// - https://www.rfc-editor.org/rfc/rfc8739.html#section-3.1.1
+	AutoRenewal *AutoRenewal `json:"auto-renewal,omitempty"`
+
+	// star-certificate (optional, string):
+	// A URL for the current certificate of a STAR order, replaced by the server before the expiration of each certificate.
+	// - https://www.rfc-editor.org/rfc/rfc8739.html#section-3.1.2
+	StarCertificate string `json:"star-certificate,omitempty"`
 }
 
 // Authorization the ACME authorization object.
@@ -214,6 +295,13 @@ type Authorization struct {
 	Wildcard bool `json:"wildcard,omitempty"`
 }
 
+// ExtendedAuthorization a extended Authorization.
+type ExtendedAuthorization struct {
+	Authorization
+	// Contains the value of the response header `Retry-After`
+	RetryAfter string `json:"-"`
+}
+
 // ExtendedChallenge a extended Challenge.
 type ExtendedChallenge struct {
 	Challenge
@@ -301,6 +389,25 @@ type RevokeCertMessage struct {
 	Reason *uint `json:"reason,omitempty"`
 }
 
+// RenewalInfoResponse the ACME Renewal Information (ARI) object.
+// - https://datatracker.ietf.org/doc/draft-ietf-acme-ari/
+type RenewalInfoResponse struct {
+	// suggestedWindow (required, object):
+	// The window of time in which the CA recommends renewing the certificate.
+	SuggestedWindow Window `json:"suggestedWindow"`
+
+	// explanationURL (optional, string):
+	// A URL pointing to a page which may explain why the suggested renewal window is what it is.
+	// Clients SHOULD provide this URL to their operator, if present.
+	ExplanationURL string `json:"explanationURL,omitempty"`
+}
+
+// Window a time window (related to RenewalInfoResponse).
+type Window struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
 // RawCertificate raw data of a certificate.
 type RawCertificate struct {
 	Cert   []byte
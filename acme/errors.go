@@ -2,14 +2,33 @@ package acme
 
 import (
 	"fmt"
+	"net/http"
+	"strings"
 )
 
 // Errors types.
 const (
-	errNS       = "urn:ietf:params:acme:error:"
-	BadNonceErr = errNS + "badNonce"
+	errNS              = "urn:ietf:params:acme:error:"
+	BadNonceErr        = errNS + "badNonce"
+	MalformedErr       = errNS + "malformed"
+	AlreadyReplacedErr = errNS + "alreadyReplaced"
+
+	BadCSRErr                = errNS + "badCSR"
+	BadPublicKeyErr          = errNS + "badPublicKey"
+	BadSignatureAlgorithmErr = errNS + "badSignatureAlgorithm"
+
+	RateLimitedErr    = errNS + "rateLimited"
+	ServerInternalErr = errNS + "serverInternal"
 )
 
+// RetryableError is implemented by the errors which know if the failed request can be sent again.
+type RetryableError interface {
+	error
+
+	// Retryable returns true if the failure is transient (e.g. rate limit, server error).
+	Retryable() bool
+}
+
 // ProblemDetails the problem details object.
 // - https://www.rfc-editor.org/rfc/rfc7807.html#section-3.1
 // - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.3.3
@@ -20,6 +39,10 @@ type ProblemDetails struct {
 	Instance    string       `json:"instance,omitempty"`
 	SubProblems []SubProblem `json:"subproblems,omitempty"`
 
+	// Algorithms the signature algorithms supported by the server (badSignatureAlgorithm).
+	// - https://www.rfc-editor.org/rfc/rfc8555.html#section-6.2
+	Algorithms []string `json:"algorithms,omitempty"`
+
 	// additional values to have a better error message (Not defined by the RFC)
 	Method string `json:"method,omitempty"`
 	URL    string `json:"url,omitempty"`
@@ -33,6 +56,27 @@ type SubProblem struct {
 	Identifier Identifier `json:"identifier,omitempty"`
 }
 
+// Retryable returns true if the problem is transient: bad nonce, rate limit, or server error.
+func (p ProblemDetails) Retryable() bool {
+	switch p.Type {
+	case BadNonceErr, RateLimitedErr, ServerInternalErr:
+		return true
+	}
+
+	return p.HTTPStatus == http.StatusTooManyRequests || p.HTTPStatus >= http.StatusInternalServerError
+}
+
+// HasSubProblem returns true if one of the subproblems has the given type.
+func (p ProblemDetails) HasSubProblem(problemType string) bool {
+	for _, sub := range p.SubProblems {
+		if sub.Type == problemType {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (p ProblemDetails) Error() string {
 	msg := fmt.Sprintf("acme: error: %d", p.HTTPStatus)
 	if p.Method != "" || p.URL != "" {
@@ -44,6 +88,10 @@ func (p ProblemDetails) Error() string {
 		msg += fmt.Sprintf(", problem: %q :: %s", sub.Type, sub.Detail)
 	}
 
+	if len(p.Algorithms) > 0 {
+		msg += ", supported algorithms: " + strings.Join(p.Algorithms, ", ")
+	}
+
 	if p.Instance != "" {
 		msg += ", url: " + p.Instance
 	}
@@ -56,3 +104,23 @@ func (p ProblemDetails) Error() string {
 type NonceError struct {
 	*ProblemDetails
 }
+
+// Unwrap returns the problem document of the error.
+func (e *NonceError) Unwrap() error {
+	return e.ProblemDetails
+}
+
+// KeyInUseError represents the error which is returned
+// if the new key of a key change is already used by another account.
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.3.5
+type KeyInUseError struct {
+	*ProblemDetails
+
+	// AccountURL the URL of the account using the key, if provided by the server.
+	AccountURL string
+}
+
+// Unwrap returns the problem document of the error.
+func (e *KeyInUseError) Unwrap() error {
+	return e.ProblemDetails
+}
@@ -14,6 +14,8 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,6 +26,8 @@ import (
 const (
 	EC256   = KeyType("P256")
 	EC384   = KeyType("P384")
+	EC521   = KeyType("P521")
+	ED25519 = KeyType("Ed25519")
 	RSA2048 = KeyType("2048")
 	RSA4096 = KeyType("4096")
 	RSA8192 = KeyType("8192")
@@ -119,6 +123,11 @@ func GeneratePrivateKey(keyType KeyType) (crypto.PrivateKey, error) {
 		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	case EC384:
 		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case EC521:
+		return ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case ED25519:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
 	case RSA2048:
 		return rsa.GenerateKey(rand.Reader, 2048)
 	case RSA4096:
@@ -130,22 +139,149 @@ func GeneratePrivateKey(keyType KeyType) (crypto.PrivateKey, error) {
 	return nil, fmt.Errorf("invalid KeyType: %s", keyType)
 }
 
+// Constants for the key usages.
+var (
+	keyUsageExtensionOID    = asn1.ObjectIdentifier{2, 5, 29, 15}
+	extKeyUsageExtensionOID = asn1.ObjectIdentifier{2, 5, 29, 37}
+)
+
+// CSROptions are the options of a generated CSR.
+// The key usages are only requested: the CA may ignore them, or reject the CSR.
+type CSROptions struct {
+	// MustStaple adds the OCSP Must-Staple TLS feature extension (RFC 7633).
+	MustStaple bool
+
+	// KeyUsage adds the key usage extension (e.g. x509.KeyUsageDigitalSignature).
+	KeyUsage x509.KeyUsage
+
+	// ExtKeyUsages adds the extended key usage extension,
+	// the usages are OIDs in dotted notation (e.g. "1.3.6.1.5.5.7.3.2" for the client authentication).
+	ExtKeyUsages []string
+}
+
+// GenerateCSR generates a CSR, the IP addresses of the SAN are added as IP SANs.
+// The common name is not set when the domain is an IP address.
 func GenerateCSR(privateKey crypto.PrivateKey, domain string, san []string, mustStaple bool) ([]byte, error) {
-	template := x509.CertificateRequest{
-		Subject:  pkix.Name{CommonName: domain},
-		DNSNames: san,
+	return GenerateCSRWithOptions(privateKey, domain, san, CSROptions{MustStaple: mustStaple})
+}
+
+// GenerateCSRWithOptions generates a CSR like GenerateCSR, with the extensions defined by the options.
+func GenerateCSRWithOptions(privateKey crypto.PrivateKey, domain string, san []string, opts CSROptions) ([]byte, error) {
+	var template x509.CertificateRequest
+
+	if net.ParseIP(domain) == nil {
+		template.Subject = pkix.Name{CommonName: domain}
+	}
+
+	for _, name := range san {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+			continue
+		}
+
+		template.DNSNames = append(template.DNSNames, name)
 	}
 
-	if mustStaple {
+	if opts.MustStaple {
 		template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{
 			Id:    tlsFeatureExtensionOID,
 			Value: ocspMustStapleFeature,
 		})
 	}
 
+	if opts.KeyUsage != 0 {
+		ext, err := keyUsageExtension(opts.KeyUsage)
+		if err != nil {
+			return nil, err
+		}
+
+		template.ExtraExtensions = append(template.ExtraExtensions, ext)
+	}
+
+	if len(opts.ExtKeyUsages) > 0 {
+		ext, err := extKeyUsageExtension(opts.ExtKeyUsages)
+		if err != nil {
+			return nil, err
+		}
+
+		template.ExtraExtensions = append(template.ExtraExtensions, ext)
+	}
+
 	return x509.CreateCertificateRequest(rand.Reader, &template, privateKey)
 }
 
+// ParseOID parses an OID in dotted notation (e.g. "1.3.6.1.5.5.7.3.2").
+func ParseOID(value string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid OID %q: at least 2 arcs are expected", value)
+	}
+
+	oid := make(asn1.ObjectIdentifier, len(parts))
+
+	for i, part := range parts {
+		arc, err := strconv.Atoi(part)
+		if err != nil || arc < 0 || part != strconv.Itoa(arc) {
+			return nil, fmt.Errorf("invalid OID %q: invalid arc %q", value, part)
+		}
+
+		oid[i] = arc
+	}
+
+	// https://www.itu.int/rec/T-REC-X.690 (8.19.4)
+	if oid[0] > 2 || (oid[0] < 2 && oid[1] >= 40) {
+		return nil, fmt.Errorf("invalid OID %q: invalid root arcs", value)
+	}
+
+	return oid, nil
+}
+
+// keyUsageExtension creates the key usage extension (RFC 5280, 4.2.1.3).
+func keyUsageExtension(usage x509.KeyUsage) (pkix.Extension, error) {
+	var bits asn1.BitString
+
+	for i := 0; i < 16; i++ {
+		if usage&(1<<i) == 0 {
+			continue
+		}
+
+		if len(bits.Bytes) <= i/8 {
+			bits.Bytes = append(bits.Bytes, make([]byte, i/8+1-len(bits.Bytes))...)
+		}
+
+		bits.Bytes[i/8] |= 0x80 >> (i % 8)
+		bits.BitLength = i + 1
+	}
+
+	value, err := asn1.Marshal(bits)
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("key usage: %w", err)
+	}
+
+	return pkix.Extension{Id: keyUsageExtensionOID, Critical: true, Value: value}, nil
+}
+
+// extKeyUsageExtension creates the extended key usage extension (RFC 5280, 4.2.1.12).
+func extKeyUsageExtension(usages []string) (pkix.Extension, error) {
+	var oids []asn1.ObjectIdentifier
+
+	for _, usage := range usages {
+		oid, err := ParseOID(usage)
+		if err != nil {
+			return pkix.Extension{}, fmt.Errorf("extended key usage: %w", err)
+		}
+
+		oids = append(oids, oid)
+	}
+
+	value, err := asn1.Marshal(oids)
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("extended key usage: %w", err)
+	}
+
+	return pkix.Extension{Id: extKeyUsageExtensionOID, Value: value}, nil
+}
+
 func PEMEncode(data interface{}) []byte {
 	return pem.EncodeToMemory(PEMBlock(data))
 }
@@ -158,6 +294,9 @@ func PEMBlock(data interface{}) *pem.Block {
 		pemBlock = &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}
 	case *rsa.PrivateKey:
 		pemBlock = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	case ed25519.PrivateKey:
+		keyBytes, _ := x509.MarshalPKCS8PrivateKey(key)
+		pemBlock = &pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes}
 	case *x509.CertificateRequest:
 		pemBlock = &pem.Block{Type: "CERTIFICATE REQUEST", Bytes: key.Raw}
 	case DERCertificateBytes:
@@ -215,6 +354,13 @@ func ExtractDomains(cert *x509.Certificate) []string {
 		domains = append(domains, sanDomain)
 	}
 
+	for _, ip := range cert.IPAddresses {
+		if containsSAN(domains, ip.String()) {
+			continue
+		}
+		domains = append(domains, ip.String())
+	}
+
 	return domains
 }
 
@@ -235,6 +381,15 @@ func ExtractDomainsCSR(csr *x509.CertificateRequest) []string {
 		domains = append(domains, sanName)
 	}
 
+	// loop over the SubjectAltName IP addresses
+	for _, ip := range csr.IPAddresses {
+		if containsSAN(domains, ip.String()) {
+			continue
+		}
+
+		domains = append(domains, ip.String())
+	}
+
 	return domains
 }
 
@@ -277,9 +432,15 @@ func generateDerCert(privateKey *rsa.PrivateKey, expiration time.Time, domain st
 
 		KeyUsage:              x509.KeyUsageKeyEncipherment,
 		BasicConstraintsValid: true,
-		DNSNames:              []string{domain},
 		ExtraExtensions:       extensions,
 	}
 
+	// https://www.rfc-editor.org/rfc/rfc8738.html#section-6
+	if ip := net.ParseIP(domain); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{domain}
+	}
+
 	return x509.CreateCertificate(rand.Reader, &template, &template, &privateKey.PublicKey, privateKey)
 }
@@ -5,7 +5,10 @@ import (
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
+	"math/big"
 	"regexp"
 	"testing"
 	"time"
@@ -21,6 +24,47 @@ func TestGeneratePrivateKey(t *testing.T) {
 	assert.NotNil(t, key)
 }
 
+func TestGeneratePrivateKey_keyTypes(t *testing.T) {
+	testCases := []struct {
+		keyType  KeyType
+		expected x509.PublicKeyAlgorithm
+	}{
+		{keyType: EC256, expected: x509.ECDSA},
+		{keyType: EC384, expected: x509.ECDSA},
+		{keyType: EC521, expected: x509.ECDSA},
+		{keyType: ED25519, expected: x509.Ed25519},
+		{keyType: RSA2048, expected: x509.RSA},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(string(test.keyType), func(t *testing.T) {
+			t.Parallel()
+
+			privateKey, err := GeneratePrivateKey(test.keyType)
+			require.NoError(t, err)
+
+			csrRaw, err := GenerateCSR(privateKey, "example.com", []string{"example.com"}, false)
+			require.NoError(t, err)
+
+			csr, err := x509.ParseCertificateRequest(csrRaw)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, csr.PublicKeyAlgorithm)
+
+			decoded, err := ParsePEMPrivateKey(PEMEncode(privateKey))
+			require.NoError(t, err)
+
+			assert.Equal(t, privateKey, decoded)
+		})
+	}
+}
+
+func TestGeneratePrivateKey_invalid(t *testing.T) {
+	_, err := GeneratePrivateKey("P999")
+	require.EqualError(t, err, "invalid KeyType: P999")
+}
+
 func TestGenerateCSR(t *testing.T) {
 	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
 	require.NoError(t, err, "Error generating private key")
@@ -104,6 +148,245 @@ func TestGenerateCSR(t *testing.T) {
 	}
 }
 
+func TestGenerateCSR_mustStaple(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Error generating private key")
+
+	testCases := []struct {
+		desc       string
+		mustStaple bool
+	}{
+		{
+			desc:       "with must staple",
+			mustStaple: true,
+		},
+		{
+			desc:       "without must staple",
+			mustStaple: false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			raw, err := GenerateCSR(privateKey, "lego.acme", []string{"lego.acme"}, test.mustStaple)
+			require.NoError(t, err)
+
+			csr, err := x509.ParseCertificateRequest(raw)
+			require.NoError(t, err)
+
+			var found bool
+			for _, ext := range csr.Extensions {
+				if ext.Id.Equal(tlsFeatureExtensionOID) {
+					found = true
+					assert.Equal(t, ocspMustStapleFeature, ext.Value)
+				}
+			}
+
+			assert.Equal(t, test.mustStaple, found)
+		})
+	}
+}
+
+func TestGenerateCSRWithOptions_keyUsages(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Error generating private key")
+
+	raw, err := GenerateCSRWithOptions(privateKey, "lego.acme", []string{"lego.acme"}, CSROptions{
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageDecipherOnly,
+		ExtKeyUsages: []string{"1.3.6.1.5.5.7.3.1", "1.3.6.1.5.5.7.3.2", "1.3.6.1.4.1.311.20.2.2"},
+	})
+	require.NoError(t, err)
+
+	csr, err := x509.ParseCertificateRequest(raw)
+	require.NoError(t, err)
+
+	var extensions []pkix.Extension
+	for _, ext := range csr.Extensions {
+		if ext.Id.Equal(keyUsageExtensionOID) || ext.Id.Equal(extKeyUsageExtensionOID) {
+			extensions = append(extensions, ext)
+		}
+	}
+
+	require.Len(t, extensions, 2)
+
+	// the extensions of the CSR are decoded by the standard library through a certificate.
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		NotBefore:       time.Now(),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: extensions,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, privateKey.Public(), privateKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	assert.Equal(t, x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment|x509.KeyUsageDecipherOnly, cert.KeyUsage)
+	assert.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}, cert.ExtKeyUsage)
+	require.Len(t, cert.UnknownExtKeyUsage, 1)
+	assert.Equal(t, "1.3.6.1.4.1.311.20.2.2", cert.UnknownExtKeyUsage[0].String())
+}
+
+func TestGenerateCSRWithOptions_noKeyUsages(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Error generating private key")
+
+	raw, err := GenerateCSRWithOptions(privateKey, "lego.acme", []string{"lego.acme"}, CSROptions{})
+	require.NoError(t, err)
+
+	csr, err := x509.ParseCertificateRequest(raw)
+	require.NoError(t, err)
+
+	for _, ext := range csr.Extensions {
+		assert.False(t, ext.Id.Equal(keyUsageExtensionOID))
+		assert.False(t, ext.Id.Equal(extKeyUsageExtensionOID))
+	}
+}
+
+func TestGenerateCSRWithOptions_invalidExtKeyUsage(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Error generating private key")
+
+	_, err = GenerateCSRWithOptions(privateKey, "lego.acme", []string{"lego.acme"}, CSROptions{
+		ExtKeyUsages: []string{"clientAuth"},
+	})
+	require.EqualError(t, err, `extended key usage: invalid OID "clientAuth": at least 2 arcs are expected`)
+}
+
+func TestParseOID(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		value       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			desc:     "client authentication",
+			value:    "1.3.6.1.5.5.7.3.2",
+			expected: "1.3.6.1.5.5.7.3.2",
+		},
+		{
+			desc:     "joint root",
+			value:    "2.999.1",
+			expected: "2.999.1",
+		},
+		{
+			desc:        "empty",
+			expectedErr: `invalid OID "": at least 2 arcs are expected`,
+		},
+		{
+			desc:        "single arc",
+			value:       "1",
+			expectedErr: `invalid OID "1": at least 2 arcs are expected`,
+		},
+		{
+			desc:        "empty arc",
+			value:       "1..3",
+			expectedErr: `invalid OID "1..3": invalid arc ""`,
+		},
+		{
+			desc:        "negative arc",
+			value:       "1.3.-6",
+			expectedErr: `invalid OID "1.3.-6": invalid arc "-6"`,
+		},
+		{
+			desc:        "leading zero",
+			value:       "1.03.6",
+			expectedErr: `invalid OID "1.03.6": invalid arc "03"`,
+		},
+		{
+			desc:        "invalid first arc",
+			value:       "3.1",
+			expectedErr: `invalid OID "3.1": invalid root arcs`,
+		},
+		{
+			desc:        "invalid second arc",
+			value:       "1.40",
+			expectedErr: `invalid OID "1.40": invalid root arcs`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			oid, err := ParseOID(test.value)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, oid.String())
+		})
+	}
+}
+
+func TestGenerateCSR_ipAddresses(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Error generating private key")
+
+	testCases := []struct {
+		desc               string
+		domain             string
+		san                []string
+		expectedCommonName string
+		expectedDNSNames   []string
+		expectedIPs        []string
+	}{
+		{
+			desc:               "domain with IP SANs",
+			domain:             "lego.acme",
+			san:                []string{"lego.acme", "192.0.2.1", "2001:db8::1"},
+			expectedCommonName: "lego.acme",
+			expectedDNSNames:   []string{"lego.acme"},
+			expectedIPs:        []string{"192.0.2.1", "2001:db8::1"},
+		},
+		{
+			desc:        "IPv4 only",
+			domain:      "192.0.2.1",
+			san:         []string{"192.0.2.1"},
+			expectedIPs: []string{"192.0.2.1"},
+		},
+		{
+			desc:        "IPv6 only",
+			domain:      "2001:db8::1",
+			san:         []string{"2001:db8::1"},
+			expectedIPs: []string{"2001:db8::1"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			raw, err := GenerateCSR(privateKey, test.domain, test.san, false)
+			require.NoError(t, err)
+
+			csr, err := x509.ParseCertificateRequest(raw)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedCommonName, csr.Subject.CommonName)
+			assert.Equal(t, test.expectedDNSNames, csr.DNSNames)
+
+			var ips []string
+			for _, ip := range csr.IPAddresses {
+				ips = append(ips, ip.String())
+			}
+			assert.Equal(t, test.expectedIPs, ips)
+
+			assert.Equal(t, test.san, ExtractDomainsCSR(csr))
+		})
+	}
+}
+
 func TestPEMEncode(t *testing.T) {
 	buf := bytes.NewBufferString("TestingRSAIsSoMuchFun")
 
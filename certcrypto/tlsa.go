@@ -0,0 +1,90 @@
+package certcrypto
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+)
+
+// TLSA certificate usages, selectors and matching types (RFC 6698, section 2.1).
+const (
+	// TLSAUsageDANEEE the record matches the end-entity certificate (DANE-EE).
+	TLSAUsageDANEEE uint8 = 3
+
+	// TLSASelectorCert the record matches the full certificate.
+	TLSASelectorCert uint8 = 0
+	// TLSASelectorSPKI the record matches the SubjectPublicKeyInfo of the certificate.
+	TLSASelectorSPKI uint8 = 1
+
+	// TLSAMatchingFull the record contains the exact content.
+	TLSAMatchingFull uint8 = 0
+	// TLSAMatchingSHA256 the record contains the SHA-256 digest of the content.
+	TLSAMatchingSHA256 uint8 = 1
+	// TLSAMatchingSHA512 the record contains the SHA-512 digest of the content.
+	TLSAMatchingSHA512 uint8 = 2
+)
+
+// TLSARecord the data of a TLSA record (RFC 6698).
+type TLSARecord struct {
+	Usage        uint8  `json:"usage"`
+	Selector     uint8  `json:"selector"`
+	MatchingType uint8  `json:"matchingType"`
+	Data         string `json:"data"` // hex encoded.
+}
+
+// String returns the record data in the presentation format (e.g. `3 1 1 <hex>`).
+func (r TLSARecord) String() string {
+	return fmt.Sprintf("%d %d %d %s", r.Usage, r.Selector, r.MatchingType, r.Data)
+}
+
+// NewTLSARecord computes a TLSA record of a certificate.
+func NewTLSARecord(cert *x509.Certificate, usage, selector, matchingType uint8) (TLSARecord, error) {
+	var content []byte
+
+	switch selector {
+	case TLSASelectorCert:
+		content = cert.Raw
+	case TLSASelectorSPKI:
+		content = cert.RawSubjectPublicKeyInfo
+	default:
+		return TLSARecord{}, fmt.Errorf("unsupported TLSA selector: %d", selector)
+	}
+
+	switch matchingType {
+	case TLSAMatchingFull:
+		// the content is used as-is.
+	case TLSAMatchingSHA256:
+		digest := sha256.Sum256(content)
+		content = digest[:]
+	case TLSAMatchingSHA512:
+		digest := sha512.Sum512(content)
+		content = digest[:]
+	default:
+		return TLSARecord{}, fmt.Errorf("unsupported TLSA matching type: %d", matchingType)
+	}
+
+	return TLSARecord{
+		Usage:        usage,
+		Selector:     selector,
+		MatchingType: matchingType,
+		Data:         hex.EncodeToString(content),
+	}, nil
+}
+
+// DANEEERecords returns the DANE-EE TLSA records of a certificate matching its public key:
+// `3 1 1` (SHA-256 of the SubjectPublicKeyInfo) and `3 1 2` (SHA-512 of the SubjectPublicKeyInfo).
+// These records don't change when the private key is reused for the renewal of the certificate.
+func DANEEERecords(cert *x509.Certificate) []TLSARecord {
+	var records []TLSARecord
+
+	for _, matchingType := range []uint8{TLSAMatchingSHA256, TLSAMatchingSHA512} {
+		// the selector and the matching types are supported: no error.
+		record, _ := NewTLSARecord(cert, TLSAUsageDANEEE, TLSASelectorSPKI, matchingType)
+
+		records = append(records, record)
+	}
+
+	return records
+}
@@ -0,0 +1,107 @@
+package certcrypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The digests have been computed with openssl, e.g.:
+// openssl x509 -in cert.pem -noout -pubkey | openssl pkey -pubin -outform DER | openssl dgst -sha256 -hex.
+const tlsaTestCertificate = `-----BEGIN CERTIFICATE-----
+MIIBgjCCASmgAwIBAgIUEdlFjwyfwxNy13DvmH7ZAbEp8SEwCgYIKoZIzj0EAwIw
+FjEUMBIGA1UEAwwLZXhhbXBsZS5jb20wIBcNMjYxMDE0MDU1NzU3WhgPMjEyNjA5
+MjAwNTU3NTdaMBYxFDASBgNVBAMMC2V4YW1wbGUuY29tMFkwEwYHKoZIzj0CAQYI
+KoZIzj0DAQcDQgAEFL2Mu/y0XB0l1pOeSetNkd5+lJDJ/o0OWgD/R1zouK06jjUm
+/m3zSq34LIThQbKUHCvbQ4HthNhzxq1XNjiYiaNTMFEwHQYDVR0OBBYEFEitF6qY
+0HqKmDF+4llOX2/ZEQ4NMB8GA1UdIwQYMBaAFEitF6qY0HqKmDF+4llOX2/ZEQ4N
+MA8GA1UdEwEB/wQFMAMBAf8wCgYIKoZIzj0EAwIDRwAwRAIgOd3QgQXJHBUJLUvP
+yspz6YD+m7JIugL0Mp+NOLicD6kCICGx6t97I3+m3yFIUltgiuxQK8fR4IbDVloH
+pe1eSPgU
+-----END CERTIFICATE-----
+`
+
+func TestDANEEERecords(t *testing.T) {
+	cert, err := ParsePEMCertificate([]byte(tlsaTestCertificate))
+	require.NoError(t, err)
+
+	records := DANEEERecords(cert)
+
+	expected := []string{
+		"3 1 1 b013f042c4cf5e5caf87c062afec45939875bd81d86f6c6ab8ffb5c17a08b458",
+		"3 1 2 5dc041f8d40aa3fbce7036e915a884f2569c8a92ea90140490f3ab856bbafe87adbdae95888bc4087697d4c98811ef1ccbf57cefc8cb7cdd61dd9e9870a5e96b",
+	}
+
+	require.Len(t, records, len(expected))
+
+	for i, record := range records {
+		assert.Equal(t, expected[i], record.String())
+	}
+}
+
+func TestNewTLSARecord(t *testing.T) {
+	cert, err := ParsePEMCertificate([]byte(tlsaTestCertificate))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc         string
+		selector     uint8
+		matchingType uint8
+		expected     string
+		expectedErr  string
+	}{
+		{
+			desc:         "full certificate SHA-256",
+			selector:     TLSASelectorCert,
+			matchingType: TLSAMatchingSHA256,
+			expected:     "3 0 1 86329a628e4b71f6cd180cdc914b40b43bce0b0573b3882921c44b2113a0f835",
+		},
+		{
+			desc:         "SPKI SHA-256",
+			selector:     TLSASelectorSPKI,
+			matchingType: TLSAMatchingSHA256,
+			expected:     "3 1 1 b013f042c4cf5e5caf87c062afec45939875bd81d86f6c6ab8ffb5c17a08b458",
+		},
+		{
+			desc:         "unsupported selector",
+			selector:     2,
+			matchingType: TLSAMatchingSHA256,
+			expectedErr:  "unsupported TLSA selector: 2",
+		},
+		{
+			desc:         "unsupported matching type",
+			selector:     TLSASelectorSPKI,
+			matchingType: 3,
+			expectedErr:  "unsupported TLSA matching type: 3",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			record, err := NewTLSARecord(cert, TLSAUsageDANEEE, test.selector, test.matchingType)
+
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, record.String())
+		})
+	}
+}
+
+func TestNewTLSARecord_full(t *testing.T) {
+	cert, err := ParsePEMCertificate([]byte(tlsaTestCertificate))
+	require.NoError(t, err)
+
+	record, err := NewTLSARecord(cert, TLSAUsageDANEEE, TLSASelectorSPKI, TLSAMatchingFull)
+	require.NoError(t, err)
+
+	// the DER encoding of the SubjectPublicKeyInfo of a P-256 key.
+	assert.Len(t, record.Data, 2*91)
+}
@@ -0,0 +1,125 @@
+package certificate
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v4/log"
+	"golang.org/x/time/rate"
+)
+
+// RenewAllOptions configures RenewAll.
+type RenewAllOptions struct {
+	// RenewalOptions defines the certificates to renew (see NeedsRenewal).
+	RenewalOptions RenewalOptions
+
+	Bundle         bool
+	MustStaple     bool
+	PreferredChain string
+
+	// Concurrency is the maximum number of certificates renewed at the same time (1 if lower than 1).
+	// The requests to the CA are concurrent, but the challenges are solved one renewal at a time,
+	// because the solvers share their state (e.g. the port of the http-01 server).
+	Concurrency int
+
+	// RateLimit is the maximum number of renewals started per second, shared by all the renewals (0 disables the limit).
+	// It helps to respect the rate limits of the CA (e.g. the number of new orders of Let's Encrypt).
+	RateLimit float64
+	// RateLimitBurst is the maximum number of renewals started at once when the rate limit is enabled (1 if lower than 1).
+	RateLimitBurst int
+}
+
+// RenewResult is the result of the renewal of a certificate by RenewAll.
+type RenewResult struct {
+	// Domain is the domain of the certificate resource.
+	Domain string
+
+	// Renewed is true when a new certificate has been obtained.
+	Renewed bool
+
+	// RenewalTime is the renewal time computed for the certificate (see NeedsRenewal).
+	RenewalTime time.Time
+
+	// Resource is the new certificate resource, if renewed.
+	Resource *Resource
+
+	// Err is the error of the renewal, if any.
+	Err error
+}
+
+// RenewAll renews the certificates needing renewal, with a bounded concurrency and a shared rate limit.
+//
+// The results are in the same order as the certificates.
+// The failure of a renewal doesn't stop the renewal of the other certificates:
+// the errors are reported by the results.
+func (c *Certifier) RenewAll(certificates []Resource, opts RenewAllOptions) []RenewResult {
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var limiter *rate.Limiter
+	if opts.RateLimit > 0 {
+		burst := opts.RateLimitBurst
+		if burst < 1 {
+			burst = 1
+		}
+
+		limiter = rate.NewLimiter(rate.Limit(opts.RateLimit), burst)
+	}
+
+	results := make([]RenewResult, len(certificates))
+
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for i, certRes := range certificates {
+		results[i].Domain = certRes.Domain
+
+		needed, renewalTime, err := NeedsRenewal(certRes.Certificate, opts.RenewalOptions)
+		results[i].RenewalTime = renewalTime
+
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		if !needed {
+			log.Infof("[%s] acme: The certificate doesn't need renewal (renewal time: %s)", certRes.Domain, renewalTime.Format(time.RFC3339))
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, certRes Resource) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if limiter != nil {
+				err := limiter.Wait(context.Background())
+				if err != nil {
+					results[i].Err = err
+					return
+				}
+			}
+
+			newRes, err := c.Renew(certRes, opts.Bundle, opts.MustStaple, opts.PreferredChain)
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+
+			results[i].Renewed = true
+			results[i].Resource = newRes
+		}(i, certRes)
+	}
+
+	wg.Wait()
+
+	return results
+}
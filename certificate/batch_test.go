@@ -0,0 +1,210 @@
+package certificate
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertifier_RenewAll(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	var mu sync.Mutex
+	var ordered []string
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		body, err := readSignedBody(r, key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var order acme.Order
+		err = json.Unmarshal(body, &order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		domain := order.Identifiers[0].Value
+
+		mu.Lock()
+		ordered = append(ordered, domain)
+		mu.Unlock()
+
+		if domain == "fail.example.com" {
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"type":"urn:ietf:params:acme:error:rejectedIdentifier","detail":"rejected","status":403}`))
+			return
+		}
+
+		w.Header().Set("Location", apiURL+"/order/"+domain)
+		w.WriteHeader(http.StatusCreated)
+
+		err = tester.WriteJSONResponse(w, acme.Order{
+			Status:         acme.StatusReady,
+			Identifiers:    order.Identifiers,
+			Authorizations: []string{apiURL + "/authz/" + domain},
+			Finalize:       apiURL + "/finalize/" + domain,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/authz/", func(w http.ResponseWriter, r *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusPending,
+			Identifier: acme.Identifier{Type: "dns", Value: r.URL.Path[len("/authz/"):]},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/finalize/", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusValid,
+			Certificate: apiURL + "/certificate",
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(certResponseMock))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	resolver := &concurrencyResolver{delay: 50 * time.Millisecond}
+
+	certifier := NewCertifier(core, resolver, CertifierOptions{KeyType: certcrypto.EC256})
+
+	now := time.Now()
+
+	certificates := []Resource{
+		{Domain: "due1.example.com", Certificate: generateBatchCertificate(t, "due1.example.com", now.Add(5*24*time.Hour))},
+		{Domain: "notdue.example.com", Certificate: generateBatchCertificate(t, "notdue.example.com", now.Add(80*24*time.Hour))},
+		{Domain: "fail.example.com", Certificate: generateBatchCertificate(t, "fail.example.com", now.Add(24*time.Hour))},
+		{Domain: "invalid.example.com", Certificate: []byte("invalid")},
+		{Domain: "due2.example.com", Certificate: generateBatchCertificate(t, "due2.example.com", now.Add(10*24*time.Hour))},
+	}
+
+	results := certifier.RenewAll(certificates, RenewAllOptions{
+		RenewalOptions: RenewalOptions{Days: 30},
+		Concurrency:    2,
+		RateLimit:      100,
+	})
+
+	require.Len(t, results, len(certificates))
+
+	for i, result := range results {
+		assert.Equal(t, certificates[i].Domain, result.Domain)
+	}
+
+	// due
+	for _, i := range []int{0, 4} {
+		require.NoError(t, results[i].Err)
+		assert.True(t, results[i].Renewed)
+		require.NotNil(t, results[i].Resource)
+		assert.NotEmpty(t, results[i].Resource.Certificate)
+	}
+
+	// not due
+	require.NoError(t, results[1].Err)
+	assert.False(t, results[1].Renewed)
+	assert.Nil(t, results[1].Resource)
+	assert.False(t, results[1].RenewalTime.IsZero())
+
+	// failures
+	require.Error(t, results[2].Err)
+	assert.False(t, results[2].Renewed)
+
+	require.Error(t, results[3].Err)
+	assert.False(t, results[3].Renewed)
+
+	sort.Strings(ordered)
+	assert.Equal(t, []string{"due1.example.com", "due2.example.com", "fail.example.com"}, ordered)
+
+	// the challenges are solved one issuance at a time.
+	assert.Equal(t, int32(2), resolver.calls.Load())
+	assert.Equal(t, int32(1), resolver.maxActive.Load())
+}
+
+// concurrencyResolver records the maximum number of concurrent resolutions.
+type concurrencyResolver struct {
+	delay time.Duration
+
+	calls     atomic.Int32
+	active    atomic.Int32
+	maxActive atomic.Int32
+}
+
+func (r *concurrencyResolver) Solve(_ []acme.Authorization) error {
+	r.calls.Add(1)
+
+	active := r.active.Add(1)
+	defer r.active.Add(-1)
+
+	for {
+		current := r.maxActive.Load()
+		if active <= current || r.maxActive.CompareAndSwap(current, active) {
+			break
+		}
+	}
+
+	time.Sleep(r.delay)
+
+	return nil
+}
+
+func generateBatchCertificate(t *testing.T, domain string, notAfter time.Time) []byte {
+	t.Helper()
+
+	key, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.(crypto.Signer).Public(), key)
+	require.NoError(t, err)
+
+	return certcrypto.PEMEncode(certcrypto.DERCertificateBytes(der))
+}
@@ -0,0 +1,133 @@
+package certificate
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/go-acme/lego/v4/log"
+	"github.com/miekg/dns"
+)
+
+// caaFlagCritical the issuer critical flag of a CAA record (RFC 8659, section 4.1).
+const caaFlagCritical = 128
+
+// CAAPreflight configures the CAA pre-flight check.
+// Before the creation of an order, the CAA records of the domains are checked (RFC 8659)
+// to detect early a CA not authorized to issue the certificate.
+type CAAPreflight struct {
+	// Identifiers are the issuer domain names of the CA (e.g. "letsencrypt.org").
+	// The check is disabled if empty, unless UseDirectory is true.
+	Identifiers []string
+
+	// UseDirectory enables the check with the issuer domain names advertised by the CA
+	// in the `caaIdentities` field of the metadata of its directory (RFC 8555, section 7.1.1).
+	// Identifiers are used as fallback when the directory doesn't advertise them.
+	UseDirectory bool
+
+	// Strict aborts the issuance if the CA is not authorized.
+	// By default, only a warning is logged.
+	Strict bool
+}
+
+// checkCAA checks that the CA is authorized to issue a certificate for the domains.
+func (c *Certifier) checkCAA(domains []string) error {
+	identifiers := c.getCAAIdentifiers()
+	if len(identifiers) == 0 {
+		return nil
+	}
+
+	var unauthorized []string
+
+	for _, domain := range domains {
+		// the CAA records only apply to the domain names, not to the IP identifiers (RFC 8738).
+		if net.ParseIP(domain) != nil {
+			continue
+		}
+
+		records, err := c.caaLookup(domain)
+		if err != nil {
+			// The CA performs its own checks: a lookup failure doesn't block the issuance.
+			log.Warnf("[%s] acme: CAA pre-flight: %v", domain, err)
+			continue
+		}
+
+		if !isCAAAuthorized(records, strings.HasPrefix(domain, "*."), identifiers) {
+			unauthorized = append(unauthorized, domain)
+		}
+	}
+
+	if len(unauthorized) == 0 {
+		return nil
+	}
+
+	err := fmt.Errorf("acme: CAA pre-flight: the CA (%s) is not authorized to issue a certificate for: %s",
+		strings.Join(identifiers, ", "), strings.Join(unauthorized, ", "))
+
+	if c.options.CAAPreflight.Strict {
+		return err
+	}
+
+	log.Warnf("%v", err)
+
+	return nil
+}
+
+// getCAAIdentifiers returns the issuer domain names of the CA used by the CAA pre-flight check.
+func (c *Certifier) getCAAIdentifiers() []string {
+	preflight := c.options.CAAPreflight
+
+	if preflight.UseDirectory && c.core != nil {
+		identities := c.core.GetDirectory().Meta.CaaIdentities
+		if len(identities) > 0 {
+			return identities
+		}
+
+		log.Infof("acme: CAA pre-flight: the directory doesn't advertise CAA identities")
+	}
+
+	return preflight.Identifiers
+}
+
+// isCAAAuthorized checks if one of the identifiers is authorized by the relevant CAA records of a domain.
+func isCAAAuthorized(records []*dns.CAA, wildcard bool, identifiers []string) bool {
+	var issue, issueWild []*dns.CAA
+
+	for _, record := range records {
+		switch strings.ToLower(record.Tag) {
+		case "issue":
+			issue = append(issue, record)
+
+		case "issuewild":
+			issueWild = append(issueWild, record)
+
+		default:
+			// An unknown property with the critical flag forbids the issuance.
+			if record.Flag&caaFlagCritical != 0 {
+				return false
+			}
+		}
+	}
+
+	relevant := issue
+	if wildcard && len(issueWild) > 0 {
+		relevant = issueWild
+	}
+
+	if len(relevant) == 0 {
+		return true
+	}
+
+	for _, record := range relevant {
+		issuer, _, _ := strings.Cut(record.Value, ";")
+		issuer = strings.TrimSpace(issuer)
+
+		for _, identifier := range identifiers {
+			if issuer != "" && strings.EqualFold(issuer, identifier) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,317 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_isCAAAuthorized(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		records  []*dns.CAA
+		wildcard bool
+		expected bool
+	}{
+		{
+			desc:     "no records",
+			expected: true,
+		},
+		{
+			desc:     "authorized",
+			records:  []*dns.CAA{{Tag: "issue", Value: "ca.example.org"}},
+			expected: true,
+		},
+		{
+			desc:     "authorized with parameters",
+			records:  []*dns.CAA{{Tag: "issue", Value: "ca.example.org; accounturi=https://ca.example.org/acct/1"}},
+			expected: true,
+		},
+		{
+			desc:     "authorized case-insensitive",
+			records:  []*dns.CAA{{Tag: "ISSUE", Value: "CA.example.org"}},
+			expected: true,
+		},
+		{
+			desc: "authorized among others",
+			records: []*dns.CAA{
+				{Tag: "issue", Value: "other.example.net"},
+				{Tag: "issue", Value: "ca.example.org"},
+			},
+			expected: true,
+		},
+		{
+			desc:    "unauthorized",
+			records: []*dns.CAA{{Tag: "issue", Value: "other.example.net"}},
+		},
+		{
+			desc:    "no issuer allowed",
+			records: []*dns.CAA{{Tag: "issue", Value: ";"}},
+		},
+		{
+			desc:     "only iodef",
+			records:  []*dns.CAA{{Tag: "iodef", Value: "mailto:security@example.com"}},
+			expected: true,
+		},
+		{
+			desc:    "unknown critical property",
+			records: []*dns.CAA{{Flag: 128, Tag: "tbs", Value: "unknown"}, {Tag: "issue", Value: "ca.example.org"}},
+		},
+		{
+			desc:     "wildcard: issuewild authorized",
+			records:  []*dns.CAA{{Tag: "issue", Value: "other.example.net"}, {Tag: "issuewild", Value: "ca.example.org"}},
+			wildcard: true,
+			expected: true,
+		},
+		{
+			desc:     "wildcard: issuewild unauthorized",
+			records:  []*dns.CAA{{Tag: "issue", Value: "ca.example.org"}, {Tag: "issuewild", Value: ";"}},
+			wildcard: true,
+		},
+		{
+			desc:     "wildcard: fallback on issue",
+			records:  []*dns.CAA{{Tag: "issue", Value: "ca.example.org"}},
+			wildcard: true,
+			expected: true,
+		},
+		{
+			desc:     "not wildcard: issuewild ignored",
+			records:  []*dns.CAA{{Tag: "issuewild", Value: "other.example.net"}},
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			authorized := isCAAAuthorized(test.records, test.wildcard, []string{"ca.example.org"})
+
+			assert.Equal(t, test.expected, authorized)
+		})
+	}
+}
+
+func TestCertifier_checkCAA(t *testing.T) {
+	records := map[string][]*dns.CAA{
+		"authorized.example.com":   {{Tag: "issue", Value: "ca.example.org"}},
+		"unauthorized.example.com": {{Tag: "issue", Value: "other.example.net"}},
+		"192.0.2.1":                {{Tag: "issue", Value: "other.example.net"}},
+		"2001:db8::1":              {{Tag: "issue", Value: "other.example.net"}},
+	}
+
+	lookup := func(domain string) ([]*dns.CAA, error) {
+		if domain == "error.example.com" {
+			return nil, errors.New("SERVFAIL")
+		}
+
+		return records[domain], nil
+	}
+
+	testCases := []struct {
+		desc        string
+		domains     []string
+		preflight   CAAPreflight
+		expectedErr string
+	}{
+		{
+			desc:    "disabled",
+			domains: []string{"unauthorized.example.com"},
+		},
+		{
+			desc:      "authorized",
+			domains:   []string{"authorized.example.com", "norecord.example.com"},
+			preflight: CAAPreflight{Identifiers: []string{"ca.example.org"}, Strict: true},
+		},
+		{
+			desc:      "unauthorized not strict",
+			domains:   []string{"authorized.example.com", "unauthorized.example.com"},
+			preflight: CAAPreflight{Identifiers: []string{"ca.example.org"}},
+		},
+		{
+			desc:        "unauthorized strict",
+			domains:     []string{"authorized.example.com", "unauthorized.example.com"},
+			preflight:   CAAPreflight{Identifiers: []string{"ca.example.org"}, Strict: true},
+			expectedErr: "acme: CAA pre-flight: the CA (ca.example.org) is not authorized to issue a certificate for: unauthorized.example.com",
+		},
+		{
+			desc:      "IP identifiers",
+			domains:   []string{"authorized.example.com", "192.0.2.1", "2001:db8::1"},
+			preflight: CAAPreflight{Identifiers: []string{"ca.example.org"}, Strict: true},
+		},
+		{
+			desc:      "lookup error",
+			domains:   []string{"error.example.com"},
+			preflight: CAAPreflight{Identifiers: []string{"ca.example.org"}, Strict: true},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			certifier := NewCertifier(nil, &resolverMock{}, CertifierOptions{CAAPreflight: test.preflight})
+			certifier.caaLookup = lookup
+
+			err := certifier.checkCAA(test.domains)
+
+			if test.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestCertifier_Obtain_caaStrict(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	var ordered bool
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		ordered = true
+
+		http.Error(w, "unexpected order", http.StatusBadRequest)
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{
+		KeyType:      certcrypto.RSA2048,
+		CAAPreflight: CAAPreflight{Identifiers: []string{"ca.example.org"}, Strict: true},
+	})
+	certifier.caaLookup = func(_ string) ([]*dns.CAA, error) {
+		return []*dns.CAA{{Tag: "issue", Value: "other.example.net"}}, nil
+	}
+
+	_, err = certifier.Obtain(ObtainRequest{Domains: []string{"example.com"}})
+	require.EqualError(t, err, "acme: CAA pre-flight: the CA (ca.example.org) is not authorized to issue a certificate for: example.com")
+
+	assert.False(t, ordered, "the order must not be created")
+}
+
+func TestCertifier_checkCAA_directory(t *testing.T) {
+	records := map[string][]*dns.CAA{
+		"ca.example.com":       {{Tag: "issue", Value: "ca.example.org"}},
+		"fallback.example.com": {{Tag: "issue", Value: "fallback.example.net"}},
+	}
+
+	testCases := []struct {
+		desc        string
+		identities  []string
+		preflight   CAAPreflight
+		domain      string
+		expectedErr string
+	}{
+		{
+			desc:       "directory identities",
+			identities: []string{"other.example.net", "ca.example.org"},
+			preflight:  CAAPreflight{UseDirectory: true, Strict: true},
+			domain:     "ca.example.com",
+		},
+		{
+			desc:        "directory identities: unauthorized",
+			identities:  []string{"ca.example.org"},
+			preflight:   CAAPreflight{UseDirectory: true, Identifiers: []string{"fallback.example.net"}, Strict: true},
+			domain:      "fallback.example.com",
+			expectedErr: "acme: CAA pre-flight: the CA (ca.example.org) is not authorized to issue a certificate for: fallback.example.com",
+		},
+		{
+			desc:      "no directory identities: fallback",
+			preflight: CAAPreflight{UseDirectory: true, Identifiers: []string{"fallback.example.net"}, Strict: true},
+			domain:    "fallback.example.com",
+		},
+		{
+			desc:        "no directory identities: fallback unauthorized",
+			preflight:   CAAPreflight{UseDirectory: true, Identifiers: []string{"fallback.example.net"}, Strict: true},
+			domain:      "ca.example.com",
+			expectedErr: "acme: CAA pre-flight: the CA (fallback.example.net) is not authorized to issue a certificate for: ca.example.com",
+		},
+		{
+			desc:      "no directory identities: disabled",
+			preflight: CAAPreflight{UseDirectory: true, Strict: true},
+			domain:    "ca.example.com",
+		},
+		{
+			desc:        "directory not used",
+			identities:  []string{"ca.example.org"},
+			preflight:   CAAPreflight{Identifiers: []string{"fallback.example.net"}, Strict: true},
+			domain:      "ca.example.com",
+			expectedErr: "acme: CAA pre-flight: the CA (fallback.example.net) is not authorized to issue a certificate for: ca.example.com",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			apiURL := setupCAADirectory(t, test.identities)
+
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			require.NoError(t, err, "Could not generate test key")
+
+			core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+			require.NoError(t, err)
+
+			certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{CAAPreflight: test.preflight})
+			certifier.caaLookup = func(domain string) ([]*dns.CAA, error) {
+				return records[domain], nil
+			}
+
+			err = certifier.checkCAA([]string{test.domain})
+
+			if test.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expectedErr)
+			}
+		})
+	}
+}
+
+// setupCAADirectory creates a fake ACME server with a directory advertising CAA identities.
+func setupCAADirectory(t *testing.T, identities []string) string {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/dir", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Directory{
+			NewNonceURL:   server.URL + "/nonce",
+			NewAccountURL: server.URL + "/account",
+			NewOrderURL:   server.URL + "/newOrder",
+			Meta:          acme.Meta{CaaIdentities: identities},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/nonce", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Replay-Nonce", "12345")
+	})
+
+	return server.URL
+}
@@ -2,22 +2,32 @@ package certificate
 
 import (
 	"bytes"
+	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/acme/api"
 	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/log"
-	"github.com/go-acme/lego/v4/platform/wait"
+	"github.com/go-acme/lego/v4/platform/observer"
+	"github.com/miekg/dns"
 	"golang.org/x/crypto/ocsp"
 	"golang.org/x/net/idna"
 )
@@ -30,6 +40,12 @@ const maxBodySize = 1024 * 1024
 // already PEM encoded and can be directly written to disk.
 // Certificate may be a certificate bundle,
 // depending on the options supplied to create it.
+//
+// Order and Authorizations are the raw ACME objects of the request (they are not persisted):
+// Order is the state of the order after its finalization (before its finalization with a dry-run),
+// Authorizations are the authorizations of the order, as fetched before solving the challenges.
+//
+// Stats are the statistics of the issuance (not persisted), set by Obtain and ObtainForCSR.
 type Resource struct {
 	Domain            string `json:"domain"`
 	CertURL           string `json:"certUrl"`
@@ -38,12 +54,21 @@ type Resource struct {
 	Certificate       []byte `json:"-"`
 	IssuerCertificate []byte `json:"-"`
 	CSR               []byte `json:"-"`
+
+	Order          *acme.ExtendedOrder  `json:"-"`
+	Authorizations []acme.Authorization `json:"-"`
+	Stats          *IssuanceStats       `json:"-"`
+
+	// FailedDomains the domains excluded from the certificate, and their errors (see ObtainRequest.SkipFailedDomains).
+	FailedDomains map[string]error `json:"-"`
 }
 
 // ObtainRequest The request to obtain certificate.
 //
 // The first domain in domains is used for the CommonName field of the certificate,
 // all other domains are added using the Subject Alternate Names extension.
+// A wildcard domain (e.g. `*.example.com`) doesn't imply its apex domain (`example.com`):
+// the apex domain must be added explicitly to be part of the certificate.
 //
 // A new private key is generated for every invocation of the function Obtain.
 // If you do not want that you can supply your own private key in the privateKey parameter.
@@ -51,77 +76,250 @@ type Resource struct {
 //
 // If `Bundle` is true, the `[]byte` contains both the issuer certificate and your issued certificate as a bundle.
 //
+// If `MustStaple` is true, the OCSP Must-Staple TLS feature extension (RFC 7633) is added to the generated CSR.
+// Some CAs don't support this extension (e.g. CAs without OCSP responders) and reject the finalization of the order.
+// See https://www.rfc-editor.org/rfc/rfc7633.html.
+//
+// `KeyUsage` and `ExtKeyUsages` add the key usage and the extended key usage extensions to the generated CSR
+// (e.g. "1.3.6.1.5.5.7.3.2" for the client authentication), see certcrypto.CSROptions.
+// The CA may ignore the requested usages, or reject the finalization of the order.
+//
 // If `AlwaysDeactivateAuthorizations` is true, the authorizations are also relinquished if the obtain request was successful.
 // See https://datatracker.ietf.org/doc/html/rfc8555#section-7.5.2.
+//
+// If `PreferredChain` is set and the CA offers alternate certificate chains,
+// the chain with a topmost issuer matching this common name (or distinguished name) is used.
+// If no chain matches, the default chain is used.
+//
+// `ReplacesCertID` is the ARI certificate identifier (see MakeARICertID) of the certificate replaced by the new one.
+// Alternatively, the replaced certificate can be set with `ReplacesCertificate`, the identifier is computed from it.
+// If the server rejects the order because of the replaced certificate, the order is created again without it.
+// See https://datatracker.ietf.org/doc/draft-ietf-acme-ari/.
+//
+// `Profile` is the name of the certificate profile to use, it must be advertised by the CA in its directory.
+// See https://datatracker.ietf.org/doc/draft-aaron-acme-profiles/.
+//
+// If `DryRun` is true, the order is created and the challenges are solved, but the order is not finalized:
+// no certificate is issued, and the returned resource only contains the domain.
+//
+// `FinalizeTimeout` and `FinalizeInterval` define how long and how often the order is polled after its finalization,
+// until the certificate is issued (by default, the timeout of the Certifier and 1/60 of the timeout).
+// The `Retry-After` header of the order responses takes precedence over the interval.
+//
+// `OrderURL` is the URL of an order created by a previous request (e.g. interrupted before the finalization) to resume:
+// the challenges of the valid authorizations are not solved again.
+// If the order cannot be resumed (e.g. expired, invalid, or for other identifiers), a new order is created.
+// `OrderCreated` is called with the URL of the order, once created or resumed, before solving the challenges:
+// it allows to persist the URL to resume the order later. Optional.
+//
+// If `SkipFailedDomains` is true and the authorizations of some domains fail,
+// a new order is created without these domains (an ACME order with a failed authorization cannot be finalized),
+// the CA usually reuses the valid authorizations for the new order.
+// The errors of the excluded domains are available in Resource.FailedDomains.
+//
+// `AutoRenewal` requests a Short-Term Automatic Renewal (STAR) order, the CA must advertise this capability in its directory:
+// the CA issues a new certificate before the expiration of the previous one, until the end date of the auto-renewal.
+// The URL of the current certificate is Resource.CertURL (see Certifier.Get), the auto-renewal can be canceled with Certifier.CancelAutoRenewal.
+// See https://www.rfc-editor.org/rfc/rfc8739.html.
 type ObtainRequest struct {
 	Domains                        []string
 	Bundle                         bool
 	PrivateKey                     crypto.PrivateKey
 	MustStaple                     bool
+	KeyUsage                       x509.KeyUsage
+	ExtKeyUsages                   []string
 	PreferredChain                 string
 	AlwaysDeactivateAuthorizations bool
+	ReplacesCertID                 string
+	ReplacesCertificate            *x509.Certificate
+	Profile                        string
+	DryRun                         bool
+	FinalizeTimeout                time.Duration
+	FinalizeInterval               time.Duration
+	OrderURL                       string
+	OrderCreated                   func(orderURL string)
+	SkipFailedDomains              bool
+	AutoRenewal                    *acme.AutoRenewal
 }
 
 // ObtainForCSRRequest The request to obtain a certificate matching the CSR passed into it.
 //
+// The extensions of the CSR (e.g. the OCSP Must-Staple TLS feature) are kept as-is.
+//
 // If `Bundle` is true, the `[]byte` contains both the issuer certificate and your issued certificate as a bundle.
 //
 // If `AlwaysDeactivateAuthorizations` is true, the authorizations are also relinquished if the obtain request was successful.
 // See https://datatracker.ietf.org/doc/html/rfc8555#section-7.5.2.
+//
+// If `PreferredChain` is set and the CA offers alternate certificate chains,
+// the chain with a topmost issuer matching this common name (or distinguished name) is used.
+// If no chain matches, the default chain is used.
+//
+// `ReplacesCertID` is the ARI certificate identifier (see MakeARICertID) of the certificate replaced by the new one.
+// Alternatively, the replaced certificate can be set with `ReplacesCertificate`, the identifier is computed from it.
+// If the server rejects the order because of the replaced certificate, the order is created again without it.
+// See https://datatracker.ietf.org/doc/draft-ietf-acme-ari/.
+//
+// `Profile` is the name of the certificate profile to use, it must be advertised by the CA in its directory.
+// See https://datatracker.ietf.org/doc/draft-aaron-acme-profiles/.
+//
+// If `DryRun` is true, the order is created and the challenges are solved, but the order is not finalized:
+// no certificate is issued, and the returned resource only contains the domain.
+//
+// `FinalizeTimeout` and `FinalizeInterval` define how long and how often the order is polled after its finalization,
+// until the certificate is issued (by default, the timeout of the Certifier and 1/60 of the timeout).
+// The `Retry-After` header of the order responses takes precedence over the interval.
+//
+// `OrderURL` and `OrderCreated` allow to resume an order, see ObtainRequest.
+//
+// `AutoRenewal` requests a Short-Term Automatic Renewal (STAR) order, see ObtainRequest.
 type ObtainForCSRRequest struct {
 	CSR                            *x509.CertificateRequest
 	Bundle                         bool
 	PreferredChain                 string
 	AlwaysDeactivateAuthorizations bool
+	ReplacesCertID                 string
+	ReplacesCertificate            *x509.Certificate
+	Profile                        string
+	DryRun                         bool
+	FinalizeTimeout                time.Duration
+	FinalizeInterval               time.Duration
+	OrderURL                       string
+	OrderCreated                   func(orderURL string)
+	AutoRenewal                    *acme.AutoRenewal
 }
 
 type resolver interface {
 	Solve(authorizations []acme.Authorization) error
 }
 
+// serialResolver serializes the resolutions of a resolver.
+// The solvers of the challenges have a shared state (e.g. the single-port servers of http-01 and tls-alpn-01,
+// the delay between the calls to Present of dns-01):
+// the challenges of the concurrent issuances (e.g. RenewAll) must not be solved at the same time.
+type serialResolver struct {
+	mu       *sync.Mutex
+	resolver resolver
+}
+
+func newSerialResolver(r resolver) serialResolver {
+	return serialResolver{mu: &sync.Mutex{}, resolver: r}
+}
+
+func (r serialResolver) Solve(authorizations []acme.Authorization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.resolver.Solve(authorizations)
+}
+
+func (r serialResolver) SolveContext(ctx context.Context, authorizations []acme.Authorization) error {
+	cr, ok := r.resolver.(contextResolver)
+	if !ok {
+		return r.Solve(authorizations)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return cr.SolveContext(ctx, authorizations)
+}
+
 type CertifierOptions struct {
 	KeyType certcrypto.KeyType
 	Timeout time.Duration
+
+	// Observer receives the duration of the creation of the orders and of the download of the certificates.
+	// Optional.
+	Observer observer.Observer
+
+	// CAAPreflight configures the CAA check done before the creation of the orders.
+	// Optional.
+	CAAPreflight CAAPreflight
 }
 
 // Certifier A service to obtain/renew/revoke certificates.
 type Certifier struct {
-	core     *api.Core
-	resolver resolver
-	options  CertifierOptions
+	core      *api.Core
+	resolver  resolver
+	options   CertifierOptions
+	caaLookup func(domain string) ([]*dns.CAA, error)
+	recorder  *statsRecorder
+
+	// ctx the context of the issuance (see ObtainWithContext).
+	ctx context.Context
 }
 
 // NewCertifier creates a Certifier.
 func NewCertifier(core *api.Core, resolver resolver, options CertifierOptions) *Certifier {
 	return &Certifier{
-		core:     core,
-		resolver: resolver,
-		options:  options,
+		core:      core,
+		resolver:  newSerialResolver(resolver),
+		options:   options,
+		caaLookup: dns01.LookupCAA,
+		recorder:  newStatsRecorder(options.Observer),
 	}
 }
 
+// Observer returns the observer to use for the solvers of the challenges:
+// it forwards the observations to the observer of the options,
+// and records the propagation waits and the challenge types for the statistics of the issuances (see IssuanceStats).
+func (c *Certifier) Observer() observer.Observer {
+	return c.recorder
+}
+
 // Obtain tries to obtain a single certificate using all domains passed into it.
 //
 // This function will never return a partial certificate.
-// If one domain in the list fails, the whole certificate will fail.
+// If one domain in the list fails, the whole certificate will fail (see ObtainRequest.SkipFailedDomains).
 func (c *Certifier) Obtain(request ObtainRequest) (*Resource, error) {
 	if len(request.Domains) == 0 {
 		return nil, errors.New("no domains to obtain a certificate for")
 	}
 
+	for _, usage := range request.ExtKeyUsages {
+		if _, err := certcrypto.ParseOID(usage); err != nil {
+			return nil, fmt.Errorf("extended key usage: %w", err)
+		}
+	}
+
 	domains := sanitizeDomain(request.Domains)
 
 	if request.Bundle {
-		log.Infof("[%s] acme: Obtaining bundled SAN certificate", strings.Join(domains, ", "))
+		log.Infof("[%s] acme: Obtaining bundled SAN certificate", displayDomains(domains))
 	} else {
-		log.Infof("[%s] acme: Obtaining SAN certificate", strings.Join(domains, ", "))
+		log.Infof("[%s] acme: Obtaining SAN certificate", displayDomains(domains))
+	}
+
+	if request.DryRun {
+		log.Infof("[%s] acme: dry-run: the order will be created and the challenges solved, but the order will not be finalized", strings.Join(domains, ", "))
+	}
+
+	iss := c.startIssuance()
+
+	err := c.checkCAA(domains)
+	if err != nil {
+		return nil, err
+	}
+
+	replacesCertID, err := getReplacesCertID(request.ReplacesCertID, request.ReplacesCertificate)
+	if err != nil {
+		return nil, err
 	}
 
-	order, err := c.core.Orders.New(domains)
+	order, err := c.createOrResumeOrder(domains, request.OrderURL, &api.OrderOptions{
+		ReplacesCertID: replacesCertID,
+		Profile:        request.Profile,
+		AutoRenewal:    request.AutoRenewal,
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	if request.OrderCreated != nil {
+		request.OrderCreated(order.Location)
+	}
+
 	authz, err := c.getAuthorizations(order)
 	if err != nil {
 		// If any challenge fails, return. Do not generate partial SAN certificates.
@@ -129,23 +327,56 @@ func (c *Certifier) Obtain(request ObtainRequest) (*Resource, error) {
 		return nil, err
 	}
 
+	c.recorder.reset(authz)
+
 	err = c.resolver.Solve(authz)
 	if err != nil {
+		var failed obtainError
+		if request.SkipFailedDomains {
+			// before the deactivation of the authorizations.
+			failed = c.getFailedDomains(order)
+		}
+
 		// If any challenge fails, return. Do not generate partial SAN certificates.
 		c.deactivateAuthorizations(order, request.AlwaysDeactivateAuthorizations)
+
+		if len(failed) > 0 {
+			return c.obtainWithoutFailedDomains(request, domains, failed, err)
+		}
+
 		return nil, err
 	}
 
+	if request.DryRun {
+		certRes := c.endDryRun(domains, order, authz, request.AlwaysDeactivateAuthorizations)
+		certRes.Stats = c.stats(iss, authz)
+
+		return certRes, nil
+	}
+
 	log.Infof("[%s] acme: Validations succeeded; requesting certificates", strings.Join(domains, ", "))
 
 	failures := make(obtainError)
-	cert, err := c.getForOrder(domains, order, request.Bundle, request.PrivateKey, request.MustStaple, request.PreferredChain)
+	polling := finalizePolling{timeout: request.FinalizeTimeout, interval: request.FinalizeInterval}
+
+	csrOptions := certcrypto.CSROptions{
+		MustStaple:   request.MustStaple,
+		KeyUsage:     request.KeyUsage,
+		ExtKeyUsages: request.ExtKeyUsages,
+	}
+
+	cert, err := c.getForOrder(domains, order, request.Bundle, request.PrivateKey, csrOptions, request.PreferredChain, polling)
 	if err != nil {
 		for _, auth := range authz {
 			failures[challenge.GetTargetedDomain(auth)] = err
 		}
 	}
 
+	if cert != nil {
+		cert.Authorizations = authz
+		cert.Stats = c.stats(iss, authz)
+	}
+
 	if request.AlwaysDeactivateAuthorizations {
 		c.deactivateAuthorizations(order, true)
 	}
@@ -160,7 +391,8 @@ func (c *Certifier) Obtain(request ObtainRequest) (*Resource, error) {
 
 // ObtainForCSR tries to obtain a certificate matching the CSR passed into it.
 //
-// The domains are inferred from the CommonName and SubjectAltNames, if any.
+// The identifiers of the order are inferred from the CommonName and the SubjectAltNames (DNS names and IP addresses).
+// The signature of the CSR is checked before the creation of the order.
 // The private key for this CSR is not required.
 //
 // If bundle is true, the []byte contains both the issuer certificate and your issued certificate as a bundle.
@@ -172,9 +404,17 @@ func (c *Certifier) ObtainForCSR(request ObtainForCSRRequest) (*Resource, error)
 		return nil, errors.New("cannot obtain resource for CSR: CSR is missing")
 	}
 
+	err := request.CSR.CheckSignature()
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain resource for CSR: invalid CSR signature: %w", err)
+	}
+
 	// figure out what domains it concerns
-	// start with the common name
+	// start with the common name, then the DNS and IP SANs
 	domains := certcrypto.ExtractDomainsCSR(request.CSR)
+	if len(domains) == 0 {
+		return nil, errors.New("cannot obtain resource for CSR: the CSR contains no common name, DNS or IP SANs")
+	}
 
 	if request.Bundle {
 		log.Infof("[%s] acme: Obtaining bundled SAN certificate given a CSR", strings.Join(domains, ", "))
@@ -182,11 +422,35 @@ func (c *Certifier) ObtainForCSR(request ObtainForCSRRequest) (*Resource, error)
 		log.Infof("[%s] acme: Obtaining SAN certificate given a CSR", strings.Join(domains, ", "))
 	}
 
-	order, err := c.core.Orders.New(domains)
+	if request.DryRun {
+		log.Infof("[%s] acme: dry-run: the order will be created and the challenges solved, but the order will not be finalized", strings.Join(domains, ", "))
+	}
+
+	iss := c.startIssuance()
+
+	err = c.checkCAA(domains)
+	if err != nil {
+		return nil, err
+	}
+
+	replacesCertID, err := getReplacesCertID(request.ReplacesCertID, request.ReplacesCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := c.createOrResumeOrder(domains, request.OrderURL, &api.OrderOptions{
+		ReplacesCertID: replacesCertID,
+		Profile:        request.Profile,
+		AutoRenewal:    request.AutoRenewal,
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	if request.OrderCreated != nil {
+		request.OrderCreated(order.Location)
+	}
+
 	authz, err := c.getAuthorizations(order)
 	if err != nil {
 		// If any challenge fails, return. Do not generate partial SAN certificates.
@@ -194,6 +458,8 @@ func (c *Certifier) ObtainForCSR(request ObtainForCSRRequest) (*Resource, error)
 		return nil, err
 	}
 
+	c.recorder.reset(authz)
+
 	err = c.resolver.Solve(authz)
 	if err != nil {
 		// If any challenge fails, return. Do not generate partial SAN certificates.
@@ -201,16 +467,30 @@ func (c *Certifier) ObtainForCSR(request ObtainForCSRRequest) (*Resource, error)
 		return nil, err
 	}
 
+	if request.DryRun {
+		certRes := c.endDryRun(domains, order, authz, request.AlwaysDeactivateAuthorizations)
+		certRes.Stats = c.stats(iss, authz)
+
+		return certRes, nil
+	}
+
 	log.Infof("[%s] acme: Validations succeeded; requesting certificates", strings.Join(domains, ", "))
 
 	failures := make(obtainError)
-	cert, err := c.getForCSR(domains, order, request.Bundle, request.CSR.Raw, nil, request.PreferredChain)
+	polling := finalizePolling{timeout: request.FinalizeTimeout, interval: request.FinalizeInterval}
+
+	cert, err := c.getForCSR(domains, order, request.Bundle, request.CSR.Raw, nil, request.PreferredChain, polling)
 	if err != nil {
 		for _, auth := range authz {
 			failures[challenge.GetTargetedDomain(auth)] = err
 		}
 	}
 
+	if cert != nil {
+		cert.Authorizations = authz
+		cert.Stats = c.stats(iss, authz)
+	}
+
 	if request.AlwaysDeactivateAuthorizations {
 		c.deactivateAuthorizations(order, true)
 	}
@@ -228,7 +508,7 @@ func (c *Certifier) ObtainForCSR(request ObtainForCSRRequest) (*Resource, error)
 	return cert, nil
 }
 
-func (c *Certifier) getForOrder(domains []string, order acme.ExtendedOrder, bundle bool, privateKey crypto.PrivateKey, mustStaple bool, preferredChain string) (*Resource, error) {
+func (c *Certifier) getForOrder(domains []string, order acme.ExtendedOrder, bundle bool, privateKey crypto.PrivateKey, csrOptions certcrypto.CSROptions, preferredChain string, polling finalizePolling) (*Resource, error) {
 	if privateKey == nil {
 		var err error
 		privateKey, err = certcrypto.GeneratePrivateKey(c.options.KeyType)
@@ -253,19 +533,18 @@ func (c *Certifier) getForOrder(domains []string, order acme.ExtendedOrder, bund
 		}
 	}
 
-	// TODO: should the CSR be customizable?
-	csr, err := certcrypto.GenerateCSR(privateKey, commonName, san, mustStaple)
+	csr, err := certcrypto.GenerateCSRWithOptions(privateKey, commonName, san, csrOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.getForCSR(domains, order, bundle, csr, certcrypto.PEMEncode(privateKey), preferredChain)
+	return c.getForCSR(domains, order, bundle, csr, certcrypto.PEMEncode(privateKey), preferredChain, polling)
 }
 
-func (c *Certifier) getForCSR(domains []string, order acme.ExtendedOrder, bundle bool, csr, privateKeyPem []byte, preferredChain string) (*Resource, error) {
+func (c *Certifier) getForCSR(domains []string, order acme.ExtendedOrder, bundle bool, csr, privateKeyPem []byte, preferredChain string, polling finalizePolling) (*Resource, error) {
 	respOrder, err := c.core.Orders.UpdateForCSR(order.Finalize, csr)
 	if err != nil {
-		return nil, err
+		return nil, checkKeyTypeError(csr, err)
 	}
 
 	commonName := domains[0]
@@ -287,26 +566,82 @@ func (c *Certifier) getForCSR(domains []string, order acme.ExtendedOrder, bundle
 		}
 	}
 
-	timeout := c.options.Timeout
-	if c.options.Timeout <= 0 {
+	err = c.waitForCertificate(order.Location, respOrder.RetryAfter, certRes, bundle, preferredChain, polling)
+
+	return certRes, err
+}
+
+// finalizePolling defines the polling of an order after its finalization.
+type finalizePolling struct {
+	timeout  time.Duration
+	interval time.Duration
+}
+
+// waitForCertificate polls the order until the certificate is available.
+// The delay between two requests is the value of the Retry-After header of the previous response, if any,
+// otherwise the polling interval.
+func (c *Certifier) waitForCertificate(orderURL, retryAfter string, certRes *Resource, bundle bool, preferredChain string, polling finalizePolling) error {
+	timeout := polling.timeout
+	if timeout <= 0 {
+		timeout = c.options.Timeout
+	}
+
+	if timeout <= 0 {
 		timeout = 30 * time.Second
 	}
 
-	err = wait.For("certificate", timeout, timeout/60, func() (bool, error) {
-		ord, errW := c.core.Orders.Get(order.Location)
-		if errW != nil {
-			return false, errW
+	interval := polling.interval
+	if interval <= 0 {
+		interval = timeout / 60
+	}
+
+	log.Infof("Wait for certificate [timeout: %s, interval: %s]", timeout, interval)
+
+	deadline := time.Now().Add(timeout)
+
+	// the first request is sent right away, unless the server asked to wait.
+	delay, _ := api.ParseRetryAfter(retryAfter)
+
+	var lastErr error
+
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			if lastErr == nil {
+				return errors.New("time limit exceeded")
+			}
+
+			return fmt.Errorf("time limit exceeded: last error: %w", lastErr)
 		}
 
-		done, errW := c.checkResponse(ord, certRes, bundle, preferredChain)
-		if errW != nil {
-			return false, errW
+		if delay > remaining {
+			delay = remaining
 		}
 
-		return done, nil
-	})
+		select {
+		case <-time.After(delay):
+		case <-c.context().Done():
+			return c.context().Err()
+		}
 
-	return certRes, err
+		ord, err := c.core.Orders.Get(orderURL)
+		if err == nil {
+			var done bool
+			done, err = c.checkResponse(ord, certRes, bundle, preferredChain)
+			if done {
+				return nil
+			}
+		}
+
+		if err != nil {
+			lastErr = err
+		}
+
+		delay = interval
+		if ra, ok := api.ParseRetryAfter(ord.RetryAfter); ok {
+			delay = ra
+		}
+	}
 }
 
 // checkResponse checks to see if the certificate is ready and a link is contained in the response.
@@ -323,16 +658,29 @@ func (c *Certifier) checkResponse(order acme.ExtendedOrder, certRes *Resource, b
 		return valid, err
 	}
 
-	certs, err := c.core.Certificates.GetAll(order.Certificate, bundle)
+	// the certificate of a STAR order is replaced by the CA, at the same URL.
+	certURL := order.Certificate
+	if certURL == "" {
+		certURL = order.StarCertificate
+	}
+
+	start := time.Now()
+
+	certs, err := c.core.Certificates.GetAll(certURL, bundle)
+
+	observer.Observe(c.options.Observer, observer.PhaseDownload, certRes.Domain, start)
+
 	if err != nil {
 		return false, err
 	}
 
+	certRes.Order = &order
+
 	// Set the default certificate
-	certRes.IssuerCertificate = certs[order.Certificate].Issuer
-	certRes.Certificate = certs[order.Certificate].Cert
-	certRes.CertURL = order.Certificate
-	certRes.CertStableURL = order.Certificate
+	certRes.IssuerCertificate = certs[certURL].Issuer
+	certRes.Certificate = certs[certURL].Cert
+	certRes.CertURL = certURL
+	certRes.CertStableURL = certURL
 
 	if preferredChain == "" {
 		log.Infof("[%s] Server responded with a certificate.", certRes.Domain)
@@ -340,25 +688,43 @@ func (c *Certifier) checkResponse(order acme.ExtendedOrder, certRes *Resource, b
 		return true, nil
 	}
 
-	for link, cert := range certs {
-		ok, err := hasPreferredChain(cert.Issuer, preferredChain)
+	// The default chain is evaluated first, then the alternate chains, in a stable order.
+	links := []string{certURL}
+	for link := range certs {
+		if link != certURL {
+			links = append(links, link)
+		}
+	}
+
+	sort.Strings(links[1:])
+
+	var available []string
+
+	for _, link := range links {
+		cert := certs[link]
+
+		issuer, err := getTopIssuer(cert.Issuer)
 		if err != nil {
 			return false, err
 		}
 
-		if ok {
-			log.Infof("[%s] Server responded with a certificate for the preferred certificate chains %q.", certRes.Domain, preferredChain)
+		if !matchPreferredChain(issuer, preferredChain) {
+			available = append(available, fmt.Sprintf("%q", issuer.CommonName))
+			continue
+		}
 
-			certRes.IssuerCertificate = cert.Issuer
-			certRes.Certificate = cert.Cert
-			certRes.CertURL = link
-			certRes.CertStableURL = link
+		log.Infof("[%s] Server responded with a certificate for the preferred certificate chains %q.", certRes.Domain, preferredChain)
 
-			return true, nil
-		}
+		certRes.IssuerCertificate = cert.Issuer
+		certRes.Certificate = cert.Cert
+		certRes.CertURL = link
+		certRes.CertStableURL = link
+
+		return true, nil
 	}
 
-	log.Infof("lego has been configured to prefer certificate chains with issuer %q, but no chain from the CA matched this issuer. Using the default certificate chain instead.", preferredChain)
+	log.Infof("lego has been configured to prefer certificate chains with issuer %q, but no chain from the CA matched this issuer (available chains: %s). Using the default certificate chain instead.",
+		preferredChain, strings.Join(available, ", "))
 
 	return true, nil
 }
@@ -369,15 +735,54 @@ func (c *Certifier) Revoke(cert []byte) error {
 }
 
 // RevokeWithReason takes a PEM encoded certificate or bundle and tries to revoke it at the CA.
+// The reason is one of the CRL reason codes defined by RFC 5280 (e.g. acme.CRLReasonKeyCompromise),
+// a nil reason omits the reason of the revocation.
 func (c *Certifier) RevokeWithReason(cert []byte, reason *uint) error {
-	certificates, err := certcrypto.ParsePEMBundle(cert)
+	revokeMsg, _, err := newRevokeMessage(cert, reason)
 	if err != nil {
 		return err
 	}
 
+	return c.core.Certificates.Revoke(revokeMsg)
+}
+
+// RevokeWithKey takes a PEM encoded certificate or bundle and tries to revoke it at the CA,
+// the request is signed with the private key of the certificate instead of the account key.
+// It allows to revoke a certificate without access to the account which requested it (e.g. after a key compromise).
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.6
+func (c *Certifier) RevokeWithKey(cert []byte, privateKey crypto.PrivateKey, reason *uint) error {
+	revokeMsg, x509Cert, err := newRevokeMessage(cert, reason)
+	if err != nil {
+		return err
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("unsupported private key type: %T", privateKey)
+	}
+
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(x509Cert.PublicKey) {
+		return errors.New("the private key does not match the public key of the certificate")
+	}
+
+	return c.core.Certificates.RevokeWithKey(revokeMsg, privateKey)
+}
+
+func newRevokeMessage(cert []byte, reason *uint) (acme.RevokeCertMessage, *x509.Certificate, error) {
+	err := validateRevocationReason(reason)
+	if err != nil {
+		return acme.RevokeCertMessage{}, nil, err
+	}
+
+	certificates, err := certcrypto.ParsePEMBundle(cert)
+	if err != nil {
+		return acme.RevokeCertMessage{}, nil, err
+	}
+
 	x509Cert := certificates[0]
 	if x509Cert.IsCA {
-		return errors.New("certificate bundle starts with a CA certificate")
+		return acme.RevokeCertMessage{}, nil, errors.New("certificate bundle starts with a CA certificate")
 	}
 
 	revokeMsg := acme.RevokeCertMessage{
@@ -385,7 +790,22 @@ func (c *Certifier) RevokeWithReason(cert []byte, reason *uint) error {
 		Reason:      reason,
 	}
 
-	return c.core.Certificates.Revoke(revokeMsg)
+	return revokeMsg, x509Cert, nil
+}
+
+// validateRevocationReason checks that the reason is a CRL reason code defined by RFC 5280.
+// - https://www.rfc-editor.org/rfc/rfc5280.html#section-5.3.1
+func validateRevocationReason(reason *uint) error {
+	if reason == nil {
+		return nil
+	}
+
+	// the value 7 is not used.
+	if *reason > acme.CRLReasonAACompromise || *reason == 7 {
+		return fmt.Errorf("invalid revocation reason: %d", *reason)
+	}
+
+	return nil
 }
 
 // Renew takes a Resource and tries to renew the certificate.
@@ -557,19 +977,19 @@ func (c *Certifier) Get(url string, bundle bool) (*Resource, error) {
 	}, nil
 }
 
-func hasPreferredChain(issuer []byte, preferredChain string) (bool, error) {
+// getTopIssuer returns the issuer of the topmost certificate of a chain.
+func getTopIssuer(issuer []byte) (pkix.Name, error) {
 	certs, err := certcrypto.ParsePEMBundle(issuer)
 	if err != nil {
-		return false, err
+		return pkix.Name{}, err
 	}
 
-	topCert := certs[len(certs)-1]
-
-	if topCert.Issuer.CommonName == preferredChain {
-		return true, nil
-	}
+	return certs[len(certs)-1].Issuer, nil
+}
 
-	return false, nil
+// matchPreferredChain checks if the preferred chain matches the common name or the distinguished name of an issuer.
+func matchPreferredChain(issuer pkix.Name, preferredChain string) bool {
+	return issuer.CommonName == preferredChain || issuer.String() == preferredChain
 }
 
 func checkOrderStatus(order acme.ExtendedOrder) (bool, error) {
@@ -577,6 +997,10 @@ func checkOrderStatus(order acme.ExtendedOrder) (bool, error) {
 	case acme.StatusValid:
 		return true, nil
 	case acme.StatusInvalid:
+		if order.Error == nil {
+			// avoids a non-nil error interface holding a nil problem.
+			return false, fmt.Errorf("the order state %s", order.Status)
+		}
 		return false, order.Error
 	default:
 		return false, nil
@@ -588,10 +1012,18 @@ func checkOrderStatus(order acme.ExtendedOrder) (bool, error) {
 // That is, it MUST be encoded according to the rules in Section 7 of [RFC5280].
 //
 // https://www.rfc-editor.org/rfc/rfc5280.html#section-7
+//
+// The internationalized domain names are converted to A-labels (IDNA 2008, UTS #46 mapping: e.g. `Müller.example` -> `xn--mller-kva.example`).
+// The IP addresses are kept in their canonical form.
 func sanitizeDomain(domains []string) []string {
 	var sanitizedDomains []string
 	for _, domain := range domains {
-		sanitizedDomain, err := idna.ToASCII(domain)
+		if ip := net.ParseIP(domain); ip != nil {
+			sanitizedDomains = append(sanitizedDomains, ip.String())
+			continue
+		}
+
+		sanitizedDomain, err := toALabel(domain)
 		if err != nil {
 			log.Infof("skip domain %q: unable to sanitize (punnycode): %v", domain, err)
 		} else {
@@ -600,3 +1032,215 @@ func sanitizeDomain(domains []string) []string {
 	}
 	return sanitizedDomains
 }
+
+// idnaProfile maps the IDNs like the lookup profile, without the STD3 and the hyphen rules:
+// the names like `a_b.example.com` or `ab--cd.example.com` are valid for the CAs.
+var idnaProfile = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.StrictDomainName(false), idna.CheckHyphens(false))
+
+// toALabel converts a domain to its A-label form, the wildcard prefix is kept.
+func toALabel(domain string) (string, error) {
+	if strings.HasPrefix(domain, "*.") {
+		aLabel, err := idnaProfile.ToASCII(strings.TrimPrefix(domain, "*."))
+		if err != nil {
+			return "", err
+		}
+
+		return "*." + aLabel, nil
+	}
+
+	return idnaProfile.ToASCII(domain)
+}
+
+// displayDomains formats the domains for the logs: the A-labels are followed by their U-labels.
+func displayDomains(domains []string) string {
+	var names []string
+	for _, domain := range domains {
+		uLabel, err := idnaProfile.ToUnicode(strings.TrimPrefix(domain, "*."))
+		if err != nil || uLabel == strings.TrimPrefix(domain, "*.") {
+			names = append(names, domain)
+			continue
+		}
+
+		if strings.HasPrefix(domain, "*.") {
+			uLabel = "*." + uLabel
+		}
+
+		names = append(names, fmt.Sprintf("%s (%s)", domain, uLabel))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// checkKeyTypeError adds the key type of the CSR to the error when the CA rejects the key of the CSR:
+// all the CAs don't support all the key types (e.g. Ed25519).
+func checkKeyTypeError(csr []byte, err error) error {
+	var problem *acme.ProblemDetails
+	if !errors.As(err, &problem) || (problem.Type != acme.BadCSRErr && problem.Type != acme.BadPublicKeyErr) {
+		return err
+	}
+
+	request, errP := x509.ParseCertificateRequest(csr)
+	if errP != nil {
+		return err
+	}
+
+	return fmt.Errorf("acme: the CA rejected the certificate key, the key type (%s) may not be supported by the CA: %w",
+		describePublicKey(request.PublicKey), err)
+}
+
+func describePublicKey(publicKey crypto.PublicKey) string {
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d", k.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "EC " + k.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "Ed25519"
+	default:
+		return fmt.Sprintf("%T", publicKey)
+	}
+}
+
+// newOrder creates a new order.
+// If the server rejects the certificate replaced by the order
+// (e.g. the `replaces` field is not supported, or the certificate has already been replaced),
+// the order is created again without the replaced certificate.
+func (c *Certifier) newOrder(domains []string, opts *api.OrderOptions) (acme.ExtendedOrder, error) {
+	start := time.Now()
+
+	order, err := c.core.Orders.NewWithOptions(domains, opts)
+	if err != nil && opts.ReplacesCertID != "" && isReplacesRejected(err) {
+		log.Warnf("[%s] acme: the order replacing the certificate %s has been rejected, retrying without the replaced certificate: %v",
+			strings.Join(domains, ", "), opts.ReplacesCertID, err)
+
+		retryOpts := *opts
+		retryOpts.ReplacesCertID = ""
+
+		order, err = c.core.Orders.NewWithOptions(domains, &retryOpts)
+	}
+
+	observer.Observe(c.options.Observer, observer.PhaseOrder, mainDomain(domains), start)
+
+	return order, err
+}
+
+// createOrResumeOrder resumes the order if orderURL is not empty and the order can be resumed,
+// otherwise creates a new order.
+func (c *Certifier) createOrResumeOrder(domains []string, orderURL string, opts *api.OrderOptions) (acme.ExtendedOrder, error) {
+	if orderURL == "" {
+		return c.newOrder(domains, opts)
+	}
+
+	order, err := c.resumeOrder(domains, orderURL, opts)
+	if err != nil {
+		log.Warnf("[%s] acme: the order %s cannot be resumed, creating a new order: %v", strings.Join(domains, ", "), orderURL, err)
+
+		return c.newOrder(domains, opts)
+	}
+
+	log.Infof("[%s] acme: resuming the order %s", strings.Join(domains, ", "), orderURL)
+
+	return order, nil
+}
+
+// resumeOrder gets an existing order, and checks that it can be used to obtain a certificate for the domains,
+// with the profile and the replaced certificate of the options.
+func (c *Certifier) resumeOrder(domains []string, orderURL string, opts *api.OrderOptions) (acme.ExtendedOrder, error) {
+	order, err := c.core.Orders.Get(orderURL)
+	if err != nil {
+		return acme.ExtendedOrder{}, err
+	}
+
+	order.Location = orderURL
+
+	if order.Status != acme.StatusPending && order.Status != acme.StatusReady {
+		return acme.ExtendedOrder{}, fmt.Errorf("the status of the order is %q", order.Status)
+	}
+
+	if order.Expires != "" {
+		expires, errP := time.Parse(time.RFC3339, order.Expires)
+		if errP == nil && !time.Now().Before(expires) {
+			return acme.ExtendedOrder{}, fmt.Errorf("the order has expired (%s)", order.Expires)
+		}
+	}
+
+	identifiers := make(map[string]struct{}, len(order.Identifiers))
+	for _, identifier := range order.Identifiers {
+		identifiers[strings.ToLower(identifier.Value)] = struct{}{}
+	}
+
+	expected := make(map[string]struct{}, len(domains))
+	for _, domain := range domains {
+		expected[strings.ToLower(domain)] = struct{}{}
+	}
+
+	if len(identifiers) != len(expected) {
+		return acme.ExtendedOrder{}, errors.New("the identifiers of the order don't match the domains")
+	}
+
+	for domain := range expected {
+		if _, ok := identifiers[domain]; !ok {
+			return acme.ExtendedOrder{}, errors.New("the identifiers of the order don't match the domains")
+		}
+	}
+
+	if opts == nil {
+		return order, nil
+	}
+
+	if opts.Profile != "" && order.Profile != opts.Profile {
+		return acme.ExtendedOrder{}, fmt.Errorf("the profile of the order is %q instead of %q", order.Profile, opts.Profile)
+	}
+
+	// an order without replaced certificate is accepted:
+	// the server may have rejected the replacement when the order was created (see newOrder).
+	if order.Replaces != "" && order.Replaces != opts.ReplacesCertID {
+		return acme.ExtendedOrder{}, fmt.Errorf("the order replaces the certificate %s", order.Replaces)
+	}
+
+	return order, nil
+}
+
+func isReplacesRejected(err error) bool {
+	var problem *acme.ProblemDetails
+	if !errors.As(err, &problem) {
+		return false
+	}
+
+	return problem.Type == acme.MalformedErr || problem.Type == acme.AlreadyReplacedErr
+}
+
+// getReplacesCertID returns the ARI certificate identifier of the replaced certificate.
+func getReplacesCertID(certID string, cert *x509.Certificate) (string, error) {
+	if certID != "" || cert == nil {
+		return certID, nil
+	}
+
+	certID, err := MakeARICertID(cert)
+	if err != nil {
+		return "", fmt.Errorf("error making the ARI certificate identifier of the replaced certificate: %w", err)
+	}
+
+	return certID, nil
+}
+
+// endDryRun ends a dry run before the finalization of the order.
+func (c *Certifier) endDryRun(domains []string, order acme.ExtendedOrder, authz []acme.Authorization, alwaysDeactivateAuthorizations bool) *Resource {
+	log.Infof("[%s] acme: dry-run: Validations succeeded; the finalization of the order and the download of the certificate are skipped",
+		strings.Join(domains, ", "))
+
+	if alwaysDeactivateAuthorizations {
+		c.deactivateAuthorizations(order, true)
+	}
+
+	return &Resource{Domain: mainDomain(domains), Order: &order, Authorizations: authz}
+}
+
+// mainDomain returns the domain used to identify a certificate request (the first one).
+func mainDomain(domains []string) string {
+	if len(domains) == 0 {
+		return ""
+	}
+
+	return domains[0]
+}
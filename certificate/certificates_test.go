@@ -1,17 +1,30 @@
 package certificate
 
 import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/acme/api"
 	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/platform/observer"
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-jose/go-jose/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -357,6 +370,89 @@ func Test_checkResponse_alternate(t *testing.T) {
 	assert.Equal(t, issuerMock2, string(certRes.IssuerCertificate), "IssuerCertificate")
 }
 
+func Test_checkResponse_preferredChain(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		preferredChain string
+		expectedURL    string
+		expectedCert   string
+		expectedIssuer string
+	}{
+		{
+			desc:           "match default chain",
+			preferredChain: "Pebble Root CA 50ffbd",
+			expectedURL:    "/certificate",
+			expectedCert:   certResponseMock,
+			expectedIssuer: issuerMock,
+		},
+		{
+			desc:           "match alternate chain distinguished name",
+			preferredChain: "CN=DST Root CA X3,O=Digital Signature Trust Co.",
+			expectedURL:    "/certificate/1",
+			expectedCert:   certResponseMock2,
+			expectedIssuer: issuerMock2,
+		},
+		{
+			desc:           "no match",
+			preferredChain: "ISRG Root X1",
+			expectedURL:    "/certificate",
+			expectedCert:   certResponseMock,
+			expectedIssuer: issuerMock,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			mux, apiURL := tester.SetupFakeAPI(t)
+
+			mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Add("Link", fmt.Sprintf(`<%s/certificate/1>;title="foo";rel="alternate"`, apiURL))
+
+				_, err := w.Write([]byte(certResponseMock))
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			mux.HandleFunc("/certificate/1", func(w http.ResponseWriter, _ *http.Request) {
+				_, err := w.Write([]byte(certResponseMock2))
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			require.NoError(t, err, "Could not generate test key")
+
+			core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+			require.NoError(t, err)
+
+			certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+			order := acme.ExtendedOrder{
+				Order: acme.Order{
+					Status:      acme.StatusValid,
+					Certificate: apiURL + "/certificate",
+				},
+			}
+			certRes := &Resource{
+				Domain: "example.com",
+			}
+
+			valid, err := certifier.checkResponse(order, certRes, true, test.preferredChain)
+			require.NoError(t, err)
+
+			assert.True(t, valid)
+			assert.Equal(t, apiURL+test.expectedURL, certRes.CertURL)
+			assert.Equal(t, apiURL+test.expectedURL, certRes.CertStableURL)
+			assert.Equal(t, test.expectedCert, string(certRes.Certificate), "Certificate")
+			assert.Equal(t, test.expectedIssuer, string(certRes.IssuerCertificate), "IssuerCertificate")
+		})
+	}
+}
+
 func Test_Get(t *testing.T) {
 	mux, apiURL := tester.SetupFakeAPI(t)
 
@@ -389,6 +485,166 @@ func Test_Get(t *testing.T) {
 	assert.Equal(t, issuerMock, string(certRes.IssuerCertificate), "IssuerCertificate")
 }
 
+func TestCertifier_Obtain_dryRun(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+
+		err := tester.WriteJSONResponse(w, acme.Order{
+			Status:         acme.StatusPending,
+			Identifiers:    []acme.Identifier{{Type: "dns", Value: "example.com"}},
+			Authorizations: []string{apiURL + "/authz/1"},
+			Finalize:       apiURL + "/finalize",
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusPending,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	var finalized bool
+
+	mux.HandleFunc("/finalize", func(w http.ResponseWriter, _ *http.Request) {
+		finalized = true
+
+		http.Error(w, "unexpected finalization", http.StatusBadRequest)
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	resolvr := &resolverRecorderMock{}
+
+	certifier := NewCertifier(core, resolvr, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	certRes, err := certifier.Obtain(ObtainRequest{Domains: []string{"example.com"}, DryRun: true})
+	require.NoError(t, err)
+
+	assert.False(t, finalized, "the order must not be finalized")
+	assert.Len(t, resolvr.authorizations, 1)
+
+	require.NotNil(t, certRes)
+	assert.Equal(t, "example.com", certRes.Domain)
+	assert.Nil(t, certRes.Certificate)
+	assert.Nil(t, certRes.PrivateKey)
+}
+
+func TestCertifier_Obtain_rawObjects(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	identifiers := []acme.Identifier{{Type: "dns", Value: "example.com"}, {Type: "dns", Value: "www.example.com"}}
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+
+		err := tester.WriteJSONResponse(w, acme.Order{
+			Status:         acme.StatusPending,
+			Identifiers:    identifiers,
+			Authorizations: []string{apiURL + "/authz/1", apiURL + "/authz/2"},
+			Finalize:       apiURL + "/finalize",
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	for i, identifier := range identifiers {
+		identifier := identifier
+
+		mux.HandleFunc(fmt.Sprintf("/authz/%d", i+1), func(w http.ResponseWriter, _ *http.Request) {
+			err := tester.WriteJSONResponse(w, acme.Authorization{
+				Status:     acme.StatusPending,
+				Identifier: identifier,
+				Challenges: []acme.Challenge{
+					{Type: "http-01", Status: acme.StatusPending, Token: "token"},
+					{Type: "dns-01", Status: acme.StatusPending, Token: "token"},
+				},
+			})
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		})
+	}
+
+	mux.HandleFunc("/finalize", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusValid,
+			Identifiers: identifiers,
+			Finalize:    apiURL + "/finalize",
+			Certificate: apiURL + "/certificate",
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(certResponseMock))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.EC256})
+
+	certRes, err := certifier.Obtain(ObtainRequest{Domains: []string{"example.com", "www.example.com"}})
+	require.NoError(t, err)
+
+	require.NotNil(t, certRes.Order)
+	assert.Equal(t, acme.StatusValid, certRes.Order.Status)
+	assert.Equal(t, identifiers, certRes.Order.Identifiers)
+	assert.Equal(t, apiURL+"/certificate", certRes.Order.Certificate)
+
+	require.Len(t, certRes.Authorizations, 2)
+
+	for _, authz := range certRes.Authorizations {
+		assert.Contains(t, identifiers, authz.Identifier)
+
+		var types []string
+		for _, chlg := range authz.Challenges {
+			types = append(types, chlg.Type)
+		}
+
+		assert.Equal(t, []string{"http-01", "dns-01"}, types)
+	}
+}
+
+type resolverRecorderMock struct {
+	authorizations []acme.Authorization
+}
+
+func (r *resolverRecorderMock) Solve(authorizations []acme.Authorization) error {
+	r.authorizations = append(r.authorizations, authorizations...)
+	return nil
+}
+
 type resolverMock struct {
 	error error
 }
@@ -396,3 +652,1567 @@ type resolverMock struct {
 func (r *resolverMock) Solve(_ []acme.Authorization) error {
 	return r.error
 }
+
+func TestCertifier_Obtain_replaces(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		rejected bool
+		expected []string
+	}{
+		{
+			desc:     "replaces accepted",
+			expected: []string{ariCertID},
+		},
+		{
+			desc:     "replaces rejected",
+			rejected: true,
+			expected: []string{ariCertID, ""},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			mux, apiURL := tester.SetupFakeAPI(t)
+
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			require.NoError(t, err, "Could not generate test key")
+
+			var replaces []string
+
+			mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+				body, errS := readSignedBody(r, key)
+				if errS != nil {
+					http.Error(w, errS.Error(), http.StatusBadRequest)
+					return
+				}
+
+				order := acme.Order{}
+				errS = json.Unmarshal(body, &order)
+				if errS != nil {
+					http.Error(w, errS.Error(), http.StatusBadRequest)
+					return
+				}
+
+				replaces = append(replaces, order.Replaces)
+
+				if test.rejected && order.Replaces != "" {
+					w.Header().Set("Content-Type", "application/problem+json")
+					w.WriteHeader(http.StatusBadRequest)
+					_ = json.NewEncoder(w).Encode(acme.ProblemDetails{
+						Type:   acme.MalformedErr,
+						Detail: "unknown field replaces",
+					})
+					return
+				}
+
+				w.Header().Set("Location", apiURL+"/order/1")
+				w.WriteHeader(http.StatusCreated)
+
+				errS = tester.WriteJSONResponse(w, acme.Order{
+					Status:         acme.StatusPending,
+					Identifiers:    []acme.Identifier{{Type: "dns", Value: "example.com"}},
+					Authorizations: []string{apiURL + "/authz/1"},
+					Finalize:       apiURL + "/finalize",
+				})
+				if errS != nil {
+					http.Error(w, errS.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+				errS := tester.WriteJSONResponse(w, acme.Authorization{
+					Status:     acme.StatusPending,
+					Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+				})
+				if errS != nil {
+					http.Error(w, errS.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+			require.NoError(t, err)
+
+			certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+			request := ObtainRequest{
+				Domains:             []string{"example.com"},
+				ReplacesCertificate: ariLeafCert(t),
+				DryRun:              true,
+			}
+
+			_, err = certifier.Obtain(request)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, replaces)
+		})
+	}
+}
+
+func TestCertifier_RevokeWithReason(t *testing.T) {
+	reason := func(v uint) *uint { return &v }
+
+	testCases := []struct {
+		desc     string
+		reason   *uint
+		expected string
+		err      string
+	}{
+		{
+			desc:     "without reason",
+			expected: `{"certificate":"%s"}`,
+		},
+		{
+			desc:     "key compromise",
+			reason:   reason(acme.CRLReasonKeyCompromise),
+			expected: `{"certificate":"%s","reason":1}`,
+		},
+		{
+			desc:     "unspecified",
+			reason:   reason(acme.CRLReasonUnspecified),
+			expected: `{"certificate":"%s","reason":0}`,
+		},
+		{
+			desc:   "unused value",
+			reason: reason(7),
+			err:    "invalid revocation reason: 7",
+		},
+		{
+			desc:   "out of range",
+			reason: reason(11),
+			err:    "invalid revocation reason: 11",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			mux, apiURL := tester.SetupFakeAPI(t)
+
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			require.NoError(t, err, "Could not generate test key")
+
+			var payloads []string
+
+			mux.HandleFunc("/revokeCert", func(w http.ResponseWriter, r *http.Request) {
+				body, errS := readSignedBody(r, key)
+				if errS != nil {
+					http.Error(w, errS.Error(), http.StatusBadRequest)
+					return
+				}
+
+				payloads = append(payloads, string(body))
+			})
+
+			core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+			require.NoError(t, err)
+
+			certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+			certPEM := generatePEMCertificate(t, time.Now(), time.Now().Add(24*time.Hour), false)
+
+			err = certifier.RevokeWithReason(certPEM, test.reason)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				assert.Empty(t, payloads)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			cert, err := certcrypto.ParsePEMCertificate(certPEM)
+			require.NoError(t, err)
+
+			expected := fmt.Sprintf(test.expected, base64.RawURLEncoding.EncodeToString(cert.Raw))
+
+			assert.Equal(t, []string{expected}, payloads)
+		})
+	}
+}
+
+func TestCertifier_RevokeWithKey(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	accountKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	cert, certKey := generateChainCertificate(t, "example.com", nil, nil, false)
+
+	var payloads []string
+
+	mux.HandleFunc("/revokeCert", func(w http.ResponseWriter, r *http.Request) {
+		reqBody, errR := io.ReadAll(r.Body)
+		if errR != nil {
+			http.Error(w, errR.Error(), http.StatusBadRequest)
+			return
+		}
+
+		jws, errR := jose.ParseSigned(string(reqBody))
+		if errR != nil {
+			http.Error(w, errR.Error(), http.StatusBadRequest)
+			return
+		}
+
+		header := jws.Signatures[0].Protected
+		if header.KeyID != "" || header.JSONWebKey == nil {
+			http.Error(w, "the JWS must contain the JWK of the certificate key", http.StatusBadRequest)
+			return
+		}
+
+		if !certKey.Public().(*ecdsa.PublicKey).Equal(header.JSONWebKey.Key) {
+			http.Error(w, "the JWK is not the public key of the certificate", http.StatusBadRequest)
+			return
+		}
+
+		body, errR := jws.Verify(certKey.Public())
+		if errR != nil {
+			http.Error(w, errR.Error(), http.StatusBadRequest)
+			return
+		}
+
+		payloads = append(payloads, string(body))
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "https://example.com/acme/acct/1", accountKey)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	reason := uint(acme.CRLReasonKeyCompromise)
+
+	err = certifier.RevokeWithKey(pemEncode(cert), certKey, &reason)
+	require.NoError(t, err)
+
+	expected := fmt.Sprintf(`{"certificate":"%s","reason":1}`, base64.RawURLEncoding.EncodeToString(cert.Raw))
+
+	assert.Equal(t, []string{expected}, payloads)
+}
+
+func TestCertifier_RevokeWithKey_keyMismatch(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	accountKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	cert, _ := generateChainCertificate(t, "example.com", nil, nil, false)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", accountKey)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	err = certifier.RevokeWithKey(pemEncode(cert), accountKey, nil)
+	require.EqualError(t, err, "the private key does not match the public key of the certificate")
+}
+
+func readSignedBody(r *http.Request, privateKey *rsa.PrivateKey) ([]byte, error) {
+	reqBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	jws, err := jose.ParseSigned(string(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := jws.Verify(&jose.JSONWebKey{
+		Key:       privateKey.Public(),
+		Algorithm: "RSA",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func TestCertifier_Obtain_keyTypes(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		keyType   certcrypto.KeyType
+		rejected  bool
+		expected  x509.PublicKeyAlgorithm
+		expectErr string
+	}{
+		{desc: "P256", keyType: certcrypto.EC256, expected: x509.ECDSA},
+		{desc: "P384", keyType: certcrypto.EC384, expected: x509.ECDSA},
+		{desc: "P521", keyType: certcrypto.EC521, expected: x509.ECDSA},
+		{desc: "Ed25519", keyType: certcrypto.ED25519, expected: x509.Ed25519},
+		{desc: "RSA2048", keyType: certcrypto.RSA2048, expected: x509.RSA},
+		{
+			desc:      "key type rejected by the CA",
+			keyType:   certcrypto.ED25519,
+			rejected:  true,
+			expectErr: "acme: the CA rejected the certificate key, the key type (Ed25519) may not be supported by the CA: acme: error: 400 :: POST :: ",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			mux, apiURL := tester.SetupFakeAPI(t)
+
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			require.NoError(t, err, "Could not generate test key")
+
+			mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Location", apiURL+"/order/1")
+				w.WriteHeader(http.StatusCreated)
+
+				errW := tester.WriteJSONResponse(w, acme.Order{
+					Status:         acme.StatusReady,
+					Identifiers:    []acme.Identifier{{Type: "dns", Value: "example.com"}},
+					Authorizations: []string{apiURL + "/authz/1"},
+					Finalize:       apiURL + "/finalize",
+				})
+				if errW != nil {
+					http.Error(w, errW.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+				errW := tester.WriteJSONResponse(w, acme.Authorization{
+					Status:     acme.StatusValid,
+					Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+				})
+				if errW != nil {
+					http.Error(w, errW.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			var algorithm x509.PublicKeyAlgorithm
+
+			mux.HandleFunc("/finalize", func(w http.ResponseWriter, r *http.Request) {
+				body, errS := readSignedBody(r, key)
+				if errS != nil {
+					http.Error(w, errS.Error(), http.StatusBadRequest)
+					return
+				}
+
+				var msg acme.CSRMessage
+				errS = json.Unmarshal(body, &msg)
+				if errS != nil {
+					http.Error(w, errS.Error(), http.StatusBadRequest)
+					return
+				}
+
+				raw, errS := base64.RawURLEncoding.DecodeString(msg.Csr)
+				if errS != nil {
+					http.Error(w, errS.Error(), http.StatusBadRequest)
+					return
+				}
+
+				csr, errS := x509.ParseCertificateRequest(raw)
+				if errS != nil {
+					http.Error(w, errS.Error(), http.StatusBadRequest)
+					return
+				}
+
+				algorithm = csr.PublicKeyAlgorithm
+
+				if test.rejected {
+					w.Header().Set("Content-Type", "application/problem+json")
+					w.WriteHeader(http.StatusBadRequest)
+					_ = json.NewEncoder(w).Encode(acme.ProblemDetails{
+						Type:       acme.BadCSRErr,
+						Detail:     "unsupported key type",
+						HTTPStatus: http.StatusBadRequest,
+					})
+					return
+				}
+
+				errS = tester.WriteJSONResponse(w, acme.Order{
+					Status:      acme.StatusValid,
+					Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}},
+					Certificate: apiURL + "/certificate",
+				})
+				if errS != nil {
+					http.Error(w, errS.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+				_, errW := w.Write([]byte(certResponseMock))
+				if errW != nil {
+					http.Error(w, errW.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+			require.NoError(t, err)
+
+			certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: test.keyType})
+
+			certRes, err := certifier.Obtain(ObtainRequest{Domains: []string{"example.com"}})
+
+			if test.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectErr)
+				assert.Contains(t, err.Error(), acme.BadCSRErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, algorithm)
+
+			privateKey, err := certcrypto.ParsePEMPrivateKey(certRes.PrivateKey)
+			require.NoError(t, err)
+			assert.NotNil(t, privateKey)
+		})
+	}
+}
+
+func TestCertifier_Obtain_keyUsages(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+
+		errW := tester.WriteJSONResponse(w, acme.Order{
+			Status:         acme.StatusReady,
+			Identifiers:    []acme.Identifier{{Type: "dns", Value: "example.com"}},
+			Authorizations: []string{apiURL + "/authz/1"},
+			Finalize:       apiURL + "/finalize",
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusValid,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	var extensions []pkix.Extension
+
+	mux.HandleFunc("/finalize", func(w http.ResponseWriter, r *http.Request) {
+		body, errS := readSignedBody(r, key)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var msg acme.CSRMessage
+		errS = json.Unmarshal(body, &msg)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		raw, errS := base64.RawURLEncoding.DecodeString(msg.Csr)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		csr, errS := x509.ParseCertificateRequest(raw)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		extensions = csr.Extensions
+
+		errS = tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusValid,
+			Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}},
+			Certificate: apiURL + "/certificate",
+		})
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+		_, errW := w.Write([]byte(certResponseMock))
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.EC256})
+
+	_, err = certifier.Obtain(ObtainRequest{
+		Domains:      []string{"example.com"},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsages: []string{"1.3.6.1.5.5.7.3.2"},
+	})
+	require.NoError(t, err)
+
+	var keyUsage asn1.BitString
+	var extKeyUsages []asn1.ObjectIdentifier
+
+	for _, ext := range extensions {
+		switch {
+		case ext.Id.Equal(asn1.ObjectIdentifier{2, 5, 29, 15}):
+			_, err = asn1.Unmarshal(ext.Value, &keyUsage)
+			require.NoError(t, err)
+		case ext.Id.Equal(asn1.ObjectIdentifier{2, 5, 29, 37}):
+			_, err = asn1.Unmarshal(ext.Value, &extKeyUsages)
+			require.NoError(t, err)
+		}
+	}
+
+	// the digital signature is the first bit.
+	assert.Equal(t, asn1.BitString{Bytes: []byte{0x80}, BitLength: 1}, keyUsage)
+	assert.Equal(t, []asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 3, 2}}, extKeyUsages)
+}
+
+func TestCertifier_Obtain_invalidExtKeyUsage(t *testing.T) {
+	// the CA must not be called.
+	certifier := NewCertifier(nil, &resolverMock{}, CertifierOptions{KeyType: certcrypto.EC256})
+
+	_, err := certifier.Obtain(ObtainRequest{
+		Domains:      []string{"example.com"},
+		ExtKeyUsages: []string{"1.3.6.1.5.5.7.3.2", "1.3.6.x"},
+	})
+	require.EqualError(t, err, `extended key usage: invalid OID "1.3.6.x": invalid arc "x"`)
+}
+
+func TestCertifier_Obtain_idna(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	expected := []string{"xn--mller-kva.example", "*.xn--mller-kva.example", "www.example.com"}
+
+	var identifiers []acme.Identifier
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		body, errS := readSignedBody(r, key)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var order acme.Order
+		errS = json.Unmarshal(body, &order)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		identifiers = order.Identifiers
+
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+
+		errS = tester.WriteJSONResponse(w, acme.Order{
+			Status:         acme.StatusReady,
+			Identifiers:    order.Identifiers,
+			Authorizations: []string{apiURL + "/authz/1"},
+			Finalize:       apiURL + "/finalize",
+		})
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusValid,
+			Identifier: acme.Identifier{Type: "dns", Value: "xn--mller-kva.example"},
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	var csr *x509.CertificateRequest
+
+	mux.HandleFunc("/finalize", func(w http.ResponseWriter, r *http.Request) {
+		body, errS := readSignedBody(r, key)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var msg acme.CSRMessage
+		errS = json.Unmarshal(body, &msg)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		raw, errS := base64.RawURLEncoding.DecodeString(msg.Csr)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		csr, errS = x509.ParseCertificateRequest(raw)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		errS = tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusValid,
+			Certificate: apiURL + "/certificate",
+		})
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+		_, errW := w.Write([]byte(certResponseMock))
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.EC256})
+
+	certRes, err := certifier.Obtain(ObtainRequest{Domains: []string{"Müller.example", "*.müller.example", "www.example.com"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "xn--mller-kva.example", certRes.Domain)
+
+	var values []string
+	for _, identifier := range identifiers {
+		values = append(values, identifier.Value)
+	}
+
+	assert.Equal(t, expected, values)
+
+	require.NotNil(t, csr)
+	assert.Equal(t, "xn--mller-kva.example", csr.Subject.CommonName)
+	assert.Equal(t, expected, csr.DNSNames)
+}
+
+func Test_sanitizeDomain(t *testing.T) {
+	domains := []string{"Müller.example", "*.MÜLLER.example", "xn--mller-kva.example", "EXAMPLE.com", "ß.de", "2001:db8::0:1", "a_b.example.com", "ab--cd.example.com", "xn--zz.example"}
+
+	expected := []string{"xn--mller-kva.example", "*.xn--mller-kva.example", "xn--mller-kva.example", "example.com", "xn--zca.de", "2001:db8::1", "a_b.example.com", "ab--cd.example.com"}
+
+	assert.Equal(t, expected, sanitizeDomain(domains))
+}
+
+func Test_displayDomains(t *testing.T) {
+	actual := displayDomains([]string{"xn--mller-kva.example", "*.xn--mller-kva.example", "example.com"})
+
+	assert.Equal(t, "xn--mller-kva.example (müller.example), *.xn--mller-kva.example (*.müller.example), example.com", actual)
+}
+
+func TestCertifier_Obtain_finalizePolling(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		interval    time.Duration
+		retryAfter  string
+		expectedGap time.Duration
+	}{
+		{
+			desc:        "configured interval",
+			interval:    100 * time.Millisecond,
+			expectedGap: 100 * time.Millisecond,
+		},
+		{
+			desc:        "Retry-After",
+			interval:    10 * time.Millisecond,
+			retryAfter:  "1",
+			expectedGap: time.Second,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mux, apiURL := tester.SetupFakeAPI(t)
+
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			require.NoError(t, err, "Could not generate test key")
+
+			mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Location", apiURL+"/order/1")
+				w.WriteHeader(http.StatusCreated)
+
+				errW := tester.WriteJSONResponse(w, acme.Order{
+					Status:         acme.StatusReady,
+					Identifiers:    []acme.Identifier{{Type: "dns", Value: "example.com"}},
+					Authorizations: []string{apiURL + "/authz/1"},
+					Finalize:       apiURL + "/finalize",
+				})
+				if errW != nil {
+					http.Error(w, errW.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+				errW := tester.WriteJSONResponse(w, acme.Authorization{
+					Status:     acme.StatusValid,
+					Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+				})
+				if errW != nil {
+					http.Error(w, errW.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			mux.HandleFunc("/finalize", func(w http.ResponseWriter, _ *http.Request) {
+				errW := tester.WriteJSONResponse(w, acme.Order{
+					Status:      acme.StatusProcessing,
+					Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}},
+				})
+				if errW != nil {
+					http.Error(w, errW.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			var polls []time.Time
+
+			mux.HandleFunc("/order/1", func(w http.ResponseWriter, _ *http.Request) {
+				polls = append(polls, time.Now())
+
+				order := acme.Order{
+					Status:      acme.StatusProcessing,
+					Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}},
+				}
+
+				// valid after 3 "processing" responses.
+				if len(polls) > 3 {
+					order.Status = acme.StatusValid
+					order.Certificate = apiURL + "/certificate"
+				}
+
+				if test.retryAfter != "" {
+					w.Header().Set("Retry-After", test.retryAfter)
+				}
+
+				errW := tester.WriteJSONResponse(w, order)
+				if errW != nil {
+					http.Error(w, errW.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+				_, errW := w.Write([]byte(certResponseMock))
+				if errW != nil {
+					http.Error(w, errW.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+			require.NoError(t, err)
+
+			certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.EC256})
+
+			certRes, err := certifier.Obtain(ObtainRequest{
+				Domains:          []string{"example.com"},
+				FinalizeTimeout:  10 * time.Second,
+				FinalizeInterval: test.interval,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, apiURL+"/certificate", certRes.CertURL)
+
+			require.Len(t, polls, 4)
+
+			for i := 1; i < len(polls); i++ {
+				assert.GreaterOrEqual(t, polls[i].Sub(polls[i-1]), test.expectedGap)
+			}
+		})
+	}
+}
+
+func TestCertifier_Obtain_finalizeTimeout(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+
+		errW := tester.WriteJSONResponse(w, acme.Order{
+			Status:         acme.StatusReady,
+			Identifiers:    []acme.Identifier{{Type: "dns", Value: "example.com"}},
+			Authorizations: []string{apiURL + "/authz/1"},
+			Finalize:       apiURL + "/finalize",
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusValid,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	processing := func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusProcessing,
+			Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}},
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	mux.HandleFunc("/finalize", processing)
+	mux.HandleFunc("/order/1", processing)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.EC256})
+
+	start := time.Now()
+
+	_, err = certifier.Obtain(ObtainRequest{
+		Domains:          []string{"example.com"},
+		FinalizeTimeout:  300 * time.Millisecond,
+		FinalizeInterval: 50 * time.Millisecond,
+	})
+	require.ErrorContains(t, err, "time limit exceeded")
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// resolverRecorder records the authorizations to solve, the valid authorizations are skipped (like the Prober).
+type resolverRecorder struct {
+	solved []acme.Authorization
+}
+
+func (r *resolverRecorder) Solve(authorizations []acme.Authorization) error {
+	for _, authz := range authorizations {
+		if authz.Status != acme.StatusValid {
+			r.solved = append(r.solved, authz)
+		}
+	}
+
+	return nil
+}
+
+func TestCertifier_Obtain_resumeOrder(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		expires         string
+		authzStatus     string
+		expectedNew     bool
+		expectedSolved  int
+		expectedCreated string
+	}{
+		{
+			desc:            "ready order with valid authorizations",
+			expires:         time.Now().Add(time.Hour).Format(time.RFC3339),
+			authzStatus:     acme.StatusValid,
+			expectedCreated: "/order/1",
+		},
+		{
+			desc:            "expired order",
+			expires:         time.Now().Add(-time.Hour).Format(time.RFC3339),
+			authzStatus:     acme.StatusPending,
+			expectedNew:     true,
+			expectedSolved:  1,
+			expectedCreated: "/order/2",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mux, apiURL := tester.SetupFakeAPI(t)
+
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			require.NoError(t, err, "Could not generate test key")
+
+			var newOrders int
+
+			mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+				newOrders++
+
+				w.Header().Set("Location", apiURL+"/order/2")
+				w.WriteHeader(http.StatusCreated)
+
+				errW := tester.WriteJSONResponse(w, acme.Order{
+					Status:         acme.StatusPending,
+					Identifiers:    []acme.Identifier{{Type: "dns", Value: "example.com"}},
+					Authorizations: []string{apiURL + "/authz/2"},
+					Finalize:       apiURL + "/finalize",
+				})
+				if errW != nil {
+					http.Error(w, errW.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			mux.HandleFunc("/order/1", func(w http.ResponseWriter, _ *http.Request) {
+				errW := tester.WriteJSONResponse(w, acme.Order{
+					Status:         acme.StatusReady,
+					Expires:        test.expires,
+					Identifiers:    []acme.Identifier{{Type: "dns", Value: "example.com"}},
+					Authorizations: []string{apiURL + "/authz/1"},
+					Finalize:       apiURL + "/finalize",
+				})
+				if errW != nil {
+					http.Error(w, errW.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			authz := func(status string) http.HandlerFunc {
+				return func(w http.ResponseWriter, _ *http.Request) {
+					errW := tester.WriteJSONResponse(w, acme.Authorization{
+						Status:     status,
+						Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+					})
+					if errW != nil {
+						http.Error(w, errW.Error(), http.StatusInternalServerError)
+						return
+					}
+				}
+			}
+
+			mux.HandleFunc("/authz/1", authz(test.authzStatus))
+			mux.HandleFunc("/authz/2", authz(acme.StatusPending))
+
+			mux.HandleFunc("/finalize", func(w http.ResponseWriter, _ *http.Request) {
+				errW := tester.WriteJSONResponse(w, acme.Order{
+					Status:      acme.StatusValid,
+					Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}},
+					Certificate: apiURL + "/certificate",
+				})
+				if errW != nil {
+					http.Error(w, errW.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+				_, errW := w.Write([]byte(certResponseMock))
+				if errW != nil {
+					http.Error(w, errW.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+			require.NoError(t, err)
+
+			resolver := &resolverRecorder{}
+
+			certifier := NewCertifier(core, resolver, CertifierOptions{KeyType: certcrypto.EC256})
+
+			var created string
+
+			certRes, err := certifier.Obtain(ObtainRequest{
+				Domains:      []string{"example.com"},
+				OrderURL:     apiURL + "/order/1",
+				OrderCreated: func(orderURL string) { created = orderURL },
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, apiURL+"/certificate", certRes.CertURL)
+			assert.Equal(t, apiURL+test.expectedCreated, created)
+			assert.Len(t, resolver.solved, test.expectedSolved)
+
+			if test.expectedNew {
+				assert.Equal(t, 1, newOrders)
+			} else {
+				assert.Zero(t, newOrders, "the order must be resumed")
+			}
+		})
+	}
+}
+
+func Test_resumeOrder_identifiers(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	mux.HandleFunc("/order/1", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusPending,
+			Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}, {Type: "dns", Value: "www.example.com"}},
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.EC256})
+
+	order, err := certifier.resumeOrder([]string{"www.example.com", "Example.com"}, apiURL+"/order/1", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, apiURL+"/order/1", order.Location)
+
+	_, err = certifier.resumeOrder([]string{"example.com"}, apiURL+"/order/1", nil)
+	require.EqualError(t, err, "the identifiers of the order don't match the domains")
+}
+
+func Test_resumeOrder_options(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	mux.HandleFunc("/order/1", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusPending,
+			Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}},
+			Profile:     "classic",
+			Replaces:    "cert-1",
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.EC256})
+
+	testCases := []struct {
+		desc     string
+		opts     *api.OrderOptions
+		expected string
+	}{
+		{
+			desc: "same options",
+			opts: &api.OrderOptions{Profile: "classic", ReplacesCertID: "cert-1"},
+		},
+		{
+			desc: "default profile",
+			opts: &api.OrderOptions{ReplacesCertID: "cert-1"},
+		},
+		{
+			desc:     "other profile",
+			opts:     &api.OrderOptions{Profile: "shortlived", ReplacesCertID: "cert-1"},
+			expected: `the profile of the order is "classic" instead of "shortlived"`,
+		},
+		{
+			desc:     "other replaced certificate",
+			opts:     &api.OrderOptions{Profile: "classic", ReplacesCertID: "cert-2"},
+			expected: "the order replaces the certificate cert-1",
+		},
+		{
+			desc:     "no replaced certificate",
+			opts:     &api.OrderOptions{Profile: "classic"},
+			expected: "the order replaces the certificate cert-1",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := certifier.resumeOrder([]string{"example.com"}, apiURL+"/order/1", test.opts)
+			if test.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+// resolverObserverMock simulates the observations of the solvers.
+type resolverObserverMock struct {
+	observer observer.Observer
+}
+
+func (r *resolverObserverMock) Solve(authorizations []acme.Authorization) error {
+	for _, authz := range authorizations {
+		r.observer.ObservePhase(observer.PhasePropagation, authz.Identifier.Value, 2*time.Second)
+		r.observer.ObservePhase(observer.PhaseValidation, authz.Identifier.Value, time.Second)
+		observer.ObserveChallenge(r.observer, authz.Identifier.Value, "dns-01")
+	}
+
+	return nil
+}
+
+func TestCertifier_Obtain_stats(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	var newOrders int
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		newOrders++
+
+		// the first attempt is rejected because of the nonce.
+		if newOrders == 1 {
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.Header().Set("Replay-Nonce", "fresh")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"type":"urn:ietf:params:acme:error:badNonce","detail":"JWS has an invalid anti-replay nonce","status":400}`))
+
+			return
+		}
+
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+
+		errW := tester.WriteJSONResponse(w, acme.Order{
+			Status:         acme.StatusPending,
+			Identifiers:    []acme.Identifier{{Type: "dns", Value: "example.com"}, {Type: "dns", Value: "www.example.com"}},
+			Authorizations: []string{apiURL + "/authz/1", apiURL + "/authz/2"},
+			Finalize:       apiURL + "/finalize",
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	authz := func(domain string) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			errW := tester.WriteJSONResponse(w, acme.Authorization{
+				Status:     acme.StatusPending,
+				Identifier: acme.Identifier{Type: "dns", Value: domain},
+			})
+			if errW != nil {
+				http.Error(w, errW.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	mux.HandleFunc("/authz/1", authz("example.com"))
+	mux.HandleFunc("/authz/2", authz("www.example.com"))
+
+	mux.HandleFunc("/finalize", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusValid,
+			Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}, {Type: "dns", Value: "www.example.com"}},
+			Certificate: apiURL + "/certificate",
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+		_, errW := w.Write([]byte(certResponseMock))
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	var phases []observer.Phase
+
+	resolver := &resolverObserverMock{}
+
+	certifier := NewCertifier(core, resolver, CertifierOptions{
+		KeyType: certcrypto.EC256,
+		Observer: observer.Func(func(phase observer.Phase, _ string, _ time.Duration) {
+			phases = append(phases, phase)
+		}),
+	})
+
+	resolver.observer = certifier.Observer()
+
+	certRes, err := certifier.Obtain(ObtainRequest{Domains: []string{"example.com", "www.example.com"}})
+	require.NoError(t, err)
+
+	require.NotNil(t, certRes.Stats)
+
+	assert.EqualValues(t, 1, certRes.Stats.NonceRetries)
+	assert.Equal(t, 4*time.Second, certRes.Stats.PropagationWait)
+	assert.Equal(t, map[string]string{"example.com": "dns-01", "www.example.com": "dns-01"}, certRes.Stats.ChallengeTypes)
+	assert.Positive(t, certRes.Stats.Duration)
+
+	// the observations are forwarded to the observer of the options.
+	expected := []observer.Phase{
+		observer.PhaseOrder,
+		observer.PhasePropagation, observer.PhaseValidation,
+		observer.PhasePropagation, observer.PhaseValidation,
+		observer.PhaseDownload,
+	}
+	assert.Equal(t, expected, phases)
+}
+
+func TestCertifier_ObtainForCSR_identifiers(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	var identifiers []acme.Identifier
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		body, errS := readSignedBody(r, key)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var order acme.Order
+		errS = json.Unmarshal(body, &order)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		identifiers = order.Identifiers
+
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+
+		errS = tester.WriteJSONResponse(w, acme.Order{
+			Status:         acme.StatusPending,
+			Identifiers:    order.Identifiers,
+			Authorizations: []string{apiURL + "/authz/1"},
+			Finalize:       apiURL + "/finalize",
+		})
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusPending,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	// the common name is also a SAN, it must not be duplicated.
+	rawCSR, err := certcrypto.GenerateCSR(privateKey, "example.com", []string{"example.com", "www.example.com", "example.org", "192.0.2.1", "2001:db8::1"}, false)
+	require.NoError(t, err)
+
+	csr, err := x509.ParseCertificateRequest(rawCSR)
+	require.NoError(t, err)
+
+	certRes, err := certifier.ObtainForCSR(ObtainForCSRRequest{CSR: csr, DryRun: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", certRes.Domain)
+
+	expected := []acme.Identifier{
+		{Type: "dns", Value: "example.com"},
+		{Type: "dns", Value: "www.example.com"},
+		{Type: "dns", Value: "example.org"},
+		{Type: "ip", Value: "192.0.2.1"},
+		{Type: "ip", Value: "2001:db8::1"},
+	}
+
+	assert.Equal(t, expected, identifiers)
+}
+
+func TestCertifier_ObtainForCSR_invalidCSR(t *testing.T) {
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		csr      func(t *testing.T) *x509.CertificateRequest
+		expected string
+	}{
+		{
+			desc: "invalid signature",
+			csr: func(t *testing.T) *x509.CertificateRequest {
+				t.Helper()
+
+				rawCSR, err := certcrypto.GenerateCSR(privateKey, "example.com", []string{"example.com"}, false)
+				require.NoError(t, err)
+
+				csr, err := x509.ParseCertificateRequest(rawCSR)
+				require.NoError(t, err)
+
+				// the signature doesn't match the content anymore.
+				csr.Signature[len(csr.Signature)-1] ^= 0xff
+
+				return csr
+			},
+			expected: "cannot obtain resource for CSR: invalid CSR signature: ",
+		},
+		{
+			desc: "no identifiers",
+			csr: func(t *testing.T) *x509.CertificateRequest {
+				t.Helper()
+
+				rawCSR, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, privateKey)
+				require.NoError(t, err)
+
+				csr, err := x509.ParseCertificateRequest(rawCSR)
+				require.NoError(t, err)
+
+				return csr
+			},
+			expected: "cannot obtain resource for CSR: the CSR contains no common name, DNS or IP SANs",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			// the CA must not be called.
+			certifier := NewCertifier(nil, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+			_, err := certifier.ObtainForCSR(ObtainForCSRRequest{CSR: test.csr(t)})
+			require.ErrorContains(t, err, test.expected)
+		})
+	}
+}
+
+func TestCertifier_Renew_reuseKey(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		body, errS := readSignedBody(r, key)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var order acme.Order
+		errS = json.Unmarshal(body, &order)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+
+		errS = tester.WriteJSONResponse(w, acme.Order{
+			Status:         acme.StatusReady,
+			Identifiers:    order.Identifiers,
+			Authorizations: []string{apiURL + "/authz/1"},
+			Finalize:       apiURL + "/finalize",
+		})
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusValid,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	var publicKeys []crypto.PublicKey
+
+	mux.HandleFunc("/finalize", func(w http.ResponseWriter, r *http.Request) {
+		body, errS := readSignedBody(r, key)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var msg acme.CSRMessage
+		errS = json.Unmarshal(body, &msg)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		raw, errS := base64.RawURLEncoding.DecodeString(msg.Csr)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		csr, errS := x509.ParseCertificateRequest(raw)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		publicKeys = append(publicKeys, csr.PublicKey)
+
+		errS = tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusValid,
+			Certificate: apiURL + "/certificate",
+		})
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+		_, errW := w.Write([]byte(certResponseMock))
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.EC256})
+
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	certRes := &Resource{
+		Domain:      "example.com",
+		Certificate: []byte(certResponseMock),
+		PrivateKey:  certcrypto.PEMEncode(privateKey),
+	}
+
+	// two renewals in a row, each one from the result of the previous one.
+	for i := 0; i < 2; i++ {
+		certRes, err = certifier.Renew(*certRes, true, false, "")
+		require.NoError(t, err)
+
+		assert.Equal(t, certcrypto.PEMEncode(privateKey), certRes.PrivateKey)
+	}
+
+	require.Len(t, publicKeys, 2)
+
+	for _, publicKey := range publicKeys {
+		assert.Equal(t, privateKey.(crypto.Signer).Public(), publicKey)
+	}
+}
+
+type resolverContextMock struct{}
+
+func (r *resolverContextMock) Solve(_ []acme.Authorization) error {
+	return errors.New("the context must be used")
+}
+
+func (r *resolverContextMock) SolveContext(ctx context.Context, _ []acme.Authorization) error {
+	// e.g. a long propagation wait.
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCertifier_ObtainWithContext_canceled(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+
+		errW := tester.WriteJSONResponse(w, acme.Order{
+			Status:         acme.StatusPending,
+			Identifiers:    []acme.Identifier{{Type: "dns", Value: "example.com"}},
+			Authorizations: []string{apiURL + "/authz/1"},
+			Finalize:       apiURL + "/finalize",
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusPending,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+			Challenges: []acme.Challenge{{Type: "dns-01", URL: apiURL + "/chlg/1", Token: "token"}},
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/finalize", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "the order must not be finalized", http.StatusInternalServerError)
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverContextMock{}, CertifierOptions{KeyType: certcrypto.EC256})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	_, err = certifier.ObtainWithContext(ctx, ObtainRequest{Domains: []string{"example.com"}})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the requests of a canceled context are aborted.
+	err = certifier.RevokeWithContext(ctx, []byte(certResponseMock), nil)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,67 @@
+package certificate
+
+import (
+	"crypto/x509"
+	"errors"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+)
+
+// Leaf parses and returns the issued certificate (the first certificate of Certificate).
+func (r *Resource) Leaf() (*x509.Certificate, error) {
+	certs, err := r.certificates()
+	if err != nil {
+		return nil, err
+	}
+
+	return certs[0], nil
+}
+
+// Intermediates parses and returns the intermediate certificates, from the issuer of the leaf to the topmost certificate.
+// The intermediates are read from IssuerCertificate, or from the bundle of Certificate if IssuerCertificate is empty.
+func (r *Resource) Intermediates() ([]*x509.Certificate, error) {
+	if len(r.IssuerCertificate) > 0 {
+		return certcrypto.ParsePEMBundle(r.IssuerCertificate)
+	}
+
+	certs, err := r.certificates()
+	if err != nil {
+		return nil, err
+	}
+
+	return certs[1:], nil
+}
+
+// Issuer parses and returns the certificate which issued the leaf certificate.
+// The issuer is the intermediate certificate whose key signed the leaf, the first intermediate by default.
+func (r *Resource) Issuer() (*x509.Certificate, error) {
+	leaf, err := r.Leaf()
+	if err != nil {
+		return nil, err
+	}
+
+	intermediates, err := r.Intermediates()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(intermediates) == 0 {
+		return nil, errors.New("no issuer certificate")
+	}
+
+	for _, cert := range intermediates {
+		if leaf.CheckSignatureFrom(cert) == nil {
+			return cert, nil
+		}
+	}
+
+	return intermediates[0], nil
+}
+
+func (r *Resource) certificates() ([]*x509.Certificate, error) {
+	if len(r.Certificate) == 0 {
+		return nil, errors.New("no certificate")
+	}
+
+	return certcrypto.ParsePEMBundle(r.Certificate)
+}
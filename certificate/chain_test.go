@@ -0,0 +1,147 @@
+package certificate
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResource_chain(t *testing.T) {
+	root, rootKey := generateChainCertificate(t, "Root CA", nil, nil, true)
+	intermediate, intermediateKey := generateChainCertificate(t, "Intermediate CA", root, rootKey, true)
+	leaf, _ := generateChainCertificate(t, "example.com", intermediate, intermediateKey, false)
+
+	testCases := []struct {
+		desc     string
+		resource *Resource
+	}{
+		{
+			desc: "not bundled",
+			resource: &Resource{
+				Certificate:       pemEncode(leaf),
+				IssuerCertificate: pemEncode(intermediate, root),
+			},
+		},
+		{
+			desc: "bundled",
+			resource: &Resource{
+				Certificate:       pemEncode(leaf, intermediate, root),
+				IssuerCertificate: pemEncode(intermediate, root),
+			},
+		},
+		{
+			desc: "bundled without issuer",
+			resource: &Resource{
+				Certificate: pemEncode(leaf, intermediate, root),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			gotLeaf, err := test.resource.Leaf()
+			require.NoError(t, err)
+			assert.Equal(t, leaf.Raw, gotLeaf.Raw)
+
+			intermediates, err := test.resource.Intermediates()
+			require.NoError(t, err)
+			require.Len(t, intermediates, 2)
+			assert.Equal(t, intermediate.Raw, intermediates[0].Raw)
+			assert.Equal(t, root.Raw, intermediates[1].Raw)
+
+			issuer, err := test.resource.Issuer()
+			require.NoError(t, err)
+			assert.Equal(t, intermediate.Raw, issuer.Raw)
+		})
+	}
+}
+
+func TestResource_Issuer_unordered(t *testing.T) {
+	root, rootKey := generateChainCertificate(t, "Root CA", nil, nil, true)
+	intermediate, intermediateKey := generateChainCertificate(t, "Intermediate CA", root, rootKey, true)
+	leaf, _ := generateChainCertificate(t, "example.com", intermediate, intermediateKey, false)
+
+	resource := &Resource{
+		Certificate:       pemEncode(leaf),
+		IssuerCertificate: pemEncode(root, intermediate),
+	}
+
+	issuer, err := resource.Issuer()
+	require.NoError(t, err)
+
+	assert.Equal(t, intermediate.Raw, issuer.Raw)
+}
+
+func TestResource_Issuer_missing(t *testing.T) {
+	leaf, _ := generateChainCertificate(t, "example.com", nil, nil, false)
+
+	resource := &Resource{Certificate: pemEncode(leaf)}
+
+	_, err := resource.Issuer()
+	require.EqualError(t, err, "no issuer certificate")
+
+	_, err = (&Resource{}).Leaf()
+	require.EqualError(t, err, "no certificate")
+}
+
+// generateChainCertificate generates a certificate signed by the parent, or a self-signed certificate if parent is nil.
+func generateChainCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey crypto.Signer, isCA bool) (*x509.Certificate, crypto.Signer) {
+	t.Helper()
+
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	key := privateKey.(crypto.Signer)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+
+	if isCA {
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	} else {
+		template.DNSNames = []string{name}
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
+
+func pemEncode(certs ...*x509.Certificate) []byte {
+	var bundle []byte
+	for _, cert := range certs {
+		bundle = append(bundle, certcrypto.PEMEncode(certcrypto.DERCertificateBytes(cert.Raw))...)
+	}
+
+	return bundle
+}
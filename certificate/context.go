@@ -0,0 +1,69 @@
+package certificate
+
+import (
+	"context"
+
+	"github.com/go-acme/lego/v4/acme"
+)
+
+// contextResolver is implemented by the resolvers whose resolution can be aborted with a context (e.g. resolver.Prober).
+type contextResolver interface {
+	SolveContext(ctx context.Context, authorizations []acme.Authorization) error
+}
+
+// boundResolver binds a contextResolver to a context.
+type boundResolver struct {
+	ctx      context.Context
+	resolver contextResolver
+}
+
+func (r boundResolver) Solve(authorizations []acme.Authorization) error {
+	return r.resolver.SolveContext(r.ctx, authorizations)
+}
+
+// ObtainWithContext is like Obtain, the issuance is aborted when the context is canceled:
+// the requests to the CA, the resolution of the challenges (e.g. the wait for the DNS propagation),
+// and the calls to the providers implementing challenge.ProviderContext are bound to the context.
+func (c *Certifier) ObtainWithContext(ctx context.Context, request ObtainRequest) (*Resource, error) {
+	return c.withContext(ctx).Obtain(request)
+}
+
+// ObtainForCSRWithContext is like ObtainForCSR, the issuance is aborted when the context is canceled (see ObtainWithContext).
+func (c *Certifier) ObtainForCSRWithContext(ctx context.Context, request ObtainForCSRRequest) (*Resource, error) {
+	return c.withContext(ctx).ObtainForCSR(request)
+}
+
+// RenewWithContext is like Renew, the renewal is aborted when the context is canceled (see ObtainWithContext).
+func (c *Certifier) RenewWithContext(ctx context.Context, certRes Resource, bundle, mustStaple bool, preferredChain string) (*Resource, error) {
+	return c.withContext(ctx).Renew(certRes, bundle, mustStaple, preferredChain)
+}
+
+// RevokeWithContext is like RevokeWithReason, the request is aborted when the context is canceled.
+func (c *Certifier) RevokeWithContext(ctx context.Context, cert []byte, reason *uint) error {
+	return c.withContext(ctx).RevokeWithReason(cert, reason)
+}
+
+// withContext returns a copy of the Certifier bound to the context.
+func (c *Certifier) withContext(ctx context.Context) *Certifier {
+	cc := *c
+	cc.ctx = ctx
+
+	if c.core != nil {
+		cc.core = c.core.WithContext(ctx)
+	}
+
+	if r, ok := c.resolver.(contextResolver); ok {
+		cc.resolver = boundResolver{ctx: ctx, resolver: r}
+	}
+
+	return &cc
+}
+
+// context returns the context of the issuance.
+func (c *Certifier) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+
+	return c.ctx
+}
@@ -2,6 +2,7 @@ package certificate
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"sort"
 )
@@ -24,6 +25,45 @@ func (e obtainError) Error() string {
 	return buffer.String()
 }
 
+// Is reports whether the error of one of the domains matches the target (see errors.Is).
+func (e obtainError) Is(target error) bool {
+	for _, err := range e.errors() {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error of the domains, sorted by domain, that matches the target (see errors.As):
+// the problem documents of the CA are available with errors.As.
+func (e obtainError) As(target any) bool {
+	for _, err := range e.errors() {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// errors returns the errors of the domains, sorted by domain.
+func (e obtainError) errors() []error {
+	var domains []string
+	for domain := range e {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	var errs []error
+	for _, domain := range domains {
+		errs = append(errs, e[domain])
+	}
+
+	return errs
+}
+
 type domainError struct {
 	Domain string
 	Error  error
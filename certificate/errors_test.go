@@ -0,0 +1,32 @@
+package certificate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_obtainError_As(t *testing.T) {
+	err := error(obtainError{
+		"b.example.com": fmt.Errorf("acme: %w", &acme.ProblemDetails{Type: acme.RateLimitedErr}),
+		"a.example.com": &acme.ProblemDetails{Type: acme.MalformedErr},
+		"c.example.com": fmt.Errorf("read: %w", io.ErrUnexpectedEOF),
+	})
+
+	var problem *acme.ProblemDetails
+	require.True(t, errors.As(err, &problem))
+
+	// the domains are sorted.
+	assert.Equal(t, acme.MalformedErr, problem.Type)
+
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.NotErrorIs(t, err, io.EOF)
+
+	var nonceErr *acme.NonceError
+	assert.False(t, errors.As(err, &nonceErr))
+}
@@ -0,0 +1,79 @@
+package certificate
+
+import (
+	"fmt"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/log"
+)
+
+// getFailedDomains returns the domains of the authorizations of the order which are not valid, and their errors.
+func (c *Certifier) getFailedDomains(order acme.ExtendedOrder) obtainError {
+	failed := make(obtainError)
+
+	for _, authzURL := range order.Authorizations {
+		auth, err := c.core.Authorizations.Get(authzURL)
+		if err != nil {
+			log.Warnf("Unable to get the authorization %s: %v", authzURL, err)
+			return nil
+		}
+
+		if auth.Status == acme.StatusValid {
+			continue
+		}
+
+		failed[challenge.GetTargetedDomain(auth)] = authorizationError(auth)
+	}
+
+	return failed
+}
+
+// obtainWithoutFailedDomains creates a new order without the failed domains (see ObtainRequest.SkipFailedDomains).
+func (c *Certifier) obtainWithoutFailedDomains(request ObtainRequest, domains []string, failed obtainError, solveErr error) (*Resource, error) {
+	var remaining, excluded []string
+	for _, domain := range domains {
+		if _, ok := failed[domain]; ok {
+			excluded = append(excluded, domain)
+		} else {
+			remaining = append(remaining, domain)
+		}
+	}
+
+	if len(remaining) == 0 {
+		return nil, solveErr
+	}
+
+	log.Warnf("[%s] acme: Excluding the domains with a failed authorization", displayDomains(excluded))
+	log.Infof("[%s] acme: Creating a new order without the failed domains", displayDomains(remaining))
+
+	retry := request
+	retry.Domains = remaining
+	retry.OrderURL = ""
+
+	cert, err := c.Obtain(retry)
+	if err != nil {
+		return cert, err
+	}
+
+	if cert.FailedDomains == nil {
+		cert.FailedDomains = make(map[string]error)
+	}
+
+	for domain, e := range failed {
+		cert.FailedDomains[domain] = e
+	}
+
+	return cert, nil
+}
+
+// authorizationError returns the error of the challenges of an authorization.
+func authorizationError(auth acme.Authorization) error {
+	for _, chlg := range auth.Challenges {
+		if chlg.Error != nil {
+			return chlg.Error
+		}
+	}
+
+	return fmt.Errorf("the authorization is %s", auth.Status)
+}
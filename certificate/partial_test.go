@@ -0,0 +1,224 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resolverFailingMock validates the authorizations, except the authorizations of the failing domain.
+// The valid authorizations are skipped (like the Prober).
+type resolverFailingMock struct {
+	mu       sync.Mutex
+	failing  string
+	statuses map[string]string
+	solved   []string
+}
+
+func (r *resolverFailingMock) Solve(authorizations []acme.Authorization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var failed bool
+	for _, authz := range authorizations {
+		if authz.Status == acme.StatusValid {
+			continue
+		}
+
+		r.solved = append(r.solved, authz.Identifier.Value)
+
+		if authz.Identifier.Value == r.failing {
+			r.statuses[authz.Identifier.Value] = acme.StatusInvalid
+			failed = true
+
+			continue
+		}
+
+		r.statuses[authz.Identifier.Value] = acme.StatusValid
+	}
+
+	if failed {
+		return errors.New("validation failed")
+	}
+
+	return nil
+}
+
+func (r *resolverFailingMock) status(domain string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.statuses[domain]
+}
+
+func TestCertifier_Obtain_skipFailedDomains(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	resolver := &resolverFailingMock{
+		failing:  "b.example.com",
+		statuses: map[string]string{"a.example.com": acme.StatusPending, "b.example.com": acme.StatusPending},
+	}
+
+	var orders [][]string
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		body, errS := readSignedBody(r, key)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var order acme.Order
+		errS = json.Unmarshal(body, &order)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var domains, authorizations []string
+		for _, identifier := range order.Identifiers {
+			domains = append(domains, identifier.Value)
+			authorizations = append(authorizations, apiURL+"/authz/"+identifier.Value)
+		}
+
+		orders = append(orders, domains)
+
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+
+		errW := tester.WriteJSONResponse(w, acme.Order{
+			Status:         acme.StatusPending,
+			Identifiers:    order.Identifiers,
+			Authorizations: authorizations,
+			Finalize:       apiURL + "/finalize",
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	for _, domain := range []string{"a.example.com", "b.example.com"} {
+		domain := domain
+
+		mux.HandleFunc("/authz/"+domain, func(w http.ResponseWriter, _ *http.Request) {
+			authz := acme.Authorization{
+				Status:     resolver.status(domain),
+				Identifier: acme.Identifier{Type: "dns", Value: domain},
+				Challenges: []acme.Challenge{{Type: "dns-01", Status: resolver.status(domain)}},
+			}
+
+			if authz.Status == acme.StatusInvalid {
+				authz.Challenges[0].Error = &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:dns", Detail: "no TXT record found"}
+			}
+
+			errW := tester.WriteJSONResponse(w, authz)
+			if errW != nil {
+				http.Error(w, errW.Error(), http.StatusInternalServerError)
+				return
+			}
+		})
+	}
+
+	mux.HandleFunc("/finalize", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusValid,
+			Certificate: apiURL + "/certificate",
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+		_, errW := w.Write([]byte(certResponseMock))
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, resolver, CertifierOptions{KeyType: certcrypto.EC256})
+
+	certRes, err := certifier.Obtain(ObtainRequest{
+		Domains:           []string{"a.example.com", "b.example.com"},
+		SkipFailedDomains: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{{"a.example.com", "b.example.com"}, {"a.example.com"}}, orders)
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, resolver.solved, "the valid authorization must not be solved again")
+
+	assert.Equal(t, "a.example.com", certRes.Domain)
+	require.Len(t, certRes.FailedDomains, 1)
+	require.EqualError(t, certRes.FailedDomains["b.example.com"], "acme: error: 0 :: urn:ietf:params:acme:error:dns :: no TXT record found")
+}
+
+func TestCertifier_Obtain_skipFailedDomains_allFailed(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	var newOrders int
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		newOrders++
+
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+
+		errW := tester.WriteJSONResponse(w, acme.Order{
+			Status:         acme.StatusPending,
+			Identifiers:    []acme.Identifier{{Type: "dns", Value: "example.com"}},
+			Authorizations: []string{apiURL + "/authz/1"},
+			Finalize:       apiURL + "/finalize",
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusInvalid,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{error: errors.New("validation failed")}, CertifierOptions{KeyType: certcrypto.EC256})
+
+	_, err = certifier.Obtain(ObtainRequest{
+		Domains:           []string{"example.com"},
+		SkipFailedDomains: true,
+	})
+	require.EqualError(t, err, "validation failed")
+
+	assert.Equal(t, 1, newOrders)
+}
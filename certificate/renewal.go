@@ -0,0 +1,299 @@
+package certificate
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/certcrypto"
+)
+
+// RenewalInfoRequest contains the necessary renewal information.
+type RenewalInfoRequest struct {
+	Cert *x509.Certificate
+}
+
+// RenewalInfoResponse is a wrapper around acme.RenewalInfoResponse that provides a method for determining when to renew a certificate.
+type RenewalInfoResponse struct {
+	acme.RenewalInfoResponse
+
+	// RetryAfter header indicating the polling interval that the ACME server recommends.
+	// Clients SHOULD query the renewalInfo URL again after the RetryAfter period has passed,
+	// as the server may provide a different suggestedWindow.
+	RetryAfter time.Duration
+}
+
+// ShouldRenewAt determines the optimal renewal time based on the current time (UTC),
+// the renewal window suggested by ARI, and the client's willingness to sleep.
+// It returns a pointer to a time.Time value indicating when the renewal should be attempted,
+// or nil if deferred until the next normal wake time.
+//
+// This method implements the RECOMMENDED algorithm described in draft-ietf-acme-ari.
+// - https://datatracker.ietf.org/doc/draft-ietf-acme-ari/
+func (r *RenewalInfoResponse) ShouldRenewAt(now time.Time, willingToSleep time.Duration) *time.Time {
+	// Explicitly convert all times to UTC.
+	now = now.UTC()
+	start := r.SuggestedWindow.Start.UTC()
+	end := r.SuggestedWindow.End.UTC()
+
+	// Select a uniform random time within the suggested window.
+	rt := start
+	if window := end.Sub(start); window > 0 {
+		rt = start.Add(time.Duration(rand.Int63n(int64(window))))
+	}
+
+	// If the selected time is in the past, attempt renewal immediately.
+	if rt.Before(now) {
+		return &now
+	}
+
+	// Otherwise, if the client can schedule itself to attempt renewal at exactly the selected time, do so.
+	willingToSleepUntil := now.Add(willingToSleep)
+	if !willingToSleepUntil.Before(rt) {
+		return &rt
+	}
+
+	// Otherwise, sleep until the next normal wake time, re-check ARI, and return to Step 1.
+	return nil
+}
+
+// RenewalTimeByRatio returns the time at which a certificate should be renewed,
+// when only the given ratio of its total validity period remains.
+//
+// Unlike a fixed number of days, it works with any certificate lifetime:
+// with a ratio of 1/3, a 90-day certificate is renewed 30 days before its expiration,
+// and a 6-day certificate is renewed 2 days before its expiration.
+// The ratio is clamped to [0, 1].
+func RenewalTimeByRatio(cert *x509.Certificate, ratio float64) time.Time {
+	if ratio < 0 {
+		ratio = 0
+	}
+
+	if ratio > 1 {
+		ratio = 1
+	}
+
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+
+	return cert.NotAfter.Add(-time.Duration(float64(lifetime) * ratio))
+}
+
+// RenewalOptions configures NeedsRenewal.
+type RenewalOptions struct {
+	// Days is the number of days left before the expiration of the certificate at which the renewal is needed:
+	// the certificate is renewed when the number of whole days left is lower than or equal to Days.
+	// A negative value means that the certificate is always renewed.
+	Days int
+
+	// Ratio is the ratio of the validity period left at which the renewal is needed (see RenewalTimeByRatio).
+	// If greater than 0, Days is ignored.
+	Ratio float64
+
+	// RenewalInfo is the ARI response of the certificate (see Certifier.GetRenewalInfo).
+	// If set, the start of the suggested window is used as the renewal time, Days and Ratio are ignored.
+	RenewalInfo *RenewalInfoResponse
+
+	// Domains are the desired domains (and IP addresses) of the certificate.
+	// If set, the renewal is needed when the SANs of the certificate are different, even before the renewal time.
+	Domains []string
+
+	// KeyType is the desired key type of the certificate.
+	// If set, the renewal is needed when the key of the certificate has another type, even before the renewal time.
+	KeyType certcrypto.KeyType
+
+	// Now is the reference time, time.Now() if zero.
+	Now time.Time
+}
+
+// NeedsRenewal checks if a certificate needs to be renewed,
+// and returns the computed renewal time.
+// The bundle is a PEM bundle starting with the certificate to check (e.g. the content of a `.crt` file).
+func NeedsRenewal(bundle []byte, opts RenewalOptions) (bool, time.Time, error) {
+	certificates, err := certcrypto.ParsePEMBundle(bundle)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	cert := certificates[0]
+	if cert.IsCA {
+		return false, time.Time{}, errors.New("the certificate bundle starts with a CA certificate")
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	if MatchRequest(cert, opts.Domains, opts.KeyType) != nil {
+		return true, now, nil
+	}
+
+	renewalTime := getRenewalTime(cert, opts, now)
+
+	return !now.Before(renewalTime), renewalTime, nil
+}
+
+// MatchRequest checks that the certificate matches the desired domains and key type,
+// and returns an error describing the difference otherwise.
+// The domains are compared as a set (case-insensitive), an empty list of domains or an empty key type is not checked.
+func MatchRequest(cert *x509.Certificate, domains []string, keyType certcrypto.KeyType) error {
+	if len(domains) > 0 {
+		expected := toDomainSet(domains)
+		actual := toDomainSet(certcrypto.ExtractDomains(cert))
+
+		var missing, extra []string
+		for domain := range expected {
+			if !actual[domain] {
+				missing = append(missing, domain)
+			}
+		}
+		for domain := range actual {
+			if !expected[domain] {
+				extra = append(extra, domain)
+			}
+		}
+
+		if len(missing) > 0 || len(extra) > 0 {
+			sort.Strings(missing)
+			sort.Strings(extra)
+
+			return fmt.Errorf("the domains of the certificate have changed: missing [%s], unexpected [%s]",
+				strings.Join(missing, ", "), strings.Join(extra, ", "))
+		}
+	}
+
+	if keyType != "" {
+		actual := publicKeyType(cert.PublicKey)
+		if actual != keyType {
+			return fmt.Errorf("the key type of the certificate has changed: %s instead of %s", actual, keyType)
+		}
+	}
+
+	return nil
+}
+
+func toDomainSet(domains []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, domain := range sanitizeDomain(domains) {
+		set[strings.ToLower(domain)] = true
+	}
+
+	return set
+}
+
+// publicKeyType returns the key type of the public key, or an empty key type if unknown.
+func publicKeyType(publicKey crypto.PublicKey) certcrypto.KeyType {
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return certcrypto.KeyType(strconv.Itoa(k.N.BitLen()))
+	case *ecdsa.PublicKey:
+		switch k.Curve.Params().BitSize {
+		case 256:
+			return certcrypto.EC256
+		case 384:
+			return certcrypto.EC384
+		case 521:
+			return certcrypto.EC521
+		}
+	case ed25519.PublicKey:
+		return certcrypto.ED25519
+	}
+
+	return ""
+}
+
+func getRenewalTime(cert *x509.Certificate, opts RenewalOptions, now time.Time) time.Time {
+	switch {
+	case opts.RenewalInfo != nil:
+		return opts.RenewalInfo.SuggestedWindow.Start
+
+	case opts.Ratio > 0:
+		return RenewalTimeByRatio(cert, opts.Ratio)
+
+	case opts.Days < 0:
+		return now
+
+	default:
+		// the renewal is needed when fewer than Days+1 days are left.
+		return cert.NotAfter.Add(-time.Duration(opts.Days+1) * 24 * time.Hour)
+	}
+}
+
+// GetRenewalInfo sends a request to the ACME server's renewalInfo endpoint to obtain a suggested renewal window.
+//
+// Note: this endpoint is part of a draft specification, not all ACME servers will implement it.
+// This method will return api.ErrNoARI if the server does not advertise a renewal info endpoint.
+// - https://datatracker.ietf.org/doc/draft-ietf-acme-ari/
+func (c *Certifier) GetRenewalInfo(req RenewalInfoRequest) (*RenewalInfoResponse, error) {
+	certID, err := MakeARICertID(req.Cert)
+	if err != nil {
+		return nil, fmt.Errorf("error making certID: %w", err)
+	}
+
+	resp, err := c.core.Certificates.GetRenewalInfo(certID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var info RenewalInfoResponse
+	err = json.NewDecoder(resp.Body).Decode(&info)
+	if err != nil {
+		return nil, err
+	}
+
+	// an invalid Retry-After header is ignored.
+	if retryAfter, ok := api.ParseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		info.RetryAfter = retryAfter
+	}
+
+	return &info, nil
+}
+
+// MakeARICertID constructs a certificate identifier as described in draft-ietf-acme-ari.
+// The identifier is the base64url-encoded Authority Key Identifier and the base64url-encoded DER serial number,
+// separated by a dot.
+// - https://datatracker.ietf.org/doc/draft-ietf-acme-ari/
+func MakeARICertID(leaf *x509.Certificate) (string, error) {
+	if leaf == nil {
+		return "", errors.New("leaf certificate is nil")
+	}
+
+	if len(leaf.AuthorityKeyId) == 0 {
+		return "", errors.New("missing Authority Key Identifier")
+	}
+
+	// Marshal the Serial Number into DER.
+	der, err := asn1.Marshal(leaf.SerialNumber)
+	if err != nil {
+		return "", err
+	}
+
+	// Check if the DER encoded bytes are sufficient (at least 3 bytes: tag, length, and value).
+	if len(der) < 3 {
+		return "", errors.New("invalid DER encoding of serial number")
+	}
+
+	// Extract only the integer bytes from the DER encoded Serial Number (skipping the tag and the length).
+	serial := base64.RawURLEncoding.EncodeToString(der[2:])
+
+	// Convert the Authority Key Identifier to base64url encoding without padding.
+	aki := base64.RawURLEncoding.EncodeToString(leaf.AuthorityKeyId)
+
+	return fmt.Sprintf("%s.%s", aki, serial), nil
+}
@@ -0,0 +1,535 @@
+package certificate
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ariCertID the certificate identifier of the example of draft-ietf-acme-ari.
+const ariCertID = "aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE"
+
+func ariLeafCert(t *testing.T) *x509.Certificate {
+	t.Helper()
+
+	aki, err := hex.DecodeString("69885B6B87464041E1B37B847BA0AE2CDE01C8D4")
+	require.NoError(t, err)
+
+	return &x509.Certificate{
+		SerialNumber:   big.NewInt(0x87654321),
+		AuthorityKeyId: aki,
+	}
+}
+
+func TestMakeARICertID(t *testing.T) {
+	certID, err := MakeARICertID(ariLeafCert(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, ariCertID, certID)
+}
+
+func TestMakeARICertID_errors(t *testing.T) {
+	_, err := MakeARICertID(nil)
+	require.EqualError(t, err, "leaf certificate is nil")
+
+	_, err = MakeARICertID(&x509.Certificate{SerialNumber: big.NewInt(1)})
+	require.EqualError(t, err, "missing Authority Key Identifier")
+}
+
+func TestCertifier_GetRenewalInfo(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	mux.HandleFunc("/renewalInfo/"+ariCertID, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Retry-After", "21600")
+
+		err := tester.WriteJSONResponse(w, acme.RenewalInfoResponse{
+			SuggestedWindow: acme.Window{
+				Start: time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2026, time.January, 3, 0, 0, 0, 0, time.UTC),
+			},
+			ExplanationURL: "https://acme.example.com/docs/ari",
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	certifier := newTestCertifier(t, apiURL+"/dir")
+
+	info, err := certifier.GetRenewalInfo(RenewalInfoRequest{Cert: ariLeafCert(t)})
+	require.NoError(t, err)
+
+	expected := &RenewalInfoResponse{
+		RenewalInfoResponse: acme.RenewalInfoResponse{
+			SuggestedWindow: acme.Window{
+				Start: time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2026, time.January, 3, 0, 0, 0, 0, time.UTC),
+			},
+			ExplanationURL: "https://acme.example.com/docs/ari",
+		},
+		RetryAfter: 6 * time.Hour,
+	}
+
+	assert.Equal(t, expected, info)
+}
+
+func TestCertifier_GetRenewalInfo_retryAfter(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		retryAfter string
+		min, max   time.Duration
+	}{
+		{
+			desc:       "HTTP date",
+			retryAfter: time.Now().Add(2 * time.Hour).UTC().Format(http.TimeFormat),
+			min:        time.Hour,
+			max:        2 * time.Hour,
+		},
+		{
+			desc:       "invalid",
+			retryAfter: "soon",
+		},
+		{
+			desc: "missing",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mux, apiURL := tester.SetupFakeAPI(t)
+
+			mux.HandleFunc("/renewalInfo/"+ariCertID, func(w http.ResponseWriter, _ *http.Request) {
+				if test.retryAfter != "" {
+					w.Header().Set("Retry-After", test.retryAfter)
+				}
+
+				err := tester.WriteJSONResponse(w, acme.RenewalInfoResponse{})
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+				}
+			})
+
+			certifier := newTestCertifier(t, apiURL+"/dir")
+
+			info, err := certifier.GetRenewalInfo(RenewalInfoRequest{Cert: ariLeafCert(t)})
+			require.NoError(t, err)
+
+			assert.GreaterOrEqual(t, info.RetryAfter, test.min)
+			assert.LessOrEqual(t, info.RetryAfter, test.max)
+		})
+	}
+}
+
+func TestCertifier_GetRenewalInfo_noARI(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/dir", func(w http.ResponseWriter, _ *http.Request) {
+		_ = tester.WriteJSONResponse(w, acme.Directory{
+			NewNonceURL:   server.URL + "/nonce",
+			NewAccountURL: server.URL + "/account",
+			NewOrderURL:   server.URL + "/newOrder",
+		})
+	})
+
+	certifier := newTestCertifier(t, server.URL+"/dir")
+
+	_, err := certifier.GetRenewalInfo(RenewalInfoRequest{Cert: ariLeafCert(t)})
+	require.ErrorIs(t, err, api.ErrNoARI)
+}
+
+func TestRenewalInfoResponse_ShouldRenewAt(t *testing.T) {
+	now := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc           string
+		start, end     time.Time
+		willingToSleep time.Duration
+		assert         func(t *testing.T, renewAt *time.Time)
+	}{
+		{
+			desc:  "window in the past",
+			start: now.Add(-2 * time.Hour),
+			end:   now.Add(-time.Hour),
+			assert: func(t *testing.T, renewAt *time.Time) {
+				t.Helper()
+
+				require.NotNil(t, renewAt)
+				assert.Equal(t, now, *renewAt)
+			},
+		},
+		{
+			desc:           "window in the future, willing to sleep",
+			start:          now.Add(time.Hour),
+			end:            now.Add(2 * time.Hour),
+			willingToSleep: 3 * time.Hour,
+			assert: func(t *testing.T, renewAt *time.Time) {
+				t.Helper()
+
+				require.NotNil(t, renewAt)
+				assert.False(t, renewAt.Before(now.Add(time.Hour)))
+				assert.True(t, renewAt.Before(now.Add(2*time.Hour)))
+			},
+		},
+		{
+			desc:  "window in the future, not willing to sleep",
+			start: now.Add(time.Hour),
+			end:   now.Add(2 * time.Hour),
+			assert: func(t *testing.T, renewAt *time.Time) {
+				t.Helper()
+
+				assert.Nil(t, renewAt)
+			},
+		},
+		{
+			desc:  "empty window",
+			start: now.Add(-time.Hour),
+			end:   now.Add(-time.Hour),
+			assert: func(t *testing.T, renewAt *time.Time) {
+				t.Helper()
+
+				require.NotNil(t, renewAt)
+				assert.Equal(t, now, *renewAt)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			info := RenewalInfoResponse{
+				RenewalInfoResponse: acme.RenewalInfoResponse{
+					SuggestedWindow: acme.Window{Start: test.start, End: test.end},
+				},
+			}
+
+			test.assert(t, info.ShouldRenewAt(now, test.willingToSleep))
+		})
+	}
+}
+
+func TestRenewalTimeByRatio(t *testing.T) {
+	notBefore := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		lifetime time.Duration
+		ratio    float64
+		expected time.Time
+	}{
+		{
+			desc:     "long-lived certificate",
+			lifetime: 90 * 24 * time.Hour,
+			ratio:    1.0 / 3,
+			expected: notBefore.Add(60 * 24 * time.Hour),
+		},
+		{
+			desc:     "short-lived certificate",
+			lifetime: 6 * 24 * time.Hour,
+			ratio:    1.0 / 3,
+			expected: notBefore.Add(4 * 24 * time.Hour),
+		},
+		{
+			desc:     "negative ratio",
+			lifetime: 6 * 24 * time.Hour,
+			ratio:    -1,
+			expected: notBefore.Add(6 * 24 * time.Hour),
+		},
+		{
+			desc:     "ratio greater than 1",
+			lifetime: 6 * 24 * time.Hour,
+			ratio:    2,
+			expected: notBefore,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cert := &x509.Certificate{
+				NotBefore: notBefore,
+				NotAfter:  notBefore.Add(test.lifetime),
+			}
+
+			renewalTime := RenewalTimeByRatio(cert, test.ratio)
+
+			assert.WithinDuration(t, test.expected, renewalTime, time.Second)
+		})
+	}
+}
+
+func TestNeedsRenewal(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc         string
+		notBefore    time.Time
+		notAfter     time.Time
+		opts         RenewalOptions
+		expected     bool
+		expectedTime time.Time
+	}{
+		{
+			desc:         "30 days, expires in 60 days",
+			notBefore:    now.Add(-30 * 24 * time.Hour),
+			notAfter:     now.Add(60 * 24 * time.Hour),
+			opts:         RenewalOptions{Days: 30},
+			expectedTime: now.Add(29 * 24 * time.Hour),
+		},
+		{
+			desc:         "30 days, expires in 31 days",
+			notBefore:    now.Add(-59 * 24 * time.Hour),
+			notAfter:     now.Add(31*24*time.Hour + time.Second),
+			opts:         RenewalOptions{Days: 30},
+			expectedTime: now.Add(time.Second),
+		},
+		{
+			desc:         "30 days, expires in 30 days",
+			notBefore:    now.Add(-60 * 24 * time.Hour),
+			notAfter:     now.Add(30 * 24 * time.Hour),
+			opts:         RenewalOptions{Days: 30},
+			expected:     true,
+			expectedTime: now.Add(-24 * time.Hour),
+		},
+		{
+			desc:         "30 days, expired",
+			notBefore:    now.Add(-90 * 24 * time.Hour),
+			notAfter:     now.Add(-time.Hour),
+			opts:         RenewalOptions{Days: 30},
+			expected:     true,
+			expectedTime: now.Add(-31*24*time.Hour - time.Hour),
+		},
+		{
+			desc:         "negative days: always renew",
+			notBefore:    now.Add(-24 * time.Hour),
+			notAfter:     now.Add(89 * 24 * time.Hour),
+			opts:         RenewalOptions{Days: -1},
+			expected:     true,
+			expectedTime: now,
+		},
+		{
+			desc:         "ratio, 6-day certificate, 3 days left",
+			notBefore:    now.Add(-3 * 24 * time.Hour),
+			notAfter:     now.Add(3 * 24 * time.Hour),
+			opts:         RenewalOptions{Days: 30, Ratio: 1.0 / 3},
+			expectedTime: now.Add(24 * time.Hour),
+		},
+		{
+			desc:         "ratio, 6-day certificate, 1 day left",
+			notBefore:    now.Add(-5 * 24 * time.Hour),
+			notAfter:     now.Add(24 * time.Hour),
+			opts:         RenewalOptions{Ratio: 1.0 / 3},
+			expected:     true,
+			expectedTime: now.Add(-24 * time.Hour),
+		},
+		{
+			desc:      "ARI, window in the future",
+			notBefore: now.Add(-30 * 24 * time.Hour),
+			notAfter:  now.Add(60 * 24 * time.Hour),
+			opts: RenewalOptions{Days: 90, RenewalInfo: &RenewalInfoResponse{
+				RenewalInfoResponse: acme.RenewalInfoResponse{
+					SuggestedWindow: acme.Window{Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)},
+				},
+			}},
+			expectedTime: now.Add(time.Hour),
+		},
+		{
+			desc:      "ARI, window started",
+			notBefore: now.Add(-30 * 24 * time.Hour),
+			notAfter:  now.Add(60 * 24 * time.Hour),
+			opts: RenewalOptions{RenewalInfo: &RenewalInfoResponse{
+				RenewalInfoResponse: acme.RenewalInfoResponse{
+					SuggestedWindow: acme.Window{Start: now.Add(-time.Hour), End: now.Add(time.Hour)},
+				},
+			}},
+			expected:     true,
+			expectedTime: now.Add(-time.Hour),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			bundle := generatePEMCertificate(t, test.notBefore, test.notAfter, false)
+
+			opts := test.opts
+			opts.Now = now
+
+			needed, renewalTime, err := NeedsRenewal(bundle, opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, needed)
+			assert.WithinDuration(t, test.expectedTime, renewalTime, time.Second)
+		})
+	}
+}
+
+func TestNeedsRenewal_errors(t *testing.T) {
+	_, _, err := NeedsRenewal([]byte("not a PEM bundle"), RenewalOptions{})
+	require.EqualError(t, err, "no certificates were found while parsing the bundle")
+
+	bundle := generatePEMCertificate(t, time.Now(), time.Now().Add(time.Hour), true)
+
+	_, _, err = NeedsRenewal(bundle, RenewalOptions{})
+	require.EqualError(t, err, "the certificate bundle starts with a CA certificate")
+}
+
+func TestMatchRequest(t *testing.T) {
+	cert, _ := generateChainCertificate(t, "example.com", nil, nil, false)
+	cert.DNSNames = []string{"example.com", "www.example.com"}
+
+	testCases := []struct {
+		desc    string
+		domains []string
+		keyType certcrypto.KeyType
+		err     string
+	}{
+		{
+			desc:    "everything matches",
+			domains: []string{"www.EXAMPLE.com", "example.com"},
+			keyType: certcrypto.EC256,
+		},
+		{
+			desc: "nothing to check",
+		},
+		{
+			desc:    "domain added",
+			domains: []string{"example.com", "www.example.com", "api.example.com"},
+			err:     "the domains of the certificate have changed: missing [api.example.com], unexpected []",
+		},
+		{
+			desc:    "domain removed",
+			domains: []string{"example.com"},
+			err:     "the domains of the certificate have changed: missing [], unexpected [www.example.com]",
+		},
+		{
+			desc:    "key type changed",
+			domains: []string{"example.com", "www.example.com"},
+			keyType: certcrypto.RSA2048,
+			err:     "the key type of the certificate has changed: P256 instead of 2048",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := MatchRequest(cert, test.domains, test.keyType)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNeedsRenewal_changed(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	bundle := generatePEMCertificate(t, now.Add(-24*time.Hour), now.Add(89*24*time.Hour), false)
+
+	testCases := []struct {
+		desc     string
+		opts     RenewalOptions
+		expected bool
+	}{
+		{
+			desc: "everything matches",
+			opts: RenewalOptions{Days: 30, Domains: []string{"example.com"}, KeyType: certcrypto.EC256},
+		},
+		{
+			desc:     "SANs differ",
+			opts:     RenewalOptions{Days: 30, Domains: []string{"example.com", "example.org"}},
+			expected: true,
+		},
+		{
+			desc:     "key type differs",
+			opts:     RenewalOptions{Days: 30, KeyType: certcrypto.EC384},
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			opts := test.opts
+			opts.Now = now
+
+			needed, renewalTime, err := NeedsRenewal(bundle, opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, needed)
+
+			if test.expected {
+				assert.Equal(t, now, renewalTime)
+			}
+		})
+	}
+}
+
+// generatePEMCertificate generates a self-signed PEM encoded certificate.
+func generatePEMCertificate(t *testing.T, notBefore, notAfter time.Time, isCA bool) []byte {
+	t.Helper()
+
+	key, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "example.com"},
+		DNSNames:              []string{"example.com"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.(crypto.Signer).Public(), key)
+	require.NoError(t, err)
+
+	return certcrypto.PEMEncode(certcrypto.DERCertificateBytes(der))
+}
+
+func newTestCertifier(t *testing.T, dirURL string) *Certifier {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", dirURL, "", key)
+	require.NoError(t, err)
+
+	return NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+}
@@ -0,0 +1,24 @@
+package certificate
+
+import (
+	"fmt"
+
+	"github.com/go-acme/lego/v4/acme"
+)
+
+// CancelAutoRenewal cancels the auto-renewal of a STAR order (see ObtainRequest.AutoRenewal):
+// the CA stops issuing new certificates for the order.
+// The orderURL is the URL of the order (see ObtainRequest.OrderCreated).
+// See https://www.rfc-editor.org/rfc/rfc8739.html#section-3.1.2.
+func (c *Certifier) CancelAutoRenewal(orderURL string) error {
+	order, err := c.core.Orders.Cancel(orderURL)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != acme.StatusCanceled {
+		return fmt.Errorf("the auto-renewal of the order %s has not been canceled: the order state %s", orderURL, order.Status)
+	}
+
+	return nil
+}
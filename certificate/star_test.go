@@ -0,0 +1,168 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertifier_Obtain_autoRenewal(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	apiURL := server.URL
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	mux.HandleFunc("/dir", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Directory{
+			NewNonceURL:   apiURL + "/nonce",
+			NewAccountURL: apiURL + "/account",
+			NewOrderURL:   apiURL + "/newOrder",
+			Meta: acme.Meta{
+				AutoRenewal: &acme.AutoRenewalMeta{MinLifetime: 86400, MaxDuration: 31536000},
+			},
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/nonce", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Replay-Nonce", "12345")
+	})
+
+	var autoRenewal *acme.AutoRenewal
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		body, errS := readSignedBody(r, key)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var order acme.Order
+		errS = json.Unmarshal(body, &order)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		autoRenewal = order.AutoRenewal
+
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+
+		errW := tester.WriteJSONResponse(w, acme.Order{
+			Status:         acme.StatusPending,
+			Identifiers:    order.Identifiers,
+			Authorizations: []string{apiURL + "/authz/1"},
+			Finalize:       apiURL + "/finalize",
+			AutoRenewal:    order.AutoRenewal,
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusValid,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	// a STAR order has a star-certificate URL instead of a certificate URL.
+	mux.HandleFunc("/finalize", func(w http.ResponseWriter, _ *http.Request) {
+		errW := tester.WriteJSONResponse(w, acme.Order{
+			Status:          acme.StatusValid,
+			Identifiers:     []acme.Identifier{{Type: "dns", Value: "example.com"}},
+			StarCertificate: apiURL + "/star/1",
+		})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/star/1", func(w http.ResponseWriter, _ *http.Request) {
+		_, errW := w.Write([]byte(certResponseMock))
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	var canceled bool
+
+	mux.HandleFunc("/order/1", func(w http.ResponseWriter, r *http.Request) {
+		body, errS := readSignedBody(r, key)
+		if errS != nil {
+			http.Error(w, errS.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if string(body) != `{"status":"canceled"}` {
+			http.Error(w, "unexpected body: "+string(body), http.StatusBadRequest)
+			return
+		}
+
+		canceled = true
+
+		errW := tester.WriteJSONResponse(w, acme.Order{Status: acme.StatusCanceled})
+		if errW != nil {
+			http.Error(w, errW.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.EC256})
+
+	request := ObtainRequest{
+		Domains: []string{"example.com"},
+		AutoRenewal: &acme.AutoRenewal{
+			EndDate:  time.Now().Add(30 * 24 * time.Hour).UTC().Format(time.RFC3339),
+			Lifetime: 4 * 86400,
+		},
+	}
+
+	certRes, err := certifier.Obtain(request)
+	require.NoError(t, err)
+
+	assert.Equal(t, request.AutoRenewal, autoRenewal)
+	assert.Equal(t, apiURL+"/star/1", certRes.CertURL)
+	assert.NotEmpty(t, certRes.Certificate)
+
+	// the current certificate of the STAR order.
+	current, err := certifier.Get(certRes.CertURL, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, certRes.Certificate, current.Certificate)
+
+	err = certifier.CancelAutoRenewal(apiURL + "/order/1")
+	require.NoError(t, err)
+
+	assert.True(t, canceled)
+}
@@ -0,0 +1,125 @@
+package certificate
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/platform/observer"
+)
+
+// IssuanceStats are statistics about the issuance of a certificate, to diagnose slow or flaky issuances.
+//
+// The propagation waits and the challenge types are reported by the solvers to the observer of the Certifier (see Certifier.Observer),
+// which is the observer of the solvers of a lego.Client.
+type IssuanceStats struct {
+	// NonceRetries is the number of requests sent again after a "badNonce" error.
+	// It includes the retries of the other requests sent at the same time with the same client.
+	NonceRetries int64
+	// PropagationWait is the sum of the waits for the propagation of the DNS records (DNS-01 only) of the domains.
+	PropagationWait time.Duration
+	// ChallengeTypes are the types of the challenges solved, by domain.
+	// The domains with an authorization already valid are not included.
+	ChallengeTypes map[string]string
+	// Duration is the duration of the issuance.
+	Duration time.Duration
+}
+
+// statsRecorder records the propagation waits and the challenge types of the domains,
+// and forwards the observations to the observer of the Certifier.
+type statsRecorder struct {
+	next observer.Observer
+
+	mu          sync.Mutex
+	propagation map[string]time.Duration
+	challenges  map[string]string
+}
+
+func newStatsRecorder(next observer.Observer) *statsRecorder {
+	return &statsRecorder{
+		next:        next,
+		propagation: map[string]time.Duration{},
+		challenges:  map[string]string{},
+	}
+}
+
+func (r *statsRecorder) ObservePhase(phase observer.Phase, domain string, duration time.Duration) {
+	if phase == observer.PhasePropagation {
+		r.mu.Lock()
+		r.propagation[domain] += duration
+		r.mu.Unlock()
+	}
+
+	if r.next != nil {
+		r.next.ObservePhase(phase, domain, duration)
+	}
+}
+
+func (r *statsRecorder) ObserveChallenge(domain, challengeType string) {
+	r.mu.Lock()
+	r.challenges[domain] = challengeType
+	r.mu.Unlock()
+
+	observer.ObserveChallenge(r.next, domain, challengeType)
+}
+
+// reset removes the observations of the domains of the authorizations.
+func (r *statsRecorder) reset(authz []acme.Authorization) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, auth := range authz {
+		domain := challenge.GetTargetedDomain(auth)
+
+		delete(r.propagation, domain)
+		delete(r.challenges, domain)
+	}
+}
+
+// collect returns, and removes, the observations of the domains of the authorizations.
+func (r *statsRecorder) collect(authz []acme.Authorization) (time.Duration, map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var wait time.Duration
+	challenges := map[string]string{}
+
+	for _, auth := range authz {
+		domain := challenge.GetTargetedDomain(auth)
+
+		if d, ok := r.propagation[domain]; ok {
+			wait += d
+			delete(r.propagation, domain)
+		}
+
+		if t, ok := r.challenges[domain]; ok {
+			challenges[domain] = t
+			delete(r.challenges, domain)
+		}
+	}
+
+	return wait, challenges
+}
+
+// issuance tracks the statistics of an issuance.
+type issuance struct {
+	start        time.Time
+	nonceRetries int64
+}
+
+func (c *Certifier) startIssuance() issuance {
+	return issuance{start: time.Now(), nonceRetries: c.core.NonceRetries()}
+}
+
+// stats returns the statistics of the issuance.
+func (c *Certifier) stats(iss issuance, authz []acme.Authorization) *IssuanceStats {
+	wait, challenges := c.recorder.collect(authz)
+
+	return &IssuanceStats{
+		NonceRetries:    c.core.NonceRetries() - iss.nonceRetries,
+		PropagationWait: wait,
+		ChallengeTypes:  challenges,
+		Duration:        time.Since(iss.start),
+	}
+}
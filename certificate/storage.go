@@ -0,0 +1,80 @@
+package certificate
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrResourceNotFound is returned by a Storage when there is no certificate resource for a domain.
+var ErrResourceNotFound = errors.New("certificate resource not found")
+
+// Storage is used to persist the certificate resources (the certificate, the private key, and the metadata).
+type Storage interface {
+	// Save stores a certificate resource, identified by its domain (Resource.Domain).
+	// An existing resource with the same domain is replaced.
+	Save(certRes *Resource) error
+
+	// Load returns the certificate resource of a domain.
+	// It returns ErrResourceNotFound if there is no resource for this domain.
+	Load(domain string) (*Resource, error)
+}
+
+// MemoryStorage is an in-memory implementation of Storage.
+// The resources are only kept during the lifetime of the process: nothing is written to the filesystem.
+type MemoryStorage struct {
+	mu        sync.RWMutex
+	resources map[string]*Resource
+}
+
+// NewMemoryStorage creates a new MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{resources: map[string]*Resource{}}
+}
+
+// Save stores a copy of a certificate resource.
+func (s *MemoryStorage) Save(certRes *Resource) error {
+	if certRes == nil {
+		return errors.New("certificate resource is nil")
+	}
+
+	if certRes.Domain == "" {
+		return errors.New("the domain of the certificate resource is empty")
+	}
+
+	s.mu.Lock()
+	s.resources[certRes.Domain] = copyResource(certRes)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Load returns a copy of the certificate resource of a domain.
+func (s *MemoryStorage) Load(domain string) (*Resource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	certRes, ok := s.resources[domain]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+
+	return copyResource(certRes), nil
+}
+
+func copyResource(certRes *Resource) *Resource {
+	r := *certRes
+	r.PrivateKey = copyBytes(certRes.PrivateKey)
+	r.Certificate = copyBytes(certRes.Certificate)
+	r.IssuerCertificate = copyBytes(certRes.IssuerCertificate)
+	r.CSR = copyBytes(certRes.CSR)
+
+	return &r
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+
+	return append([]byte(nil), b...)
+}
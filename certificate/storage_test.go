@@ -0,0 +1,57 @@
+package certificate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	storage := NewMemoryStorage()
+
+	_, err := storage.Load("example.com")
+	require.ErrorIs(t, err, ErrResourceNotFound)
+
+	certRes := &Resource{
+		Domain:            "example.com",
+		CertURL:           "https://example.org/cert/1",
+		CertStableURL:     "https://example.org/cert/1",
+		PrivateKey:        []byte("private key"),
+		Certificate:       []byte(certResponseMock),
+		IssuerCertificate: []byte(issuerMock),
+	}
+
+	err = storage.Save(certRes)
+	require.NoError(t, err)
+
+	// the storage keeps a copy.
+	certRes.PrivateKey[0] = 'P'
+
+	loaded, err := storage.Load("example.com")
+	require.NoError(t, err)
+
+	expected := &Resource{
+		Domain:            "example.com",
+		CertURL:           "https://example.org/cert/1",
+		CertStableURL:     "https://example.org/cert/1",
+		PrivateKey:        []byte("private key"),
+		Certificate:       []byte(certResponseMock),
+		IssuerCertificate: []byte(issuerMock),
+	}
+
+	assert.Equal(t, expected, loaded)
+
+	_, err = storage.Load("example.org")
+	require.ErrorIs(t, err, ErrResourceNotFound)
+}
+
+func TestMemoryStorage_Save_errors(t *testing.T) {
+	storage := NewMemoryStorage()
+
+	err := storage.Save(nil)
+	require.EqualError(t, err, "certificate resource is nil")
+
+	err = storage.Save(&Resource{Certificate: []byte(certResponseMock)})
+	require.EqualError(t, err, "the domain of the certificate resource is empty")
+}
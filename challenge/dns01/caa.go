@@ -0,0 +1,51 @@
+package dns01
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// LookupCAA returns the relevant CAA records of a domain (RFC 8659, section 3).
+// The CAA records of the domain are returned if any,
+// otherwise the tree is climbed up to the closest ancestor having CAA records.
+// An empty result means that there is no CAA restriction.
+// The wildcard label of a domain is ignored.
+func LookupCAA(domain string) ([]*dns.CAA, error) {
+	return LookupCAACustom(domain, recursiveNameservers)
+}
+
+// LookupCAACustom returns the relevant CAA records of a domain (RFC 8659, section 3),
+// using the given nameservers.
+func LookupCAACustom(domain string, nameservers []string) ([]*dns.CAA, error) {
+	fqdn := ToFqdn(strings.TrimPrefix(domain, "*."))
+
+	for _, index := range dns.Split(fqdn) {
+		name := fqdn[index:]
+
+		in, err := dnsQuery(name, dns.TypeCAA, nameservers, true)
+		if err != nil {
+			return nil, fmt.Errorf("could not get CAA records of %s: %w", name, err)
+		}
+
+		if in.Rcode != dns.RcodeSuccess && in.Rcode != dns.RcodeNameError {
+			return nil, fmt.Errorf("could not get CAA records of %s: unexpected response code: %s", name, dns.RcodeToString[in.Rcode])
+		}
+
+		var records []*dns.CAA
+
+		// the answer can contain the CNAME records followed by the resolver.
+		for _, rr := range in.Answer {
+			if caa, ok := rr.(*dns.CAA); ok {
+				records = append(records, caa)
+			}
+		}
+
+		if len(records) > 0 {
+			return records, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,71 @@
+package dns01
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupCAACustom(t *testing.T) {
+	var queries []string
+
+	addr := runLocalDNSTestServer(t, func(w dns.ResponseWriter, req *dns.Msg) {
+		name := req.Question[0].Name
+		queries = append(queries, name)
+
+		m := new(dns.Msg)
+		m.SetReply(req)
+
+		if name == "example.com." {
+			m.Answer = []dns.RR{&dns.CAA{
+				Hdr:   dns.RR_Header{Name: name, Rrtype: dns.TypeCAA, Class: dns.ClassINET, Ttl: 120},
+				Tag:   "issue",
+				Value: "letsencrypt.org",
+			}}
+		}
+
+		_ = w.WriteMsg(m)
+	})
+
+	records, err := LookupCAACustom("*.a.b.example.com", []string{addr})
+	require.NoError(t, err)
+
+	require.Len(t, records, 1)
+	assert.Equal(t, "issue", records[0].Tag)
+	assert.Equal(t, "letsencrypt.org", records[0].Value)
+
+	assert.Equal(t, []string{"a.b.example.com.", "b.example.com.", "example.com."}, queries)
+}
+
+func TestLookupCAACustom_noRecords(t *testing.T) {
+	var queries []string
+
+	addr := runLocalDNSTestServer(t, func(w dns.ResponseWriter, req *dns.Msg) {
+		queries = append(queries, req.Question[0].Name)
+
+		m := new(dns.Msg)
+		m.SetRcode(req, dns.RcodeNameError)
+
+		_ = w.WriteMsg(m)
+	})
+
+	records, err := LookupCAACustom("example.com", []string{addr})
+	require.NoError(t, err)
+
+	assert.Empty(t, records)
+	assert.Equal(t, []string{"example.com.", "com."}, queries)
+}
+
+func TestLookupCAACustom_error(t *testing.T) {
+	addr := runLocalDNSTestServer(t, func(w dns.ResponseWriter, req *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetRcode(req, dns.RcodeServerFailure)
+
+		_ = w.WriteMsg(m)
+	})
+
+	_, err := LookupCAACustom("example.com", []string{addr})
+	require.EqualError(t, err, "could not get CAA records of example.com.: unexpected response code: SERVFAIL")
+}
@@ -0,0 +1,78 @@
+package dns01
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-acme/lego/v4/log"
+)
+
+// CleanUpFunc removes the DNS record created to fulfill the `dns-01` challenge.
+type CleanUpFunc func(domain, token, keyAuth string) error
+
+// CleanUpRetry Allow to retry, with a bounded exponential backoff, the clean up of the DNS record when the DNS provider fails.
+// `retries` is the maximum number of retries after the first attempt,
+// and `interval` is the delay before the first retry (doubled for each following retry).
+func CleanUpRetry(retries int, interval time.Duration) ChallengeOption {
+	return func(chlg *Challenge) error {
+		if retries < 0 {
+			return errors.New("the number of clean up retries must not be negative")
+		}
+
+		if interval <= 0 {
+			return errors.New("the clean up retry interval must be positive")
+		}
+
+		chlg.cleanUpRetry = cleanUpRetry{retries: retries, interval: interval}
+
+		return nil
+	}
+}
+
+type cleanUpRetry struct {
+	// maximum number of retries after the first attempt.
+	retries int
+	// delay before the first retry.
+	interval time.Duration
+}
+
+// maxInterval returns the delay before the last retry, saturated to avoid an overflow with many retries.
+func (r cleanUpRetry) maxInterval() time.Duration {
+	interval := r.interval
+
+	for i := 1; i < r.retries; i++ {
+		if interval > math.MaxInt64/2 {
+			return math.MaxInt64
+		}
+
+		interval *= 2
+	}
+
+	return interval
+}
+
+// call calls the clean up function, and retries it while it fails.
+func (r cleanUpRetry) call(domain, token, keyAuth string, cleanUp CleanUpFunc) error {
+	if r.retries <= 0 {
+		return cleanUp(domain, token, keyAuth)
+	}
+
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = r.interval
+	bo.RandomizationFactor = 0
+	bo.Multiplier = 2
+	bo.MaxInterval = r.maxInterval()
+	bo.MaxElapsedTime = 0
+
+	notify := func(err error, next time.Duration) {
+		log.Warnf("[%s] acme: cleaning up the DNS record failed, retrying in %s: %v", domain, next, err)
+	}
+
+	operation := func() error {
+		return cleanUp(domain, token, keyAuth)
+	}
+
+	return backoff.RetryNotify(operation, backoff.WithMaxRetries(bo, uint64(r.retries)), notify)
+}
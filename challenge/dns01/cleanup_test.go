@@ -0,0 +1,117 @@
+package dns01
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type providerFlakyMock struct {
+	failures int
+	calls    int
+}
+
+func (p *providerFlakyMock) Present(_, _, _ string) error { return nil }
+
+func (p *providerFlakyMock) CleanUp(_, _, _ string) error {
+	p.calls++
+	if p.calls <= p.failures {
+		return errors.New("API unavailable")
+	}
+
+	return nil
+}
+
+func TestChallenge_CleanUp_retry(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc          string
+		retries       int
+		failures      int
+		expectedCalls int
+		expectError   bool
+	}{
+		{
+			desc:          "two failures then success",
+			retries:       3,
+			failures:      2,
+			expectedCalls: 3,
+		},
+		{
+			desc:          "too many failures",
+			retries:       2,
+			failures:      5,
+			expectedCalls: 3,
+			expectError:   true,
+		},
+		{
+			desc:          "no retry",
+			retries:       0,
+			failures:      1,
+			expectedCalls: 1,
+			expectError:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			provider := &providerFlakyMock{failures: test.failures}
+
+			validate := func(_ *api.Core, _ string, _ acme.Challenge) error { return nil }
+
+			chlg := NewChallenge(core, validate, provider, CleanUpRetry(test.retries, time.Millisecond))
+
+			authz := acme.Authorization{
+				Identifier: acme.Identifier{
+					Value: "example.com",
+				},
+				Challenges: []acme.Challenge{
+					{Type: challenge.DNS01.String()},
+				},
+			}
+
+			err = chlg.CleanUp(authz)
+			if test.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedCalls, provider.calls)
+		})
+	}
+}
+
+func Test_cleanUpRetry_maxInterval(t *testing.T) {
+	assert.Equal(t, time.Second, cleanUpRetry{retries: 1, interval: time.Second}.maxInterval())
+	assert.Equal(t, 4*time.Second, cleanUpRetry{retries: 3, interval: time.Second}.maxInterval())
+
+	// saturated.
+	assert.Equal(t, time.Duration(math.MaxInt64), cleanUpRetry{retries: 100, interval: time.Second}.maxInterval())
+}
+
+func TestCleanUpRetry_invalid(t *testing.T) {
+	err := CleanUpRetry(-1, time.Second)(&Challenge{})
+	require.EqualError(t, err, "the number of clean up retries must not be negative")
+
+	err = CleanUpRetry(1, 0)(&Challenge{})
+	require.EqualError(t, err, "the clean up retry interval must be positive")
+}
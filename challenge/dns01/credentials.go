@@ -0,0 +1,31 @@
+package dns01
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-acme/lego/v4/challenge"
+)
+
+// CredentialsChecker is implemented by the DNS providers able to check their credentials,
+// e.g. to detect a misconfiguration before a long run.
+type CredentialsChecker interface {
+	// CredentialsValid checks that the credentials are accepted by the API of the DNS provider.
+	CredentialsValid(ctx context.Context) error
+}
+
+// CheckCredentials checks the credentials of the DNS provider.
+// It returns false if the DNS provider doesn't implement CredentialsChecker: the credentials are not checked.
+func CheckCredentials(ctx context.Context, provider challenge.Provider) (bool, error) {
+	checker, ok := provider.(CredentialsChecker)
+	if !ok {
+		return false, nil
+	}
+
+	err := checker.CredentialsValid(ctx)
+	if err != nil {
+		return true, fmt.Errorf("dns01: invalid credentials: %w", err)
+	}
+
+	return true, nil
+}
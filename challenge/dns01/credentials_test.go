@@ -0,0 +1,63 @@
+package dns01
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type providerCredentialsMock struct {
+	providerMock
+	err error
+}
+
+func (p *providerCredentialsMock) CredentialsValid(_ context.Context) error {
+	return p.err
+}
+
+func TestCheckCredentials(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		provider        *providerCredentialsMock
+		expectedChecked bool
+		expectedErr     string
+	}{
+		{
+			desc:            "valid credentials",
+			provider:        &providerCredentialsMock{},
+			expectedChecked: true,
+		},
+		{
+			desc:            "invalid credentials",
+			provider:        &providerCredentialsMock{err: errors.New("401 Unauthorized")},
+			expectedChecked: true,
+			expectedErr:     "dns01: invalid credentials: 401 Unauthorized",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			checked, err := CheckCredentials(context.Background(), test.provider)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedChecked, checked)
+		})
+	}
+}
+
+func TestCheckCredentials_notSupported(t *testing.T) {
+	checked, err := CheckCredentials(context.Background(), &providerMock{})
+	require.NoError(t, err)
+
+	assert.False(t, checked)
+}
@@ -1,17 +1,23 @@
 package dns01
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/acme/api"
 	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/internal/domainsuffix"
 	"github.com/go-acme/lego/v4/log"
+	"github.com/go-acme/lego/v4/platform/observer"
 	"github.com/go-acme/lego/v4/platform/wait"
 	"github.com/miekg/dns"
 )
@@ -25,6 +31,9 @@ const (
 
 	// DefaultTTL default TTL.
 	DefaultTTL = 120
+
+	// DefaultChallengePrefix default label of the challenge domain.
+	DefaultChallengePrefix = "_acme-challenge"
 )
 
 type ValidateFunc func(core *api.Core, domain string, chlng acme.Challenge) error
@@ -47,8 +56,43 @@ type Challenge struct {
 	core       *api.Core
 	validate   ValidateFunc
 	provider   challenge.Provider
+	resolver   ProviderResolver
 	preCheck   preCheck
 	dnsTimeout time.Duration
+
+	cleanUpRetry cleanUpRetry
+
+	// propagationTimeouts the propagation timeouts by domain (see WithPropagationTimeout).
+	propagationTimeouts domainsuffix.Map[time.Duration]
+
+	observer observer.Observer
+
+	// lastPresent is the time of the last call to Present by provider, used to space out the calls.
+	lastPresent   map[challenge.Provider]time.Time
+	lastPresentMu sync.Mutex
+
+	// prefix is the label prepended to the domains to get the challenge domains (see WithChallengePrefix).
+	prefix string
+}
+
+// WithChallengePrefix sets the label prepended to the domains to get the challenge domains (`_acme-challenge` by default).
+// The prefix is given to the DNS providers through the context (see ChallengePrefix):
+// only the providers implementing challenge.ProviderContext support a custom prefix.
+func WithChallengePrefix(prefix string) ChallengeOption {
+	return func(chlg *Challenge) error {
+		prefix = strings.Trim(prefix, ".")
+		if prefix == "" {
+			return errors.New("empty challenge prefix")
+		}
+
+		if _, ok := dns.IsDomainName(prefix); !ok {
+			return fmt.Errorf("invalid challenge prefix %q", prefix)
+		}
+
+		chlg.prefix = prefix
+
+		return nil
+	}
 }
 
 func NewChallenge(core *api.Core, validate ValidateFunc, provider challenge.Provider, opts ...ChallengeOption) *Challenge {
@@ -58,6 +102,7 @@ func NewChallenge(core *api.Core, validate ValidateFunc, provider challenge.Prov
 		provider:   provider,
 		preCheck:   newPreCheck(),
 		dnsTimeout: 10 * time.Second,
+		prefix:     DefaultChallengePrefix,
 	}
 
 	for _, opt := range opts {
@@ -70,9 +115,19 @@ func NewChallenge(core *api.Core, validate ValidateFunc, provider challenge.Prov
 	return chlg
 }
 
+// SetObserver sets the observer of the duration of the presentation of the challenge and of the propagation of the DNS record.
+func (c *Challenge) SetObserver(o observer.Observer) {
+	c.observer = o
+}
+
 // PreSolve just submits the txt record to the dns provider.
 // It does not validate record propagation, or do anything at all with the acme server.
 func (c *Challenge) PreSolve(authz acme.Authorization) error {
+	return c.PreSolveContext(context.Background(), authz)
+}
+
+// PreSolveContext is like PreSolve, the context is given to the providers implementing challenge.ProviderContext.
+func (c *Challenge) PreSolveContext(ctx context.Context, authz acme.Authorization) error {
 	domain := challenge.GetTargetedDomain(authz)
 	log.Infof("[%s] acme: Preparing to solve DNS-01", domain)
 
@@ -81,8 +136,9 @@ func (c *Challenge) PreSolve(authz acme.Authorization) error {
 		return err
 	}
 
-	if c.provider == nil {
-		return fmt.Errorf("[%s] acme: no DNS Provider configured", domain)
+	provider, err := c.providerFor(authz.Identifier.Value)
+	if err != nil {
+		return err
 	}
 
 	// Generate the Key Authorization for the challenge
@@ -91,7 +147,31 @@ func (c *Challenge) PreSolve(authz acme.Authorization) error {
 		return err
 	}
 
-	err = c.provider.Present(authz.Identifier.Value, chlng.Token, keyAuth)
+	if err = c.checkPrefixSupport(provider); err != nil {
+		return fmt.Errorf("[%s] acme: %w", domain, err)
+	}
+
+	if err = c.waitSequentialDuration(ctx, provider, domain); err != nil {
+		return fmt.Errorf("[%s] acme: error presenting token: %w", domain, err)
+	}
+
+	start := time.Now()
+
+	if p, ok := provider.(challenge.ProviderContext); ok {
+		err = p.PresentContext(withChallengePrefix(ctx, c.prefix), authz.Identifier.Value, chlng.Token, keyAuth)
+	} else {
+		err = provider.Present(authz.Identifier.Value, chlng.Token, keyAuth)
+	}
+
+	c.lastPresentMu.Lock()
+	if c.lastPresent == nil {
+		c.lastPresent = make(map[challenge.Provider]time.Time)
+	}
+	c.lastPresent[provider] = time.Now()
+	c.lastPresentMu.Unlock()
+
+	observer.Observe(c.observer, observer.PhasePresent, domain, start)
+
 	if err != nil {
 		return fmt.Errorf("[%s] acme: error presenting token: %w", domain, err)
 	}
@@ -99,7 +179,48 @@ func (c *Challenge) PreSolve(authz acme.Authorization) error {
 	return nil
 }
 
+// waitSequentialDuration waits, if the provider requires it, the delay between two calls to Present of the provider.
+// It returns the error of the context if the context is canceled before the end of the delay.
+func (c *Challenge) waitSequentialDuration(ctx context.Context, provider challenge.Provider, domain string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p, ok := provider.(challenge.ProviderSequentialDuration)
+	if !ok {
+		return nil
+	}
+
+	c.lastPresentMu.Lock()
+	lastPresent, ok := c.lastPresent[provider]
+	c.lastPresentMu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	delay := time.Until(lastPresent.Add(p.SequentialDuration()))
+	if delay <= 0 {
+		return nil
+	}
+
+	log.Infof("[%s] acme: Waiting %s before presenting the DNS-01 challenge", domain, delay)
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Solve checks the propagation of the TXT record, then validates the challenge.
 func (c *Challenge) Solve(authz acme.Authorization) error {
+	return c.SolveContext(context.Background(), authz)
+}
+
+// SolveContext is like Solve, the propagation wait and the validation are aborted when the context is canceled.
+func (c *Challenge) SolveContext(ctx context.Context, authz acme.Authorization) error {
 	domain := challenge.GetTargetedDomain(authz)
 	log.Infof("[%s] acme: Trying to solve DNS-01", domain)
 
@@ -114,38 +235,72 @@ func (c *Challenge) Solve(authz acme.Authorization) error {
 		return err
 	}
 
-	fqdn, value := GetRecord(authz.Identifier.Value, keyAuth)
+	chlng.KeyAuthorization = keyAuth
+
+	provider, err := c.providerFor(authz.Identifier.Value)
+	if err != nil {
+		return err
+	}
+
+	if p, ok := provider.(challenge.ProviderSynchronous); ok && p.Synchronous() {
+		log.Infof("[%s] acme: The DNS provider confirmed the record synchronously, skipping the propagation check", domain)
+
+		return c.validate(c.core.WithContext(ctx), domain, chlng)
+	}
+
+	info := getChallengeInfo(c.prefix, authz.Identifier.Value, keyAuth, c.preCheck.nameservers())
 
 	var timeout, interval time.Duration
-	switch provider := c.provider.(type) {
+	switch p := provider.(type) {
 	case challenge.ProviderTimeout:
-		timeout, interval = provider.Timeout()
+		timeout, interval = p.Timeout()
 	default:
 		timeout, interval = DefaultPropagationTimeout, DefaultPollingInterval
 	}
 
-	log.Infof("[%s] acme: Checking DNS record propagation using %+v", domain, recursiveNameservers)
+	timeout = c.propagationTimeout(authz.Identifier.Value, timeout)
 
-	time.Sleep(interval)
+	log.Infof("[%s] acme: Checking DNS record propagation using %+v", domain, c.preCheck.nameservers())
 
-	err = wait.For("propagation", timeout, interval, func() (bool, error) {
-		stop, errP := c.preCheck.call(domain, fqdn, value)
+	pc := c.preCheck
+	if p, ok := provider.(AuthoritativeNameserversProvider); ok {
+		pc.authoritative = p
+	}
+
+	start := time.Now()
+
+	select {
+	case <-time.After(interval):
+	case <-ctx.Done():
+		return fmt.Errorf("[%s] acme: %w", domain, ctx.Err())
+	}
+
+	err = wait.ForContext(ctx, "propagation", timeout, interval, func() (bool, error) {
+		stop, errP := pc.call(domain, info.EffectiveFQDN, info.Value)
 		if !stop || errP != nil {
 			log.Infof("[%s] acme: Waiting for DNS record propagation.", domain)
 		}
 		return stop, errP
 	})
+
+	observer.Observe(c.observer, observer.PhasePropagation, domain, start)
+
 	if err != nil {
 		return err
 	}
 
-	chlng.KeyAuthorization = keyAuth
-	return c.validate(c.core, domain, chlng)
+	return c.validate(c.core.WithContext(ctx), domain, chlng)
 }
 
 // CleanUp cleans the challenge.
 func (c *Challenge) CleanUp(authz acme.Authorization) error {
-	log.Infof("[%s] acme: Cleaning DNS-01 challenge", challenge.GetTargetedDomain(authz))
+	return c.CleanUpContext(context.Background(), authz)
+}
+
+// CleanUpContext is like CleanUp, the context is given to the providers implementing challenge.ProviderContext.
+func (c *Challenge) CleanUpContext(ctx context.Context, authz acme.Authorization) error {
+	domain := challenge.GetTargetedDomain(authz)
+	log.Infof("[%s] acme: Cleaning DNS-01 challenge", domain)
 
 	chlng, err := challenge.FindChallenge(challenge.DNS01, authz)
 	if err != nil {
@@ -157,9 +312,36 @@ func (c *Challenge) CleanUp(authz acme.Authorization) error {
 		return err
 	}
 
-	return c.provider.CleanUp(authz.Identifier.Value, chlng.Token, keyAuth)
+	provider, err := c.providerFor(authz.Identifier.Value)
+	if err != nil {
+		return err
+	}
+
+	if err = c.checkPrefixSupport(provider); err != nil {
+		return fmt.Errorf("[%s] acme: %w", domain, err)
+	}
+
+	cleanUp := provider.CleanUp
+	if p, ok := provider.(challenge.ProviderContext); ok {
+		cleanUp = func(domain, token, keyAuth string) error {
+			return p.CleanUpContext(withChallengePrefix(ctx, c.prefix), domain, token, keyAuth)
+		}
+	}
+
+	return c.cleanUpRetry.call(authz.Identifier.Value, chlng.Token, keyAuth, cleanUp)
+}
+
+// checkPrefixSupport returns an error if the challenge uses a custom prefix the provider can't know.
+func (c *Challenge) checkPrefixSupport(provider challenge.Provider) error {
+	if _, ok := provider.(challenge.ProviderContext); ok || c.prefix == DefaultChallengePrefix {
+		return nil
+	}
+
+	return fmt.Errorf("the DNS provider doesn't support the custom challenge prefix %q", c.prefix)
 }
 
+// Sequential returns true if the provider of the challenge requires to solve the challenges one by one.
+// With a provider resolver, only the provider of the challenge is considered (see SequentialFor).
 func (c *Challenge) Sequential() (bool, time.Duration) {
 	if p, ok := c.provider.(sequential); ok {
 		return ok, p.Sequential()
@@ -167,32 +349,95 @@ func (c *Challenge) Sequential() (bool, time.Duration) {
 	return false, 0
 }
 
+// SequentialFor is like Sequential, for the provider of the domain of the authorization (see WithProviderResolver).
+func (c *Challenge) SequentialFor(authz acme.Authorization) (bool, time.Duration) {
+	provider, err := c.providerFor(authz.Identifier.Value)
+	if err != nil {
+		// the error is reported by the presentation of the challenge.
+		return false, 0
+	}
+
+	if p, ok := provider.(sequential); ok {
+		return ok, p.Sequential()
+	}
+	return false, 0
+}
+
 type sequential interface {
 	Sequential() time.Duration
 }
 
-// GetRecord returns a DNS record which will fulfill the `dns-01` challenge.
-func GetRecord(domain, keyAuth string) (fqdn, value string) {
+// ChallengeInfo contains the information used to create the TXT record of the `dns-01` challenge.
+type ChallengeInfo struct {
+	// FQDN is the full-qualified challenge domain (i.e. `_acme-challenge.[domain].`, or `[prefix].[domain].` with WithChallengePrefix).
+	FQDN string
+
+	// EffectiveFQDN is the FQDN where the TXT record must be created:
+	// when the challenge domain is delegated with CNAMEs (e.g. to a dedicated validation zone),
+	// this is the target of the CNAME chain, otherwise this is FQDN.
+	EffectiveFQDN string
+
+	// Value is the value of the TXT record.
+	Value string
+}
+
+type challengePrefixKey struct{}
+
+func withChallengePrefix(ctx context.Context, prefix string) context.Context {
+	return context.WithValue(ctx, challengePrefixKey{}, prefix)
+}
+
+// ChallengePrefix returns the challenge prefix given by the challenge to the providers implementing challenge.ProviderContext,
+// DefaultChallengePrefix if the context doesn't contain a prefix.
+func ChallengePrefix(ctx context.Context) string {
+	if prefix, ok := ctx.Value(challengePrefixKey{}).(string); ok {
+		return prefix
+	}
+
+	return DefaultChallengePrefix
+}
+
+// GetChallengeInfo returns the information used to create the DNS record which will fulfill the `dns-01` challenge.
+// The prefix is the label of the challenge domain (DefaultChallengePrefix, or the result of ChallengePrefix).
+// The CNAMEs of the challenge domain are followed (unless LEGO_DISABLE_CNAME_SUPPORT is true),
+// so a provider creating the record at EffectiveFQDN supports the delegation of the challenge domain.
+func GetChallengeInfo(prefix, domain, keyAuth string) ChallengeInfo {
+	return getChallengeInfo(prefix, domain, keyAuth, recursiveNameservers)
+}
+
+func getChallengeInfo(prefix, domain, keyAuth string, nameservers []string) ChallengeInfo {
 	keyAuthShaBytes := sha256.Sum256([]byte(keyAuth))
-	// base64URL encoding without padding
-	value = base64.RawURLEncoding.EncodeToString(keyAuthShaBytes[:sha256.Size])
 
-	fqdn = getChallengeFqdn(domain)
+	fqdn := fmt.Sprintf("%s.%s.", prefix, domain)
 
-	return
+	return ChallengeInfo{
+		FQDN:          fqdn,
+		EffectiveFQDN: getChallengeFqdn(fqdn, nameservers),
+		// base64URL encoding without padding
+		Value: base64.RawURLEncoding.EncodeToString(keyAuthShaBytes[:sha256.Size]),
+	}
 }
 
-func getChallengeFqdn(domain string) string {
-	fqdn := fmt.Sprintf("_acme-challenge.%s.", domain)
+// GetRecord returns a DNS record which will fulfill the `dns-01` challenge.
+// The FQDN is the effective FQDN (see GetChallengeInfo) of the default challenge domain (`_acme-challenge.[domain].`).
+func GetRecord(domain, keyAuth string) (fqdn, value string) {
+	info := GetChallengeInfo(DefaultChallengePrefix, domain, keyAuth)
+
+	return info.EffectiveFQDN, info.Value
+}
 
+// getChallengeFqdn follows the CNAME chain of the challenge FQDN.
+func getChallengeFqdn(fqdn string, nameservers []string) string {
 	if ok, _ := strconv.ParseBool(os.Getenv("LEGO_DISABLE_CNAME_SUPPORT")); ok {
 		return fqdn
 	}
 
+	visited := map[string]struct{}{fqdn: {}}
+
 	// recursion counter so it doesn't spin out of control
 	for limit := 0; limit < 50; limit++ {
 		// Keep following CNAMEs
-		r, err := dnsQuery(fqdn, dns.TypeCNAME, recursiveNameservers, true)
+		r, err := dnsQuery(fqdn, dns.TypeCNAME, nameservers, true)
 
 		if err != nil || r.Rcode != dns.RcodeSuccess {
 			// No more CNAME records to follow, exit
@@ -205,6 +450,13 @@ func getChallengeFqdn(domain string) string {
 			break
 		}
 
+		if _, ok := visited[cname]; ok {
+			log.Warnf("CNAME loop detected for %q: %q", fqdn, cname)
+			break
+		}
+
+		visited[cname] = struct{}{}
+
 		log.Infof("Found CNAME entry for %q: %q", fqdn, cname)
 
 		fqdn = cname
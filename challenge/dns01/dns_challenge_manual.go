@@ -2,6 +2,7 @@ package dns01
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -20,8 +21,14 @@ func NewDNSProviderManual() (*DNSProviderManual, error) {
 }
 
 // Present prints instructions for manually creating the TXT record.
-func (*DNSProviderManual) Present(domain, token, keyAuth string) error {
-	fqdn, value := GetRecord(domain, keyAuth)
+func (d *DNSProviderManual) Present(domain, token, keyAuth string) error {
+	return d.PresentContext(context.Background(), domain, token, keyAuth)
+}
+
+// PresentContext is like Present, the record is named with the challenge prefix of the context (see ChallengePrefix).
+func (*DNSProviderManual) PresentContext(ctx context.Context, domain, _, keyAuth string) error {
+	info := GetChallengeInfo(ChallengePrefix(ctx), domain, keyAuth)
+	fqdn, value := info.EffectiveFQDN, info.Value
 
 	authZone, err := FindZoneByFqdn(fqdn)
 	if err != nil {
@@ -38,8 +45,13 @@ func (*DNSProviderManual) Present(domain, token, keyAuth string) error {
 }
 
 // CleanUp prints instructions for manually removing the TXT record.
-func (*DNSProviderManual) CleanUp(domain, token, keyAuth string) error {
-	fqdn, _ := GetRecord(domain, keyAuth)
+func (d *DNSProviderManual) CleanUp(domain, token, keyAuth string) error {
+	return d.CleanUpContext(context.Background(), domain, token, keyAuth)
+}
+
+// CleanUpContext is like CleanUp, the record is named with the challenge prefix of the context (see ChallengePrefix).
+func (*DNSProviderManual) CleanUpContext(ctx context.Context, domain, _, keyAuth string) error {
+	fqdn := GetChallengeInfo(ChallengePrefix(ctx), domain, keyAuth).EffectiveFQDN
 
 	authZone, err := FindZoneByFqdn(fqdn)
 	if err != nil {
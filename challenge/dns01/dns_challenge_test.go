@@ -1,10 +1,12 @@
 package dns01
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"errors"
 	"net/http"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -12,6 +14,8 @@ import (
 	"github.com/go-acme/lego/v4/acme/api"
 	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -31,6 +35,46 @@ func (p *providerTimeoutMock) Present(domain, token, keyAuth string) error { ret
 func (p *providerTimeoutMock) CleanUp(domain, token, keyAuth string) error { return p.cleanUp }
 func (p *providerTimeoutMock) Timeout() (time.Duration, time.Duration)     { return p.timeout, p.interval }
 
+// providerRecorderMock records the records presented and cleaned.
+type providerRecorderMock struct {
+	presented, cleaned map[string]string
+}
+
+func (p *providerRecorderMock) Present(domain, token, keyAuth string) error {
+	return p.PresentContext(context.Background(), domain, token, keyAuth)
+}
+
+func (p *providerRecorderMock) PresentContext(ctx context.Context, domain, _, keyAuth string) error {
+	info := GetChallengeInfo(ChallengePrefix(ctx), domain, keyAuth)
+	p.presented[info.EffectiveFQDN] = info.Value
+
+	return nil
+}
+
+func (p *providerRecorderMock) CleanUp(domain, token, keyAuth string) error {
+	return p.CleanUpContext(context.Background(), domain, token, keyAuth)
+}
+
+func (p *providerRecorderMock) CleanUpContext(ctx context.Context, domain, _, keyAuth string) error {
+	info := GetChallengeInfo(ChallengePrefix(ctx), domain, keyAuth)
+	p.cleaned[info.EffectiveFQDN] = info.Value
+
+	return nil
+}
+
+type providerSequentialDurationMock struct {
+	duration time.Duration
+	presents []time.Time
+}
+
+func (p *providerSequentialDurationMock) Present(_, _, _ string) error {
+	p.presents = append(p.presents, time.Now())
+	return nil
+}
+
+func (p *providerSequentialDurationMock) CleanUp(_, _, _ string) error      { return nil }
+func (p *providerSequentialDurationMock) SequentialDuration() time.Duration { return p.duration }
+
 func TestChallenge_PreSolve(t *testing.T) {
 	_, apiURL := tester.SetupFakeAPI(t)
 
@@ -200,6 +244,83 @@ func TestChallenge_Solve(t *testing.T) {
 	}
 }
 
+type providerContextMock struct {
+	providerTimeoutMock
+
+	presentCtx, cleanUpCtx context.Context
+}
+
+func (p *providerContextMock) PresentContext(ctx context.Context, _, _, _ string) error {
+	p.presentCtx = ctx
+	return nil
+}
+
+func (p *providerContextMock) CleanUpContext(ctx context.Context, _, _, _ string) error {
+	p.cleanUpCtx = ctx
+	return nil
+}
+
+func TestChallenge_SolveContext_canceled(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	var checks int32
+
+	// the record never propagates.
+	preCheck := func(_, _, _ string, _ PreCheckFunc) (bool, error) {
+		atomic.AddInt32(&checks, 1)
+		return false, nil
+	}
+
+	validate := func(_ *api.Core, _ string, _ acme.Challenge) error {
+		return errors.New("the challenge must not be validated")
+	}
+
+	provider := &providerContextMock{
+		providerTimeoutMock: providerTimeoutMock{timeout: time.Hour, interval: 100 * time.Millisecond},
+	}
+
+	chlg := NewChallenge(core, validate, provider, WrapPreCheck(preCheck))
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Value: "example.com"},
+		Challenges: []acme.Challenge{{Type: challenge.DNS01.String()}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = chlg.PreSolveContext(ctx, authz)
+	require.NoError(t, err)
+
+	require.NotNil(t, provider.presentCtx)
+	require.NoError(t, provider.presentCtx.Err())
+
+	time.AfterFunc(500*time.Millisecond, cancel)
+
+	start := time.Now()
+
+	err = chlg.SolveContext(ctx, authz)
+	require.ErrorIs(t, err, context.Canceled)
+
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.NotZero(t, atomic.LoadInt32(&checks))
+
+	// the context given to the provider is the context of the caller.
+	require.ErrorIs(t, provider.presentCtx.Err(), context.Canceled)
+
+	err = chlg.CleanUp(authz)
+	require.NoError(t, err)
+
+	require.NotNil(t, provider.cleanUpCtx)
+	require.NoError(t, provider.cleanUpCtx.Err())
+}
+
 func TestChallenge_CleanUp(t *testing.T) {
 	_, apiURL := tester.SetupFakeAPI(t)
 
@@ -281,3 +402,424 @@ func TestChallenge_CleanUp(t *testing.T) {
 		})
 	}
 }
+
+func TestChallenge_wildcardOnly(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	provider := &providerRecorderMock{presented: map[string]string{}, cleaned: map[string]string{}}
+
+	chlg := NewChallenge(core, nil, provider)
+
+	// the authorization of a wildcard identifier contains the apex domain.
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		Wildcard:   true,
+		Challenges: []acme.Challenge{
+			{Type: challenge.DNS01.String(), Token: "wildcard"},
+		},
+	}
+
+	assert.Equal(t, "*.example.com", challenge.GetTargetedDomain(authz))
+
+	err = chlg.PreSolve(authz)
+	require.NoError(t, err)
+
+	err = chlg.CleanUp(authz)
+	require.NoError(t, err)
+
+	keyAuth, err := core.GetKeyAuthorization("wildcard")
+	require.NoError(t, err)
+
+	_, value := GetRecord("example.com", keyAuth)
+
+	expected := map[string]string{"_acme-challenge.example.com.": value}
+
+	assert.Equal(t, expected, provider.presented)
+	assert.Equal(t, expected, provider.cleaned)
+}
+
+func TestChallenge_challengePrefix(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	provider := &providerRecorderMock{presented: map[string]string{}, cleaned: map[string]string{}}
+
+	chlg := NewChallenge(core, nil, provider, WithChallengePrefix("_custom-challenge."))
+
+	// the prefix is specific to the challenge.
+	other := NewChallenge(core, nil, provider)
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		Challenges: []acme.Challenge{{Type: challenge.DNS01.String(), Token: "token"}},
+	}
+
+	err = chlg.PreSolve(authz)
+	require.NoError(t, err)
+
+	err = other.PreSolve(authz)
+	require.NoError(t, err)
+
+	err = chlg.CleanUp(authz)
+	require.NoError(t, err)
+
+	keyAuth, err := core.GetKeyAuthorization("token")
+	require.NoError(t, err)
+
+	info := GetChallengeInfo("_custom-challenge", "example.com", keyAuth)
+	assert.Equal(t, "_custom-challenge.example.com.", info.FQDN)
+
+	expected := map[string]string{
+		"_custom-challenge.example.com.": info.Value,
+		"_acme-challenge.example.com.":   info.Value,
+	}
+	assert.Equal(t, expected, provider.presented)
+
+	assert.Equal(t, map[string]string{"_custom-challenge.example.com.": info.Value}, provider.cleaned)
+}
+
+func TestChallenge_challengePrefix_unsupported(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	provider := &providerTimeoutMock{timeout: time.Second, interval: time.Second}
+
+	chlg := NewChallenge(core, nil, provider, WithChallengePrefix("_custom-challenge"))
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		Challenges: []acme.Challenge{{Type: challenge.DNS01.String(), Token: "token"}},
+	}
+
+	err = chlg.PreSolve(authz)
+	require.EqualError(t, err, `[example.com] acme: the DNS provider doesn't support the custom challenge prefix "_custom-challenge"`)
+
+	err = chlg.CleanUp(authz)
+	require.EqualError(t, err, `[example.com] acme: the DNS provider doesn't support the custom challenge prefix "_custom-challenge"`)
+}
+
+func TestWithChallengePrefix_invalid(t *testing.T) {
+	for _, prefix := range []string{"", ".", "_acme..challenge"} {
+		chlg := &Challenge{prefix: DefaultChallengePrefix}
+
+		err := WithChallengePrefix(prefix)(chlg)
+		require.Error(t, err, prefix)
+
+		assert.Equal(t, DefaultChallengePrefix, chlg.prefix)
+	}
+}
+
+func TestChallengePrefix(t *testing.T) {
+	assert.Equal(t, DefaultChallengePrefix, ChallengePrefix(context.Background()))
+	assert.Equal(t, "_custom", ChallengePrefix(withChallengePrefix(context.Background(), "_custom")))
+}
+
+func TestChallenge_PreSolve_sequentialDuration(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	provider := &providerSequentialDurationMock{duration: 100 * time.Millisecond}
+
+	chlg := NewChallenge(core, nil, provider)
+
+	for _, domain := range []string{"a.example.com", "b.example.com", "c.example.com"} {
+		err = chlg.PreSolve(acme.Authorization{
+			Identifier: acme.Identifier{Type: "dns", Value: domain},
+			Challenges: []acme.Challenge{{Type: challenge.DNS01.String(), Token: domain}},
+		})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, provider.presents, 3)
+
+	for i := 1; i < len(provider.presents); i++ {
+		assert.GreaterOrEqual(t, provider.presents[i].Sub(provider.presents[i-1]), provider.duration)
+	}
+}
+
+func TestChallenge_PreSolveContext_sequentialDuration_canceled(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	provider := &providerSequentialDurationMock{duration: time.Hour}
+
+	chlg := NewChallenge(core, nil, provider)
+
+	authz := func(domain string) acme.Authorization {
+		return acme.Authorization{
+			Identifier: acme.Identifier{Type: "dns", Value: domain},
+			Challenges: []acme.Challenge{{Type: challenge.DNS01.String(), Token: domain}},
+		}
+	}
+
+	require.NoError(t, chlg.PreSolve(authz("a.example.com")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	err = chlg.PreSolveContext(ctx, authz("b.example.com"))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Len(t, provider.presents, 1)
+}
+
+func TestChallenge_PreSolve_sequentialDuration_byProvider(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	providerA := &providerSequentialDurationMock{duration: time.Minute}
+	providerB := &providerSequentialDurationMock{duration: time.Minute}
+
+	providers := NewDomainProviders(nil)
+	require.NoError(t, providers.Add(providerA, "example.com"))
+	require.NoError(t, providers.Add(providerB, "example.org"))
+
+	chlg := NewChallenge(core, nil, nil, WithProviderResolver(providers))
+
+	start := time.Now()
+
+	// the delay only spaces out the calls of the same provider.
+	for _, domain := range []string{"example.com", "example.org"} {
+		err = chlg.PreSolve(acme.Authorization{
+			Identifier: acme.Identifier{Type: "dns", Value: domain},
+			Challenges: []acme.Challenge{{Type: challenge.DNS01.String(), Token: domain}},
+		})
+		require.NoError(t, err)
+	}
+
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Len(t, providerA.presents, 1)
+	assert.Len(t, providerB.presents, 1)
+}
+
+// cnameHandler answers the CNAME queries with the given CNAME records (owner -> target).
+func cnameHandler(cnames map[string]string) dns.HandlerFunc {
+	return func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+
+		q := r.Question[0]
+		if target, ok := cnames[q.Name]; ok && q.Qtype == dns.TypeCNAME {
+			m.Answer = append(m.Answer, &dns.CNAME{
+				Hdr:    dns.RR_Header{Name: q.Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 60},
+				Target: target,
+			})
+		} else {
+			m.SetRcode(r, dns.RcodeNameError)
+		}
+
+		_ = w.WriteMsg(m)
+	}
+}
+
+func Test_getChallengeInfo(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		cnames   map[string]string
+		expected string
+	}{
+		{
+			desc:     "no CNAME",
+			expected: "_acme-challenge.example.com.",
+		},
+		{
+			desc: "CNAME to a validation zone",
+			cnames: map[string]string{
+				"_acme-challenge.example.com.": "example.com.validation.example.net.",
+			},
+			expected: "example.com.validation.example.net.",
+		},
+		{
+			desc: "chained CNAMEs",
+			cnames: map[string]string{
+				"_acme-challenge.example.com.":        "example.com.validation.example.net.",
+				"example.com.validation.example.net.": "acme.example.org.",
+			},
+			expected: "acme.example.org.",
+		},
+		{
+			desc: "CNAME loop",
+			cnames: map[string]string{
+				"_acme-challenge.example.com.":        "example.com.validation.example.net.",
+				"example.com.validation.example.net.": "_acme-challenge.example.com.",
+			},
+			expected: "example.com.validation.example.net.",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			addr := runLocalDNSTestServer(t, cnameHandler(test.cnames))
+
+			info := getChallengeInfo(DefaultChallengePrefix, "example.com", "123d==", []string{addr})
+
+			assert.Equal(t, "_acme-challenge.example.com.", info.FQDN)
+			assert.Equal(t, test.expected, info.EffectiveFQDN)
+			assert.Equal(t, "ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY", info.Value)
+		})
+	}
+}
+
+func TestChallenge_PreSolve_cnameDelegation(t *testing.T) {
+	addr := runLocalDNSTestServer(t, cnameHandler(map[string]string{
+		"_acme-challenge.example.com.":        "example.com.validation.example.net.",
+		"example.com.validation.example.net.": "acme.example.org.",
+	}))
+
+	defaultNameservers := recursiveNameservers
+	t.Cleanup(func() { recursiveNameservers = defaultNameservers })
+
+	recursiveNameservers = []string{addr}
+
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	provider := &providerRecorderMock{presented: map[string]string{}, cleaned: map[string]string{}}
+
+	chlg := NewChallenge(core, nil, provider)
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		Challenges: []acme.Challenge{
+			{Type: challenge.DNS01.String(), Token: "delegated"},
+		},
+	}
+
+	err = chlg.PreSolve(authz)
+	require.NoError(t, err)
+
+	err = chlg.CleanUp(authz)
+	require.NoError(t, err)
+
+	keyAuth, err := core.GetKeyAuthorization("delegated")
+	require.NoError(t, err)
+
+	info := GetChallengeInfo(DefaultChallengePrefix, "example.com", keyAuth)
+
+	expected := map[string]string{"acme.example.org.": info.Value}
+
+	assert.Equal(t, expected, provider.presented)
+	assert.Equal(t, expected, provider.cleaned)
+}
+
+type providerSynchronousMock struct {
+	synchronous bool
+}
+
+func (p *providerSynchronousMock) Present(_, _, _ string) error { return nil }
+func (p *providerSynchronousMock) CleanUp(_, _, _ string) error { return nil }
+func (p *providerSynchronousMock) Synchronous() bool            { return p.synchronous }
+func (p *providerSynchronousMock) Timeout() (time.Duration, time.Duration) {
+	return 50 * time.Millisecond, 10 * time.Millisecond
+}
+
+func TestChallenge_Solve_synchronous(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		synchronous bool
+		expectError bool
+	}{
+		{
+			desc:        "synchronous",
+			synchronous: true,
+		},
+		{
+			desc:        "not synchronous",
+			expectError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var queries int32
+
+			addr := runLocalDNSTestServer(t, func(w dns.ResponseWriter, r *dns.Msg) {
+				atomic.AddInt32(&queries, 1)
+
+				m := new(dns.Msg)
+				m.SetRcode(r, dns.RcodeServerFailure)
+
+				_ = w.WriteMsg(m)
+			})
+
+			_, apiURL := tester.SetupFakeAPI(t)
+
+			privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+			require.NoError(t, err)
+
+			core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+			require.NoError(t, err)
+
+			var validated bool
+			validate := func(_ *api.Core, _ string, chlng acme.Challenge) error {
+				validated = true
+				assert.NotEmpty(t, chlng.KeyAuthorization)
+
+				return nil
+			}
+
+			chlg := NewChallenge(core, validate, &providerSynchronousMock{synchronous: test.synchronous},
+				AddScopedRecursiveNameservers([]string{addr}))
+
+			authz := acme.Authorization{
+				Identifier: acme.Identifier{Value: "example.com"},
+				Challenges: []acme.Challenge{{Type: challenge.DNS01.String()}},
+			}
+
+			err = chlg.Solve(authz)
+			if test.expectError {
+				require.Error(t, err)
+				assert.False(t, validated)
+				assert.NotZero(t, atomic.LoadInt32(&queries))
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, validated)
+			assert.Zero(t, atomic.LoadInt32(&queries))
+		})
+	}
+}
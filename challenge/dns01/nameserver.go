@@ -23,6 +23,9 @@ var defaultNameservers = []string{
 	"google-public-dns-b.google.com:53",
 }
 
+// dnsRetries is the number of retries of a DNS query without response (see AddDNSRetries).
+var dnsRetries int
+
 // recursiveNameservers are used to pre-check DNS propagation.
 var recursiveNameservers = getNameservers(defaultResolvConf, defaultNameservers)
 
@@ -60,6 +63,22 @@ func AddDNSTimeout(timeout time.Duration) ChallengeOption {
 	}
 }
 
+// AddDNSRetries sets the number of retries of a DNS query without response (e.g. a dropped UDP packet),
+// before querying the next nameserver.
+// The responses with an error code (e.g. NXDOMAIN, SERVFAIL) are not retried.
+func AddDNSRetries(retries int) ChallengeOption {
+	return func(_ *Challenge) error {
+		if retries < 0 {
+			return errors.New("dns01: the number of DNS retries must not be negative")
+		}
+
+		dnsRetries = retries
+
+		return nil
+	}
+}
+
+// AddRecursiveNameservers sets the recursive nameservers used by all the challenges (process-wide).
 func AddRecursiveNameservers(nameservers []string) ChallengeOption {
 	return func(_ *Challenge) error {
 		recursiveNameservers = ParseNameservers(nameservers)
@@ -67,6 +86,32 @@ func AddRecursiveNameservers(nameservers []string) ChallengeOption {
 	}
 }
 
+// AddScopedRecursiveNameservers sets the recursive nameservers used by a single challenge,
+// without changing the process-wide nameservers defined by AddRecursiveNameservers.
+func AddScopedRecursiveNameservers(nameservers []string) ChallengeOption {
+	return func(chlg *Challenge) error {
+		chlg.preCheck.recursiveNameservers = ParseNameservers(nameservers)
+		return nil
+	}
+}
+
+// UseExternalNameservers restricts the propagation check of a single challenge to the given nameservers (e.g. public resolvers).
+// It's intended for split-horizon DNS deployments, where the system resolvers serve an internal view of the zones.
+// The nameservers are used for the recursive queries and to resolve the addresses of the authoritative nameservers:
+// the system resolvers (resolv.conf) are never used.
+func UseExternalNameservers(nameservers []string) ChallengeOption {
+	return func(chlg *Challenge) error {
+		if len(nameservers) == 0 {
+			return errors.New("no external nameservers")
+		}
+
+		chlg.preCheck.recursiveNameservers = ParseNameservers(nameservers)
+		chlg.preCheck.externalOnly = true
+
+		return nil
+	}
+}
+
 // getNameservers attempts to get systems nameservers before falling back to the defaults.
 func getNameservers(path string, defaults []string) []string {
 	config, err := dns.ClientConfigFromFile(path)
@@ -91,15 +136,15 @@ func ParseNameservers(servers []string) []string {
 }
 
 // lookupNameservers returns the authoritative nameservers for the given fqdn.
-func lookupNameservers(fqdn string) ([]string, error) {
+func lookupNameservers(fqdn string, nameservers []string) ([]string, error) {
 	var authoritativeNss []string
 
-	zone, err := FindZoneByFqdn(fqdn)
+	zone, err := FindZoneByFqdnCustom(fqdn, nameservers)
 	if err != nil {
 		return nil, fmt.Errorf("could not determine the zone: %w", err)
 	}
 
-	r, err := dnsQuery(zone, dns.TypeNS, recursiveNameservers, true)
+	r, err := dnsQuery(zone, dns.TypeNS, nameservers, true)
 	if err != nil {
 		return nil, err
 	}
@@ -249,7 +294,19 @@ func createDNSMsg(fqdn string, rtype uint16, recursive bool) *dns.Msg {
 	return m
 }
 
+// sendDNSQuery sends the query to the nameserver, and retries it while there is no response (see AddDNSRetries).
 func sendDNSQuery(m *dns.Msg, ns string) (*dns.Msg, error) {
+	in, err := exchange(m, ns)
+
+	for i := 0; i < dnsRetries && in == nil && isNetworkError(err); i++ {
+		in, err = exchange(m, ns)
+	}
+
+	return in, err
+}
+
+// exchange sends the query over UDP, and over TCP if the UDP response is truncated.
+func exchange(m *dns.Msg, ns string) (*dns.Msg, error) {
 	udp := &dns.Client{Net: "udp", Timeout: dnsTimeout}
 	in, _, err := udp.Exchange(m, ns)
 
@@ -262,6 +319,14 @@ func sendDNSQuery(m *dns.Msg, ns string) (*dns.Msg, error) {
 	return in, err
 }
 
+// isNetworkError returns true if the error is a network error (e.g. a timeout),
+// and not an error of a response (e.g. an invalid message).
+func isNetworkError(err error) bool {
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
+
 func formatDNSError(msg *dns.Msg, err error) string {
 	var parts []string
 
@@ -2,8 +2,11 @@ package dns01
 
 import (
 	"sort"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -32,7 +35,7 @@ func TestLookupNameserversOK(t *testing.T) {
 		t.Run(test.fqdn, func(t *testing.T) {
 			t.Parallel()
 
-			nss, err := lookupNameservers(test.fqdn)
+			nss, err := lookupNameservers(test.fqdn, recursiveNameservers)
 			require.NoError(t, err)
 
 			sort.Strings(nss)
@@ -61,7 +64,7 @@ func TestLookupNameserversErr(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			_, err := lookupNameservers(test.fqdn)
+			_, err := lookupNameservers(test.fqdn, recursiveNameservers)
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), test.error)
 		})
@@ -197,3 +200,71 @@ func TestResolveConfServers(t *testing.T) {
 		})
 	}
 }
+
+func Test_sendDNSQuery_retries(t *testing.T) {
+	defer func(timeout time.Duration, retries int) {
+		dnsTimeout = timeout
+		dnsRetries = retries
+	}(dnsTimeout, dnsRetries)
+
+	dnsTimeout = 200 * time.Millisecond
+
+	testCases := []struct {
+		desc        string
+		retries     int
+		expectedErr bool
+		expected    int
+	}{
+		{
+			desc:        "without retry",
+			expectedErr: true,
+			expected:    1,
+		},
+		{
+			desc:     "dropped first query",
+			retries:  2,
+			expected: 2,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var queries atomic.Int32
+
+			addr := runLocalDNSTestServer(t, func(w dns.ResponseWriter, r *dns.Msg) {
+				// the first query is dropped.
+				if queries.Add(1) == 1 {
+					return
+				}
+
+				m := new(dns.Msg)
+				m.SetReply(r)
+				m.Answer = append(m.Answer, &dns.TXT{
+					Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 120},
+					Txt: []string{"value"},
+				})
+
+				_ = w.WriteMsg(m)
+			})
+
+			err := AddDNSRetries(test.retries)(nil)
+			require.NoError(t, err)
+
+			in, err := sendDNSQuery(createDNSMsg("_acme-challenge.example.com.", dns.TypeTXT, true), addr)
+			if test.expectedErr {
+				require.Error(t, err)
+				assert.True(t, isNetworkError(err))
+			} else {
+				require.NoError(t, err)
+				require.Len(t, in.Answer, 1)
+			}
+
+			assert.EqualValues(t, test.expected, queries.Load())
+		})
+	}
+}
+
+func TestAddDNSRetries_invalid(t *testing.T) {
+	err := AddDNSRetries(-1)(nil)
+	require.EqualError(t, err, "dns01: the number of DNS retries must not be negative")
+}
@@ -0,0 +1,80 @@
+package dns01
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/log"
+)
+
+// ChallengeRecord is a TXT record created to fulfill a `dns-01` challenge (a `_acme-challenge` record).
+type ChallengeRecord struct {
+	// ID is the identifier of the record in the DNS provider, it can be empty.
+	ID    string
+	FQDN  string
+	Value string
+}
+
+// ChallengeRecordsManager is implemented by the DNS providers able to list and remove the challenge records of a zone,
+// e.g. to remove the records left by a crashed process.
+type ChallengeRecordsManager interface {
+	// ListChallengeRecords returns the challenge TXT records of the zone, the records starting with the prefix.
+	ListChallengeRecords(zone, prefix string) ([]ChallengeRecord, error)
+	// RemoveChallengeRecord removes a record returned by ListChallengeRecords.
+	RemoveChallengeRecord(zone string, record ChallengeRecord) error
+}
+
+// CleanUpChallengeRecords lists the challenge TXT records of the zone, and removes them unless dryRun is true.
+// It returns the records found.
+// The prefix is the one of the challenge records (see WithChallengePrefix), DefaultChallengePrefix if empty.
+//
+// The records of the challenges in progress are also removed:
+// it must not be used while the provider solves challenges for the zone.
+func CleanUpChallengeRecords(provider challenge.Provider, zone, prefix string, dryRun bool) ([]ChallengeRecord, error) {
+	manager, ok := provider.(ChallengeRecordsManager)
+	if !ok {
+		return nil, errors.New("dns01: the DNS provider doesn't support the listing of the challenge records")
+	}
+
+	if prefix == "" {
+		prefix = DefaultChallengePrefix
+	}
+
+	zone = ToFqdn(strings.ToLower(zone))
+
+	found, err := manager.ListChallengeRecords(zone, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("dns01: list the challenge records of %s: %w", zone, err)
+	}
+
+	var records []ChallengeRecord
+	for _, record := range found {
+		// the providers may return other records, which must not be removed.
+		if IsChallengeRecord(prefix, record.FQDN) {
+			records = append(records, record)
+		}
+	}
+
+	if dryRun {
+		return records, nil
+	}
+
+	for _, record := range records {
+		err = manager.RemoveChallengeRecord(zone, record)
+		if err != nil {
+			return records, fmt.Errorf("dns01: remove the challenge record %s (%s): %w", record.FQDN, record.Value, err)
+		}
+
+		log.Infof("[%s] acme: removed the challenge record %s", UnFqdn(zone), record.FQDN)
+	}
+
+	return records, nil
+}
+
+// IsChallengeRecord returns true if the FQDN is a challenge FQDN:
+// it starts with the challenge prefix (DefaultChallengePrefix, or a custom prefix, see WithChallengePrefix).
+func IsChallengeRecord(prefix, fqdn string) bool {
+	return strings.HasPrefix(strings.ToLower(fqdn), strings.ToLower(prefix)+".")
+}
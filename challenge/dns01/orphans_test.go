@@ -0,0 +1,124 @@
+package dns01
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type providerRecordsMock struct {
+	records   map[string][]ChallengeRecord
+	removeErr error
+}
+
+func (p *providerRecordsMock) Present(_, _, _ string) error { return nil }
+
+func (p *providerRecordsMock) CleanUp(_, _, _ string) error { return nil }
+
+func (p *providerRecordsMock) ListChallengeRecords(zone, prefix string) ([]ChallengeRecord, error) {
+	var records []ChallengeRecord
+	for _, record := range p.records[zone] {
+		if IsChallengeRecord(prefix, record.FQDN) {
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+func (p *providerRecordsMock) RemoveChallengeRecord(zone string, record ChallengeRecord) error {
+	if p.removeErr != nil {
+		return p.removeErr
+	}
+
+	var records []ChallengeRecord
+	for _, r := range p.records[zone] {
+		if r.ID != record.ID {
+			records = append(records, r)
+		}
+	}
+
+	p.records[zone] = records
+
+	return nil
+}
+
+func newProviderRecordsMock() *providerRecordsMock {
+	return &providerRecordsMock{
+		records: map[string][]ChallengeRecord{
+			"example.com.": {
+				{ID: "1", FQDN: "_acme-challenge.example.com.", Value: "a"},
+				{ID: "2", FQDN: "_acme-challenge.example.com.", Value: "b"},
+				{ID: "3", FQDN: "_ACME-CHALLENGE.www.example.com.", Value: "c"},
+				{ID: "4", FQDN: "example.com.", Value: "v=spf1 -all"},
+				{ID: "5", FQDN: "_custom.example.com.", Value: "d"},
+			},
+		},
+	}
+}
+
+func TestCleanUpChallengeRecords(t *testing.T) {
+	provider := newProviderRecordsMock()
+
+	records, err := CleanUpChallengeRecords(provider, "Example.com", "", false)
+	require.NoError(t, err)
+
+	expected := []ChallengeRecord{
+		{ID: "1", FQDN: "_acme-challenge.example.com.", Value: "a"},
+		{ID: "2", FQDN: "_acme-challenge.example.com.", Value: "b"},
+		{ID: "3", FQDN: "_ACME-CHALLENGE.www.example.com.", Value: "c"},
+	}
+
+	assert.Equal(t, expected, records)
+
+	remaining := []ChallengeRecord{
+		{ID: "4", FQDN: "example.com.", Value: "v=spf1 -all"},
+		{ID: "5", FQDN: "_custom.example.com.", Value: "d"},
+	}
+	assert.Equal(t, remaining, provider.records["example.com."])
+}
+
+func TestCleanUpChallengeRecords_customPrefix(t *testing.T) {
+	provider := newProviderRecordsMock()
+
+	records, err := CleanUpChallengeRecords(provider, "example.com", "_custom", false)
+	require.NoError(t, err)
+
+	expected := []ChallengeRecord{{ID: "5", FQDN: "_custom.example.com.", Value: "d"}}
+	assert.Equal(t, expected, records)
+
+	assert.Len(t, provider.records["example.com."], 4)
+}
+
+func TestCleanUpChallengeRecords_dryRun(t *testing.T) {
+	provider := newProviderRecordsMock()
+
+	records, err := CleanUpChallengeRecords(provider, "example.com.", "", true)
+	require.NoError(t, err)
+
+	assert.Len(t, records, 3)
+	assert.Len(t, provider.records["example.com."], 5)
+}
+
+func TestCleanUpChallengeRecords_errors(t *testing.T) {
+	_, err := CleanUpChallengeRecords(&providerMock{}, "example.com", "", false)
+	require.EqualError(t, err, "dns01: the DNS provider doesn't support the listing of the challenge records")
+
+	provider := newProviderRecordsMock()
+	provider.removeErr = errors.New("API unavailable")
+
+	_, err = CleanUpChallengeRecords(provider, "example.com", "", false)
+	require.EqualError(t, err, "dns01: remove the challenge record _acme-challenge.example.com. (a): API unavailable")
+}
+
+func TestIsChallengeRecord(t *testing.T) {
+	assert.True(t, IsChallengeRecord(DefaultChallengePrefix, "_acme-challenge.example.com."))
+	assert.True(t, IsChallengeRecord(DefaultChallengePrefix, "_Acme-Challenge.www.example.com"))
+	assert.False(t, IsChallengeRecord(DefaultChallengePrefix, "_acme-challenge"))
+	assert.False(t, IsChallengeRecord(DefaultChallengePrefix, "www._acme-challenge.example.com."))
+	assert.False(t, IsChallengeRecord(DefaultChallengePrefix, "_acme-challenger.example.com."))
+	assert.True(t, IsChallengeRecord("_custom", "_custom.example.com."))
+	assert.False(t, IsChallengeRecord("_custom", "_acme-challenge.example.com."))
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/miekg/dns"
 )
 
+// authoritativePort is the port used to query the authoritative nameservers.
+var authoritativePort = "53"
+
 // PreCheckFunc checks DNS propagation before notifying ACME that the DNS challenge is ready.
 type PreCheckFunc func(fqdn, value string) (bool, error)
 
@@ -30,11 +33,25 @@ func DisableCompletePropagationRequirement() ChallengeOption {
 	}
 }
 
+// AuthoritativeNameserversProvider is implemented by the DNS providers which write the records to a server
+// that is not queried by the CA, e.g. the hidden primary of a zone.
+// The propagation check queries the returned nameservers ("host" or "host:port") instead of the nameservers of the zone.
+// The nameservers of the zone are used if the list is empty.
+type AuthoritativeNameserversProvider interface {
+	AuthoritativeNameservers(fqdn string) ([]string, error)
+}
+
 type preCheck struct {
 	// checks DNS propagation before notifying ACME that the DNS challenge is ready.
 	checkFunc WrapPreCheckFunc
 	// require the TXT record to be propagated to all authoritative name servers
 	requireCompletePropagation bool
+	// recursive nameservers of the challenge, the process-wide nameservers are used if empty.
+	recursiveNameservers []string
+	// resolves the addresses of the authoritative nameservers with the recursive nameservers, instead of the system resolver.
+	externalOnly bool
+	// provides the authoritative nameservers to check, instead of the nameservers of the zone.
+	authoritative AuthoritativeNameserversProvider
 }
 
 func newPreCheck() preCheck {
@@ -43,6 +60,15 @@ func newPreCheck() preCheck {
 	}
 }
 
+// nameservers returns the recursive nameservers to use.
+func (p preCheck) nameservers() []string {
+	if len(p.recursiveNameservers) > 0 {
+		return p.recursiveNameservers
+	}
+
+	return recursiveNameservers
+}
+
 func (p preCheck) call(domain, fqdn, value string) (bool, error) {
 	if p.checkFunc == nil {
 		return p.checkDNSPropagation(fqdn, value)
@@ -54,7 +80,7 @@ func (p preCheck) call(domain, fqdn, value string) (bool, error) {
 // checkDNSPropagation checks if the expected TXT record has been propagated to all authoritative nameservers.
 func (p preCheck) checkDNSPropagation(fqdn, value string) (bool, error) {
 	// Initial attempt to resolve at the recursive NS
-	r, err := dnsQuery(fqdn, dns.TypeTXT, recursiveNameservers, true)
+	r, err := dnsQuery(fqdn, dns.TypeTXT, p.nameservers(), true)
 	if err != nil {
 		return false, err
 	}
@@ -67,18 +93,36 @@ func (p preCheck) checkDNSPropagation(fqdn, value string) (bool, error) {
 		fqdn = updateDomainWithCName(r, fqdn)
 	}
 
-	authoritativeNss, err := lookupNameservers(fqdn)
+	if p.authoritative != nil {
+		nss, errA := p.authoritative.AuthoritativeNameservers(fqdn)
+		if errA != nil {
+			return false, errA
+		}
+
+		if len(nss) > 0 {
+			return checkAuthoritativeNss(fqdn, value, nss)
+		}
+	}
+
+	authoritativeNss, err := lookupNameservers(fqdn, p.nameservers())
 	if err != nil {
 		return false, err
 	}
 
+	if p.externalOnly {
+		authoritativeNss, err = resolveNameservers(authoritativeNss, p.nameservers())
+		if err != nil {
+			return false, err
+		}
+	}
+
 	return checkAuthoritativeNss(fqdn, value, authoritativeNss)
 }
 
 // checkAuthoritativeNss queries each of the given nameservers for the expected TXT record.
 func checkAuthoritativeNss(fqdn, value string, nameservers []string) (bool, error) {
 	for _, ns := range nameservers {
-		r, err := dnsQuery(fqdn, dns.TypeTXT, []string{net.JoinHostPort(ns, "53")}, false)
+		r, err := dnsQuery(fqdn, dns.TypeTXT, []string{authoritativeAddress(ns)}, false)
 		if err != nil {
 			return false, err
 		}
@@ -108,3 +152,45 @@ func checkAuthoritativeNss(fqdn, value string, nameservers []string) (bool, erro
 
 	return true, nil
 }
+
+// authoritativeAddress returns the address of an authoritative nameserver: the port is added if the nameserver has none.
+func authoritativeAddress(ns string) string {
+	if _, _, err := net.SplitHostPort(ns); err == nil {
+		return ns
+	}
+
+	return net.JoinHostPort(ns, authoritativePort)
+}
+
+// resolveNameservers resolves the addresses of the nameservers (hostnames) with the given recursive nameservers.
+func resolveNameservers(hosts, nameservers []string) ([]string, error) {
+	var addresses []string
+
+	for _, host := range hosts {
+		var found bool
+
+		for _, rtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+			r, err := dnsQuery(dns.Fqdn(host), rtype, nameservers, true)
+			if err != nil {
+				return nil, fmt.Errorf("could not resolve the nameserver %s: %w", host, err)
+			}
+
+			for _, rr := range r.Answer {
+				switch record := rr.(type) {
+				case *dns.A:
+					addresses = append(addresses, record.A.String())
+					found = true
+				case *dns.AAAA:
+					addresses = append(addresses, record.AAAA.String())
+					found = true
+				}
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("could not resolve the nameserver %s: no address", host)
+		}
+	}
+
+	return addresses, nil
+}
@@ -1,8 +1,13 @@
 package dns01
 
 import (
+	"net"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -115,3 +120,225 @@ func TestCheckAuthoritativeNssErr(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckDNSPropagation_scopedNameservers(t *testing.T) {
+	var queries int32
+
+	addr := runLocalDNSTestServer(t, func(w dns.ResponseWriter, req *dns.Msg) {
+		atomic.AddInt32(&queries, 1)
+
+		m := new(dns.Msg)
+		m.SetReply(req)
+		m.Answer = []dns.RR{&dns.TXT{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 120},
+			Txt: []string{"value"},
+		}}
+
+		_ = w.WriteMsg(m)
+	})
+
+	global := append([]string(nil), recursiveNameservers...)
+
+	chlg := NewChallenge(nil, nil, nil,
+		AddScopedRecursiveNameservers([]string{addr}),
+		DisableCompletePropagationRequirement(),
+	)
+
+	assert.Equal(t, global, recursiveNameservers)
+	assert.Equal(t, []string{addr}, chlg.preCheck.nameservers())
+
+	ok, err := chlg.preCheck.call("example.com", "_acme-challenge.example.com.", "value")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&queries))
+	assert.Equal(t, global, newPreCheck().nameservers())
+}
+
+func TestCheckDNSPropagation_externalNameservers(t *testing.T) {
+	const fqdn = "_acme-challenge.split.test."
+
+	var internalQueries, externalQueries, authoritativeQueries int32
+
+	// the internal view of the zone, served by the system resolvers, never contains the record.
+	internal := runLocalDNSTestServer(t, func(w dns.ResponseWriter, req *dns.Msg) {
+		atomic.AddInt32(&internalQueries, 1)
+
+		m := new(dns.Msg)
+		m.SetRcode(req, dns.RcodeNameError)
+
+		_ = w.WriteMsg(m)
+	})
+
+	external := runLocalDNSTestServer(t, func(w dns.ResponseWriter, req *dns.Msg) {
+		atomic.AddInt32(&externalQueries, 1)
+
+		q := req.Question[0]
+		hdr := dns.RR_Header{Name: q.Name, Rrtype: q.Qtype, Class: dns.ClassINET, Ttl: 120}
+
+		m := new(dns.Msg)
+		m.SetReply(req)
+
+		switch {
+		case q.Qtype == dns.TypeTXT && q.Name == fqdn:
+			m.Answer = []dns.RR{&dns.TXT{Hdr: hdr, Txt: []string{"value"}}}
+		case q.Qtype == dns.TypeSOA && q.Name == "split.test.":
+			m.Answer = []dns.RR{&dns.SOA{Hdr: hdr, Ns: "ns1.split.test.", Mbox: "admin.split.test.", Refresh: 60}}
+		case q.Qtype == dns.TypeNS && q.Name == "split.test.":
+			m.Answer = []dns.RR{&dns.NS{Hdr: hdr, Ns: "ns1.split.test."}}
+		case q.Qtype == dns.TypeA && q.Name == "ns1.split.test.":
+			m.Answer = []dns.RR{&dns.A{Hdr: hdr, A: net.ParseIP("127.0.0.1")}}
+		case q.Qtype == dns.TypeAAAA:
+			// no IPv6 address.
+		default:
+			m.SetRcode(req, dns.RcodeNameError)
+		}
+
+		_ = w.WriteMsg(m)
+	})
+
+	authoritative := runLocalDNSTestServer(t, func(w dns.ResponseWriter, req *dns.Msg) {
+		atomic.AddInt32(&authoritativeQueries, 1)
+
+		m := new(dns.Msg)
+		m.SetReply(req)
+		m.Authoritative = true
+		m.Answer = []dns.RR{&dns.TXT{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 120},
+			Txt: []string{"value"},
+		}}
+
+		_ = w.WriteMsg(m)
+	})
+
+	_, port, err := net.SplitHostPort(authoritative)
+	require.NoError(t, err)
+
+	global := recursiveNameservers
+	recursiveNameservers = []string{internal}
+	authoritativePort = port
+
+	t.Cleanup(func() {
+		recursiveNameservers = global
+		authoritativePort = "53"
+	})
+
+	ClearFqdnCache()
+
+	chlg := NewChallenge(nil, nil, nil, UseExternalNameservers([]string{external}))
+
+	ok, err := chlg.preCheck.call("split.test", fqdn, "value")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.Zero(t, atomic.LoadInt32(&internalQueries))
+	assert.NotZero(t, atomic.LoadInt32(&externalQueries))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&authoritativeQueries))
+}
+
+type authoritativeNameserversMock []string
+
+func (m authoritativeNameserversMock) AuthoritativeNameservers(_ string) ([]string, error) {
+	return m, nil
+}
+
+func TestCheckDNSPropagation_hiddenPrimary(t *testing.T) {
+	const fqdn = "_acme-challenge.hidden.test."
+
+	testCases := []struct {
+		desc        string
+		value       string
+		expectedErr string
+	}{
+		{
+			desc:  "propagated to the secondary",
+			value: "value",
+		},
+		{
+			desc:        "not propagated to the secondary",
+			value:       "other",
+			expectedErr: "did not return the expected TXT record",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var primaryQueries, secondaryQueries int32
+
+			// the hidden primary (as seen by the recursive nameservers) always has the record, but it is not checked.
+			primary := runLocalDNSTestServer(t, func(w dns.ResponseWriter, req *dns.Msg) {
+				atomic.AddInt32(&primaryQueries, 1)
+
+				m := new(dns.Msg)
+				m.SetReply(req)
+				m.Answer = []dns.RR{&dns.TXT{
+					Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 120},
+					Txt: []string{test.value},
+				}}
+
+				_ = w.WriteMsg(m)
+			})
+
+			secondary := runLocalDNSTestServer(t, func(w dns.ResponseWriter, req *dns.Msg) {
+				atomic.AddInt32(&secondaryQueries, 1)
+
+				m := new(dns.Msg)
+				m.SetReply(req)
+				m.Authoritative = true
+				m.Answer = []dns.RR{&dns.TXT{
+					Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 120},
+					Txt: []string{"value"},
+				}}
+
+				_ = w.WriteMsg(m)
+			})
+
+			chlg := NewChallenge(nil, nil, nil, AddScopedRecursiveNameservers([]string{primary}))
+
+			pc := chlg.preCheck
+			pc.authoritative = authoritativeNameserversMock{secondary}
+
+			ok, err := pc.call("hidden.test", fqdn, test.value)
+			if test.expectedErr != "" {
+				require.ErrorContains(t, err, test.expectedErr)
+				assert.False(t, ok)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, ok)
+			}
+
+			// only the initial query of the recursive nameservers: the nameservers of the zone are not looked up.
+			assert.EqualValues(t, 1, atomic.LoadInt32(&primaryQueries))
+			assert.EqualValues(t, 1, atomic.LoadInt32(&secondaryQueries))
+		})
+	}
+}
+
+func runLocalDNSTestServer(t *testing.T, handler dns.HandlerFunc) string {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{
+		PacketConn:   pc,
+		Handler:      handler,
+		ReadTimeout:  time.Hour,
+		WriteTimeout: time.Hour,
+	}
+
+	waitLock := sync.Mutex{}
+	waitLock.Lock()
+	server.NotifyStartedFunc = waitLock.Unlock
+
+	go func() {
+		_ = server.ActivateAndServe()
+		pc.Close()
+	}()
+
+	waitLock.Lock()
+
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	return pc.LocalAddr().String()
+}
@@ -0,0 +1,106 @@
+package dns01
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/internal/domainsuffix"
+)
+
+// ProviderResolver selects the DNS provider used to solve the challenge of a domain.
+// It allows a single order to contain domains hosted by different DNS providers.
+type ProviderResolver interface {
+	Provider(domain string) (challenge.Provider, error)
+}
+
+// WithProviderResolver sets the resolver of the DNS provider of each domain.
+// The provider of the challenge is used when the resolver returns no provider.
+func WithProviderResolver(resolver ProviderResolver) ChallengeOption {
+	return func(chlg *Challenge) error {
+		chlg.resolver = resolver
+		return nil
+	}
+}
+
+// DomainProviders is a ProviderResolver selecting the DNS provider by domain.
+//
+// A domain associated with a provider also selects this provider for its subdomains:
+// the most specific association is used.
+type DomainProviders struct {
+	mu        sync.RWMutex
+	providers domainsuffix.Map[challenge.Provider]
+	fallback  challenge.Provider
+}
+
+// NewDomainProviders creates a new DomainProviders.
+// The fallback provider is used for the domains without association, it can be nil.
+func NewDomainProviders(fallback challenge.Provider) *DomainProviders {
+	return &DomainProviders{
+		providers: domainsuffix.Map[challenge.Provider]{},
+		fallback:  fallback,
+	}
+}
+
+// Add associates the domains with the provider.
+// A domain already associated with another provider is overridden.
+func (d *DomainProviders) Add(provider challenge.Provider, domains ...string) error {
+	if provider == nil {
+		return errors.New("dns01: the provider cannot be nil")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, domain := range domains {
+		if !d.providers.Set(domain, provider) {
+			return fmt.Errorf("dns01: invalid domain %q", domain)
+		}
+	}
+
+	return nil
+}
+
+// Provider returns the provider associated with the domain,
+// or with its nearest parent domain, or the fallback provider.
+func (d *DomainProviders) Provider(domain string) (challenge.Provider, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if provider, ok := d.providers.Lookup(domain); ok {
+		return provider, nil
+	}
+
+	if d.fallback != nil {
+		return d.fallback, nil
+	}
+
+	return nil, fmt.Errorf("dns01: no DNS provider associated with the domain %s", domain)
+}
+
+// providerFor returns the provider of the domain: the provider selected by the resolver, or the provider of the challenge.
+func (c *Challenge) providerFor(domain string) (challenge.Provider, error) {
+	if c.resolver == nil {
+		if c.provider == nil {
+			return nil, fmt.Errorf("[%s] acme: no DNS Provider configured", domain)
+		}
+
+		return c.provider, nil
+	}
+
+	provider, err := c.resolver.Provider(domain)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] acme: %w", domain, err)
+	}
+
+	if provider == nil {
+		provider = c.provider
+	}
+
+	if provider == nil {
+		return nil, fmt.Errorf("[%s] acme: no DNS Provider configured", domain)
+	}
+
+	return provider, nil
+}
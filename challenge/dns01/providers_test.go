@@ -0,0 +1,151 @@
+package dns01
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDomainProviders_Provider(t *testing.T) {
+	providerA, providerB, fallback := &providerMock{}, &providerMock{}, &providerMock{}
+
+	providers := NewDomainProviders(fallback)
+
+	require.NoError(t, providers.Add(providerA, "example.com", "example.org"))
+	require.NoError(t, providers.Add(providerB, "sub.example.com"))
+
+	testCases := []struct {
+		domain   string
+		expected challenge.Provider
+	}{
+		{domain: "example.com", expected: providerA},
+		{domain: "EXAMPLE.com.", expected: providerA},
+		{domain: "*.example.com", expected: providerA},
+		{domain: "www.example.com", expected: providerA},
+		{domain: "sub.example.com", expected: providerB},
+		{domain: "a.sub.example.com", expected: providerB},
+		{domain: "example.org", expected: providerA},
+		{domain: "example.net", expected: fallback},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.domain, func(t *testing.T) {
+			t.Parallel()
+
+			provider, err := providers.Provider(test.domain)
+			require.NoError(t, err)
+
+			assert.Same(t, test.expected, provider)
+		})
+	}
+}
+
+func TestDomainProviders_Provider_noFallback(t *testing.T) {
+	providers := NewDomainProviders(nil)
+
+	require.NoError(t, providers.Add(&providerMock{}, "example.com"))
+
+	_, err := providers.Provider("example.org")
+	require.EqualError(t, err, "dns01: no DNS provider associated with the domain example.org")
+}
+
+func TestChallenge_providerResolver(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	providerA := &providerRecorderMock{presented: map[string]string{}, cleaned: map[string]string{}}
+	providerB := &providerRecorderMock{presented: map[string]string{}, cleaned: map[string]string{}}
+
+	providers := NewDomainProviders(nil)
+	require.NoError(t, providers.Add(providerA, "example.com"))
+	require.NoError(t, providers.Add(providerB, "example.org"))
+
+	chlg := NewChallenge(core, nil, nil, WithProviderResolver(providers))
+
+	// the SANs of a single order.
+	var authzs []acme.Authorization
+	for _, domain := range []string{"example.com", "www.example.org"} {
+		authzs = append(authzs, acme.Authorization{
+			Identifier: acme.Identifier{Type: "dns", Value: domain},
+			Challenges: []acme.Challenge{{Type: challenge.DNS01.String(), Token: domain}},
+		})
+	}
+
+	for _, authz := range authzs {
+		require.NoError(t, chlg.PreSolve(authz))
+	}
+
+	for _, authz := range authzs {
+		require.NoError(t, chlg.CleanUp(authz))
+	}
+
+	keyAuthA, err := core.GetKeyAuthorization("example.com")
+	require.NoError(t, err)
+
+	_, valueA := GetRecord("example.com", keyAuthA)
+
+	keyAuthB, err := core.GetKeyAuthorization("www.example.org")
+	require.NoError(t, err)
+
+	_, valueB := GetRecord("www.example.org", keyAuthB)
+
+	expectedA := map[string]string{"_acme-challenge.example.com.": valueA}
+	assert.Equal(t, expectedA, providerA.presented)
+	assert.Equal(t, expectedA, providerA.cleaned)
+
+	expectedB := map[string]string{"_acme-challenge.www.example.org.": valueB}
+	assert.Equal(t, expectedB, providerB.presented)
+	assert.Equal(t, expectedB, providerB.cleaned)
+
+	// a domain without provider.
+	err = chlg.PreSolve(acme.Authorization{
+		Identifier: acme.Identifier{Type: "dns", Value: "example.net"},
+		Challenges: []acme.Challenge{{Type: challenge.DNS01.String(), Token: "example.net"}},
+	})
+	require.EqualError(t, err, "[example.net] acme: dns01: no DNS provider associated with the domain example.net")
+}
+
+type providerSequentialMock struct {
+	providerMock
+}
+
+func (p *providerSequentialMock) Sequential() time.Duration { return time.Minute }
+
+func TestChallenge_SequentialFor(t *testing.T) {
+	sequentialProvider := &providerSequentialMock{}
+
+	providers := NewDomainProviders(nil)
+	require.NoError(t, providers.Add(sequentialProvider, "example.com"))
+	require.NoError(t, providers.Add(&providerMock{}, "example.org"))
+
+	chlg := NewChallenge(nil, nil, nil, WithProviderResolver(providers))
+
+	ok, interval := chlg.Sequential()
+	assert.False(t, ok)
+	assert.Zero(t, interval)
+
+	ok, interval = chlg.SequentialFor(acme.Authorization{Identifier: acme.Identifier{Type: "dns", Value: "www.example.com"}})
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, interval)
+
+	ok, _ = chlg.SequentialFor(acme.Authorization{Identifier: acme.Identifier{Type: "dns", Value: "example.org"}})
+	assert.False(t, ok)
+
+	ok, _ = chlg.SequentialFor(acme.Authorization{Identifier: acme.Identifier{Type: "dns", Value: "example.net"}})
+	assert.False(t, ok)
+}
@@ -0,0 +1,43 @@
+package dns01
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-acme/lego/v4/internal/domainsuffix"
+)
+
+// WithPropagationTimeout sets the timeout of the propagation check of the domains (and of their subdomains),
+// instead of the timeout of the DNS provider.
+// It allows a slow zone to have a longer timeout, without extending the timeout of the other domains of a certificate.
+// The most specific domain is used.
+func WithPropagationTimeout(timeout time.Duration, domains ...string) ChallengeOption {
+	return func(chlg *Challenge) error {
+		if timeout <= 0 {
+			return errors.New("dns01: the propagation timeout must be positive")
+		}
+
+		if chlg.propagationTimeouts == nil {
+			chlg.propagationTimeouts = domainsuffix.Map[time.Duration]{}
+		}
+
+		for _, domain := range domains {
+			if !chlg.propagationTimeouts.Set(domain, timeout) {
+				return fmt.Errorf("dns01: invalid domain %q", domain)
+			}
+		}
+
+		return nil
+	}
+}
+
+// propagationTimeout returns the propagation timeout of the domain,
+// or of its nearest parent domain, or the default timeout.
+func (c *Challenge) propagationTimeout(domain string, defaultTimeout time.Duration) time.Duration {
+	if timeout, ok := c.propagationTimeouts.Lookup(domain); ok {
+		return timeout
+	}
+
+	return defaultTimeout
+}
@@ -0,0 +1,114 @@
+package dns01
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallenge_propagationTimeout(t *testing.T) {
+	chlg := NewChallenge(nil, nil, nil,
+		WithPropagationTimeout(10*time.Minute, "example.com"),
+		WithPropagationTimeout(time.Hour, "*.slow.example.com."),
+	)
+
+	testCases := []struct {
+		domain   string
+		expected time.Duration
+	}{
+		{domain: "example.com", expected: 10 * time.Minute},
+		{domain: "www.example.com", expected: 10 * time.Minute},
+		{domain: "slow.example.com", expected: time.Hour},
+		{domain: "*.slow.example.com", expected: time.Hour},
+		{domain: "a.slow.example.com", expected: time.Hour},
+		{domain: "example.org", expected: time.Minute},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.domain, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, chlg.propagationTimeout(test.domain, time.Minute))
+		})
+	}
+}
+
+func TestWithPropagationTimeout_invalid(t *testing.T) {
+	err := WithPropagationTimeout(0, "example.com")(&Challenge{})
+	require.EqualError(t, err, "dns01: the propagation timeout must be positive")
+
+	err = WithPropagationTimeout(time.Minute, " ")(&Challenge{})
+	require.EqualError(t, err, `dns01: invalid domain " "`)
+}
+
+func TestChallenge_Solve_propagationTimeout(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc        string
+		domain      string
+		expectedErr string
+	}{
+		{
+			desc:   "domain with a longer timeout",
+			domain: "slow.example.com",
+		},
+		{
+			desc:        "domain with the timeout of the provider",
+			domain:      "fast.example.com",
+			expectedErr: "time limit exceeded",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			start := time.Now()
+
+			// the record of all the domains propagates after 1.5s.
+			preCheck := func(_, _, _ string, _ PreCheckFunc) (bool, error) {
+				return time.Since(start) > 1500*time.Millisecond, nil
+			}
+
+			validate := func(_ *api.Core, _ string, _ acme.Challenge) error { return nil }
+
+			provider := &providerTimeoutMock{timeout: 500 * time.Millisecond, interval: 100 * time.Millisecond}
+
+			chlg := NewChallenge(core, validate, provider,
+				WrapPreCheck(preCheck),
+				WithPropagationTimeout(5*time.Second, "slow.example.com"),
+			)
+
+			authz := acme.Authorization{
+				Identifier: acme.Identifier{Value: test.domain},
+				Challenges: []acme.Challenge{{Type: challenge.DNS01.String()}},
+			}
+
+			err := chlg.Solve(authz)
+			if test.expectedErr != "" {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
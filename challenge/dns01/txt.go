@@ -0,0 +1,46 @@
+package dns01
+
+import "strings"
+
+// TXTStringMaxLength the maximum length (in bytes) of a character-string of a TXT record (RFC 1035, section 3.3).
+const TXTStringMaxLength = 255
+
+// SplitTXTValue splits a TXT value into character-strings of at most 255 bytes.
+// The DNS resolvers concatenate the character-strings of a TXT record to get the value.
+func SplitTXTValue(value string) []string {
+	if len(value) <= TXTStringMaxLength {
+		return []string{value}
+	}
+
+	var chunks []string
+
+	for len(value) > TXTStringMaxLength {
+		chunks = append(chunks, value[:TXTStringMaxLength])
+		value = value[TXTStringMaxLength:]
+	}
+
+	if value != "" {
+		chunks = append(chunks, value)
+	}
+
+	return chunks
+}
+
+// FormatTXTValue formats a TXT value in the presentation format (RFC 1035, section 5.1):
+// the value is split into quoted character-strings of at most 255 bytes, separated by a space
+// (e.g. `"aaa...aaa" "bbb"`).
+// It's useful for the APIs expecting the record data as in a zone file.
+func FormatTXTValue(value string) string {
+	chunks := SplitTXTValue(value)
+
+	quoted := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		quoted[i] = `"` + escapeTXTString(chunk) + `"`
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+func escapeTXTString(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
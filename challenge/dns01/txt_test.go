@@ -0,0 +1,102 @@
+package dns01
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitTXTValue(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected []string
+	}{
+		{
+			desc:     "empty",
+			value:    "",
+			expected: []string{""},
+		},
+		{
+			desc:     "challenge value",
+			value:    "ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY",
+			expected: []string{"ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY"},
+		},
+		{
+			desc:     "255 bytes",
+			value:    strings.Repeat("a", 255),
+			expected: []string{strings.Repeat("a", 255)},
+		},
+		{
+			desc:     "256 bytes",
+			value:    strings.Repeat("a", 255) + "b",
+			expected: []string{strings.Repeat("a", 255), "b"},
+		},
+		{
+			desc:     "510 bytes",
+			value:    strings.Repeat("a", 255) + strings.Repeat("b", 255),
+			expected: []string{strings.Repeat("a", 255), strings.Repeat("b", 255)},
+		},
+		{
+			desc:     "511 bytes",
+			value:    strings.Repeat("a", 255) + strings.Repeat("b", 255) + "c",
+			expected: []string{strings.Repeat("a", 255), strings.Repeat("b", 255), "c"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			chunks := SplitTXTValue(test.value)
+
+			assert.Equal(t, test.expected, chunks)
+
+			for _, chunk := range chunks {
+				assert.LessOrEqual(t, len(chunk), TXTStringMaxLength)
+			}
+
+			assert.Equal(t, test.value, strings.Join(chunks, ""))
+		})
+	}
+}
+
+func TestFormatTXTValue(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected string
+	}{
+		{
+			desc:     "challenge value",
+			value:    "ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY",
+			expected: `"ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY"`,
+		},
+		{
+			desc:     "255 bytes",
+			value:    strings.Repeat("a", 255),
+			expected: `"` + strings.Repeat("a", 255) + `"`,
+		},
+		{
+			desc:     "256 bytes",
+			value:    strings.Repeat("a", 255) + "b",
+			expected: `"` + strings.Repeat("a", 255) + `" "b"`,
+		},
+		{
+			desc:     "escaped characters",
+			value:    `a"b\c`,
+			expected: `"a\"b\\c"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, FormatTXTValue(test.value))
+		})
+	}
+}
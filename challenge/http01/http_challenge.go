@@ -1,12 +1,15 @@
 package http01
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/acme/api"
 	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/log"
+	"github.com/go-acme/lego/v4/platform/observer"
 )
 
 type ValidateFunc func(core *api.Core, domain string, chlng acme.Challenge) error
@@ -20,6 +23,7 @@ type Challenge struct {
 	core     *api.Core
 	validate ValidateFunc
 	provider challenge.Provider
+	observer observer.Observer
 }
 
 func NewChallenge(core *api.Core, validate ValidateFunc, provider challenge.Provider) *Challenge {
@@ -34,7 +38,19 @@ func (c *Challenge) SetProvider(provider challenge.Provider) {
 	c.provider = provider
 }
 
+// SetObserver sets the observer of the duration of the presentation of the challenge.
+func (c *Challenge) SetObserver(o observer.Observer) {
+	c.observer = o
+}
+
+// Solve manages the provider to validate and solve the challenge.
 func (c *Challenge) Solve(authz acme.Authorization) error {
+	return c.SolveContext(context.Background(), authz)
+}
+
+// SolveContext is like Solve, the presentation and the validation are aborted when the context is canceled.
+// The cleanup is always done.
+func (c *Challenge) SolveContext(ctx context.Context, authz acme.Authorization) error {
 	domain := challenge.GetTargetedDomain(authz)
 	log.Infof("[%s] acme: Trying to solve HTTP-01", domain)
 
@@ -49,7 +65,16 @@ func (c *Challenge) Solve(authz acme.Authorization) error {
 		return err
 	}
 
-	err = c.provider.Present(authz.Identifier.Value, chlng.Token, keyAuth)
+	start := time.Now()
+
+	if p, ok := c.provider.(challenge.ProviderContext); ok {
+		err = p.PresentContext(ctx, authz.Identifier.Value, chlng.Token, keyAuth)
+	} else {
+		err = c.provider.Present(authz.Identifier.Value, chlng.Token, keyAuth)
+	}
+
+	observer.Observe(c.observer, observer.PhasePresent, domain, start)
+
 	if err != nil {
 		return fmt.Errorf("[%s] acme: error presenting token: %w", domain, err)
 	}
@@ -61,5 +86,5 @@ func (c *Challenge) Solve(authz acme.Authorization) error {
 	}()
 
 	chlng.KeyAuthorization = keyAuth
-	return c.validate(c.core, domain, chlng)
+	return c.validate(c.core.WithContext(ctx), domain, chlng)
 }
@@ -1,6 +1,7 @@
 package http01
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"net"
@@ -9,6 +10,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/go-acme/lego/v4/challenge/internal/listeners"
 	"github.com/go-acme/lego/v4/log"
 )
 
@@ -21,9 +23,24 @@ type ProviderServer struct {
 
 	socketMode fs.FileMode
 
+	// basePath is the prefix added to the challenge path (see SetBasePath).
+	basePath string
+
+	// contentType is the Content-Type of the challenge response (see SetContentType).
+	contentType string
+	// headers are added to the challenge response (see SetResponseHeader).
+	headers http.Header
+
+	// healthCheckPath is the path of the health check endpoint (see SetHealthCheckPath).
+	healthCheckPath string
+
 	matcher  domainMatcher
 	done     chan bool
 	listener net.Listener
+	server   *http.Server
+
+	// listener provided by the user (see SetListener).
+	bindListener *listeners.Shared
 }
 
 // NewProviderServer creates a new ProviderServer on the selected interface and port.
@@ -41,8 +58,30 @@ func NewUnixProviderServer(socketPath string, mode fs.FileMode) *ProviderServer
 	return &ProviderServer{network: "unix", address: socketPath, socketMode: mode, matcher: &hostMatcher{}}
 }
 
+// SetListener sets an already-open listener (e.g. from systemd socket activation)
+// that the server uses instead of creating its own.
+// When a listener is supplied, the network, the address (host/port or socket path), and the socket mode are ignored.
+// The listener is not closed by CleanUp: it can be used for several challenges, and must be closed by the caller.
+func (s *ProviderServer) SetListener(listener net.Listener) {
+	s.bindListener = listeners.NewShared(listener)
+}
+
 // Present starts a web server and makes the token available at `ChallengePath(token)` for web requests.
 func (s *ProviderServer) Present(domain, token, keyAuth string) error {
+	// the base path can be set after the health check path.
+	if s.healthCheckPath != "" && s.isChallengePath(s.healthCheckPath) {
+		return fmt.Errorf("the health check path %q is inside the challenge path", s.healthCheckPath)
+	}
+
+	if s.bindListener != nil {
+		s.listener = s.bindListener.Listener()
+
+		s.done = make(chan bool)
+		s.server = s.newServer(domain, token, keyAuth)
+		go s.serve()
+		return nil
+	}
+
 	var err error
 	s.listener, err = net.Listen(s.network, s.GetAddress())
 	if err != nil {
@@ -56,11 +95,16 @@ func (s *ProviderServer) Present(domain, token, keyAuth string) error {
 	}
 
 	s.done = make(chan bool)
-	go s.serve(domain, token, keyAuth)
+	s.server = s.newServer(domain, token, keyAuth)
+	go s.serve()
 	return nil
 }
 
 func (s *ProviderServer) GetAddress() string {
+	if s.bindListener != nil {
+		return s.bindListener.Addr().String()
+	}
+
 	return s.address
 }
 
@@ -69,7 +113,13 @@ func (s *ProviderServer) CleanUp(domain, token, keyAuth string) error {
 	if s.listener == nil {
 		return nil
 	}
-	s.listener.Close()
+
+	// Closing the server closes the listener used by the server:
+	// the listener opened by the server, or the view of the listener provided by the user.
+	_ = s.server.Close()
+	// The listener is not closed by the server if it is closed before serving.
+	_ = s.listener.Close()
+
 	<-s.done
 	return nil
 }
@@ -97,16 +147,91 @@ func (s *ProviderServer) SetProxyHeader(headerName string) {
 	}
 }
 
-func (s *ProviderServer) serve(domain, token, keyAuth string) {
-	path := ChallengePath(token)
+// SetBasePath sets a prefix of the path served by the server.
+// It allows to solve the challenges behind a reverse proxy
+// rewriting the `/.well-known/acme-challenge/` path with a prefix:
+// with the base path "/lego", the token is served at "/lego/.well-known/acme-challenge/<token>".
+// By default, the token is served at `ChallengePath(token)`.
+func (s *ProviderServer) SetBasePath(basePath string) {
+	s.basePath = strings.TrimSuffix(basePath, "/")
+	if s.basePath != "" && !strings.HasPrefix(s.basePath, "/") {
+		s.basePath = "/" + s.basePath
+	}
+}
+
+// SetContentType sets the Content-Type of the challenge response.
+// By default, the Content-Type is "text/plain".
+func (s *ProviderServer) SetContentType(contentType string) {
+	s.contentType = contentType
+}
+
+// SetResponseHeader adds a header to the challenge response (e.g. to satisfy a WAF).
+// The Content-Type is defined by SetContentType.
+func (s *ProviderServer) SetResponseHeader(key, value string) {
+	if s.headers == nil {
+		s.headers = http.Header{}
+	}
+
+	s.headers.Add(key, value)
+}
+
+// SetHealthCheckPath enables a health check endpoint (e.g. "/healthz") for readiness probes.
+// The endpoint answers 200 to the GET and HEAD requests while the server is ready to answer the challenges.
+// The path must not be inside the challenge path (including the base path, see SetBasePath),
+// by default no health check endpoint is served.
+func (s *ProviderServer) SetHealthCheckPath(path string) error {
+	s.healthCheckPath = ""
+
+	path = strings.TrimSuffix(path, "/")
+	if path == "" {
+		return nil
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	if s.isChallengePath(path) {
+		return fmt.Errorf("the health check path %q is inside the challenge path", path)
+	}
+
+	s.healthCheckPath = path
+
+	return nil
+}
+
+// isChallengePath returns true if the path is inside the challenge path.
+func (s *ProviderServer) isChallengePath(path string) bool {
+	return strings.HasPrefix(path+"/", s.basePath+ChallengePath(""))
+}
+
+func (s *ProviderServer) newServer(domain, token, keyAuth string) *http.Server {
+	path := s.basePath + ChallengePath(token)
 
 	// The incoming request will be validated to prevent DNS rebind attacks.
 	// We only respond with the keyAuth, when we're receiving a GET requests with
 	// the "Host" header matching the domain (the latter is configurable though SetProxyHeader).
+	// IPv6 addresses are enclosed in square brackets in the Host header.
+	host := domain
+	if ip := net.ParseIP(domain); ip != nil && ip.To4() == nil {
+		host = "[" + domain + "]"
+	}
+
+	contentType := s.contentType
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+
 	mux := http.NewServeMux()
 	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodGet && s.matcher.matches(r, domain) {
-			w.Header().Set("Content-Type", "text/plain")
+		if r.Method == http.MethodGet && s.matcher.matches(r, host) {
+			for key, values := range s.headers {
+				for _, value := range values {
+					w.Header().Add(key, value)
+				}
+			}
+
+			w.Header().Set("Content-Type", contentType)
 			_, err := w.Write([]byte(keyAuth))
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -123,15 +248,32 @@ func (s *ProviderServer) serve(domain, token, keyAuth string) {
 		}
 	})
 
+	if s.healthCheckPath != "" {
+		mux.HandleFunc(s.healthCheckPath, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				w.Header().Set("Allow", "GET, HEAD")
+				http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("OK"))
+		})
+	}
+
 	httpServer := &http.Server{Handler: mux}
 
 	// Once httpServer is shut down
 	// we don't want any lingering connections, so disable KeepAlives.
 	httpServer.SetKeepAlivesEnabled(false)
 
-	err := httpServer.Serve(s.listener)
-	if err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
-		log.Println(err)
+	return httpServer
+}
+
+func (s *ProviderServer) serve() {
+	err := s.server.Serve(s.listener)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) && !strings.Contains(err.Error(), "use of closed network connection") {
+		log.Errorf("HTTP server for challenge: %v", err)
 	}
 	s.done <- true
 }
@@ -119,6 +119,267 @@ func TestChallenge(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestChallengeWithListener(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	// the host and the port must be ignored.
+	providerServer := NewProviderServer("", "1")
+	providerServer.SetListener(listener)
+
+	assert.Equal(t, listener.Addr().String(), providerServer.GetAddress())
+
+	validate := func(_ *api.Core, _ string, chlng acme.Challenge) error {
+		uri := "http://localhost:" + port + ChallengePath(chlng.Token)
+
+		resp, err := http.DefaultClient.Get(uri)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		if string(body) != chlng.KeyAuthorization {
+			t.Errorf("Get(%q) Body: got %q, want %q", uri, string(body), chlng.KeyAuthorization)
+		}
+
+		return nil
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	solver := NewChallenge(core, validate, providerServer)
+
+	// the listener is reused by the authorizations of the other domains of the order.
+	for _, token := range []string{"http1", "http2"} {
+		authz := acme.Authorization{
+			Identifier: acme.Identifier{
+				Value: "localhost:" + port,
+			},
+			Challenges: []acme.Challenge{
+				{Type: challenge.HTTP01.String(), Token: token},
+			},
+		}
+
+		err = solver.Solve(authz)
+		require.NoError(t, err)
+	}
+
+	// the listener provided by the user is not closed.
+	require.NoError(t, listener.Close())
+}
+
+func TestChallengeWithHealthCheck(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	providerServer := NewProviderServer("", "23461")
+	err := providerServer.SetHealthCheckPath("healthz")
+	require.NoError(t, err)
+
+	validate := func(_ *api.Core, _ string, chlng acme.Challenge) error {
+		resp, err := http.DefaultClient.Get("http://localhost" + providerServer.GetAddress() + "/healthz")
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, err = http.DefaultClient.Post("http://localhost"+providerServer.GetAddress()+"/healthz", "text/plain", nil)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+		uri := "http://localhost" + providerServer.GetAddress() + ChallengePath(chlng.Token)
+
+		resp, err = http.DefaultClient.Get(uri)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		if string(body) != chlng.KeyAuthorization {
+			t.Errorf("Get(%q) Body: got %q, want %q", uri, string(body), chlng.KeyAuthorization)
+		}
+
+		return nil
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	solver := NewChallenge(core, validate, providerServer)
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{
+			Value: "localhost:23461",
+		},
+		Challenges: []acme.Challenge{
+			{Type: challenge.HTTP01.String(), Token: "http1"},
+		},
+	}
+
+	err = solver.Solve(authz)
+	require.NoError(t, err)
+}
+
+func TestProviderServer_SetHealthCheckPath_challengePath(t *testing.T) {
+	providerServer := NewProviderServer("", "23462")
+
+	err := providerServer.SetHealthCheckPath("/.well-known/acme-challenge/healthz")
+	require.EqualError(t, err, `the health check path "/.well-known/acme-challenge/healthz" is inside the challenge path`)
+
+	err = providerServer.SetHealthCheckPath("/.well-known/acme-challenge")
+	require.EqualError(t, err, `the health check path "/.well-known/acme-challenge" is inside the challenge path`)
+
+	// the base path is set after the health check path.
+	err = providerServer.SetHealthCheckPath("/lego/.well-known/acme-challenge/healthz")
+	require.NoError(t, err)
+
+	providerServer.SetBasePath("/lego")
+
+	err = providerServer.Present("localhost", "token", "keyAuth")
+	require.EqualError(t, err, `the health check path "/lego/.well-known/acme-challenge/healthz" is inside the challenge path`)
+}
+
+func TestChallengeWithBasePath(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	providerServer := NewProviderServer("", "23459")
+	providerServer.SetBasePath("/lego/")
+
+	validate := func(_ *api.Core, _ string, chlng acme.Challenge) error {
+		uri := "http://localhost" + providerServer.GetAddress() + "/lego" + ChallengePath(chlng.Token)
+
+		resp, err := http.DefaultClient.Get(uri)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		if string(body) != chlng.KeyAuthorization {
+			t.Errorf("Get(%q) Body: got %q, want %q", uri, string(body), chlng.KeyAuthorization)
+		}
+
+		// the standard path is not served.
+		uri = "http://localhost" + providerServer.GetAddress() + ChallengePath(chlng.Token)
+
+		resp, err = http.DefaultClient.Get(uri)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Get(%q) StatusCode: got %d, want %d", uri, resp.StatusCode, http.StatusNotFound)
+		}
+
+		return nil
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	solver := NewChallenge(core, validate, providerServer)
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{
+			Value: "localhost:23459",
+		},
+		Challenges: []acme.Challenge{
+			{Type: challenge.HTTP01.String(), Token: "http1"},
+		},
+	}
+
+	err = solver.Solve(authz)
+	require.NoError(t, err)
+}
+
+func TestChallengeWithResponseHeaders(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	providerServer := NewProviderServer("", "23460")
+	providerServer.SetContentType("application/octet-stream")
+	providerServer.SetResponseHeader("Cache-Control", "no-store")
+	providerServer.SetResponseHeader("X-Custom", "foo")
+	providerServer.SetResponseHeader("X-Custom", "bar")
+
+	validate := func(_ *api.Core, _ string, chlng acme.Challenge) error {
+		uri := "http://localhost" + providerServer.GetAddress() + ChallengePath(chlng.Token)
+
+		resp, err := http.DefaultClient.Get(uri)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		assert.Equal(t, "application/octet-stream", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+		assert.Equal(t, []string{"foo", "bar"}, resp.Header.Values("X-Custom"))
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		assert.Equal(t, chlng.KeyAuthorization, string(body))
+
+		return nil
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	solver := NewChallenge(core, validate, providerServer)
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{
+			Value: "localhost:23460",
+		},
+		Challenges: []acme.Challenge{
+			{Type: challenge.HTTP01.String(), Token: "http1"},
+		},
+	}
+
+	err = solver.Solve(authz)
+	require.NoError(t, err)
+}
+
 func TestChallengeUnix(t *testing.T) {
 	if runtime.GOOS != "linux" {
 		t.Skip("only for UNIX systems")
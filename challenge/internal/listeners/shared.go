@@ -0,0 +1,123 @@
+// Package listeners provides helpers for the listeners of the challenge servers.
+package listeners
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// Delays between two attempts to accept a connection after a temporary error.
+const (
+	minAcceptDelay = 5 * time.Millisecond
+	maxAcceptDelay = 1 * time.Second
+)
+
+// Shared allows successive servers to use the same listener, without closing it.
+// It is used for the listeners provided by the user:
+// the listener must stay open between the challenges, and is closed by its owner.
+type Shared struct {
+	listener net.Listener
+
+	once  sync.Once
+	conns chan net.Conn
+
+	// done is closed when the underlying listener is closed.
+	done chan struct{}
+	err  error
+}
+
+// NewShared creates a new Shared.
+func NewShared(listener net.Listener) *Shared {
+	return &Shared{
+		listener: listener,
+		conns:    make(chan net.Conn),
+		done:     make(chan struct{}),
+	}
+}
+
+// Listener returns a new listener receiving the connections of the underlying listener.
+// Closing the returned listener doesn't close the underlying listener.
+// Only one of the returned listeners must be in use at a time.
+func (s *Shared) Listener() net.Listener {
+	s.once.Do(func() { go s.accept() })
+
+	return &view{shared: s, closed: make(chan struct{})}
+}
+
+// Addr returns the address of the underlying listener.
+func (s *Shared) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+func (s *Shared) accept() {
+	var delay time.Duration
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			var tempErr interface{ Temporary() bool }
+			if !errors.As(err, &tempErr) || !tempErr.Temporary() {
+				s.err = err
+				close(s.done)
+
+				return
+			}
+
+			// the temporary errors (e.g. too many open files) are retried with a backoff, like net/http.
+			if delay == 0 {
+				delay = minAcceptDelay
+			} else {
+				delay *= 2
+			}
+
+			if delay > maxAcceptDelay {
+				delay = maxAcceptDelay
+			}
+
+			time.Sleep(delay)
+
+			continue
+		}
+
+		delay = 0
+
+		s.conns <- conn
+	}
+}
+
+// view is a listener sharing the connections of a Shared listener.
+type view struct {
+	shared *Shared
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (v *view) Accept() (net.Conn, error) {
+	select {
+	case <-v.closed:
+		return nil, net.ErrClosed
+	default:
+	}
+
+	select {
+	case conn := <-v.shared.conns:
+		return conn, nil
+	case <-v.closed:
+		return nil, net.ErrClosed
+	case <-v.shared.done:
+		return nil, v.shared.err
+	}
+}
+
+func (v *view) Close() error {
+	v.closeOnce.Do(func() { close(v.closed) })
+
+	return nil
+}
+
+func (v *view) Addr() net.Addr {
+	return v.shared.Addr()
+}
@@ -0,0 +1,126 @@
+package listeners
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShared(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	shared := NewShared(listener)
+
+	for i := 0; i < 2; i++ {
+		l := shared.Listener()
+		assert.Equal(t, listener.Addr(), l.Addr())
+
+		client, err := net.Dial("tcp", listener.Addr().String())
+		require.NoError(t, err)
+
+		conn, err := l.Accept()
+		require.NoError(t, err)
+
+		_ = conn.Close()
+		_ = client.Close()
+
+		require.NoError(t, l.Close())
+
+		_, err = l.Accept()
+		require.ErrorIs(t, err, net.ErrClosed)
+	}
+
+	// the underlying listener is still open.
+	l := shared.Listener()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = client.Close() })
+
+	conn, err := l.Accept()
+	require.NoError(t, err)
+
+	_ = conn.Close()
+
+	require.NoError(t, listener.Close())
+
+	_, err = l.Accept()
+	require.True(t, errors.Is(err, net.ErrClosed))
+}
+
+type temporaryError struct{}
+
+func (temporaryError) Error() string   { return "too many open files" }
+func (temporaryError) Timeout() bool   { return false }
+func (temporaryError) Temporary() bool { return true }
+
+// errorsListener returns the errors, then the errors of the underlying listener.
+type errorsListener struct {
+	net.Listener
+
+	mu     sync.Mutex
+	errors []error
+}
+
+func (l *errorsListener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+
+	if len(l.errors) > 0 {
+		err := l.errors[0]
+		l.errors = l.errors[1:]
+		l.mu.Unlock()
+
+		return nil, err
+	}
+
+	l.mu.Unlock()
+
+	return l.Listener.Accept()
+}
+
+func TestShared_temporaryErrors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	shared := NewShared(&errorsListener{
+		Listener: listener,
+		errors:   []error{temporaryError{}, temporaryError{}},
+	})
+
+	l := shared.Listener()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = client.Close() })
+
+	conn, err := l.Accept()
+	require.NoError(t, err)
+
+	_ = conn.Close()
+}
+
+func TestShared_permanentError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	permanent := errors.New("permanent error")
+
+	shared := NewShared(&errorsListener{
+		Listener: listener,
+		errors:   []error{temporaryError{}, permanent},
+	})
+
+	_, err = shared.Listener().Accept()
+	require.ErrorIs(t, err, permanent)
+}
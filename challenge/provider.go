@@ -1,16 +1,33 @@
 package challenge
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Provider enables implementing a custom challenge
 // provider. Present presents the solution to a challenge available to
 // be solved. CleanUp will be called by the challenge if Present ends
 // in a non-error state.
+//
+// Present should be idempotent: a previous run can stop between Present and CleanUp,
+// and leave the solution in place (e.g. a stale TXT record).
+// Presenting a solution which already exists should neither fail nor create a duplicate.
 type Provider interface {
 	Present(domain, token, keyAuth string) error
 	CleanUp(domain, token, keyAuth string) error
 }
 
+// ProviderContext allows for implementing a Provider
+// whose Present and CleanUp methods accept a context,
+// e.g. to abort the API calls when the issuance is canceled (see certificate.Certifier.ObtainWithContext).
+// The cleanup of a canceled issuance is still done: the context of the issuance is not given to the cleanup.
+type ProviderContext interface {
+	Provider
+	PresentContext(ctx context.Context, domain, token, keyAuth string) error
+	CleanUpContext(ctx context.Context, domain, token, keyAuth string) error
+}
+
 // ProviderTimeout allows for implementing a
 // Provider where an unusually long timeout is required when
 // waiting for an ACME challenge to be satisfied, such as when
@@ -26,3 +43,22 @@ type ProviderTimeout interface {
 	Provider
 	Timeout() (timeout, interval time.Duration)
 }
+
+// ProviderSequentialDuration allows for implementing a Provider
+// which requires a minimal delay between two calls to Present,
+// e.g. when the API of the provider is eventually consistent and could serve stale data.
+// Unlike the sequential mode, the challenges are still solved together:
+// only the calls to Present are spaced out.
+type ProviderSequentialDuration interface {
+	Provider
+	SequentialDuration() time.Duration
+}
+
+// ProviderSynchronous allows for implementing a Provider
+// whose Present method returns only when the record is visible to the CA,
+// e.g. when the API of the provider waits for the propagation of the record to all the nameservers.
+// If Synchronous returns true, the propagation check is skipped and the challenge is validated right away.
+type ProviderSynchronous interface {
+	Provider
+	Synchronous() bool
+}
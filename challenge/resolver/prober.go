@@ -1,7 +1,9 @@
 package resolver
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-acme/lego/v4/acme"
@@ -25,10 +27,25 @@ type cleanup interface {
 	CleanUp(authorization acme.Authorization) error
 }
 
+// Interface for the challenges whose resolution can be aborted with a context.
+type contextSolver interface {
+	SolveContext(ctx context.Context, authorization acme.Authorization) error
+}
+
+// Interface for the challenges whose preparation can be aborted with a context.
+type contextPreSolver interface {
+	PreSolveContext(ctx context.Context, authorization acme.Authorization) error
+}
+
 type sequential interface {
 	Sequential() (bool, time.Duration)
 }
 
+// Interface for the challenges whose sequential resolution depends on the authorization (e.g. a DNS provider by domain).
+type authzSequential interface {
+	SequentialFor(authorization acme.Authorization) (bool, time.Duration)
+}
+
 // an authz with the solver we have chosen and the index of the challenge associated with it.
 type selectedAuthSolver struct {
 	authz  acme.Authorization
@@ -46,8 +63,14 @@ func NewProber(solverManager *SolverManager) *Prober {
 }
 
 // Solve Looks through the challenge combinations to find a solvable match.
-// Then solves the challenges in series and returns.
+// Then solves the challenges (in series, unless a concurrency limit is set on the SolverManager) and returns.
 func (p *Prober) Solve(authorizations []acme.Authorization) error {
+	return p.SolveContext(context.Background(), authorizations)
+}
+
+// SolveContext is like Solve, the resolution of the challenges is aborted when the context is canceled.
+// The challenges already presented are still cleaned up.
+func (p *Prober) SolveContext(ctx context.Context, authorizations []acme.Authorization) error {
 	failures := make(obtainError)
 
 	var authSolvers []*selectedAuthSolver
@@ -66,14 +89,9 @@ func (p *Prober) Solve(authorizations []acme.Authorization) error {
 		if solvr := p.solverManager.chooseSolver(authz); solvr != nil {
 			authSolver := &selectedAuthSolver{authz: authz, solver: solvr}
 
-			switch s := solvr.(type) {
-			case sequential:
-				if ok, _ := s.Sequential(); ok {
-					authSolversSequential = append(authSolversSequential, authSolver)
-				} else {
-					authSolvers = append(authSolvers, authSolver)
-				}
-			default:
+			if ok, _ := isSequential(solvr, authz); ok {
+				authSolversSequential = append(authSolversSequential, authSolver)
+			} else {
 				authSolvers = append(authSolvers, authSolver)
 			}
 		} else {
@@ -81,9 +99,9 @@ func (p *Prober) Solve(authorizations []acme.Authorization) error {
 		}
 	}
 
-	parallelSolve(authSolvers, failures)
+	parallelSolve(ctx, authSolvers, failures, p.solverManager.concurrency)
 
-	sequentialSolve(authSolversSequential, failures)
+	sequentialSolve(ctx, authSolversSequential, failures)
 
 	// Be careful not to return an empty failures map,
 	// for even an empty obtainError is a non-nil error value
@@ -93,13 +111,30 @@ func (p *Prober) Solve(authorizations []acme.Authorization) error {
 	return nil
 }
 
-func sequentialSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
+// isSequential returns true, and the interval between two resolutions, if the challenge of the authorization must be solved sequentially.
+func isSequential(solvr solver, authz acme.Authorization) (bool, time.Duration) {
+	switch s := solvr.(type) {
+	case authzSequential:
+		return s.SequentialFor(authz)
+	case sequential:
+		return s.Sequential()
+	default:
+		return false, 0
+	}
+}
+
+func sequentialSolve(ctx context.Context, authSolvers []*selectedAuthSolver, failures obtainError) {
 	for i, authSolver := range authSolvers {
 		// Submit the challenge
 		domain := challenge.GetTargetedDomain(authSolver.authz)
 
-		if solvr, ok := authSolver.solver.(preSolver); ok {
-			err := solvr.PreSolve(authSolver.authz)
+		if err := ctx.Err(); err != nil {
+			failures[domain] = err
+			continue
+		}
+
+		if _, ok := authSolver.solver.(preSolver); ok {
+			err := preSolve(ctx, authSolver.solver, authSolver.authz)
 			if err != nil {
 				failures[domain] = err
 				cleanUp(authSolver.solver, authSolver.authz)
@@ -108,7 +143,7 @@ func sequentialSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
 		}
 
 		// Solve challenge
-		err := authSolver.solver.Solve(authSolver.authz)
+		err := solve(ctx, authSolver.solver, authSolver.authz)
 		if err != nil {
 			failures[domain] = err
 			cleanUp(authSolver.solver, authSolver.authz)
@@ -119,20 +154,23 @@ func sequentialSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
 		cleanUp(authSolver.solver, authSolver.authz)
 
 		if len(authSolvers)-1 > i {
-			solvr := authSolver.solver.(sequential)
-			_, interval := solvr.Sequential()
+			_, interval := isSequential(authSolver.solver, authSolver.authz)
 			log.Infof("sequence: wait for %s", interval)
-			time.Sleep(interval)
+
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+			}
 		}
 	}
 }
 
-func parallelSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
+func parallelSolve(ctx context.Context, authSolvers []*selectedAuthSolver, failures obtainError, concurrency int) {
 	// For all valid preSolvers, first submit the challenges so they have max time to propagate
 	for _, authSolver := range authSolvers {
 		authz := authSolver.authz
-		if solvr, ok := authSolver.solver.(preSolver); ok {
-			err := solvr.PreSolve(authz)
+		if _, ok := authSolver.solver.(preSolver); ok {
+			err := preSolve(ctx, authSolver.solver, authz)
 			if err != nil {
 				failures[challenge.GetTargetedDomain(authz)] = err
 			}
@@ -147,21 +185,85 @@ func parallelSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
 	}()
 
 	// Finally solve all challenges for real
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	// The cleanup must wait for the end of all the resolutions.
+	defer wg.Wait()
+
+	limit := concurrency
+	if limit < 1 {
+		limit = 1
+	}
+
+	sem := make(chan struct{}, limit)
+
+	solveAuthz := func(authz acme.Authorization, solvr solver) {
+		domain := challenge.GetTargetedDomain(authz)
+
+		err := ctx.Err()
+		if err == nil {
+			err = solve(ctx, solvr, authz)
+		}
+
+		if err != nil {
+			mu.Lock()
+			failures[domain] = err
+			mu.Unlock()
+		}
+	}
+
 	for _, authSolver := range authSolvers {
 		authz := authSolver.authz
-		domain := challenge.GetTargetedDomain(authz)
-		if failures[domain] != nil {
+
+		mu.Lock()
+		failed := failures[challenge.GetTargetedDomain(authz)] != nil
+		mu.Unlock()
+
+		if failed {
 			// already failed in previous loop
 			continue
 		}
 
-		err := authSolver.solver.Solve(authz)
-		if err != nil {
-			failures[domain] = err
+		// Only the challenges with a record created in advance can be solved concurrently.
+		if _, ok := authSolver.solver.(preSolver); !ok || concurrency < 2 {
+			solveAuthz(authz, authSolver.solver)
+			continue
 		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(authz acme.Authorization, solvr solver) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			solveAuthz(authz, solvr)
+		}(authSolver.authz, authSolver.solver)
+	}
+}
+
+// solve solves the challenge with the context, if the solver supports it.
+func solve(ctx context.Context, solvr solver, authz acme.Authorization) error {
+	if s, ok := solvr.(contextSolver); ok {
+		return s.SolveContext(ctx, authz)
+	}
+
+	return solvr.Solve(authz)
+}
+
+// preSolve prepares the challenge with the context, if the solver supports it.
+func preSolve(ctx context.Context, solvr solver, authz acme.Authorization) error {
+	if s, ok := solvr.(contextPreSolver); ok {
+		return s.PreSolveContext(ctx, authz)
 	}
+
+	return solvr.(preSolver).PreSolve(authz)
 }
 
+// cleanUp cleans the challenge, the cleanup is not bound to the context of the resolution.
 func cleanUp(solvr solver, authz acme.Authorization) {
 	if solvr, ok := solvr.(cleanup); ok {
 		domain := challenge.GetTargetedDomain(authz)
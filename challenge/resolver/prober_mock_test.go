@@ -1,6 +1,7 @@
 package resolver
 
 import (
+	"sync"
 	"time"
 
 	"github.com/go-acme/lego/v4/acme"
@@ -25,6 +26,66 @@ func (s *preSolverMock) CleanUp(authorization acme.Authorization) error {
 	return s.cleanUp[authorization.Identifier.Value]
 }
 
+// concurrentSolverMock records the calls and the maximum number of concurrent resolutions.
+type concurrentSolverMock struct {
+	solve map[string]error
+
+	mu            sync.Mutex
+	events        []string
+	running       int
+	maxConcurrent int
+}
+
+func (s *concurrentSolverMock) PreSolve(authorization acme.Authorization) error {
+	s.record("present " + authorization.Identifier.Value)
+	return nil
+}
+
+func (s *concurrentSolverMock) Solve(authorization acme.Authorization) error {
+	s.mu.Lock()
+	s.events = append(s.events, "solve "+authorization.Identifier.Value)
+	s.running++
+	if s.running > s.maxConcurrent {
+		s.maxConcurrent = s.running
+	}
+	s.mu.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+
+	s.mu.Lock()
+	s.running--
+	s.mu.Unlock()
+
+	return s.solve[authorization.Identifier.Value]
+}
+
+func (s *concurrentSolverMock) CleanUp(authorization acme.Authorization) error {
+	s.record("cleanup " + authorization.Identifier.Value)
+	return nil
+}
+
+func (s *concurrentSolverMock) record(event string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, event)
+}
+
+// sequentialSolverMock is a concurrentSolverMock solving the challenges of some domains sequentially.
+type sequentialSolverMock struct {
+	concurrentSolverMock
+
+	sequential map[string]bool
+}
+
+func (s *sequentialSolverMock) Sequential() (bool, time.Duration) {
+	return false, 0
+}
+
+func (s *sequentialSolverMock) SequentialFor(authorization acme.Authorization) (bool, time.Duration) {
+	return s.sequential[authorization.Identifier.Value], 0
+}
+
 func createStubAuthorizationHTTP01(domain, status string) acme.Authorization {
 	return acme.Authorization{
 		Status:  status,
@@ -2,10 +2,12 @@ package resolver
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/challenge"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -116,3 +118,79 @@ func TestProber_Solve(t *testing.T) {
 		})
 	}
 }
+
+func TestProber_Solve_concurrency(t *testing.T) {
+	domains := []string{"a.wtf", "b.wtf", "c.wtf", "d.wtf", "e.wtf"}
+
+	solvr := &concurrentSolverMock{
+		solve: map[string]error{
+			"b.wtf": errors.New("solve error b.wtf"),
+		},
+	}
+
+	prober := &Prober{
+		solverManager: &SolverManager{
+			solvers:     map[challenge.Type]solver{challenge.HTTP01: solvr},
+			concurrency: 2,
+		},
+	}
+
+	var authz []acme.Authorization
+	for _, domain := range domains {
+		authz = append(authz, createStubAuthorizationHTTP01(domain, acme.StatusProcessing))
+	}
+
+	err := prober.Solve(authz)
+	require.EqualError(t, err, `error: one or more domains had a problem:
+[b.wtf] solve error b.wtf
+`)
+
+	assert.Equal(t, 2, solvr.maxConcurrent)
+
+	require.Len(t, solvr.events, 3*len(domains))
+
+	// all the challenges are presented before the first resolution.
+	for i, domain := range domains {
+		assert.Equal(t, "present "+domain, solvr.events[i])
+	}
+
+	var solved []string
+	for _, event := range solvr.events[len(domains) : 2*len(domains)] {
+		require.True(t, strings.HasPrefix(event, "solve "), event)
+		solved = append(solved, strings.TrimPrefix(event, "solve "))
+	}
+
+	assert.ElementsMatch(t, domains, solved)
+
+	// all the challenges are cleaned, even the failed one, after the end of all the resolutions.
+	for i, domain := range domains {
+		assert.Equal(t, "cleanup "+domain, solvr.events[2*len(domains)+i])
+	}
+}
+
+func TestProber_Solve_sequentialFor(t *testing.T) {
+	solvr := &sequentialSolverMock{
+		sequential: map[string]bool{"a.wtf": true},
+	}
+
+	prober := &Prober{
+		solverManager: &SolverManager{
+			solvers: map[challenge.Type]solver{challenge.HTTP01: solvr},
+		},
+	}
+
+	authz := []acme.Authorization{
+		createStubAuthorizationHTTP01("a.wtf", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("b.wtf", acme.StatusProcessing),
+	}
+
+	err := prober.Solve(authz)
+	require.NoError(t, err)
+
+	// the sequential challenge is solved after the others.
+	expected := []string{
+		"present b.wtf", "solve b.wtf", "cleanup b.wtf",
+		"present a.wtf", "solve a.wtf", "cleanup a.wtf",
+	}
+	assert.Equal(t, expected, solvr.events)
+}
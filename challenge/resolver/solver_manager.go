@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"sort"
-	"strconv"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -15,6 +14,15 @@ import (
 	"github.com/go-acme/lego/v4/challenge/http01"
 	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
 	"github.com/go-acme/lego/v4/log"
+	"github.com/go-acme/lego/v4/platform/observer"
+)
+
+const (
+	// defaultPollingInterval the initial interval of the polling of the authorization,
+	// when the response of the challenge has no `Retry-After` header.
+	defaultPollingInterval = 5 * time.Second
+	// minPollingInterval the minimum interval of the polling of the authorization, applied to the `Retry-After` headers.
+	minPollingInterval = time.Second
 )
 
 type byType []acme.Challenge
@@ -24,8 +32,12 @@ func (a byType) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a byType) Less(i, j int) bool { return a[i].Type > a[j].Type }
 
 type SolverManager struct {
-	core    *api.Core
-	solvers map[challenge.Type]solver
+	core     *api.Core
+	solvers  map[challenge.Type]solver
+	observer observer.Observer
+
+	// concurrency is the maximum number of challenges solved at the same time.
+	concurrency int
 }
 
 func NewSolversManager(core *api.Core) *SolverManager {
@@ -37,22 +49,52 @@ func NewSolversManager(core *api.Core) *SolverManager {
 
 // SetHTTP01Provider specifies a custom provider p that can solve the given HTTP-01 challenge.
 func (c *SolverManager) SetHTTP01Provider(p challenge.Provider) error {
-	c.solvers[challenge.HTTP01] = http01.NewChallenge(c.core, validate, p)
+	chlg := http01.NewChallenge(c.core, c.validate, p)
+	chlg.SetObserver(c.observer)
+
+	c.solvers[challenge.HTTP01] = chlg
 	return nil
 }
 
 // SetTLSALPN01Provider specifies a custom provider p that can solve the given TLS-ALPN-01 challenge.
 func (c *SolverManager) SetTLSALPN01Provider(p challenge.Provider) error {
-	c.solvers[challenge.TLSALPN01] = tlsalpn01.NewChallenge(c.core, validate, p)
+	chlg := tlsalpn01.NewChallenge(c.core, c.validate, p)
+	chlg.SetObserver(c.observer)
+
+	c.solvers[challenge.TLSALPN01] = chlg
 	return nil
 }
 
 // SetDNS01Provider specifies a custom provider p that can solve the given DNS-01 challenge.
 func (c *SolverManager) SetDNS01Provider(p challenge.Provider, opts ...dns01.ChallengeOption) error {
-	c.solvers[challenge.DNS01] = dns01.NewChallenge(c.core, validate, p, opts...)
+	chlg := dns01.NewChallenge(c.core, c.validate, p, opts...)
+	chlg.SetObserver(c.observer)
+
+	c.solvers[challenge.DNS01] = chlg
 	return nil
 }
 
+// SetObserver sets the observer of the duration of the phases of the resolution of the challenges
+// (presentation, propagation, and validation).
+// A nil observer disables the observation.
+func (c *SolverManager) SetObserver(o observer.Observer) {
+	c.observer = o
+
+	for _, solvr := range c.solvers {
+		if s, ok := solvr.(interface{ SetObserver(observer.Observer) }); ok {
+			s.SetObserver(o)
+		}
+	}
+}
+
+// SetConcurrency sets the maximum number of challenges (with a record created in advance, like DNS-01)
+// for which the propagation and the validation are performed at the same time.
+// All the records are always created before the first propagation check.
+// A value lower than 2 (the default) means that the challenges are solved one after the other.
+func (c *SolverManager) SetConcurrency(limit int) {
+	c.concurrency = limit
+}
+
 // Remove removes a challenge type from the available solvers.
 func (c *SolverManager) Remove(chlgType challenge.Type) {
 	delete(c.solvers, chlgType)
@@ -65,6 +107,12 @@ func (c *SolverManager) chooseSolver(authz acme.Authorization) solver {
 
 	domain := challenge.GetTargetedDomain(authz)
 	for _, chlg := range authz.Challenges {
+		// The DNS-01 challenge cannot be used to validate IP identifiers.
+		// https://www.rfc-editor.org/rfc/rfc8738.html#section-7
+		if authz.Identifier.Type == "ip" && challenge.Type(chlg.Type) == challenge.DNS01 {
+			continue
+		}
+
 		if solvr, ok := c.solvers[challenge.Type(chlg.Type)]; ok {
 			log.Infof("[%s] acme: use %s solver", domain, chlg.Type)
 			return solvr
@@ -75,6 +123,22 @@ func (c *SolverManager) chooseSolver(authz acme.Authorization) solver {
 	return nil
 }
 
+// validate validates the challenge and reports the duration of the validation,
+// and the type of the validated challenge, to the observer.
+func (c *SolverManager) validate(core *api.Core, domain string, chlg acme.Challenge) error {
+	start := time.Now()
+
+	err := validate(core, domain, chlg)
+
+	observer.Observe(c.observer, observer.PhaseValidation, domain, start)
+
+	if err == nil {
+		observer.ObserveChallenge(c.observer, domain, chlg.Type)
+	}
+
+	return err
+}
+
 func validate(core *api.Core, domain string, chlg acme.Challenge) error {
 	chlng, err := core.Challenges.New(chlg.URL)
 	if err != nil {
@@ -91,30 +155,31 @@ func validate(core *api.Core, domain string, chlg acme.Challenge) error {
 		return nil
 	}
 
-	ra, err := strconv.Atoi(chlng.RetryAfter)
-	if err != nil {
-		// The ACME server MUST return a Retry-After.
-		// If it doesn't, we'll just poll hard.
-		// Boulder does not implement the ability to retry challenges or the Retry-After header.
-		// https://github.com/letsencrypt/boulder/blob/master/docs/acme-divergences.md#section-82
-		ra = 5
+	// The ACME server SHOULD return a Retry-After.
+	// If it doesn't, we'll just poll hard.
+	// Boulder does not implement the ability to retry challenges or the Retry-After header.
+	// https://github.com/letsencrypt/boulder/blob/master/docs/acme-divergences.md#section-82
+	initialInterval := defaultPollingInterval
+	if ra, ok := api.ParseRetryAfter(chlng.RetryAfter); ok && ra > 0 {
+		initialInterval = ra
 	}
-	initialInterval := time.Duration(ra) * time.Second
 
 	bo := backoff.NewExponentialBackOff()
 	bo.InitialInterval = initialInterval
 	bo.MaxInterval = 10 * initialInterval
 	bo.MaxElapsedTime = 100 * initialInterval
 
+	rab := &retryAfterBackOff{BackOff: bo}
+
 	// After the path is sent, the ACME server will access our server.
 	// Repeatedly check the server for an updated status on our request.
 	operation := func() error {
-		authz, err := core.Authorizations.Get(chlng.AuthorizationURL)
+		authz, err := core.Authorizations.GetExtended(chlng.AuthorizationURL)
 		if err != nil {
 			return backoff.Permanent(err)
 		}
 
-		valid, err := checkAuthorizationStatus(authz)
+		valid, err := checkAuthorizationStatus(authz.Authorization)
 		if err != nil {
 			return backoff.Permanent(err)
 		}
@@ -124,10 +189,47 @@ func validate(core *api.Core, domain string, chlg acme.Challenge) error {
 			return nil
 		}
 
+		rab.setRetryAfter(authz.RetryAfter)
+
 		return errors.New("the server didn't respond to our request")
 	}
 
-	return backoff.Retry(operation, bo)
+	return backoff.Retry(operation, rab)
+}
+
+// retryAfterBackOff uses the delay of the `Retry-After` header of the last response, if any, instead of the delay of the BackOff.
+// The maximum elapsed time of the BackOff is still applied.
+type retryAfterBackOff struct {
+	backoff.BackOff
+
+	retryAfter time.Duration
+	hasValue   bool
+}
+
+// setRetryAfter sets the delay before the next attempt from the value of a `Retry-After` header.
+// The delay is at least minPollingInterval.
+func (b *retryAfterBackOff) setRetryAfter(value string) {
+	ra, ok := api.ParseRetryAfter(value)
+	if !ok {
+		return
+	}
+
+	if ra < minPollingInterval {
+		ra = minPollingInterval
+	}
+
+	b.retryAfter, b.hasValue = ra, true
+}
+
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if next == backoff.Stop || !b.hasValue {
+		return next
+	}
+
+	b.hasValue = false
+
+	return b.retryAfter
 }
 
 func checkChallengeStatus(chlng acme.ExtendedChallenge) (bool, error) {
@@ -137,6 +239,10 @@ func checkChallengeStatus(chlng acme.ExtendedChallenge) (bool, error) {
 	case acme.StatusPending, acme.StatusProcessing:
 		return false, nil
 	case acme.StatusInvalid:
+		if chlng.Error == nil {
+			// avoids a non-nil error interface holding a nil problem.
+			return false, fmt.Errorf("the challenge state %s", chlng.Status)
+		}
 		return false, chlng.Error
 	default:
 		return false, errors.New("the server returned an unexpected state")
@@ -8,9 +8,12 @@ import (
 	"net/http"
 	"sort"
 	"testing"
+	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/platform/tester"
 	"github.com/go-jose/go-jose/v3"
 	"github.com/stretchr/testify/assert"
@@ -31,6 +34,58 @@ func TestByType(t *testing.T) {
 	assert.Equal(t, expected, challenges)
 }
 
+func TestSolverManager_chooseSolver(t *testing.T) {
+	dnsSolver := &preSolverMock{}
+	httpSolver := &preSolverMock{}
+
+	manager := &SolverManager{
+		solvers: map[challenge.Type]solver{
+			challenge.DNS01:  dnsSolver,
+			challenge.HTTP01: httpSolver,
+		},
+	}
+
+	testCases := []struct {
+		desc       string
+		identifier acme.Identifier
+		challenges []acme.Challenge
+		expected   solver
+	}{
+		{
+			desc:       "dns identifier",
+			identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+			challenges: []acme.Challenge{{Type: "dns-01"}},
+			expected:   dnsSolver,
+		},
+		{
+			desc:       "IPv4 identifier",
+			identifier: acme.Identifier{Type: "ip", Value: "192.0.2.1"},
+			challenges: []acme.Challenge{{Type: "dns-01"}, {Type: "http-01"}},
+			expected:   httpSolver,
+		},
+		{
+			desc:       "IPv6 identifier without compatible challenge",
+			identifier: acme.Identifier{Type: "ip", Value: "2001:db8::1"},
+			challenges: []acme.Challenge{{Type: "dns-01"}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			solvr := manager.chooseSolver(acme.Authorization{
+				Identifier: test.identifier,
+				Challenges: test.challenges,
+			})
+
+			if test.expected == nil {
+				assert.Nil(t, solvr)
+			} else {
+				assert.Same(t, test.expected, solvr)
+			}
+		})
+	}
+}
+
 func TestValidate(t *testing.T) {
 	mux, apiURL := tester.SetupFakeAPI(t)
 
@@ -148,6 +203,63 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func Test_retryAfterBackOff(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected time.Duration
+	}{
+		{
+			desc:     "seconds",
+			value:    "3",
+			expected: 3 * time.Second,
+		},
+		{
+			desc:     "below the minimum",
+			value:    "0",
+			expected: minPollingInterval,
+		},
+		{
+			desc:     "HTTP date in the past",
+			value:    time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat),
+			expected: minPollingInterval,
+		},
+		{
+			desc:     "invalid",
+			value:    "soon",
+			expected: 5 * time.Millisecond,
+		},
+		{
+			desc:     "no header",
+			expected: 5 * time.Millisecond,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rab := &retryAfterBackOff{BackOff: backoff.NewConstantBackOff(5 * time.Millisecond)}
+
+			rab.setRetryAfter(test.value)
+
+			assert.Equal(t, test.expected, rab.NextBackOff())
+
+			// the value of the header is only used once.
+			assert.Equal(t, 5*time.Millisecond, rab.NextBackOff())
+		})
+	}
+}
+
+func Test_retryAfterBackOff_stop(t *testing.T) {
+	rab := &retryAfterBackOff{BackOff: &backoff.StopBackOff{}}
+
+	rab.setRetryAfter("3")
+
+	assert.Equal(t, backoff.Stop, rab.NextBackOff())
+}
+
 // validateNoBody reads the http.Request POST body, parses the JWS and validates it to read the body.
 // If there is an error doing this,
 // or if the JWS body is not the empty JSON payload "{}" or a POST-as-GET payload "" an error is returned.
@@ -1,18 +1,25 @@
 package tlsalpn01
 
 import (
+	"context"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
 	"fmt"
+	"math/big"
+	"net"
+	"time"
 
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/acme/api"
 	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/log"
+	"github.com/go-acme/lego/v4/platform/observer"
 )
 
 // idPeAcmeIdentifierV1 is the SMI Security for PKIX Certification Extension OID referencing the ACME extension.
@@ -25,6 +32,7 @@ type Challenge struct {
 	core     *api.Core
 	validate ValidateFunc
 	provider challenge.Provider
+	observer observer.Observer
 }
 
 func NewChallenge(core *api.Core, validate ValidateFunc, provider challenge.Provider) *Challenge {
@@ -39,8 +47,19 @@ func (c *Challenge) SetProvider(provider challenge.Provider) {
 	c.provider = provider
 }
 
+// SetObserver sets the observer of the duration of the presentation of the challenge.
+func (c *Challenge) SetObserver(o observer.Observer) {
+	c.observer = o
+}
+
 // Solve manages the provider to validate and solve the challenge.
 func (c *Challenge) Solve(authz acme.Authorization) error {
+	return c.SolveContext(context.Background(), authz)
+}
+
+// SolveContext is like Solve, the presentation and the validation are aborted when the context is canceled.
+// The cleanup is always done.
+func (c *Challenge) SolveContext(ctx context.Context, authz acme.Authorization) error {
 	domain := authz.Identifier.Value
 	log.Infof("[%s] acme: Trying to solve TLS-ALPN-01", challenge.GetTargetedDomain(authz))
 
@@ -55,7 +74,16 @@ func (c *Challenge) Solve(authz acme.Authorization) error {
 		return err
 	}
 
-	err = c.provider.Present(domain, chlng.Token, keyAuth)
+	start := time.Now()
+
+	if p, ok := c.provider.(challenge.ProviderContext); ok {
+		err = p.PresentContext(ctx, domain, chlng.Token, keyAuth)
+	} else {
+		err = c.provider.Present(domain, chlng.Token, keyAuth)
+	}
+
+	observer.Observe(c.observer, observer.PhasePresent, challenge.GetTargetedDomain(authz), start)
+
 	if err != nil {
 		return fmt.Errorf("[%s] acme: error presenting token: %w", challenge.GetTargetedDomain(authz), err)
 	}
@@ -67,45 +95,46 @@ func (c *Challenge) Solve(authz acme.Authorization) error {
 	}()
 
 	chlng.KeyAuthorization = keyAuth
-	return c.validate(c.core, domain, chlng)
+	return c.validate(c.core.WithContext(ctx), domain, chlng)
 }
 
 // ChallengeBlocks returns PEM blocks (certPEMBlock, keyPEMBlock) with the acmeValidation-v1 extension
 // and domain name for the `tls-alpn-01` challenge.
 func ChallengeBlocks(domain, keyAuth string) ([]byte, []byte, error) {
-	// Compute the SHA-256 digest of the key authorization.
-	zBytes := sha256.Sum256([]byte(keyAuth))
+	return ChallengeBlocksFromTemplate(domain, keyAuth, nil)
+}
 
-	value, err := asn1.Marshal(zBytes[:sha256.Size])
+// ChallengeBlocksFromTemplate returns PEM blocks (certPEMBlock, keyPEMBlock) with the acmeValidation-v1 extension
+// and domain name for the `tls-alpn-01` challenge, the certificate is created from the template.
+//
+// The template allows to customize the certificate (e.g. the subject, the serial number, the validity window).
+// The zero values are replaced by the default values:
+// a random serial number, the subject "ACME Challenge TEMP", a validity of one year starting now,
+// and the key encipherment key usage.
+// The identifiers (DNSNames, IPAddresses) and the acmeValidation-v1 extension are always defined from the domain and the keyAuth:
+// the values of the template are replaced.
+// The template is not modified.
+func ChallengeBlocksFromTemplate(domain, keyAuth string, template *x509.Certificate) ([]byte, []byte, error) {
+	// Generate a new RSA key for the certificates.
+	tempPrivateKey, err := certcrypto.GeneratePrivateKey(certcrypto.RSA2048)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// Add the keyAuth digest as the acmeValidation-v1 extension
-	// (marked as critical such that it won't be used by non-ACME software).
-	// Reference: https://www.rfc-editor.org/rfc/rfc8737.html#section-3
-	extensions := []pkix.Extension{
-		{
-			Id:       idPeAcmeIdentifierV1,
-			Critical: true,
-			Value:    value,
-		},
-	}
+	rsaPrivateKey := tempPrivateKey.(*rsa.PrivateKey)
 
-	// Generate a new RSA key for the certificates.
-	tempPrivateKey, err := certcrypto.GeneratePrivateKey(certcrypto.RSA2048)
+	tmpl, err := newChallengeTemplate(domain, keyAuth, template)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	rsaPrivateKey := tempPrivateKey.(*rsa.PrivateKey)
-
-	// Generate the PEM certificate using the provided private key, domain, and extra extensions.
-	tempCertPEM, err := certcrypto.GeneratePemCert(rsaPrivateKey, domain, extensions)
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &rsaPrivateKey.PublicKey, rsaPrivateKey)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	tempCertPEM := certcrypto.PEMEncode(certcrypto.DERCertificateBytes(der))
+
 	// Encode the private key into a PEM format. We'll need to use it to generate the x509 keypair.
 	rsaPrivatePEM := certcrypto.PEMEncode(rsaPrivateKey)
 
@@ -115,7 +144,14 @@ func ChallengeBlocks(domain, keyAuth string) ([]byte, []byte, error) {
 // ChallengeCert returns a certificate with the acmeValidation-v1 extension
 // and domain name for the `tls-alpn-01` challenge.
 func ChallengeCert(domain, keyAuth string) (*tls.Certificate, error) {
-	tempCertPEM, rsaPrivatePEM, err := ChallengeBlocks(domain, keyAuth)
+	return ChallengeCertFromTemplate(domain, keyAuth, nil)
+}
+
+// ChallengeCertFromTemplate returns a certificate with the acmeValidation-v1 extension
+// and domain name for the `tls-alpn-01` challenge, the certificate is created from the template
+// (see ChallengeBlocksFromTemplate).
+func ChallengeCertFromTemplate(domain, keyAuth string, template *x509.Certificate) (*tls.Certificate, error) {
+	tempCertPEM, rsaPrivatePEM, err := ChallengeBlocksFromTemplate(domain, keyAuth, template)
 	if err != nil {
 		return nil, err
 	}
@@ -127,3 +163,80 @@ func ChallengeCert(domain, keyAuth string) (*tls.Certificate, error) {
 
 	return &cert, nil
 }
+
+// newChallengeTemplate creates the template of the challenge certificate from a copy of the user template.
+func newChallengeTemplate(domain, keyAuth string, template *x509.Certificate) (*x509.Certificate, error) {
+	tmpl := &x509.Certificate{}
+	if template != nil {
+		*tmpl = *template
+	}
+
+	if tmpl.SerialNumber == nil {
+		serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+
+		serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		tmpl.SerialNumber = serialNumber
+	}
+
+	if tmpl.Subject.String() == "" {
+		tmpl.Subject = pkix.Name{CommonName: "ACME Challenge TEMP"}
+	}
+
+	if tmpl.NotBefore.IsZero() {
+		tmpl.NotBefore = time.Now()
+	}
+
+	if tmpl.NotAfter.IsZero() {
+		tmpl.NotAfter = tmpl.NotBefore.AddDate(1, 0, 0)
+	}
+
+	if tmpl.KeyUsage == 0 {
+		tmpl.KeyUsage = x509.KeyUsageKeyEncipherment
+	}
+
+	tmpl.BasicConstraintsValid = true
+
+	// https://www.rfc-editor.org/rfc/rfc8738.html#section-6
+	tmpl.DNSNames = nil
+	tmpl.IPAddresses = nil
+
+	if ip := net.ParseIP(domain); ip != nil {
+		tmpl.IPAddresses = []net.IP{ip}
+	} else {
+		tmpl.DNSNames = []string{domain}
+	}
+
+	// Compute the SHA-256 digest of the key authorization.
+	zBytes := sha256.Sum256([]byte(keyAuth))
+
+	value, err := asn1.Marshal(zBytes[:sha256.Size])
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the keyAuth digest as the acmeValidation-v1 extension
+	// (marked as critical such that it won't be used by non-ACME software).
+	// Reference: https://www.rfc-editor.org/rfc/rfc8737.html#section-3
+	extensions := []pkix.Extension{
+		{
+			Id:       idPeAcmeIdentifierV1,
+			Critical: true,
+			Value:    value,
+		},
+	}
+
+	// an acmeValidation-v1 extension of the template is replaced.
+	for _, ext := range tmpl.ExtraExtensions {
+		if !ext.Id.Equal(idPeAcmeIdentifierV1) {
+			extensions = append(extensions, ext)
+		}
+	}
+
+	tmpl.ExtraExtensions = extensions
+
+	return tmpl, nil
+}
@@ -2,12 +2,14 @@ package tlsalpn01
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"strings"
 
+	"github.com/go-acme/lego/v4/challenge/internal/listeners"
 	"github.com/go-acme/lego/v4/log"
 )
 
@@ -27,16 +29,44 @@ type ProviderServer struct {
 	iface    string
 	port     string
 	listener net.Listener
+	server   *http.Server
+
+	// listener provided by the user (see SetListener).
+	bindListener *listeners.Shared
+
+	// template of the challenge certificate (see SetCertificateTemplate).
+	template *x509.Certificate
 }
 
 // NewProviderServer creates a new ProviderServer on the selected interface and port.
 // Setting iface and / or port to an empty string will make the server fall back to
 // the "any" interface and port 443 respectively.
+// To listen only on a specific interface of a multi-homed host, iface must be the IP address of this interface.
 func NewProviderServer(iface, port string) *ProviderServer {
 	return &ProviderServer{iface: iface, port: port}
 }
 
+// SetListener sets an already-open listener (e.g. bound to a specific address, or from socket activation)
+// that the server uses instead of creating its own.
+// When a listener is supplied, the interface and the port are ignored.
+// The listener is wrapped into a TLS listener.
+// The listener is not closed by CleanUp: it can be used for several challenges, and must be closed by the caller.
+func (s *ProviderServer) SetListener(listener net.Listener) {
+	s.bindListener = listeners.NewShared(listener)
+}
+
+// SetCertificateTemplate sets the template used to create the challenge certificate
+// (e.g. to customize the subject, the serial number, or the validity window).
+// The identifiers and the acmeValidation-v1 extension are always defined by the server (see ChallengeBlocksFromTemplate).
+func (s *ProviderServer) SetCertificateTemplate(template *x509.Certificate) {
+	s.template = template
+}
+
 func (s *ProviderServer) GetAddress() string {
+	if s.bindListener != nil {
+		return s.bindListener.Addr().String()
+	}
+
 	return net.JoinHostPort(s.iface, s.port)
 }
 
@@ -49,7 +79,7 @@ func (s *ProviderServer) Present(domain, token, keyAuth string) error {
 	}
 
 	// Generate the challenge certificate using the provided keyAuth and domain.
-	cert, err := ChallengeCert(domain, keyAuth)
+	cert, err := ChallengeCertFromTemplate(domain, keyAuth, s.template)
 	if err != nil {
 		return err
 	}
@@ -64,17 +94,25 @@ func (s *ProviderServer) Present(domain, token, keyAuth string) error {
 	// https://www.rfc-editor.org/rfc/rfc8737.html#section-6.2
 	tlsConf.NextProtos = []string{ACMETLS1Protocol}
 
-	// Create the listener with the created tls.Config.
-	s.listener, err = tls.Listen("tcp", s.GetAddress(), tlsConf)
-	if err != nil {
-		return fmt.Errorf("could not start HTTPS server for challenge: %w", err)
+	if s.bindListener != nil {
+		s.listener = tls.NewListener(s.bindListener.Listener(), tlsConf)
+	} else {
+		// Create the listener with the created tls.Config.
+		s.listener, err = tls.Listen("tcp", s.GetAddress(), tlsConf)
+		if err != nil {
+			return fmt.Errorf("could not start HTTPS server for challenge: %w", err)
+		}
 	}
 
+	s.server = &http.Server{}
+
+	listener, server := s.listener, s.server
+
 	// Shut the server down when we're finished.
 	go func() {
-		err := http.Serve(s.listener, nil)
-		if err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
-			log.Println(err)
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) && !strings.Contains(err.Error(), "use of closed network connection") {
+			log.Errorf("TLS-ALPN server for challenge: %v", err)
 		}
 	}()
 
@@ -88,7 +126,14 @@ func (s *ProviderServer) CleanUp(domain, token, keyAuth string) error {
 	}
 
 	// Server was created, close it.
-	if err := s.listener.Close(); err != nil && errors.Is(err, http.ErrServerClosed) {
+	// Closing the server closes the listener used by the server:
+	// the listener opened by the server, or the view of the listener provided by the user.
+	if err := s.server.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+
+	// The listener is not closed by the server if it is closed before serving.
+	if err := s.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
 		return err
 	}
 
@@ -6,12 +6,18 @@ import (
 	"crypto/sha256"
 	"crypto/subtle"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/asn1"
+	"math/big"
+	"net"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/platform/tester"
 	"github.com/stretchr/testify/assert"
@@ -116,3 +122,232 @@ func TestChallengeInvalidPort(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid port")
 	assert.Contains(t, err.Error(), "123456")
 }
+
+func TestProviderServer_bindAddress(t *testing.T) {
+	port := getFreePort(t)
+
+	server := NewProviderServer("127.0.0.1", port)
+
+	err := server.Present("localhost", "token", "keyAuth")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = server.CleanUp("localhost", "token", "keyAuth") })
+
+	conn, err := tls.Dial("tcp", net.JoinHostPort("127.0.0.1", port), &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{ACMETLS1Protocol},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, ACMETLS1Protocol, conn.ConnectionState().NegotiatedProtocol)
+	_ = conn.Close()
+
+	// Another loopback address: the server must not listen on it.
+	_, err = net.DialTimeout("tcp", net.JoinHostPort("127.0.0.2", port), time.Second)
+	require.Error(t, err)
+}
+
+func TestProviderServer_SetListener(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewProviderServer("", "")
+	server.SetListener(listener)
+
+	assert.Equal(t, listener.Addr().String(), server.GetAddress())
+
+	err = server.Present("localhost", "token", "keyAuth")
+	require.NoError(t, err)
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{ACMETLS1Protocol},
+	})
+	require.NoError(t, err)
+
+	remoteCert := conn.ConnectionState().PeerCertificates[0]
+	assert.Equal(t, []string{"localhost"}, remoteCert.DNSNames)
+	_ = conn.Close()
+
+	err = server.CleanUp("localhost", "token", "keyAuth")
+	require.NoError(t, err)
+
+	// the listener provided by the user is not closed.
+	require.NoError(t, listener.Close())
+}
+
+func TestChallengeWithListener(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewProviderServer("", "")
+	server.SetListener(listener)
+
+	validate := func(_ *api.Core, domain string, chlng acme.Challenge) error {
+		conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+			InsecureSkipVerify: true,
+			NextProtos:         []string{ACMETLS1Protocol},
+		})
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		assert.Equal(t, []string{domain}, conn.ConnectionState().PeerCertificates[0].DNSNames)
+
+		return nil
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	solver := NewChallenge(core, validate, server)
+
+	// the listener is reused by the authorizations of the other domains of the order.
+	for _, domain := range []string{"a.localhost", "b.localhost"} {
+		authz := acme.Authorization{
+			Identifier: acme.Identifier{
+				Value: domain,
+			},
+			Challenges: []acme.Challenge{
+				{Type: challenge.TLSALPN01.String(), Token: "tlsalpn1"},
+			},
+		}
+
+		err = solver.Solve(authz)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, listener.Close())
+}
+
+func getFreePort(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	require.NoError(t, listener.Close())
+
+	return port
+}
+
+func TestChallengeBlocksFromTemplate(t *testing.T) {
+	keyAuth := "token.thumbprint"
+
+	zBytes := sha256.Sum256([]byte(keyAuth))
+	expectedValue, err := asn1.Marshal(zBytes[:sha256.Size])
+	require.NoError(t, err)
+
+	notBefore := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "custom", Organization: []string{"lego"}},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(time.Hour),
+		DNSNames:     []string{"ignored.example.com"},
+		ExtraExtensions: []pkix.Extension{
+			{Id: idPeAcmeIdentifierV1, Value: []byte("invalid")},
+			{Id: asn1.ObjectIdentifier{1, 2, 3, 4}, Value: []byte{0x05, 0x00}},
+		},
+	}
+
+	testCases := []struct {
+		desc     string
+		domain   string
+		template *x509.Certificate
+		assert   func(t *testing.T, cert *x509.Certificate)
+	}{
+		{
+			desc:   "default",
+			domain: "example.com",
+			assert: func(t *testing.T, cert *x509.Certificate) {
+				t.Helper()
+
+				assert.Equal(t, "ACME Challenge TEMP", cert.Subject.CommonName)
+				assert.Equal(t, []string{"example.com"}, cert.DNSNames)
+				assert.WithinDuration(t, time.Now(), cert.NotBefore, time.Minute)
+				assert.Equal(t, cert.NotBefore.AddDate(1, 0, 0), cert.NotAfter)
+			},
+		},
+		{
+			desc:     "custom template",
+			domain:   "example.com",
+			template: template,
+			assert: func(t *testing.T, cert *x509.Certificate) {
+				t.Helper()
+
+				assert.Equal(t, big.NewInt(42), cert.SerialNumber)
+				assert.Equal(t, "custom", cert.Subject.CommonName)
+				assert.Equal(t, []string{"lego"}, cert.Subject.Organization)
+				assert.Equal(t, notBefore, cert.NotBefore)
+				assert.Equal(t, notBefore.Add(time.Hour), cert.NotAfter)
+				assert.Equal(t, []string{"example.com"}, cert.DNSNames)
+
+				var found bool
+				for _, ext := range cert.Extensions {
+					if ext.Id.Equal(asn1.ObjectIdentifier{1, 2, 3, 4}) {
+						found = true
+					}
+				}
+
+				assert.True(t, found, "the extra extension of the template must be kept")
+			},
+		},
+		{
+			desc:     "IP address",
+			domain:   "192.0.2.1",
+			template: template,
+			assert: func(t *testing.T, cert *x509.Certificate) {
+				t.Helper()
+
+				assert.Empty(t, cert.DNSNames)
+				require.Len(t, cert.IPAddresses, 1)
+				assert.Equal(t, "192.0.2.1", cert.IPAddresses[0].String())
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			certPEM, keyPEM, err := ChallengeBlocksFromTemplate(test.domain, keyAuth, test.template)
+			require.NoError(t, err)
+
+			_, err = tls.X509KeyPair(certPEM, keyPEM)
+			require.NoError(t, err)
+
+			cert, err := certcrypto.ParsePEMCertificate(certPEM)
+			require.NoError(t, err)
+
+			var acmeExtensions []pkix.Extension
+			for _, ext := range cert.Extensions {
+				if ext.Id.Equal(idPeAcmeIdentifierV1) {
+					acmeExtensions = append(acmeExtensions, ext)
+				}
+			}
+
+			require.Len(t, acmeExtensions, 1)
+			assert.True(t, acmeExtensions[0].Critical)
+			assert.Equal(t, expectedValue, acmeExtensions[0].Value)
+
+			test.assert(t, cert)
+		})
+	}
+
+	// the template is not modified.
+	assert.Equal(t, []string{"ignored.example.com"}, template.DNSNames)
+	assert.Len(t, template.ExtraExtensions, 2)
+}
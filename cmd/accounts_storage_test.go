@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_generatePrivateKey_loadPrivateKey(t *testing.T) {
+	keyTypes := []certcrypto.KeyType{
+		certcrypto.EC256,
+		certcrypto.EC384,
+		certcrypto.EC521,
+		certcrypto.ED25519,
+		certcrypto.RSA2048,
+	}
+
+	for _, keyType := range keyTypes {
+		keyType := keyType
+		t.Run(string(keyType), func(t *testing.T) {
+			t.Parallel()
+
+			file := filepath.Join(t.TempDir(), "account.key")
+
+			privateKey, err := generatePrivateKey(file, keyType)
+			require.NoError(t, err)
+
+			loaded, err := loadPrivateKey(file)
+			require.NoError(t, err)
+
+			assert.Equal(t, privateKey, loaded)
+		})
+	}
+}
@@ -2,12 +2,13 @@ package cmd
 
 import (
 	"bytes"
-	"crypto"
 	"crypto/rand"
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -27,7 +28,8 @@ const (
 	baseArchivesFolderName     = "archives"
 )
 
-// CertificatesStorage a certificates' storage.
+// CertificatesStorage a certificates' storage, backed by the filesystem.
+// It implements certificate.Storage.
 //
 // rootPath:
 //
@@ -49,6 +51,8 @@ type CertificatesStorage struct {
 	filename    string // Deprecated
 }
 
+var _ certificate.Storage = (*CertificatesStorage)(nil)
+
 // NewCertificatesStorage create a new certificates storage.
 func NewCertificatesStorage(ctx *cli.Context) *CertificatesStorage {
 	return &CertificatesStorage{
@@ -79,20 +83,29 @@ func (s *CertificatesStorage) GetRootPath() string {
 	return s.rootPath
 }
 
+// SaveResource saves the certificate resource, the process exits if an error occurs.
 func (s *CertificatesStorage) SaveResource(certRes *certificate.Resource) {
+	err := s.Save(certRes)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Save saves the certificate, the issuer certificate, the private key, and the metadata (JSON) of a certificate resource.
+func (s *CertificatesStorage) Save(certRes *certificate.Resource) error {
 	domain := certRes.Domain
 
 	// We store the certificate, private key and metadata in different files
 	// as web servers would not be able to work with a combined file.
 	err := s.WriteFile(domain, ".crt", certRes.Certificate)
 	if err != nil {
-		log.Fatalf("Unable to save Certificate for domain %s\n\t%v", domain, err)
+		return fmt.Errorf("unable to save Certificate for domain %s: %w", domain, err)
 	}
 
 	if certRes.IssuerCertificate != nil {
 		err = s.WriteFile(domain, ".issuer.crt", certRes.IssuerCertificate)
 		if err != nil {
-			log.Fatalf("Unable to save IssuerCertificate for domain %s\n\t%v", domain, err)
+			return fmt.Errorf("unable to save IssuerCertificate for domain %s: %w", domain, err)
 		}
 	}
 
@@ -100,22 +113,59 @@ func (s *CertificatesStorage) SaveResource(certRes *certificate.Resource) {
 	if certRes.PrivateKey != nil {
 		err = s.WriteCertificateFiles(domain, certRes)
 		if err != nil {
-			log.Fatalf("Unable to save PrivateKey for domain %s\n\t%v", domain, err)
+			return fmt.Errorf("unable to save PrivateKey for domain %s: %w", domain, err)
 		}
 	} else if s.pem || s.pfx {
 		// we don't have the private key; can't write the .pem or .pfx file
-		log.Fatalf("Unable to save PEM or PFX without private key for domain %s. Are you using a CSR?", domain)
+		return fmt.Errorf("unable to save PEM or PFX without private key for domain %s. Are you using a CSR?", domain)
 	}
 
 	jsonBytes, err := json.MarshalIndent(certRes, "", "\t")
 	if err != nil {
-		log.Fatalf("Unable to marshal CertResource for domain %s\n\t%v", domain, err)
+		return fmt.Errorf("unable to marshal CertResource for domain %s: %w", domain, err)
 	}
 
 	err = s.WriteFile(domain, ".json", jsonBytes)
 	if err != nil {
-		log.Fatalf("Unable to save CertResource for domain %s\n\t%v", domain, err)
+		return fmt.Errorf("unable to save CertResource for domain %s: %w", domain, err)
+	}
+
+	return nil
+}
+
+// Load loads the metadata (JSON), the certificate, the issuer certificate, and the private key (if any) of a domain.
+func (s *CertificatesStorage) Load(domain string) (*certificate.Resource, error) {
+	raw, err := s.ReadFile(domain, ".json")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, certificate.ErrResourceNotFound
+		}
+
+		return nil, fmt.Errorf("error while loading the meta data for domain %s: %w", domain, err)
+	}
+
+	var resource certificate.Resource
+	if err = json.Unmarshal(raw, &resource); err != nil {
+		return nil, fmt.Errorf("error while marshaling the meta data for domain %s: %w", domain, err)
+	}
+
+	resource.Certificate, err = s.ReadFile(domain, ".crt")
+	if err != nil {
+		return nil, fmt.Errorf("error while loading the certificate for domain %s: %w", domain, err)
+	}
+
+	resource.IssuerCertificate, err = s.readOptionalFile(domain, ".issuer.crt")
+	if err != nil {
+		return nil, fmt.Errorf("error while loading the issuer certificate for domain %s: %w", domain, err)
 	}
+
+	// the private key doesn't exist when the certificate has been obtained with a CSR.
+	resource.PrivateKey, err = s.readOptionalFile(domain, ".key")
+	if err != nil {
+		return nil, fmt.Errorf("error while loading the private key for domain %s: %w", domain, err)
+	}
+
+	return &resource, nil
 }
 
 func (s *CertificatesStorage) ReadResource(domain string) certificate.Resource {
@@ -147,6 +197,16 @@ func (s *CertificatesStorage) ReadFile(domain, extension string) ([]byte, error)
 	return os.ReadFile(s.GetFileName(domain, extension))
 }
 
+// readOptionalFile reads a file, a missing file is not an error.
+func (s *CertificatesStorage) readOptionalFile(domain, extension string) ([]byte, error) {
+	content, err := s.ReadFile(domain, extension)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	return content, err
+}
+
 func (s *CertificatesStorage) GetFileName(domain, extension string) string {
 	filename := sanitizedDomain(domain) + extension
 	return filepath.Join(s.rootPath, filename)
@@ -219,27 +279,9 @@ func (s *CertificatesStorage) WritePFXFile(domain string, certRes *certificate.R
 		return fmt.Errorf("unable to load Issuer Certificate for domain %s: %w", domain, err)
 	}
 
-	keyPemBlock, _ := pem.Decode(certRes.PrivateKey)
-	if keyPemBlock == nil {
-		return fmt.Errorf("unable to parse PrivateKey for domain %s", domain)
-	}
-
-	var privateKey crypto.Signer
-	var keyErr error
-
-	switch keyPemBlock.Type {
-	case "RSA PRIVATE KEY":
-		privateKey, keyErr = x509.ParsePKCS1PrivateKey(keyPemBlock.Bytes)
-		if keyErr != nil {
-			return fmt.Errorf("unable to load RSA PrivateKey for domain %s: %w", domain, keyErr)
-		}
-	case "EC PRIVATE KEY":
-		privateKey, keyErr = x509.ParseECPrivateKey(keyPemBlock.Bytes)
-		if keyErr != nil {
-			return fmt.Errorf("unable to load EC PrivateKey for domain %s: %w", domain, keyErr)
-		}
-	default:
-		return fmt.Errorf("unsupported PrivateKey type '%s' for domain %s", keyPemBlock.Type, domain)
+	privateKey, err := certcrypto.ParsePEMPrivateKey(certRes.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("unable to load PrivateKey for domain %s: %w", domain, err)
 	}
 
 	pfxBytes, err := pkcs12.Encode(rand.Reader, privateKey, cert, []*x509.Certificate{issuerCert}, s.pfxPassword)
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func TestCertificatesStorage_SaveLoad(t *testing.T) {
+	storage := &CertificatesStorage{
+		rootPath: filepath.Join(t.TempDir(), baseCertificatesFolderName),
+	}
+
+	storage.CreateRootFolder()
+
+	_, err := storage.Load("*.example.com")
+	require.ErrorIs(t, err, certificate.ErrResourceNotFound)
+
+	certRes := &certificate.Resource{
+		Domain:            "*.example.com",
+		CertURL:           "https://example.org/cert/1",
+		CertStableURL:     "https://example.org/cert/1",
+		PrivateKey:        []byte("private key"),
+		Certificate:       []byte("certificate"),
+		IssuerCertificate: []byte("issuer certificate"),
+	}
+
+	err = storage.Save(certRes)
+	require.NoError(t, err)
+
+	for _, ext := range []string{".crt", ".issuer.crt", ".key", ".json"} {
+		assert.FileExists(t, filepath.Join(storage.rootPath, "_.example.com"+ext))
+	}
+
+	loaded, err := storage.Load("*.example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, certRes, loaded)
+}
+
+func TestCertificatesStorage_Load_withoutPrivateKey(t *testing.T) {
+	storage := &CertificatesStorage{
+		rootPath: filepath.Join(t.TempDir(), baseCertificatesFolderName),
+	}
+
+	storage.CreateRootFolder()
+
+	// a certificate obtained with a CSR.
+	certRes := &certificate.Resource{
+		Domain:      "example.com",
+		Certificate: []byte("certificate"),
+	}
+
+	err := storage.Save(certRes)
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(storage.rootPath, "example.com.key"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	loaded, err := storage.Load("example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, certRes, loaded)
+}
+
+func TestCertificatesStorage_WritePFXFile(t *testing.T) {
+	keyTypes := []certcrypto.KeyType{
+		certcrypto.EC256,
+		certcrypto.EC384,
+		certcrypto.EC521,
+		certcrypto.ED25519,
+		certcrypto.RSA2048,
+	}
+
+	for _, keyType := range keyTypes {
+		keyType := keyType
+		t.Run(string(keyType), func(t *testing.T) {
+			t.Parallel()
+
+			storage := &CertificatesStorage{
+				rootPath:    filepath.Join(t.TempDir(), baseCertificatesFolderName),
+				pfxPassword: "secret",
+			}
+
+			storage.CreateRootFolder()
+
+			privateKey, err := certcrypto.GeneratePrivateKey(keyType)
+			require.NoError(t, err)
+
+			certPEM := generateSelfSignedCertificate(t, privateKey.(crypto.Signer))
+
+			certRes := &certificate.Resource{
+				Domain:            "example.com",
+				PrivateKey:        pem.EncodeToMemory(certcrypto.PEMBlock(privateKey)),
+				Certificate:       certPEM,
+				IssuerCertificate: certPEM,
+			}
+
+			err = storage.WritePFXFile("example.com", certRes)
+			require.NoError(t, err)
+
+			pfxData, err := os.ReadFile(filepath.Join(storage.rootPath, "example.com.pfx"))
+			require.NoError(t, err)
+
+			loaded, _, _, err := pkcs12.DecodeChain(pfxData, "secret")
+			require.NoError(t, err)
+
+			assert.Equal(t, privateKey, loaded)
+		})
+	}
+}
+
+func generateSelfSignedCertificate(t *testing.T, key crypto.Signer) []byte {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
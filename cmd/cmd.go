@@ -4,11 +4,19 @@ import "github.com/urfave/cli/v2"
 
 // CreateCommands Creates all CLI commands.
 func CreateCommands() []*cli.Command {
-	return []*cli.Command{
+	commands := []*cli.Command{
 		createRun(),
 		createRevoke(),
 		createRenew(),
 		createDNSHelp(),
 		createList(),
+		createDNSCleanup(),
+		createDNSCheck(),
 	}
+
+	for _, command := range commands {
+		applyConfigFile(command)
+	}
+
+	return commands
 }
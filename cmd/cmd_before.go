@@ -6,6 +6,13 @@ import (
 )
 
 func Before(ctx *cli.Context) error {
+	if ctx.String("config") != "" {
+		err := loadConfigFile(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	if ctx.String("path") == "" {
 		log.Fatal("Could not determine current working directory. Please pass --path.")
 	}
@@ -21,3 +28,30 @@ func Before(ctx *cli.Context) error {
 
 	return nil
 }
+
+// loadConfigFile reads the configuration file, applies the global options,
+// and keeps the file for the options of the commands (see applyConfigFile).
+func loadConfigFile(ctx *cli.Context) error {
+	cfg, err := readConfigFile(ctx.String("config"))
+	if err != nil {
+		return err
+	}
+
+	err = cfg.validate(ctx.App)
+	if err != nil {
+		return err
+	}
+
+	err = cfg.apply(ctx, ctx.App.Flags)
+	if err != nil {
+		return err
+	}
+
+	if ctx.App.Metadata == nil {
+		ctx.App.Metadata = map[string]interface{}{}
+	}
+
+	ctx.App.Metadata[configFileMetadataKey] = cfg
+
+	return nil
+}
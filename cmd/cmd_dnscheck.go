@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/providers/dns"
+	"github.com/urfave/cli/v2"
+)
+
+func createDNSCheck() *cli.Command {
+	return &cli.Command{
+		Name:    "dnscheck",
+		Aliases: []string{"check"},
+		Usage:   "Check the credentials of the DNS provider before an issuance, using the '--dns' global option",
+		Action:  dnsCheck,
+	}
+}
+
+func dnsCheck(ctx *cli.Context) error {
+	name := ctx.String("dns")
+	if name == "" {
+		return errors.New("the '--dns' global option is required")
+	}
+
+	provider, err := dns.NewDNSChallengeProviderByName(name)
+	if err != nil {
+		return err
+	}
+
+	checked, err := dns01.CheckCredentials(ctx.Context, provider)
+	if err != nil {
+		return err
+	}
+
+	if !checked {
+		fmt.Fprintf(ctx.App.Writer, "%s\tthe DNS provider doesn't support the check of the credentials\n", name)
+		return nil
+	}
+
+	fmt.Fprintf(ctx.App.Writer, "%s\tthe credentials are valid\n", name)
+
+	return nil
+}
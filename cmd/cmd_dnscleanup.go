@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/providers/dns"
+	"github.com/urfave/cli/v2"
+)
+
+func createDNSCleanup() *cli.Command {
+	return &cli.Command{
+		Name:   "dnscleanup",
+		Usage:  "List and remove the orphaned challenge TXT records of DNS zones, using the '--dns' global option",
+		Action: dnsCleanup,
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "zone",
+				Aliases: []string{"z"},
+				Usage:   "DNS zone to clean up. Can be specified multiple times.",
+			},
+			&cli.StringFlag{
+				Name:  "challenge-prefix",
+				Usage: "Prefix of the challenge records, if the challenges use a custom prefix.",
+				Value: dns01.DefaultChallengePrefix,
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only list the challenge records, without removing them.",
+			},
+		},
+	}
+}
+
+func dnsCleanup(ctx *cli.Context) error {
+	if ctx.String("dns") == "" {
+		return errors.New("the '--dns' global option is required")
+	}
+
+	zones := ctx.StringSlice("zone")
+	if len(zones) == 0 {
+		return errors.New("at least one zone is required ('--zone')")
+	}
+
+	provider, err := dns.NewDNSChallengeProviderByName(ctx.String("dns"))
+	if err != nil {
+		return err
+	}
+
+	prefix := ctx.String("challenge-prefix")
+	dryRun := ctx.Bool("dry-run")
+
+	for _, zone := range zones {
+		records, err := dns01.CleanUpChallengeRecords(provider, zone, prefix, dryRun)
+
+		for _, record := range records {
+			fmt.Fprintf(ctx.App.Writer, "%s\t%s\t%s\n", dns01.UnFqdn(zone), record.FQDN, record.Value)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if len(records) == 0 {
+			fmt.Fprintf(ctx.App.Writer, "%s\tno challenge records found\n", dns01.UnFqdn(zone))
+		}
+	}
+
+	return nil
+}
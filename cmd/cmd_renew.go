@@ -3,10 +3,13 @@ package cmd
 import (
 	"crypto"
 	"crypto/x509"
+	"errors"
+	"io/fs"
 	"math/rand"
 	"os"
 	"time"
 
+	"github.com/go-acme/lego/v4/acme/api"
 	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/go-acme/lego/v4/certificate"
 	"github.com/go-acme/lego/v4/lego"
@@ -30,6 +33,8 @@ func createRenew() *cli.Command {
 		Usage:  "Renew a certificate",
 		Action: renew,
 		Before: func(ctx *cli.Context) error {
+			setupJSONOutput(ctx)
+
 			// we require either domains or csr, but not both
 			hasDomains := len(ctx.StringSlice("domains")) > 0
 			hasCsr := len(ctx.String("csr")) > 0
@@ -47,9 +52,15 @@ func createRenew() *cli.Command {
 				Value: 30,
 				Usage: "The number of days left on a certificate to renew it.",
 			},
+			&cli.Float64Flag{
+				Name: "remaining-ratio",
+				Usage: "The ratio of the validity period left on a certificate to renew it (e.g. 0.33 to renew a 90-day certificate 30 days before its expiration)." +
+					" Suitable for short-lived certificates. Overrides '--days'.",
+			},
 			&cli.BoolFlag{
-				Name:  "reuse-key",
-				Usage: "Used to indicate you want to reuse your current private key for the new certificate.",
+				Name: "reuse-key",
+				Usage: "Used to indicate you want to reuse your current private key for the new certificate." +
+					" If the private key is missing, a new one is generated.",
 			},
 			&cli.BoolFlag{
 				Name:  "no-bundle",
@@ -58,7 +69,7 @@ func createRenew() *cli.Command {
 			&cli.BoolFlag{
 				Name: "must-staple",
 				Usage: "Include the OCSP must staple TLS extension in the CSR and generated certificate." +
-					" Only works if the CSR is generated by lego.",
+					" Only works if the CSR is generated by lego. Some CAs don't support this extension.",
 			},
 			&cli.StringFlag{
 				Name:  "renew-hook",
@@ -66,18 +77,43 @@ func createRenew() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name: "preferred-chain",
-				Usage: "If the CA offers multiple certificate chains, prefer the chain with an issuer matching this Subject Common Name." +
+				Usage: "If the CA offers multiple certificate chains, prefer the chain with an issuer matching this Subject Common Name (or distinguished name, e.g. \"CN=ISRG Root X1,O=Internet Security Research Group,C=US\")." +
 					" If no match, the default offered chain will be used.",
 			},
+			&cli.StringFlag{
+				Name:  "profile",
+				Usage: "If the CA offers multiple certificate profiles (draft-aaron-acme-profiles), choose this one.",
+			},
 			&cli.StringFlag{
 				Name:  "always-deactivate-authorizations",
 				Usage: "Force the authorizations to be relinquished even if the certificate request was successful.",
 			},
+			&cli.BoolFlag{
+				Name:  "ari-enable",
+				Usage: "Use the renewalInfo endpoint (draft-ietf-acme-ari) to check if a certificate should be renewed.",
+			},
+			&cli.DurationFlag{
+				Name:  "ari-wait-to-renew-duration",
+				Usage: "The maximum duration you're willing to sleep for a renewal time returned by the renewalInfo endpoint.",
+			},
+			&cli.BoolFlag{
+				Name: "dry-run",
+				Usage: "Check the configuration without issuing a certificate: the order is created and the challenges are solved, but the order is not finalized." +
+					" The renewal is always attempted, and nothing is saved.",
+			},
 			&cli.BoolFlag{
 				Name: "no-random-sleep",
 				Usage: "Do not add a random sleep before the renewal." +
 					" We do not recommend using this flag if you are doing your renewals in an automated way.",
 			},
+			&cli.BoolFlag{
+				Name:  "tlsa",
+				Usage: "Print the DANE-EE TLSA records (3 1 1 and 3 1 2) of the new certificate.",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the result (domains, certificate paths, expiry, issuer) as a JSON object on stdout, and the errors as JSON objects on stderr.",
+			},
 		},
 	}
 }
@@ -119,32 +155,42 @@ func renewForDomains(ctx *cli.Context, client *lego.Client, certsStorage *Certif
 
 	cert := certificates[0]
 
-	if !needRenewal(cert, domain, ctx.Int("days")) {
-		return nil
+	var ariRenewalTime *time.Time
+	if ctx.Bool("ari-enable") {
+		ariRenewalTime = getARIRenewalTime(ctx, cert, domain, client)
+		if ariRenewalTime != nil {
+			now := time.Now().UTC()
+
+			// Figure out if we need to sleep before renewing.
+			if ariRenewalTime.After(now) {
+				log.Infof("[%s] Sleeping %s until renewal time %s", domain, ariRenewalTime.Sub(now), ariRenewalTime)
+				time.Sleep(ariRenewalTime.Sub(now))
+			}
+		}
+	}
+
+	certDomains := certcrypto.ExtractDomains(cert)
+
+	if ariRenewalTime == nil && !ctx.Bool("dry-run") && !certificateChanged(ctx, cert, domain, merge(certDomains, domains)) &&
+		!needRenewal(cert, domain, ctx.Int("days"), ctx.Float64("remaining-ratio")) {
+		return printCertificateOutput(ctx, certsStorage, domain, false)
 	}
 
 	// This is just meant to be informal for the user.
 	timeLeft := cert.NotAfter.Sub(time.Now().UTC())
 	log.Infof("[%s] acme: Trying renewal with %d hours remaining", domain, int(timeLeft.Hours()))
 
-	certDomains := certcrypto.ExtractDomains(cert)
-
 	var privateKey crypto.PrivateKey
 	if ctx.Bool("reuse-key") {
-		keyBytes, errR := certsStorage.ReadFile(domain, ".key")
-		if errR != nil {
-			log.Fatalf("Error while loading the private key for domain %s\n\t%v", domain, errR)
-		}
-
-		privateKey, errR = certcrypto.ParsePEMPrivateKey(keyBytes)
-		if errR != nil {
-			return errR
+		privateKey, err = readReusedPrivateKey(certsStorage, domain)
+		if err != nil {
+			log.Fatalf("Error while loading the private key for domain %s\n\t%v", domain, err)
 		}
 	}
 
 	// https://github.com/go-acme/lego/issues/1656
 	// https://github.com/certbot/certbot/blob/284023a1b7672be2bd4018dd7623b3b92197d4b0/certbot/certbot/_internal/renewal.py#L435-L440
-	if !isatty.IsTerminal(os.Stdout.Fd()) && !ctx.Bool("no-random-sleep") {
+	if ariRenewalTime == nil && !isatty.IsTerminal(os.Stdout.Fd()) && !ctx.Bool("no-random-sleep") && !ctx.Bool("dry-run") {
 		// https://github.com/certbot/certbot/blob/284023a1b7672be2bd4018dd7623b3b92197d4b0/certbot/certbot/_internal/renewal.py#L472
 		const jitter = 8 * time.Minute
 		rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -161,21 +207,46 @@ func renewForDomains(ctx *cli.Context, client *lego.Client, certsStorage *Certif
 		MustStaple:                     ctx.Bool("must-staple"),
 		PreferredChain:                 ctx.String("preferred-chain"),
 		AlwaysDeactivateAuthorizations: ctx.Bool("always-deactivate-authorizations"),
+		Profile:                        ctx.String("profile"),
+		DryRun:                         ctx.Bool("dry-run"),
+	}
+
+	if ctx.Bool("ari-enable") {
+		request.ReplacesCertID, err = certificate.MakeARICertID(cert)
+		if err != nil {
+			log.Fatalf("Error while construction the ARI CertID for domain %s\n\t%v", domain, err)
+		}
 	}
+
 	certRes, err := client.Certificate.Obtain(request)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if ctx.Bool("dry-run") {
+		log.Infof("[%s] dry-run: no certificate has been issued, nothing has been saved and the hook has not been run", domain)
+		return printDryRunOutput(ctx, domain)
+	}
+
 	certsStorage.SaveResource(certRes)
 
+	err = printCertificateOutput(ctx, certsStorage, domain, true)
+	if err != nil {
+		return err
+	}
+
+	err = printTLSARecords(ctx, certsStorage, domain)
+	if err != nil {
+		return err
+	}
+
 	meta[renewEnvCertDomain] = domain
 	meta[renewEnvCertPath] = certsStorage.GetFileName(domain, ".crt")
 	meta[renewEnvCertKeyPath] = certsStorage.GetFileName(domain, ".key")
 	meta[renewEnvCertPEMPath] = certsStorage.GetFileName(domain, ".pem")
 	meta[renewEnvCertPFXPath] = certsStorage.GetFileName(domain, ".pfx")
 
-	return launchHook(ctx.String("renew-hook"), meta)
+	return launchHook(ctx.String("renew-hook"), meta, messageWriter(ctx))
 }
 
 func renewForCSR(ctx *cli.Context, client *lego.Client, certsStorage *CertificatesStorage, bundle bool, meta map[string]string) error {
@@ -196,38 +267,124 @@ func renewForCSR(ctx *cli.Context, client *lego.Client, certsStorage *Certificat
 
 	cert := certificates[0]
 
-	if !needRenewal(cert, domain, ctx.Int("days")) {
-		return nil
+	var ariRenewalTime *time.Time
+	if ctx.Bool("ari-enable") {
+		ariRenewalTime = getARIRenewalTime(ctx, cert, domain, client)
+		if ariRenewalTime != nil {
+			now := time.Now().UTC()
+
+			// Figure out if we need to sleep before renewing.
+			if ariRenewalTime.After(now) {
+				log.Infof("[%s] Sleeping %s until renewal time %s", domain, ariRenewalTime.Sub(now), ariRenewalTime)
+				time.Sleep(ariRenewalTime.Sub(now))
+			}
+		}
+	}
+
+	if ariRenewalTime == nil && !ctx.Bool("dry-run") && !needRenewal(cert, domain, ctx.Int("days"), ctx.Float64("remaining-ratio")) {
+		return printCertificateOutput(ctx, certsStorage, domain, false)
 	}
 
 	// This is just meant to be informal for the user.
 	timeLeft := cert.NotAfter.Sub(time.Now().UTC())
 	log.Infof("[%s] acme: Trying renewal with %d hours remaining", domain, int(timeLeft.Hours()))
 
-	certRes, err := client.Certificate.ObtainForCSR(certificate.ObtainForCSRRequest{
+	request := certificate.ObtainForCSRRequest{
 		CSR:                            csr,
 		Bundle:                         bundle,
 		PreferredChain:                 ctx.String("preferred-chain"),
 		AlwaysDeactivateAuthorizations: ctx.Bool("always-deactivate-authorizations"),
-	})
+		Profile:                        ctx.String("profile"),
+		DryRun:                         ctx.Bool("dry-run"),
+	}
+
+	if ctx.Bool("ari-enable") {
+		request.ReplacesCertID, err = certificate.MakeARICertID(cert)
+		if err != nil {
+			log.Fatalf("Error while construction the ARI CertID for domain %s\n\t%v", domain, err)
+		}
+	}
+
+	certRes, err := client.Certificate.ObtainForCSR(request)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if ctx.Bool("dry-run") {
+		log.Infof("[%s] dry-run: no certificate has been issued, nothing has been saved and the hook has not been run", domain)
+		return printDryRunOutput(ctx, domain)
+	}
+
 	certsStorage.SaveResource(certRes)
 
+	err = printCertificateOutput(ctx, certsStorage, domain, true)
+	if err != nil {
+		return err
+	}
+
+	err = printTLSARecords(ctx, certsStorage, domain)
+	if err != nil {
+		return err
+	}
+
 	meta[renewEnvCertDomain] = domain
 	meta[renewEnvCertPath] = certsStorage.GetFileName(domain, ".crt")
 	meta[renewEnvCertKeyPath] = certsStorage.GetFileName(domain, ".key")
 
-	return launchHook(ctx.String("renew-hook"), meta)
+	return launchHook(ctx.String("renew-hook"), meta, messageWriter(ctx))
+}
+
+// readReusedPrivateKey reads the private key of the previous certificate, to reuse it for the new certificate.
+// If the private key is missing, a warning is logged and nil is returned: a new private key will be generated.
+func readReusedPrivateKey(certsStorage *CertificatesStorage, domain string) (crypto.PrivateKey, error) {
+	keyBytes, err := certsStorage.ReadFile(domain, ".key")
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Warnf("[%s] The private key of the previous certificate is missing, a new private key will be generated.", domain)
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return certcrypto.ParsePEMPrivateKey(keyBytes)
+}
+
+// certificateChanged checks if the certificate doesn't match the requested domains,
+// or the requested key type (only when the key type is explicitly set and the key is not reused).
+func certificateChanged(ctx *cli.Context, x509Cert *x509.Certificate, domain string, domains []string) bool {
+	var keyType certcrypto.KeyType
+	if ctx.IsSet("key-type") && !ctx.Bool("reuse-key") {
+		keyType = getKeyType(ctx)
+	}
+
+	err := certificate.MatchRequest(x509Cert, domains, keyType)
+	if err != nil {
+		log.Infof("[%s] %v: renewal needed.", domain, err)
+		return true
+	}
+
+	return false
 }
 
-func needRenewal(x509Cert *x509.Certificate, domain string, days int) bool {
+// needRenewal checks if the certificate should be renewed,
+// based on the ratio of its validity period left (if ratio is greater than 0) or on the number of days left.
+func needRenewal(x509Cert *x509.Certificate, domain string, days int, ratio float64) bool {
 	if x509Cert.IsCA {
 		log.Fatalf("[%s] Certificate bundle starts with a CA certificate", domain)
 	}
 
+	if ratio > 0 {
+		renewalTime := certificate.RenewalTimeByRatio(x509Cert, ratio)
+		if time.Now().Before(renewalTime) {
+			log.Printf("[%s] The certificate expires at %s, the renewal is planned at %s (%.2f of the validity period left): no renewal.",
+				domain, x509Cert.NotAfter.Format(time.RFC3339), renewalTime.Format(time.RFC3339), ratio)
+			return false
+		}
+
+		return true
+	}
+
 	if days >= 0 {
 		notAfter := int(time.Until(x509Cert.NotAfter).Hours() / 24.0)
 		if notAfter > days {
@@ -240,6 +397,44 @@ func needRenewal(x509Cert *x509.Certificate, domain string, days int) bool {
 	return true
 }
 
+// getARIRenewalTime checks if the certificate needs to be renewed using the renewalInfo endpoint.
+// It returns nil when the renewal is not needed, or when the server does not support ARI:
+// the expiry-based logic is used in this case.
+func getARIRenewalTime(ctx *cli.Context, cert *x509.Certificate, domain string, client *lego.Client) *time.Time {
+	if cert.IsCA {
+		log.Fatalf("[%s] Certificate bundle starts with a CA certificate", domain)
+	}
+
+	renewalInfo, err := client.Certificate.GetRenewalInfo(certificate.RenewalInfoRequest{Cert: cert})
+	if err != nil {
+		if errors.Is(err, api.ErrNoARI) {
+			// The server does not advertise a renewal info endpoint.
+			log.Warnf("[%s] acme: %v", domain, err)
+			return nil
+		}
+
+		log.Warnf("[%s] acme: calling renewal info endpoint: %v", domain, err)
+
+		return nil
+	}
+
+	now := time.Now().UTC()
+
+	renewalTime := renewalInfo.ShouldRenewAt(now, ctx.Duration("ari-wait-to-renew-duration"))
+	if renewalTime == nil {
+		log.Infof("[%s] acme: renewalInfo endpoint indicates that renewal is not needed", domain)
+		return nil
+	}
+
+	log.Infof("[%s] acme: renewalInfo endpoint indicates that renewal is needed", domain)
+
+	if renewalInfo.ExplanationURL != "" {
+		log.Infof("[%s] acme: renewalInfo endpoint provided an explanation: %s", domain, renewalInfo.ExplanationURL)
+	}
+
+	return renewalTime
+}
+
 func merge(prevDomains, nextDomains []string) []string {
 	for _, next := range nextDomains {
 		var found bool
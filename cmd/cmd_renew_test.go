@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"crypto/x509"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_merge(t *testing.T) {
@@ -110,9 +113,91 @@ func Test_needRenewal(t *testing.T) {
 	for _, test := range testCases {
 		test := test
 		t.Run(test.desc, func(t *testing.T) {
-			actual := needRenewal(test.x509Cert, "foo.com", test.days)
+			actual := needRenewal(test.x509Cert, "foo.com", test.days, 0)
 
 			assert.Equal(t, test.expected, actual)
 		})
 	}
 }
+
+func Test_needRenewal_ratio(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		desc     string
+		x509Cert *x509.Certificate
+		expected bool
+	}{
+		{
+			desc: "90-day certificate, 31 days left",
+			x509Cert: &x509.Certificate{
+				NotBefore: now.Add(-59 * 24 * time.Hour),
+				NotAfter:  now.Add(31 * 24 * time.Hour),
+			},
+			expected: false,
+		},
+		{
+			desc: "90-day certificate, 29 days left",
+			x509Cert: &x509.Certificate{
+				NotBefore: now.Add(-61 * 24 * time.Hour),
+				NotAfter:  now.Add(29 * 24 * time.Hour),
+			},
+			expected: true,
+		},
+		{
+			desc: "6-day certificate, 3 days left",
+			x509Cert: &x509.Certificate{
+				NotBefore: now.Add(-3 * 24 * time.Hour),
+				NotAfter:  now.Add(3 * 24 * time.Hour),
+			},
+			expected: false,
+		},
+		{
+			desc: "6-day certificate, 1 day left",
+			x509Cert: &x509.Certificate{
+				NotBefore: now.Add(-5 * 24 * time.Hour),
+				NotAfter:  now.Add(24 * time.Hour),
+			},
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			// the number of days is ignored when a ratio is defined.
+			actual := needRenewal(test.x509Cert, "foo.com", 30, 1.0/3)
+
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func Test_readReusedPrivateKey(t *testing.T) {
+	storage := &CertificatesStorage{
+		rootPath: filepath.Join(t.TempDir(), baseCertificatesFolderName),
+	}
+
+	storage.CreateRootFolder()
+
+	// missing private key: a new one will be generated.
+	privateKey, err := readReusedPrivateKey(storage, "example.com")
+	require.NoError(t, err)
+	assert.Nil(t, privateKey)
+
+	key, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	err = storage.WriteFile("example.com", ".key", certcrypto.PEMEncode(key))
+	require.NoError(t, err)
+
+	privateKey, err = readReusedPrivateKey(storage, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, key, privateKey)
+
+	err = storage.WriteFile("example.com", ".key", []byte("invalid"))
+	require.NoError(t, err)
+
+	_, err = readReusedPrivateKey(storage, "example.com")
+	require.Error(t, err)
+}
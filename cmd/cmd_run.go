@@ -18,6 +18,8 @@ func createRun() *cli.Command {
 		Name:  "run",
 		Usage: "Register an account, then create and install a certificate",
 		Before: func(ctx *cli.Context) error {
+			setupJSONOutput(ctx)
+
 			// we require either domains or csr, but not both
 			hasDomains := len(ctx.StringSlice("domains")) > 0
 			hasCsr := len(ctx.String("csr")) > 0
@@ -38,7 +40,7 @@ func createRun() *cli.Command {
 			&cli.BoolFlag{
 				Name: "must-staple",
 				Usage: "Include the OCSP must staple TLS extension in the CSR and generated certificate." +
-					" Only works if the CSR is generated by lego.",
+					" Only works if the CSR is generated by lego. Some CAs don't support this extension.",
 			},
 			&cli.StringFlag{
 				Name:  "run-hook",
@@ -46,13 +48,30 @@ func createRun() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name: "preferred-chain",
-				Usage: "If the CA offers multiple certificate chains, prefer the chain with an issuer matching this Subject Common Name." +
+				Usage: "If the CA offers multiple certificate chains, prefer the chain with an issuer matching this Subject Common Name (or distinguished name, e.g. \"CN=ISRG Root X1,O=Internet Security Research Group,C=US\")." +
 					" If no match, the default offered chain will be used.",
 			},
+			&cli.StringFlag{
+				Name:  "profile",
+				Usage: "If the CA offers multiple certificate profiles (draft-aaron-acme-profiles), choose this one.",
+			},
+			&cli.BoolFlag{
+				Name: "dry-run",
+				Usage: "Check the configuration without issuing a certificate: the order is created and the challenges are solved, but the order is not finalized." +
+					" The account is registered if needed.",
+			},
 			&cli.StringFlag{
 				Name:  "always-deactivate-authorizations",
 				Usage: "Force the authorizations to be relinquished even if the certificate request was successful.",
 			},
+			&cli.BoolFlag{
+				Name:  "tlsa",
+				Usage: "Print the DANE-EE TLSA records (3 1 1 and 3 1 2) of the new certificate.",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the result (domains, certificate paths, expiry, issuer) as a JSON object on stdout, and the errors as JSON objects on stderr.",
+			},
 		},
 	}
 }
@@ -85,7 +104,7 @@ func run(ctx *cli.Context) error {
 			log.Fatal(err)
 		}
 
-		fmt.Printf(rootPathWarningMessage, accountsStorage.GetRootPath())
+		fmt.Fprintf(messageWriter(ctx), rootPathWarningMessage, accountsStorage.GetRootPath())
 	}
 
 	certsStorage := NewCertificatesStorage(ctx)
@@ -98,8 +117,23 @@ func run(ctx *cli.Context) error {
 		log.Fatalf("Could not obtain certificates:\n\t%v", err)
 	}
 
+	if ctx.Bool("dry-run") {
+		log.Infof("[%s] dry-run: no certificate has been issued, nothing has been saved and the hook has not been run", cert.Domain)
+		return printDryRunOutput(ctx, cert.Domain)
+	}
+
 	certsStorage.SaveResource(cert)
 
+	err = printCertificateOutput(ctx, certsStorage, cert.Domain, true)
+	if err != nil {
+		return err
+	}
+
+	err = printTLSARecords(ctx, certsStorage, cert.Domain)
+	if err != nil {
+		return err
+	}
+
 	meta := map[string]string{
 		renewEnvAccountEmail: account.Email,
 		renewEnvCertDomain:   cert.Domain,
@@ -107,7 +141,7 @@ func run(ctx *cli.Context) error {
 		renewEnvCertKeyPath:  certsStorage.GetFileName(cert.Domain, ".key"),
 	}
 
-	return launchHook(ctx.String("run-hook"), meta)
+	return launchHook(ctx.String("run-hook"), meta, messageWriter(ctx))
 }
 
 func handleTOS(ctx *cli.Context, client *lego.Client) bool {
@@ -119,8 +153,10 @@ func handleTOS(ctx *cli.Context, client *lego.Client) bool {
 	reader := bufio.NewReader(os.Stdin)
 	log.Printf("Please review the TOS at %s", client.GetToSURL())
 
+	w := messageWriter(ctx)
+
 	for {
-		fmt.Println("Do you accept the TOS? Y/n")
+		fmt.Fprintln(w, "Do you accept the TOS? Y/n")
 		text, err := reader.ReadString('\n')
 		if err != nil {
 			log.Fatalf("Could not read from console: %v", err)
@@ -133,7 +169,7 @@ func handleTOS(ctx *cli.Context, client *lego.Client) bool {
 		case "n", "N":
 			return false
 		default:
-			fmt.Println("Your input was invalid. Please answer with one of Y/y, n/N or by pressing enter.")
+			fmt.Fprintln(w, "Your input was invalid. Please answer with one of Y/y, n/N or by pressing enter.")
 		}
 	}
 }
@@ -174,6 +210,8 @@ func obtainCertificate(ctx *cli.Context, client *lego.Client) (*certificate.Reso
 			MustStaple:                     ctx.Bool("must-staple"),
 			PreferredChain:                 ctx.String("preferred-chain"),
 			AlwaysDeactivateAuthorizations: ctx.Bool("always-deactivate-authorizations"),
+			Profile:                        ctx.String("profile"),
+			DryRun:                         ctx.Bool("dry-run"),
 		}
 		return client.Certificate.Obtain(request)
 	}
@@ -190,5 +228,7 @@ func obtainCertificate(ctx *cli.Context, client *lego.Client) (*certificate.Reso
 		Bundle:                         bundle,
 		PreferredChain:                 ctx.String("preferred-chain"),
 		AlwaysDeactivateAuthorizations: ctx.Bool("always-deactivate-authorizations"),
+		Profile:                        ctx.String("profile"),
+		DryRun:                         ctx.Bool("dry-run"),
 	})
 }
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+const configFileMetadataKey = "configFile"
+
+// configFile the content of a configuration file.
+//
+// The keys are the names of the flags (e.g. `domains`, `key-type`, `dns`),
+// the options of a command are defined inside a section named like the command (e.g. `run`, `renew`):
+//
+//	domains = ["example.com", "www.example.com"]
+//	email = "you@example.com"
+//	dns = "cloudflare"
+//
+//	[run]
+//	run-hook = "./hook.sh"
+type configFile map[string]interface{}
+
+// readConfigFile reads a YAML (`.yml`, `.yaml`) or TOML (`.toml`) configuration file.
+func readConfigFile(filename string) (configFile, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+
+	cfg := configFile{}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yml", ".yaml":
+		var raw map[string]interface{}
+
+		err = yaml.NewDecoder(bytes.NewReader(content)).Decode(&raw)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("config file: %s: %w", filename, err)
+		}
+
+		for k, v := range raw {
+			cfg[k] = normalizeYAML(v)
+		}
+
+	case ".toml":
+		_, err = toml.Decode(string(content), &cfg)
+		if err != nil {
+			return nil, fmt.Errorf("config file: %s: %w", filename, err)
+		}
+
+	default:
+		return nil, fmt.Errorf("config file: %s: unsupported format, the extension must be .yml, .yaml, or .toml", filename)
+	}
+
+	return cfg, nil
+}
+
+// validate checks that all the keys are flags of the application or of the commands.
+func (c configFile) validate(app *cli.App) error {
+	for _, key := range sortedKeys(c) {
+		if findFlag(app.Flags, key) != nil {
+			continue
+		}
+
+		command := app.Command(key)
+		if command == nil {
+			return fmt.Errorf("config file: unknown option %q", key)
+		}
+
+		section, ok := c[key].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("config file: %q must be a section", key)
+		}
+
+		for _, name := range sortedKeys(section) {
+			if findFlag(command.Flags, name) == nil {
+				return fmt.Errorf("config file: unknown option %q in the section %q", name, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// apply sets the values of the flags which are not already set (command line or environment variables).
+func (c configFile) apply(ctx *cli.Context, flags []cli.Flag) error {
+	for _, name := range sortedKeys(c) {
+		flag := findFlag(flags, name)
+		if flag == nil {
+			// command section, or option of another level.
+			continue
+		}
+
+		primary := flag.Names()[0]
+		if ctx.IsSet(primary) {
+			continue
+		}
+
+		values, err := toFlagValues(flag, c[name])
+		if err != nil {
+			return fmt.Errorf("config file: option %q: %w", name, err)
+		}
+
+		for _, value := range values {
+			err = ctx.Set(primary, value)
+			if err != nil {
+				return fmt.Errorf("config file: option %q: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// section returns the options of a command.
+func (c configFile) section(name string) configFile {
+	section, ok := c[name].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	return section
+}
+
+// applyConfigFile wraps the Before of the command to apply the options of the command section of the configuration file.
+func applyConfigFile(command *cli.Command) *cli.Command {
+	before := command.Before
+
+	command.Before = func(ctx *cli.Context) error {
+		if cfg, ok := ctx.App.Metadata[configFileMetadataKey].(configFile); ok {
+			err := cfg.section(command.Name).apply(ctx, command.Flags)
+			if err != nil {
+				return err
+			}
+		}
+
+		if before == nil {
+			return nil
+		}
+
+		return before(ctx)
+	}
+
+	return command
+}
+
+func toFlagValues(flag cli.Flag, raw interface{}) ([]string, error) {
+	list, isList := raw.([]interface{})
+
+	_, isSlice := flag.(*cli.StringSliceFlag)
+
+	switch {
+	case isList && !isSlice:
+		return nil, errors.New("a single value is expected")
+
+	case isList:
+		var values []string
+		for _, v := range list {
+			if _, ok := v.(map[string]interface{}); ok {
+				return nil, errors.New("invalid value")
+			}
+
+			values = append(values, fmt.Sprint(v))
+		}
+
+		return values, nil
+
+	default:
+		if _, ok := raw.(map[string]interface{}); ok {
+			return nil, errors.New("invalid value")
+		}
+
+		return []string{fmt.Sprint(raw)}, nil
+	}
+}
+
+func findFlag(flags []cli.Flag, name string) cli.Flag {
+	for _, flag := range flags {
+		for _, n := range flag.Names() {
+			if n == name {
+				return flag
+			}
+		}
+	}
+
+	return nil
+}
+
+// normalizeYAML converts the maps decoded by the YAML decoder (map[interface{}]interface{}) to map[string]interface{}.
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalizeYAML(e)
+		}
+
+		return m
+
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeYAML(e)
+		}
+
+		return v
+
+	default:
+		return value
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
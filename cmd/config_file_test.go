@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestConfigFile(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		filename string
+	}{
+		{desc: "YAML", filename: "./fixtures/config.yml"},
+		{desc: "TOML", filename: "./fixtures/config.toml"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			options := map[string]interface{}{}
+
+			app := newConfigTestApp(t, func(ctx *cli.Context) error {
+				options["domains"] = ctx.StringSlice("domains")
+				options["email"] = ctx.String("email")
+				options["key-type"] = ctx.String("key-type")
+				options["dns"] = ctx.String("dns")
+				options["dns.resolvers"] = ctx.StringSlice("dns.resolvers")
+				options["accept-tos"] = ctx.Bool("accept-tos")
+				options["run-hook"] = ctx.String("run-hook")
+				options["preferred-chain"] = ctx.String("preferred-chain")
+				return nil
+			})
+
+			// the options of the command line override the values of the file.
+			err := app.Run([]string{"lego", "--path", t.TempDir(), "--config", test.filename, "--email", "cli@example.com", "run", "--run-hook", "./cli-hook.sh"})
+			require.NoError(t, err)
+
+			expected := map[string]interface{}{
+				"domains":         []string{"example.com", "www.example.com"},
+				"email":           "cli@example.com",
+				"key-type":        "ec384",
+				"dns":             "cloudflare",
+				"dns.resolvers":   []string{"1.1.1.1:53"},
+				"accept-tos":      true,
+				"run-hook":        "./cli-hook.sh",
+				"preferred-chain": "ISRG Root X1",
+			}
+
+			assert.Equal(t, expected, options)
+		})
+	}
+}
+
+func TestConfigFile_validate(t *testing.T) {
+	app := newConfigTestApp(t, nil)
+
+	cfg, err := readConfigFile("./fixtures/config.yml")
+	require.NoError(t, err)
+
+	require.NoError(t, cfg.validate(app))
+
+	cfg, err = readConfigFile("./fixtures/config_unknown.yml")
+	require.NoError(t, err)
+
+	err = cfg.validate(app)
+	require.EqualError(t, err, `config file: unknown option "run-hoook" in the section "run"`)
+
+	err = configFile{"domain": "example.com"}.validate(app)
+	require.EqualError(t, err, `config file: unknown option "domain"`)
+
+	err = configFile{"run": "example.com"}.validate(app)
+	require.EqualError(t, err, `config file: "run" must be a section`)
+}
+
+func TestConfigFile_invalidValue(t *testing.T) {
+	app := newConfigTestApp(t, func(_ *cli.Context) error { return nil })
+
+	app.Before = func(ctx *cli.Context) error {
+		return configFile{"email": []interface{}{"a@example.com", "b@example.com"}}.apply(ctx, ctx.App.Flags)
+	}
+
+	err := app.Run([]string{"lego", "run"})
+	require.EqualError(t, err, `config file: option "email": a single value is expected`)
+}
+
+func Test_readConfigFile_unsupportedFormat(t *testing.T) {
+	_, err := readConfigFile("./fixtures/config.json")
+	require.EqualError(t, err, "config file: open ./fixtures/config.json: no such file or directory")
+
+	_, err = readConfigFile("./config_file.go")
+	require.EqualError(t, err, "config file: ./config_file.go: unsupported format, the extension must be .yml, .yaml, or .toml")
+}
+
+func newConfigTestApp(t *testing.T, action cli.ActionFunc) *cli.App {
+	t.Helper()
+
+	app := cli.NewApp()
+	app.Flags = CreateFlags(t.TempDir())
+	app.Before = Before
+	app.Commands = CreateCommands()
+
+	if action != nil {
+		app.Command("run").Action = action
+	}
+
+	return app
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/go-acme/lego/v4/lego"
 	"github.com/urfave/cli/v2"
 	"software.sslmate.com/src/go-pkcs12"
@@ -13,6 +15,11 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Aliases: []string{"d"},
 			Usage:   "Add a domain to the process. Can be specified multiple times.",
 		},
+		&cli.StringFlag{
+			Name:    "config",
+			EnvVars: []string{"LEGO_CONFIG"},
+			Usage:   "Configuration file (YAML or TOML) defining the options (e.g. domains, key type, DNS provider, hooks). The options of the command line override the values of the file.",
+		},
 		&cli.StringFlag{
 			Name:    "server",
 			Aliases: []string{"s"},
@@ -50,7 +57,7 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Name:    "key-type",
 			Aliases: []string{"k"},
 			Value:   "ec256",
-			Usage:   "Key type to use for private keys. Supported: rsa2048, rsa4096, rsa8192, ec256, ec384.",
+			Usage:   "Key type to use for private keys. Supported: rsa2048, rsa4096, rsa8192, ec256, ec384, ec521, ed25519. Ed25519 is not supported by all the CAs.",
 		},
 		&cli.StringFlag{
 			Name:  "filename",
@@ -76,6 +83,10 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Usage: "Validate against this HTTP header when solving HTTP based challenges behind a reverse proxy.",
 			Value: "Host",
 		},
+		&cli.StringFlag{
+			Name:  "http.base-path",
+			Usage: "Prefix of the path served by the built-in server, when a reverse proxy rewrites the /.well-known/acme-challenge/ path (e.g. /lego).",
+		},
 		&cli.StringFlag{
 			Name: "http.webroot",
 			Usage: "Set the webroot folder to use for HTTP based challenges to write directly in a file in .well-known/acme-challenge." +
@@ -109,6 +120,25 @@ func CreateFlags(defaultPath string) []cli.Flag {
 				" Supported: host:port." +
 				" The default is to use the system resolvers, or Google's DNS resolvers if the system's cannot be determined.",
 		},
+		&cli.BoolFlag{
+			Name: "dns.resolvers-only",
+			Usage: "Use only the resolvers defined by '--dns.resolvers' for the propagation check, including the resolution of the authoritative DNS servers." +
+				" The system resolvers are never used (e.g. split-horizon DNS).",
+		},
+		&cli.IntFlag{
+			Name:  "dns.cleanup-retries",
+			Usage: "Set the maximum number of retries of the clean up of the TXT record when the DNS provider fails.",
+		},
+		&cli.DurationFlag{
+			Name:  "dns.cleanup-retry-interval",
+			Usage: "Set the delay before the first retry of the clean up of the TXT record (doubled for each following retry).",
+			Value: 2 * time.Second,
+		},
+		&cli.IntFlag{
+			Name: "dns.concurrency",
+			Usage: "Set the maximum number of domains for which the DNS propagation is checked and the challenge validated at the same time." +
+				" All the TXT records are created first. By default, the domains are handled one after the other.",
+		},
 		&cli.IntFlag{
 			Name:  "http-timeout",
 			Usage: "Set the HTTP timeout value to a specific value in seconds.",
@@ -118,6 +148,10 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Usage: "Set the DNS timeout value to a specific value in seconds. Used only when performing authoritative name servers queries.",
 			Value: 10,
 		},
+		&cli.IntFlag{
+			Name:  "dns-retries",
+			Usage: "Set the number of retries of a DNS query without response (e.g. a dropped UDP packet), before querying the next name server.",
+		},
 		&cli.BoolFlag{
 			Name:  "pem",
 			Usage: "Generate a .pem file by concatenating the .key and .crt files together.",
@@ -136,9 +170,27 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Usage: "Set the certificate timeout value to a specific value in seconds. Only used when obtaining certificates.",
 			Value: 30,
 		},
+		&cli.StringSliceFlag{
+			Name:  "caa.identifier",
+			Usage: "Enable the CAA pre-flight check with the issuer domain name of the CA (e.g. letsencrypt.org). Can be specified multiple times.",
+		},
+		&cli.BoolFlag{
+			Name: "caa.directory",
+			Usage: "Enable the CAA pre-flight check with the issuer domain names advertised by the CA in its directory (caaIdentities)." +
+				" The values of '--caa.identifier' are used if the CA doesn't advertise them.",
+		},
+		&cli.BoolFlag{
+			Name:  "caa.strict",
+			Usage: "Abort the issuance when the CAA records don't authorize the CA, instead of logging a warning. Requires --caa.identifier or --caa.directory.",
+		},
 		&cli.StringFlag{
 			Name:  "user-agent",
 			Usage: "Add to the user-agent sent to the CA to identify an application embedding lego-cli",
 		},
+		&cli.StringFlag{
+			Name:    "user-agent-suffix",
+			Usage:   "Append to the user-agent sent to the CA and, through the environment variable, by the DNS providers that support it.",
+			EnvVars: []string{"LEGO_USER_AGENT_SUFFIX"},
+		},
 	}
 }
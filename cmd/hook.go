@@ -4,13 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 	"time"
 )
 
-func launchHook(hook string, meta map[string]string) error {
+// launchHook runs the hook, the output of the hook is written to w.
+func launchHook(hook string, meta map[string]string, w io.Writer) error {
 	if hook == "" {
 		return nil
 	}
@@ -26,7 +28,7 @@ func launchHook(hook string, meta map[string]string) error {
 	output, err := cmdCtx.CombinedOutput()
 
 	if len(output) > 0 {
-		fmt.Println(string(output))
+		fmt.Fprintln(w, string(output))
 	}
 
 	if errors.Is(ctxCmd.Err(), context.DeadlineExceeded) {
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+// certificateOutput is the result of the run and renew commands, printed with the --json flag.
+type certificateOutput struct {
+	Domain  string   `json:"domain"`
+	Domains []string `json:"domains,omitempty"`
+
+	// Issued is true when a new certificate has been obtained (i.e. false when the renewal was not needed).
+	Issued bool `json:"issued"`
+	DryRun bool `json:"dryRun,omitempty"`
+
+	CertificatePath string `json:"certificatePath,omitempty"`
+	KeyPath         string `json:"keyPath,omitempty"`
+	IssuerPath      string `json:"issuerPath,omitempty"`
+	PEMPath         string `json:"pemPath,omitempty"`
+	PFXPath         string `json:"pfxPath,omitempty"`
+
+	NotBefore *time.Time `json:"notBefore,omitempty"`
+	NotAfter  *time.Time `json:"notAfter,omitempty"`
+	Issuer    string     `json:"issuer,omitempty"`
+
+	// TLSA contains the DANE-EE TLSA records of the certificate (with the --tlsa flag).
+	TLSA []string `json:"tlsa,omitempty"`
+}
+
+// errorOutput is an error printed with the --json flag.
+type errorOutput struct {
+	Error string `json:"error"`
+}
+
+// newCertificateOutput creates the output from the stored certificate of a domain.
+func newCertificateOutput(certsStorage *CertificatesStorage, domain string, issued bool) (*certificateOutput, error) {
+	certificates, err := certsStorage.ReadCertificate(domain, ".crt")
+	if err != nil {
+		return nil, fmt.Errorf("error while loading the certificate for domain %s: %w", domain, err)
+	}
+
+	cert := certificates[0]
+
+	output := &certificateOutput{
+		Domain:          domain,
+		Domains:         certcrypto.ExtractDomains(cert),
+		Issued:          issued,
+		CertificatePath: certsStorage.GetFileName(domain, ".crt"),
+		NotBefore:       &cert.NotBefore,
+		NotAfter:        &cert.NotAfter,
+		Issuer:          cert.Issuer.String(),
+	}
+
+	if certsStorage.ExistsFile(domain, ".key") {
+		output.KeyPath = certsStorage.GetFileName(domain, ".key")
+	}
+
+	if certsStorage.ExistsFile(domain, ".issuer.crt") {
+		output.IssuerPath = certsStorage.GetFileName(domain, ".issuer.crt")
+	}
+
+	if certsStorage.ExistsFile(domain, ".pem") {
+		output.PEMPath = certsStorage.GetFileName(domain, ".pem")
+	}
+
+	if certsStorage.ExistsFile(domain, ".pfx") {
+		output.PFXPath = certsStorage.GetFileName(domain, ".pfx")
+	}
+
+	return output, nil
+}
+
+// printCertificateOutput prints, with the --json flag, the result of the command for the stored certificate of a domain.
+func printCertificateOutput(ctx *cli.Context, certsStorage *CertificatesStorage, domain string, issued bool) error {
+	if !ctx.Bool("json") {
+		return nil
+	}
+
+	output, err := newCertificateOutput(certsStorage, domain, issued)
+	if err != nil {
+		return err
+	}
+
+	if ctx.Bool("tlsa") {
+		output.TLSA, err = getTLSARecords(certsStorage, domain)
+		if err != nil {
+			return err
+		}
+	}
+
+	return printJSON(os.Stdout, output)
+}
+
+// printTLSARecords prints, with the --tlsa flag, the DANE-EE TLSA records of the stored certificate of a domain.
+// With the --json flag, the records are part of the JSON output.
+func printTLSARecords(ctx *cli.Context, certsStorage *CertificatesStorage, domain string) error {
+	if !ctx.Bool("tlsa") || ctx.Bool("json") {
+		return nil
+	}
+
+	records, err := getTLSARecords(certsStorage, domain)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("TLSA records for %s:\n", domain)
+
+	for _, record := range records {
+		fmt.Println("  " + record)
+	}
+
+	return nil
+}
+
+func getTLSARecords(certsStorage *CertificatesStorage, domain string) ([]string, error) {
+	certificates, err := certsStorage.ReadCertificate(domain, ".crt")
+	if err != nil {
+		return nil, fmt.Errorf("error while loading the certificate for domain %s: %w", domain, err)
+	}
+
+	var records []string
+	for _, record := range certcrypto.DANEEERecords(certificates[0]) {
+		records = append(records, record.String())
+	}
+
+	return records, nil
+}
+
+// printDryRunOutput prints, with the --json flag, the result of a dry-run.
+func printDryRunOutput(ctx *cli.Context, domain string) error {
+	if !ctx.Bool("json") {
+		return nil
+	}
+
+	return printJSON(os.Stdout, &certificateOutput{Domain: domain, DryRun: true})
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// messageWriter returns the writer of the messages intended to the user:
+// with the --json flag, stdout is reserved for the result of the command.
+func messageWriter(ctx *cli.Context) io.Writer {
+	if ctx.Bool("json") {
+		return os.Stderr
+	}
+
+	return os.Stdout
+}
+
+// setupJSONOutput enables, with the --json flag, the JSON output of the fatal errors.
+func setupJSONOutput(ctx *cli.Context) {
+	if !ctx.Bool("json") {
+		return
+	}
+
+	log.Logger = &jsonLogger{StdLogger: log.Logger, w: os.Stderr}
+}
+
+// jsonLogger writes the fatal errors as JSON objects, the other entries are written by the underlying logger.
+type jsonLogger struct {
+	log.StdLogger
+
+	w io.Writer
+}
+
+func (l *jsonLogger) Fatal(args ...interface{}) {
+	l.fatal(fmt.Sprint(args...))
+}
+
+func (l *jsonLogger) Fatalln(args ...interface{}) {
+	l.fatal(fmt.Sprintln(args...))
+}
+
+func (l *jsonLogger) Fatalf(format string, args ...interface{}) {
+	l.fatal(fmt.Sprintf(format, args...))
+}
+
+func (l *jsonLogger) fatal(msg string) {
+	_ = printJSON(l.w, errorOutput{Error: strings.TrimSpace(msg)})
+
+	os.Exit(1)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newCertificateOutput(t *testing.T) {
+	storage := &CertificatesStorage{
+		rootPath: filepath.Join(t.TempDir(), baseCertificatesFolderName),
+	}
+
+	storage.CreateRootFolder()
+
+	notAfter := time.Date(2030, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	certRes := &certificate.Resource{
+		Domain:            "example.com",
+		PrivateKey:        []byte("private key"),
+		Certificate:       generateTestCertificate(t, notAfter, "example.com", "www.example.com"),
+		IssuerCertificate: []byte("issuer certificate"),
+	}
+
+	err := storage.Save(certRes)
+	require.NoError(t, err)
+
+	output, err := newCertificateOutput(storage, "example.com", true)
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+
+	err = printJSON(buf, output)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	err = json.Unmarshal(buf.Bytes(), &result)
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"domain":          "example.com",
+		"domains":         []interface{}{"example.com", "www.example.com"},
+		"issued":          true,
+		"certificatePath": filepath.Join(storage.rootPath, "example.com.crt"),
+		"keyPath":         filepath.Join(storage.rootPath, "example.com.key"),
+		"issuerPath":      filepath.Join(storage.rootPath, "example.com.issuer.crt"),
+		"notBefore":       notAfter.Add(-90 * 24 * time.Hour).Format(time.RFC3339),
+		"notAfter":        notAfter.Format(time.RFC3339),
+		"issuer":          "CN=Test Issuer",
+	}
+
+	assert.Equal(t, expected, result)
+}
+
+func generateTestCertificate(t *testing.T, notAfter time.Time, domains ...string) []byte {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domains[0]},
+		Issuer:       pkix.Name{CommonName: "Test Issuer"},
+		DNSNames:     domains,
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+
+	parent := &x509.Certificate{Subject: pkix.Name{CommonName: "Test Issuer"}}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, privateKey.Public(), privateKey)
+	require.NoError(t, err)
+
+	return certcrypto.PEMEncode(certcrypto.DERCertificateBytes(der))
+}
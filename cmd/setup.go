@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
 	"github.com/go-acme/lego/v4/lego"
 	"github.com/go-acme/lego/v4/log"
 	"github.com/go-acme/lego/v4/registration"
@@ -40,8 +41,14 @@ func newClient(ctx *cli.Context, acc registration.User, keyType certcrypto.KeyTy
 	config.Certificate = lego.CertificateConfig{
 		KeyType: keyType,
 		Timeout: time.Duration(ctx.Int("cert.timeout")) * time.Second,
+		CAAPreflight: certificate.CAAPreflight{
+			Identifiers:  ctx.StringSlice("caa.identifier"),
+			Strict:       ctx.Bool("caa.strict"),
+			UseDirectory: ctx.Bool("caa.directory"),
+		},
 	}
 	config.UserAgent = getUserAgent(ctx)
+	config.UserAgentSuffix = ctx.String("user-agent-suffix")
 
 	if ctx.IsSet("http-timeout") {
 		config.HTTPClient.Timeout = time.Duration(ctx.Int("http-timeout")) * time.Second
@@ -73,6 +80,10 @@ func getKeyType(ctx *cli.Context) certcrypto.KeyType {
 		return certcrypto.EC256
 	case "EC384":
 		return certcrypto.EC384
+	case "EC521":
+		return certcrypto.EC521
+	case "ED25519":
+		return certcrypto.ED25519
 	}
 
 	log.Fatalf("Unsupported KeyType: %s", keyType)
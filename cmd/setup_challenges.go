@@ -70,12 +70,14 @@ func setupHTTPProvider(ctx *cli.Context) challenge.Provider {
 		if header := ctx.String("http.proxy-header"); header != "" {
 			srv.SetProxyHeader(header)
 		}
+		srv.SetBasePath(ctx.String("http.base-path"))
 		return srv
 	case ctx.Bool("http"):
 		srv := http01.NewProviderServer("", "")
 		if header := ctx.String("http.proxy-header"); header != "" {
 			srv.SetProxyHeader(header)
 		}
+		srv.SetBasePath(ctx.String("http.base-path"))
 		return srv
 	default:
 		log.Fatal("Invalid HTTP challenge options.")
@@ -112,15 +114,27 @@ func setupDNS(ctx *cli.Context, client *lego.Client) {
 	}
 
 	servers := ctx.StringSlice("dns.resolvers")
+	if ctx.Bool("dns.resolvers-only") && len(servers) == 0 {
+		log.Fatal("The flag `--dns.resolvers-only` requires the flag `--dns.resolvers`.")
+	}
+
 	err = client.Challenge.SetDNS01Provider(provider,
 		dns01.CondOption(len(servers) > 0,
 			dns01.AddRecursiveNameservers(dns01.ParseNameservers(ctx.StringSlice("dns.resolvers")))),
+		dns01.CondOption(ctx.Bool("dns.resolvers-only"),
+			dns01.UseExternalNameservers(servers)),
 		dns01.CondOption(ctx.Bool("dns.disable-cp"),
 			dns01.DisableCompletePropagationRequirement()),
 		dns01.CondOption(ctx.IsSet("dns-timeout"),
 			dns01.AddDNSTimeout(time.Duration(ctx.Int("dns-timeout"))*time.Second)),
+		dns01.CondOption(ctx.IsSet("dns-retries"),
+			dns01.AddDNSRetries(ctx.Int("dns-retries"))),
+		dns01.CondOption(ctx.Int("dns.cleanup-retries") > 0,
+			dns01.CleanUpRetry(ctx.Int("dns.cleanup-retries"), ctx.Duration("dns.cleanup-retry-interval"))),
 	)
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	client.Challenge.SetConcurrency(ctx.Int("dns.concurrency"))
 }
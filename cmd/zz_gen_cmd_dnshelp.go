@@ -50,6 +50,7 @@ func allDNSCodes() string {
 		"epik",
 		"exec",
 		"exoscale",
+		"file",
 		"freemyip",
 		"gandi",
 		"gandiv5",
@@ -111,6 +112,7 @@ func allDNSCodes() string {
 		"simply",
 		"sonic",
 		"stackpath",
+		"technitium",
 		"tencentcloud",
 		"transip",
 		"ultradns",
@@ -122,6 +124,7 @@ func allDNSCodes() string {
 		"vkcloud",
 		"vscale",
 		"vultr",
+		"webhook",
 		"websupport",
 		"wedos",
 		"yandex",
@@ -343,8 +346,12 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln()
 
 		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "BUNNY_API_URL":	The base URL of the API (Default: https://api.bunny.net)`)
+		ew.writeln(`	- "BUNNY_HTTP_TIMEOUT":	API request timeout`)
 		ew.writeln(`	- "BUNNY_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "BUNNY_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
+		ew.writeln(`	- "BUNNY_RATE_LIMIT":	Maximum number of API requests per second (Default: 0, no limit)`)
+		ew.writeln(`	- "BUNNY_RATE_LIMIT_BURST":	Maximum number of API requests sent at once when the rate limit is enabled (Default: 1)`)
 		ew.writeln(`	- "BUNNY_TTL":	The TTL of the TXT record used for the DNS challenge`)
 
 		ew.writeln()
@@ -539,6 +546,7 @@ func displayDNSHelp(w io.Writer, name string) error {
 
 		ew.writeln(`Additional Configuration:`)
 		ew.writeln(`	- "DESEC_HTTP_TIMEOUT":	API request timeout`)
+		ew.writeln(`	- "DESEC_MAX_RETRIES":	The maximum number of retries of an API request, the requests rejected by the rate limits (HTTP 429) are retried with a backoff (Default: 5)`)
 		ew.writeln(`	- "DESEC_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "DESEC_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
 		ew.writeln(`	- "DESEC_TTL":	The TTL of the TXT record used for the DNS challenge`)
@@ -896,6 +904,26 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/exoscale`)
 
+	case "file":
+		// generated from: providers/dns/file/file.toml
+		ew.writeln(`Configuration for Local file.`)
+		ew.writeln(`Code:	'file'`)
+		ew.writeln(`Since:	'v4.11.0'`)
+		ew.writeln()
+
+		ew.writeln(`Credentials:`)
+		ew.writeln(`	- "FILE_DIRECTORY":	The directory where the JSON documents are written`)
+		ew.writeln()
+
+		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "FILE_HOOK":	The program to run after writing a JSON document, instead of waiting for a signal file`)
+		ew.writeln(`	- "FILE_POLLING_INTERVAL":	Time between DNS propagation check`)
+		ew.writeln(`	- "FILE_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
+		ew.writeln(`	- "FILE_SIGNAL_TIMEOUT":	Maximum waiting time for the signal file (Default: 600)`)
+
+		ew.writeln()
+		ew.writeln(`More information: https://go-acme.github.io/lego/dns/file`)
+
 	case "freemyip":
 		// generated from: providers/dns/freemyip/freemyip.toml
 		ew.writeln(`Configuration for freemyip.com.`)
@@ -982,20 +1010,20 @@ func displayDNSHelp(w io.Writer, name string) error {
 
 	case "gcore":
 		// generated from: providers/dns/gcore/gcore.toml
-		ew.writeln(`Configuration for G-Core Labs.`)
+		ew.writeln(`Configuration for Gcore.`)
 		ew.writeln(`Code:	'gcore'`)
 		ew.writeln(`Since:	'v4.5.0'`)
 		ew.writeln()
 
 		ew.writeln(`Credentials:`)
-		ew.writeln(`	- "GCORE_PERMANENT_API_TOKEN":	Permanent API tokene (https://gcorelabs.com/blog/permanent-api-token-explained/)`)
+		ew.writeln(`	- "GCORE_PERMANENT_API_TOKEN":	Permanent API token (https://gcore.com/blog/permanent-api-token-explained/)`)
 		ew.writeln()
 
 		ew.writeln(`Additional Configuration:`)
 		ew.writeln(`	- "GCORE_HTTP_TIMEOUT":	API request timeout`)
 		ew.writeln(`	- "GCORE_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "GCORE_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
-		ew.writeln(`	- "GCORE_TTL":	The TTL of the TXT record used for the DNS challenge`)
+		ew.writeln(`	- "GCORE_TTL":	The TTL of the TXT record used for the DNS challenge, at least 120 (Default: 120)`)
 
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/gcore`)
@@ -1056,6 +1084,7 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln(`Additional Configuration:`)
 		ew.writeln(`	- "HETZNER_HTTP_TIMEOUT":	API request timeout`)
 		ew.writeln(`	- "HETZNER_POLLING_INTERVAL":	Time between DNS propagation check`)
+		ew.writeln(`	- "HETZNER_PRESENT_DELAY":	Delay after the creation of the TXT record, before the propagation check (Default: 0)`)
 		ew.writeln(`	- "HETZNER_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
 		ew.writeln(`	- "HETZNER_TTL":	The TTL of the TXT record used for the DNS challenge`)
 
@@ -1323,10 +1352,19 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln()
 
 		ew.writeln(`Additional Configuration:`)
-		ew.writeln(`	- "IONOS_HTTP_TIMEOUT":	API request timeout`)
+		ew.writeln(`	- "IONOS_API_URL":	API endpoint URL, defaults to https://api.hosting.ionos.com/dns`)
+		ew.writeln(`	- "IONOS_AUTHORITATIVE_CHECK":	Wait for the TXT record on the authoritative nameservers of the zone before ending the challenge presentation (Default: false)`)
+		ew.writeln(`	- "IONOS_AUTHORITATIVE_CHECK_TIMEOUT":	Maximum waiting time for the TXT record on the authoritative nameservers`)
+		ew.writeln(`	- "IONOS_CHALLENGE_TIMEOUT":	Maximum duration of the presentation or the cleanup of a challenge, including all the API requests and the authoritative check (Default: 0, no limit)`)
+		ew.writeln(`	- "IONOS_CONNECT_TIMEOUT":	Timeout of the connections to the API (Default: 10)`)
+		ew.writeln(`	- "IONOS_HTTP_TIMEOUT":	API request timeout (Default: 30)`)
 		ew.writeln(`	- "IONOS_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "IONOS_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
-		ew.writeln(`	- "IONOS_TTL":	The TTL of the TXT record used for the DNS challenge`)
+		ew.writeln(`	- "IONOS_RATE_LIMIT":	Maximum number of API requests per second (Default: 0, no limit)`)
+		ew.writeln(`	- "IONOS_RATE_LIMIT_BURST":	Maximum number of API requests sent at once when the rate limit is enabled (Default: 1)`)
+		ew.writeln(`	- "IONOS_TLS_INSECURE_SKIP_VERIFY":	Disable the verification of the TLS certificate of the API, only for a trusted self-hosted endpoint (Default: false)`)
+		ew.writeln(`	- "IONOS_TTL":	The TTL of the TXT record used for the DNS challenge, between 300 and 86400 (the API rejects the values outside these limits)`)
+		ew.writeln(`	- "IONOS_ZONES_CACHE_TTL":	Duration (in seconds) during which the list of zones is reused by the challenges, 0 disables the cache (Default: 60)`)
 
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/ionos`)
@@ -1865,9 +1903,11 @@ func displayDNSHelp(w io.Writer, name string) error {
 
 		ew.writeln(`Additional Configuration:`)
 		ew.writeln(`	- "PDNS_HTTP_TIMEOUT":	API request timeout`)
+		ew.writeln(`	- "PDNS_LIST_ZONES":	Resolve the zone with the list of the zones of the server instead of DNS lookups (Default: false)`)
 		ew.writeln(`	- "PDNS_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "PDNS_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
 		ew.writeln(`	- "PDNS_SERVER_NAME":	Name of the server in the URL, 'localhost' by default`)
+		ew.writeln(`	- "PDNS_TLS_INSECURE_SKIP_VERIFY":	Disable the verification of the TLS certificate of the API, only for a trusted network (Default: false)`)
 		ew.writeln(`	- "PDNS_TTL":	The TTL of the TXT record used for the DNS challenge`)
 
 		ew.writeln()
@@ -1909,6 +1949,7 @@ func displayDNSHelp(w io.Writer, name string) error {
 
 		ew.writeln(`Additional Configuration:`)
 		ew.writeln(`	- "PORKBUN_HTTP_TIMEOUT":	API request timeout`)
+		ew.writeln(`	- "PORKBUN_MAX_RETRIES":	Maximum number of retries, with an exponential backoff, of a request rejected by the rate limits of the API (Default: 5)`)
 		ew.writeln(`	- "PORKBUN_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "PORKBUN_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
 		ew.writeln(`	- "PORKBUN_TTL":	The TTL of the TXT record used for the DNS challenge`)
@@ -1976,6 +2017,7 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln(`	- "RFC2136_DNS_TIMEOUT":	API request timeout`)
 		ew.writeln(`	- "RFC2136_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "RFC2136_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
+		ew.writeln(`	- "RFC2136_SECONDARIES":	Comma-separated network addresses ("host" or "host:port") of the secondaries checked for the DNS propagation, when 'RFC2136_NAMESERVER' is a hidden primary (the nameservers of the zone by default)`)
 		ew.writeln(`	- "RFC2136_SEQUENCE_INTERVAL":	Time between sequential requests`)
 		ew.writeln(`	- "RFC2136_TTL":	The TTL of the TXT record used for the DNS challenge`)
 
@@ -2196,6 +2238,27 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/stackpath`)
 
+	case "technitium":
+		// generated from: providers/dns/technitium/technitium.toml
+		ew.writeln(`Configuration for Technitium.`)
+		ew.writeln(`Code:	'technitium'`)
+		ew.writeln(`Since:	'v4.11.0'`)
+		ew.writeln()
+
+		ew.writeln(`Credentials:`)
+		ew.writeln(`	- "TECHNITIUM_API_TOKEN":	API token`)
+		ew.writeln(`	- "TECHNITIUM_SERVER_BASE_URL":	Server base URL (e.g. https://localhost:5380)`)
+		ew.writeln()
+
+		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "TECHNITIUM_HTTP_TIMEOUT":	API request timeout`)
+		ew.writeln(`	- "TECHNITIUM_POLLING_INTERVAL":	Time between DNS propagation check`)
+		ew.writeln(`	- "TECHNITIUM_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
+		ew.writeln(`	- "TECHNITIUM_TTL":	The TTL of the TXT record used for the DNS challenge in seconds`)
+
+		ew.writeln()
+		ew.writeln(`More information: https://go-acme.github.io/lego/dns/technitium`)
+
 	case "tencentcloud":
 		// generated from: providers/dns/tencentcloud/tencentcloud.toml
 		ew.writeln(`Configuration for Tencent Cloud DNS.`)
@@ -2432,6 +2495,28 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/vultr`)
 
+	case "webhook":
+		// generated from: providers/dns/webhook/webhook.toml
+		ew.writeln(`Configuration for Webhook.`)
+		ew.writeln(`Code:	'webhook'`)
+		ew.writeln(`Since:	'v4.11.0'`)
+		ew.writeln()
+
+		ew.writeln(`Credentials:`)
+		ew.writeln(`	- "WEBHOOK_URL":	The URL of the webhook`)
+		ew.writeln()
+
+		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "WEBHOOK_HTTP_TIMEOUT":	API request timeout`)
+		ew.writeln(`	- "WEBHOOK_POLLING_INTERVAL":	Time between DNS propagation check`)
+		ew.writeln(`	- "WEBHOOK_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
+		ew.writeln(`	- "WEBHOOK_SECRET":	The secret used to sign the requests (HMAC-SHA256)`)
+		ew.writeln(`	- "WEBHOOK_STATUS_INTERVAL":	Time between two requests to the status URL (Default: 5)`)
+		ew.writeln(`	- "WEBHOOK_STATUS_TIMEOUT":	Maximum waiting time for a change to be ready, when the webhook returns a status URL (Default: 120)`)
+
+		ew.writeln()
+		ew.writeln(`More information: https://go-acme.github.io/lego/dns/webhook`)
+
 	case "websupport":
 		// generated from: providers/dns/websupport/websupport.toml
 		ew.writeln(`Configuration for Websupport.`)
@@ -0,0 +1,63 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "file.txt")
+
+	err := WriteFile(path, []byte("a"))
+	require.NoError(t, err)
+
+	err = WriteFile(path, []byte("b"))
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "b", string(content))
+
+	// the temporary files are removed.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteFile_mode(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "file.txt")
+
+	err := WriteFile(path, []byte("a"))
+	require.NoError(t, err)
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, os.FileMode(0o644), fi.Mode().Perm())
+
+	err = os.Chmod(path, 0o640)
+	require.NoError(t, err)
+
+	err = WriteFile(path, []byte("b"))
+	require.NoError(t, err)
+
+	fi, err = os.Stat(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, os.FileMode(0o640), fi.Mode().Perm())
+}
+
+func TestWriteFile_missingDirectory(t *testing.T) {
+	err := WriteFile(filepath.Join(t.TempDir(), "missing", "file.txt"), []byte("a"))
+	require.Error(t, err)
+}
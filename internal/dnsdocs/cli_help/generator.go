@@ -56,6 +56,8 @@ func generate() error {
 		{"lego", "help", "renew"},
 		{"lego", "help", "revoke"},
 		{"lego", "help", "list"},
+		{"lego", "help", "dnscleanup"},
+		{"lego", "help", "dnscheck"},
 		{"lego", "dnshelp"},
 	} {
 		content, err := run(app, args)
@@ -0,0 +1,51 @@
+// Package domainsuffix matches the domains with the values associated with their parent domains.
+package domainsuffix
+
+import "strings"
+
+// Map associates values with domains.
+// The value of a domain is also the value of its subdomains: the most specific domain is used.
+type Map[V any] map[string]V
+
+// Set associates the value with the domain (and its subdomains).
+// It returns false if the domain is invalid.
+func (m Map[V]) Set(domain string, value V) bool {
+	name := normalize(domain)
+	if name == "" {
+		return false
+	}
+
+	m[name] = value
+
+	return true
+}
+
+// Lookup returns the value associated with the domain, or with its nearest parent domain.
+func (m Map[V]) Lookup(domain string) (V, bool) {
+	name := normalize(domain)
+
+	for name != "" {
+		if value, ok := m[name]; ok {
+			return value, true
+		}
+
+		_, parent, found := strings.Cut(name, ".")
+		if !found {
+			break
+		}
+
+		name = parent
+	}
+
+	var zero V
+
+	return zero, false
+}
+
+// normalize returns the lower case domain, without the wildcard label and the trailing dot.
+func normalize(domain string) string {
+	name := strings.ToLower(strings.TrimSpace(domain))
+	name = strings.TrimPrefix(name, "*.")
+
+	return strings.TrimSuffix(name, ".")
+}
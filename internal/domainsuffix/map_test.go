@@ -0,0 +1,46 @@
+package domainsuffix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMap(t *testing.T) {
+	m := Map[string]{}
+
+	assert.True(t, m.Set("Example.com.", "a"))
+	assert.True(t, m.Set("*.sub.example.com", "b"))
+	assert.False(t, m.Set(" ", "c"))
+	assert.False(t, m.Set("*.", "c"))
+
+	testCases := []struct {
+		domain   string
+		expected string
+		found    bool
+	}{
+		{domain: "example.com", expected: "a", found: true},
+		{domain: "www.example.com.", expected: "a", found: true},
+		{domain: "*.example.com", expected: "a", found: true},
+		{domain: "sub.example.com", expected: "b", found: true},
+		{domain: "www.SUB.example.com", expected: "b", found: true},
+		{domain: "example.org"},
+		{domain: "com"},
+		{domain: ""},
+	}
+
+	for _, test := range testCases {
+		value, found := m.Lookup(test.domain)
+
+		assert.Equal(t, test.found, found, test.domain)
+		assert.Equal(t, test.expected, value, test.domain)
+	}
+}
+
+func TestMap_Lookup_nil(t *testing.T) {
+	var m Map[int]
+
+	value, found := m.Lookup("example.com")
+	assert.False(t, found)
+	assert.Zero(t, value)
+}
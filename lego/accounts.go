@@ -0,0 +1,117 @@
+package lego
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/internal/domainsuffix"
+)
+
+// Accounts selects, by domain, the client (i.e. the ACME account and its key) used to obtain the certificates.
+// It allows a single process to manage the certificates of several accounts.
+//
+// A domain associated with a client also selects this client for its subdomains:
+// the most specific association is used.
+type Accounts struct {
+	mu       sync.RWMutex
+	clients  domainsuffix.Map[*Client]
+	fallback *Client
+}
+
+// NewAccounts creates a new Accounts.
+// The fallback client is used for the domains without association, it can be nil.
+func NewAccounts(fallback *Client) *Accounts {
+	return &Accounts{
+		clients:  domainsuffix.Map[*Client]{},
+		fallback: fallback,
+	}
+}
+
+// Add associates the domains with the client.
+// A domain already associated with another client is overridden.
+func (a *Accounts) Add(client *Client, domains ...string) error {
+	if client == nil {
+		return errors.New("accounts: the client cannot be nil")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, domain := range domains {
+		if !a.clients.Set(domain, client) {
+			return fmt.Errorf("accounts: invalid domain %q", domain)
+		}
+	}
+
+	return nil
+}
+
+// Client returns the client associated with the domain,
+// or with its nearest parent domain, or the fallback client.
+func (a *Accounts) Client(domain string) (*Client, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if client, ok := a.clients.Lookup(domain); ok {
+		return client, nil
+	}
+
+	if a.fallback != nil {
+		return a.fallback, nil
+	}
+
+	return nil, fmt.Errorf("accounts: no account associated with the domain %s", domain)
+}
+
+// Obtain obtains a certificate with the client associated with the domains of the request.
+// All the domains of the request must be associated with the same client.
+func (a *Accounts) Obtain(request certificate.ObtainRequest) (*certificate.Resource, error) {
+	client, err := a.clientFor(request.Domains)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Certificate.Obtain(request)
+}
+
+// ObtainForCSR obtains a certificate with the client associated with the domains (and IP addresses) of the CSR.
+// All the domains of the CSR must be associated with the same client.
+func (a *Accounts) ObtainForCSR(request certificate.ObtainForCSRRequest) (*certificate.Resource, error) {
+	if request.CSR == nil {
+		return nil, errors.New("accounts: the CSR cannot be nil")
+	}
+
+	client, err := a.clientFor(certcrypto.ExtractDomainsCSR(request.CSR))
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Certificate.ObtainForCSR(request)
+}
+
+func (a *Accounts) clientFor(domains []string) (*Client, error) {
+	if len(domains) == 0 {
+		return nil, errors.New("accounts: no domains to obtain a certificate for")
+	}
+
+	client, err := a.Client(domains[0])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, domain := range domains[1:] {
+		c, err := a.Client(domain)
+		if err != nil {
+			return nil, err
+		}
+
+		if c != client {
+			return nil, fmt.Errorf("accounts: the domains %s and %s are associated with different accounts", domains[0], domain)
+		}
+	}
+
+	return client, nil
+}
@@ -0,0 +1,235 @@
+package lego
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-acme/lego/v4/registration"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccounts_Client(t *testing.T) {
+	clientA, clientB, fallback := &Client{}, &Client{}, &Client{}
+
+	accounts := NewAccounts(fallback)
+
+	require.NoError(t, accounts.Add(clientA, "example.com", "example.org"))
+	require.NoError(t, accounts.Add(clientB, "sub.example.com"))
+
+	testCases := []struct {
+		domain   string
+		expected *Client
+	}{
+		{domain: "example.com", expected: clientA},
+		{domain: "EXAMPLE.com.", expected: clientA},
+		{domain: "*.example.com", expected: clientA},
+		{domain: "www.example.com", expected: clientA},
+		{domain: "sub.example.com", expected: clientB},
+		{domain: "a.sub.example.com", expected: clientB},
+		{domain: "*.sub.example.com", expected: clientB},
+		{domain: "example.org", expected: clientA},
+		{domain: "example.net", expected: fallback},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.domain, func(t *testing.T) {
+			t.Parallel()
+
+			client, err := accounts.Client(test.domain)
+			require.NoError(t, err)
+
+			assert.Same(t, test.expected, client)
+		})
+	}
+}
+
+func TestAccounts_Client_noFallback(t *testing.T) {
+	accounts := NewAccounts(nil)
+
+	require.NoError(t, accounts.Add(&Client{}, "example.com"))
+
+	_, err := accounts.Client("example.org")
+	require.EqualError(t, err, "accounts: no account associated with the domain example.org")
+}
+
+func TestAccounts_Obtain(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	keyA, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	keyB, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	keys := map[string]*rsa.PrivateKey{
+		apiURL + "/account/a": keyA,
+		apiURL + "/account/b": keyB,
+	}
+
+	var mu sync.Mutex
+	signers := map[string]string{}
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		kid, body, err := readSignedBodyWithKeys(r, keys)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var order acme.Order
+		err = json.Unmarshal(body, &order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		signers[order.Identifiers[0].Value] = kid
+		mu.Unlock()
+
+		order.Status = acme.StatusReady
+		order.Finalize = apiURL + "/finalize/" + order.Identifiers[0].Value
+
+		w.Header().Set("Location", apiURL+"/order/"+order.Identifiers[0].Value)
+		w.WriteHeader(http.StatusCreated)
+
+		err = tester.WriteJSONResponse(w, order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/finalize/", func(w http.ResponseWriter, r *http.Request) {
+		domain := r.URL.Path[len("/finalize/"):]
+
+		err := tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusValid,
+			Identifiers: []acme.Identifier{{Type: "dns", Value: domain}},
+			Certificate: apiURL + "/cert/" + domain,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/cert/", func(w http.ResponseWriter, r *http.Request) {
+		certPEM, err := certcrypto.GeneratePemCert(keyA, r.URL.Path[len("/cert/"):], nil)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = w.Write(certPEM)
+	})
+
+	newTestClient := func(key *rsa.PrivateKey, kid string) *Client {
+		config := NewConfig(mockUser{
+			email:      "test@test.com",
+			regres:     &registration.Resource{URI: kid},
+			privatekey: key,
+		})
+		config.CADirURL = apiURL + "/dir"
+		config.Certificate.KeyType = certcrypto.RSA2048
+
+		client, errC := NewClient(config)
+		require.NoError(t, errC)
+
+		return client
+	}
+
+	accounts := NewAccounts(nil)
+
+	require.NoError(t, accounts.Add(newTestClient(keyA, apiURL+"/account/a"), "client-a.com"))
+	require.NoError(t, accounts.Add(newTestClient(keyB, apiURL+"/account/b"), "client-b.com"))
+
+	for _, domain := range []string{"client-a.com", "www.client-b.com"} {
+		_, err = accounts.Obtain(certificate.ObtainRequest{Domains: []string{domain}})
+		require.NoError(t, err)
+	}
+
+	expected := map[string]string{
+		"client-a.com":     apiURL + "/account/a",
+		"www.client-b.com": apiURL + "/account/b",
+	}
+
+	assert.Equal(t, expected, signers)
+}
+
+func TestAccounts_Obtain_differentAccounts(t *testing.T) {
+	accounts := NewAccounts(nil)
+
+	require.NoError(t, accounts.Add(&Client{}, "client-a.com"))
+	require.NoError(t, accounts.Add(&Client{}, "client-b.com"))
+
+	_, err := accounts.Obtain(certificate.ObtainRequest{Domains: []string{"client-a.com", "client-b.com"}})
+	require.EqualError(t, err, "accounts: the domains client-a.com and client-b.com are associated with different accounts")
+}
+
+func TestAccounts_ObtainForCSR_differentAccounts(t *testing.T) {
+	accounts := NewAccounts(nil)
+
+	require.NoError(t, accounts.Add(&Client{}, "client-a.com"))
+	require.NoError(t, accounts.Add(&Client{}, "192.0.2.1"))
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	template := &x509.CertificateRequest{
+		Subject:     pkix.Name{CommonName: "client-a.com"},
+		DNSNames:    []string{"client-a.com"},
+		IPAddresses: []net.IP{net.ParseIP("192.0.2.1")},
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, privateKey)
+	require.NoError(t, err)
+
+	csr, err := x509.ParseCertificateRequest(der)
+	require.NoError(t, err)
+
+	_, err = accounts.ObtainForCSR(certificate.ObtainForCSRRequest{CSR: csr})
+	require.EqualError(t, err, "accounts: the domains client-a.com and 192.0.2.1 are associated with different accounts")
+}
+
+// readSignedBodyWithKeys verifies the JWS with the key of the account (KeyID) and returns the KeyID and the payload.
+func readSignedBodyWithKeys(r *http.Request, keys map[string]*rsa.PrivateKey) (string, []byte, error) {
+	reqBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	jws, err := jose.ParseSigned(string(reqBody))
+	if err != nil {
+		return "", nil, err
+	}
+
+	kid := jws.Signatures[0].Protected.KeyID
+
+	key, ok := keys[kid]
+	if !ok {
+		return "", nil, jose.ErrUnprotectedNonce
+	}
+
+	body, err := jws.Verify(key.Public())
+	if err != nil {
+		return "", nil, err
+	}
+
+	return kid, body, nil
+}
@@ -45,15 +45,32 @@ func NewClient(config *Config) (*Client, error) {
 		kid = reg.URI
 	}
 
-	core, err := api.New(config.HTTPClient, config.UserAgent, config.CADirURL, kid, privateKey)
+	opts := []api.Option{api.WithUserAgentSuffix(config.UserAgentSuffix)}
+	if config.NonceSource != nil {
+		opts = append(opts, api.WithNonceSource(config.NonceSource))
+	}
+
+	core, err := api.New(config.HTTPClient, config.UserAgent, config.CADirURL, kid, privateKey, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	if config.RequestHook != nil {
+		core.SetRequestHook(config.RequestHook)
+	}
+
 	solversManager := resolver.NewSolversManager(core)
 
 	prober := resolver.NewProber(solversManager)
-	certifier := certificate.NewCertifier(core, prober, certificate.CertifierOptions{KeyType: config.Certificate.KeyType, Timeout: config.Certificate.Timeout})
+	certifier := certificate.NewCertifier(core, prober, certificate.CertifierOptions{
+		KeyType:      config.Certificate.KeyType,
+		Timeout:      config.Certificate.Timeout,
+		Observer:     config.Observer,
+		CAAPreflight: config.Certificate.CAAPreflight,
+	})
+
+	// the observations of the solvers are forwarded to config.Observer by the certifier.
+	solversManager.SetObserver(certifier.Observer())
 
 	return &Client{
 		Certificate:  certifier,
@@ -11,7 +11,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-acme/lego/v4/acme/api"
 	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/platform/observer"
 	"github.com/go-acme/lego/v4/registration"
 )
 
@@ -33,6 +36,11 @@ const (
 	// the system-wide trusted root list.
 	caServerNameEnvVar = "LEGO_CA_SERVER_NAME"
 
+	// userAgentSuffixEnvVar is the environment variable name that can be used to
+	// specify a suffix appended to the User-Agent of the requests sent to the ACME server.
+	// The same environment variable is used by the DNS providers.
+	userAgentSuffixEnvVar = "LEGO_USER_AGENT_SUFFIX"
+
 	// LEDirectoryProduction URL to the Let's Encrypt production.
 	LEDirectoryProduction = "https://acme-v02.api.letsencrypt.org/directory"
 
@@ -46,13 +54,28 @@ type Config struct {
 	UserAgent   string
 	HTTPClient  *http.Client
 	Certificate CertificateConfig
+
+	// UserAgentSuffix is appended to the User-Agent of the requests sent to the ACME server (optional).
+	// Defaults to the value of the LEGO_USER_AGENT_SUFFIX environment variable.
+	UserAgentSuffix string
+
+	// RequestHook is called after each HTTP request sent to the ACME server (optional).
+	RequestHook api.RequestHook
+
+	// NonceSource provides the nonces of the requests sent to the ACME server (optional).
+	// Defaults to the nonces provided by the server (see api.WithNonceSource).
+	NonceSource api.NonceSource
+
+	// Observer receives the duration of each phase of the issuance of a certificate (optional).
+	Observer observer.Observer
 }
 
 func NewConfig(user registration.User) *Config {
 	return &Config{
-		CADirURL:   LEDirectoryProduction,
-		User:       user,
-		HTTPClient: createDefaultHTTPClient(),
+		CADirURL:        LEDirectoryProduction,
+		User:            user,
+		HTTPClient:      createDefaultHTTPClient(),
+		UserAgentSuffix: os.Getenv(userAgentSuffixEnvVar),
 		Certificate: CertificateConfig{
 			KeyType: certcrypto.RSA2048,
 			Timeout: 30 * time.Second,
@@ -63,6 +86,9 @@ func NewConfig(user registration.User) *Config {
 type CertificateConfig struct {
 	KeyType certcrypto.KeyType
 	Timeout time.Duration
+
+	// CAAPreflight configures the CAA check done before the creation of the orders (optional).
+	CAAPreflight certificate.CAAPreflight
 }
 
 // createDefaultHTTPClient Creates an HTTP client with a reasonable timeout value
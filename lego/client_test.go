@@ -4,8 +4,16 @@ import (
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/platform/observer"
 	"github.com/go-acme/lego/v4/platform/tester"
 	"github.com/go-acme/lego/v4/registration"
 	"github.com/stretchr/testify/assert"
@@ -34,6 +42,172 @@ func TestNewClient(t *testing.T) {
 	assert.NotNil(t, client)
 }
 
+func TestNewClient_requestHook(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	mux.HandleFunc("/account", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", apiURL+"/account/1")
+		err := tester.WriteJSONResponse(w, acme.Account{Status: "valid"})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	user := mockUser{
+		email:      "test@test.com",
+		privatekey: key,
+	}
+
+	var infos []api.RequestInfo
+
+	config := NewConfig(user)
+	config.CADirURL = apiURL + "/dir"
+	config.RequestHook = func(info api.RequestInfo) {
+		infos = append(infos, info)
+	}
+
+	client, err := NewClient(config)
+	require.NoError(t, err, "Could not create client")
+
+	_, err = client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+	require.NoError(t, err)
+
+	require.Len(t, infos, 2)
+
+	assert.Equal(t, http.MethodHead, infos[0].Method)
+	assert.Equal(t, apiURL+"/nonce", infos[0].URL)
+
+	assert.Equal(t, http.MethodPost, infos[1].Method)
+	assert.Equal(t, apiURL+"/account", infos[1].URL)
+	assert.Equal(t, http.StatusOK, infos[1].StatusCode)
+	assert.Equal(t, apiURL+"/account/1", infos[1].ResponseHeader.Get("Location"))
+}
+
+func TestNewClient_observer(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	certKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	certPEM, err := certcrypto.GeneratePemCert(certKey, "example.com", nil)
+	require.NoError(t, err)
+
+	order := acme.Order{
+		Status:         acme.StatusPending,
+		Identifiers:    []acme.Identifier{{Type: "dns", Value: "example.com"}},
+		Authorizations: []string{apiURL + "/authz/1"},
+		Finalize:       apiURL + "/finalize",
+	}
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+		err := tester.WriteJSONResponse(w, order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusPending,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+			Challenges: []acme.Challenge{{Type: "dns-01", Status: acme.StatusPending, URL: apiURL + "/chlg/1", Token: "token"}},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/chlg/1", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Challenge{Type: "dns-01", Status: acme.StatusValid, URL: apiURL + "/chlg/1", Token: "token"})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/finalize", func(w http.ResponseWriter, _ *http.Request) {
+		valid := order
+		valid.Status = acme.StatusValid
+		valid.Certificate = apiURL + "/cert/1"
+
+		w.Header().Set("Location", apiURL+"/order/1")
+		err := tester.WriteJSONResponse(w, valid)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/cert/1", func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write(certPEM)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err, "Could not generate test key")
+
+	user := mockUser{
+		email:      "test@test.com",
+		regres:     &registration.Resource{URI: apiURL + "/account/1"},
+		privatekey: key,
+	}
+
+	var phases []observer.Phase
+
+	config := NewConfig(user)
+	config.CADirURL = apiURL + "/dir"
+	config.Certificate.KeyType = certcrypto.RSA2048
+	config.Observer = observer.Func(func(phase observer.Phase, domain string, duration time.Duration) {
+		assert.Equal(t, "example.com", domain)
+		assert.GreaterOrEqual(t, duration, time.Duration(0))
+
+		phases = append(phases, phase)
+	})
+
+	client, err := NewClient(config)
+	require.NoError(t, err, "Could not create client")
+
+	err = client.Challenge.SetDNS01Provider(&providerDNSMock{},
+		dns01.WrapPreCheck(func(_, _, _ string, _ dns01.PreCheckFunc) (bool, error) {
+			return true, nil
+		}))
+	require.NoError(t, err)
+
+	_, err = client.Certificate.Obtain(certificate.ObtainRequest{Domains: []string{"example.com"}})
+	require.NoError(t, err)
+
+	expected := []observer.Phase{
+		observer.PhaseOrder,
+		observer.PhasePresent,
+		observer.PhasePropagation,
+		observer.PhaseValidation,
+		observer.PhaseDownload,
+	}
+
+	assert.Equal(t, expected, phases)
+}
+
+type providerDNSMock struct{}
+
+func (p *providerDNSMock) Present(_, _, _ string) error { return nil }
+func (p *providerDNSMock) CleanUp(_, _, _ string) error { return nil }
+func (p *providerDNSMock) Timeout() (timeout, interval time.Duration) {
+	return time.Second, 10 * time.Millisecond
+}
+
 type mockUser struct {
 	email      string
 	regres     *registration.Resource
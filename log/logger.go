@@ -1,13 +1,21 @@
 package log
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 // Logger is an optional custom logger.
 var Logger StdLogger = log.New(os.Stderr, "", log.LstdFlags)
 
+// Leveled is an optional leveled logger.
+// When set, all the log entries, except the fatal ones, are sent to it instead of Logger:
+// it allows an application embedding lego to route or silence the logs of the library.
+// The entries without level (Print, Println, Printf) are sent as info entries.
+var Leveled LeveledLogger
+
 // StdLogger interface for Standard Logger.
 type StdLogger interface {
 	Fatal(args ...interface{})
@@ -18,6 +26,14 @@ type StdLogger interface {
 	Printf(format string, args ...interface{})
 }
 
+// LeveledLogger interface for a logger with levels.
+type LeveledLogger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
 // Fatal writes a log entry.
 // It uses Logger if not nil, otherwise it uses the default log.Logger.
 func Fatal(args ...interface{}) {
@@ -33,27 +49,70 @@ func Fatalf(format string, args ...interface{}) {
 // Print writes a log entry.
 // It uses Logger if not nil, otherwise it uses the default log.Logger.
 func Print(args ...interface{}) {
+	if Leveled != nil {
+		Leveled.Infof("%s", fmt.Sprint(args...))
+		return
+	}
+
 	Logger.Print(args...)
 }
 
 // Println writes a log entry.
 // It uses Logger if not nil, otherwise it uses the default log.Logger.
 func Println(args ...interface{}) {
+	if Leveled != nil {
+		Leveled.Infof("%s", strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+		return
+	}
+
 	Logger.Println(args...)
 }
 
 // Printf writes a log entry.
 // It uses Logger if not nil, otherwise it uses the default log.Logger.
 func Printf(format string, args ...interface{}) {
+	if Leveled != nil {
+		Leveled.Infof(format, args...)
+		return
+	}
+
 	Logger.Printf(format, args...)
 }
 
-// Warnf writes a log entry.
-func Warnf(format string, args ...interface{}) {
-	Printf("[WARN] "+format, args...)
+// Debugf writes a debug log entry.
+// The debug entries are only written by Leveled: they are dropped by default.
+func Debugf(format string, args ...interface{}) {
+	if Leveled != nil {
+		Leveled.Debugf(format, args...)
+	}
 }
 
 // Infof writes a log entry.
 func Infof(format string, args ...interface{}) {
-	Printf("[INFO] "+format, args...)
+	if Leveled != nil {
+		Leveled.Infof(format, args...)
+		return
+	}
+
+	Logger.Printf("[INFO] "+format, args...)
+}
+
+// Warnf writes a log entry.
+func Warnf(format string, args ...interface{}) {
+	if Leveled != nil {
+		Leveled.Warnf(format, args...)
+		return
+	}
+
+	Logger.Printf("[WARN] "+format, args...)
+}
+
+// Errorf writes an error log entry.
+func Errorf(format string, args ...interface{}) {
+	if Leveled != nil {
+		Leveled.Errorf(format, args...)
+		return
+	}
+
+	Logger.Printf("[ERROR] "+format, args...)
 }
@@ -0,0 +1,86 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recorderLogger struct {
+	entries []string
+}
+
+func (r *recorderLogger) Debugf(format string, args ...interface{}) {
+	r.entries = append(r.entries, "debug: "+fmt.Sprintf(format, args...))
+}
+
+func (r *recorderLogger) Infof(format string, args ...interface{}) {
+	r.entries = append(r.entries, "info: "+fmt.Sprintf(format, args...))
+}
+
+func (r *recorderLogger) Warnf(format string, args ...interface{}) {
+	r.entries = append(r.entries, "warn: "+fmt.Sprintf(format, args...))
+}
+
+func (r *recorderLogger) Errorf(format string, args ...interface{}) {
+	r.entries = append(r.entries, "error: "+fmt.Sprintf(format, args...))
+}
+
+func TestLeveled(t *testing.T) {
+	buf := setupStdLogger(t)
+
+	recorder := &recorderLogger{}
+
+	Leveled = recorder
+	t.Cleanup(func() { Leveled = nil })
+
+	Debugf("debug %d", 1)
+	Infof("[%s] info", "example.com")
+	Warnf("warn %s", "a")
+	Errorf("error %v", "b")
+	Print("print ", 2)
+	Println("println", 3)
+	Printf("printf %d", 4)
+
+	expected := []string{
+		"debug: debug 1",
+		"info: [example.com] info",
+		"warn: warn a",
+		"error: error b",
+		"info: print 2",
+		"info: println 3",
+		"info: printf 4",
+	}
+
+	assert.Equal(t, expected, recorder.entries)
+	assert.Empty(t, buf.String())
+}
+
+func TestDefault(t *testing.T) {
+	buf := setupStdLogger(t)
+
+	Debugf("debug %d", 1)
+	Infof("[%s] info", "example.com")
+	Warnf("warn %s", "a")
+	Errorf("error %v", "b")
+	Printf("printf %d", 4)
+
+	expected := "[INFO] [example.com] info\n[WARN] warn a\n[ERROR] error b\nprintf 4\n"
+
+	assert.Equal(t, expected, buf.String())
+}
+
+func setupStdLogger(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+
+	previous := Logger
+	Logger = log.New(buf, "", 0)
+	t.Cleanup(func() { Logger = previous })
+
+	return buf
+}
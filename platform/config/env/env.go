@@ -105,6 +105,17 @@ func GetOrDefaultInt(envVar string, defaultValue int) int {
 	return v
 }
 
+// GetOrDefaultFloat returns the given environment variable value as a float.
+// Returns the default if the envvar cannot be coopered to a float, or is not found.
+func GetOrDefaultFloat(envVar string, defaultValue float64) float64 {
+	v, err := strconv.ParseFloat(GetOrFile(envVar), 64)
+	if err != nil {
+		return defaultValue
+	}
+
+	return v
+}
+
 // GetOrDefaultSecond returns the given environment variable value as an time.Duration (second).
 // Returns the default if the envvar cannot be coopered to an int, or is not found.
 func GetOrDefaultSecond(envVar string, defaultValue time.Duration) time.Duration {
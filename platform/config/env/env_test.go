@@ -150,6 +150,51 @@ func TestGetOrDefaultInt(t *testing.T) {
 	}
 }
 
+func TestGetOrDefaultFloat(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		envValue     string
+		defaultValue float64
+		expected     float64
+	}{
+		{
+			desc:         "valid value",
+			envValue:     "1.5",
+			defaultValue: 2,
+			expected:     1.5,
+		},
+		{
+			desc:         "integer value",
+			envValue:     "100",
+			defaultValue: 2,
+			expected:     100,
+		},
+		{
+			desc:         "invalid content, use default value",
+			envValue:     "abc123",
+			defaultValue: 2,
+			expected:     2,
+		},
+		{
+			desc:         "empty value, use default value",
+			envValue:     "",
+			defaultValue: 2,
+			expected:     2,
+		},
+	}
+
+	const key = "LEGO_ENV_TC"
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Setenv(key, test.envValue)
+
+			result := GetOrDefaultFloat(key, test.defaultValue)
+			assert.InDelta(t, test.expected, result, 0)
+		})
+	}
+}
+
 func TestGetOrDefaultSecond(t *testing.T) {
 	testCases := []struct {
 		desc         string
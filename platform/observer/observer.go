@@ -0,0 +1,60 @@
+// Package observer allows to observe the duration of the phases of the issuance of a certificate
+// (e.g. to build metrics).
+package observer
+
+import "time"
+
+// Phase is a phase of the issuance of a certificate.
+type Phase string
+
+// Phases of the issuance of a certificate.
+const (
+	// PhaseOrder the creation of the order.
+	PhaseOrder Phase = "order"
+	// PhasePresent the presentation of the challenge by the provider (e.g. the creation of the DNS record).
+	PhasePresent Phase = "present"
+	// PhasePropagation the wait for the propagation of the DNS record (DNS-01 only).
+	PhasePropagation Phase = "propagation"
+	// PhaseValidation the validation of the challenge by the ACME server.
+	PhaseValidation Phase = "validation"
+	// PhaseDownload the download of the certificate.
+	PhaseDownload Phase = "download"
+)
+
+// Observer receives the duration of each phase of the issuance of a certificate.
+// The calls are synchronous, an Observer must not block.
+type Observer interface {
+	ObservePhase(phase Phase, domain string, duration time.Duration)
+}
+
+// Func is an adapter to use an ordinary function as an Observer.
+type Func func(phase Phase, domain string, duration time.Duration)
+
+// ObservePhase calls f(phase, domain, duration).
+func (f Func) ObservePhase(phase Phase, domain string, duration time.Duration) {
+	f(phase, domain, duration)
+}
+
+// Observe reports the duration since start of a phase to an Observer.
+// It's a no-op if the Observer is nil.
+func Observe(o Observer, phase Phase, domain string, start time.Time) {
+	if o == nil {
+		return
+	}
+
+	o.ObservePhase(phase, domain, time.Since(start))
+}
+
+// ChallengeObserver is an optional interface of an Observer,
+// receiving the type of the challenge used to validate each domain.
+type ChallengeObserver interface {
+	ObserveChallenge(domain, challengeType string)
+}
+
+// ObserveChallenge reports the type of the challenge used to validate a domain,
+// if the Observer implements ChallengeObserver.
+func ObserveChallenge(o Observer, domain, challengeType string) {
+	if co, ok := o.(ChallengeObserver); ok {
+		co.ObserveChallenge(domain, challengeType)
+	}
+}
@@ -1,6 +1,8 @@
 package wait
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -24,3 +26,23 @@ func TestForTimeout(t *testing.T) {
 		t.Logf("%v", err)
 	}
 }
+
+func TestForContext_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+
+	err := ForContext(ctx, "", time.Minute, 10*time.Second, func() (bool, error) {
+		return false, nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled; got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the wait was not aborted: %s", elapsed)
+	}
+}
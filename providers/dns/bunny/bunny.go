@@ -5,47 +5,85 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/platform/config/env"
-	"github.com/simplesurance/bunny-go"
+	"github.com/go-acme/lego/v4/providers/dns/bunny/internal"
+	"github.com/go-acme/lego/v4/providers/dns/internal/ratelimit"
+	"github.com/go-acme/lego/v4/providers/dns/internal/retry"
 )
 
 const minTTL = 60
 
+// retryPolicy retries the requests rejected by the rate limits of the API (429 responses), according to the Retry-After header.
+var retryPolicy = retry.Policy{
+	MaxAttempts: 4,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+	StatusCodes: []int{http.StatusTooManyRequests},
+}
+
 // Environment variables names.
 const (
 	envNamespace = "BUNNY_"
 
 	EnvAPIKey = envNamespace + "API_KEY"
+	EnvAPIURL = envNamespace + "API_URL"
 
 	EnvTTL                = envNamespace + "TTL"
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
+	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
+
+	EnvRateLimit      = envNamespace + "RATE_LIMIT"
+	EnvRateLimitBurst = envNamespace + "RATE_LIMIT_BURST"
 )
 
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
 	APIKey             string
+	BaseURL            string
 	PropagationTimeout time.Duration
 	PollingInterval    time.Duration
 	TTL                int
+	HTTPClient         *http.Client
+
+	// RateLimit is the maximum number of API requests per second (0 disables the limit).
+	RateLimit      float64
+	RateLimitBurst int
 }
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
 	return &Config{
+		BaseURL:            env.GetOrDefaultString(EnvAPIURL, internal.DefaultBaseURL),
 		TTL:                env.GetOrDefaultInt(EnvTTL, minTTL),
 		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
 		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
+		HTTPClient: &http.Client{
+			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+		},
+		RateLimit:      env.GetOrDefaultFloat(EnvRateLimit, 0),
+		RateLimitBurst: env.GetOrDefaultInt(EnvRateLimitBurst, 1),
 	}
 }
 
 // DNSProvider implements the challenge.Provider interface.
 type DNSProvider struct {
 	config *Config
-	client *bunny.Client
+	client *internal.Client
+
+	recordIDs   map[string]recordRef
+	recordIDsMu sync.Mutex
+}
+
+// recordRef references a record created by Present.
+type recordRef struct {
+	zoneID   int64
+	recordID int64
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for bunny.
@@ -76,9 +114,23 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		return nil, fmt.Errorf("bunny: invalid TTL, TTL (%d) must be greater than %d", config.TTL, minTTL)
 	}
 
-	client := bunny.NewClient(config.APIKey)
+	client, err := internal.NewClient(config.APIKey, config.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("bunny: %w", err)
+	}
+
+	if config.HTTPClient != nil {
+		client.HTTPClient = config.HTTPClient
+	}
 
-	return &DNSProvider{config: config, client: client}, nil
+	client.HTTPClient = ratelimit.Wrap(client.HTTPClient, config.RateLimit, config.RateLimitBurst)
+	client.HTTPClient = retry.Wrap(client.HTTPClient, retryPolicy)
+
+	return &DNSProvider{
+		config:    config,
+		client:    client,
+		recordIDs: make(map[string]recordRef),
+	}, nil
 }
 
 // Timeout returns the timeout and interval to use when checking for DNS
@@ -91,115 +143,84 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	fqdn, value := dns01.GetRecord(domain, keyAuth)
 
-	authZone, err := getZone(fqdn)
-	if err != nil {
-		return fmt.Errorf("bunny: failed to find zone: fqdn=%s: %w", fqdn, err)
-	}
-
 	ctx := context.Background()
 
-	zone, err := d.findZone(ctx, authZone)
+	zone, err := d.client.FindZone(ctx, fqdn)
 	if err != nil {
-		return fmt.Errorf("bunny: %w", err)
+		return fmt.Errorf("bunny: failed to find zone: fqdn=%s: %w", fqdn, err)
 	}
 
-	subDomain, err := dns01.ExtractSubDomain(fqdn, authZone)
+	subDomain, err := dns01.ExtractSubDomain(fqdn, zone.Domain)
 	if err != nil {
 		return fmt.Errorf("bunny: %w", err)
 	}
 
-	record := &bunny.AddOrUpdateDNSRecordOptions{
-		Type:  pointer(bunny.DNSRecordTypeTXT),
-		Name:  pointer(subDomain),
-		Value: pointer(value),
-		TTL:   pointer(int32(d.config.TTL)),
+	record := internal.Record{
+		Type:  internal.RecordTypeTXT,
+		Name:  subDomain,
+		Value: value,
+		TTL:   d.config.TTL,
 	}
 
-	if _, err := d.client.DNSZone.AddDNSRecord(ctx, deref(zone.ID), record); err != nil {
-		return fmt.Errorf("bunny: failed to add TXT record: fqdn=%s, zoneID=%d: %w", fqdn, deref(zone.ID), err)
+	newRecord, err := d.client.AddRecord(ctx, zone.ID, record)
+	if err != nil {
+		return fmt.Errorf("bunny: failed to add TXT record: fqdn=%s, zoneID=%d: %w", fqdn, zone.ID, err)
 	}
 
+	d.recordIDsMu.Lock()
+	d.recordIDs[token] = recordRef{zoneID: zone.ID, recordID: newRecord.ID}
+	d.recordIDsMu.Unlock()
+
 	return nil
 }
 
 // CleanUp removes the TXT record matching the specified parameters.
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
-	fqdn, _ := dns01.GetRecord(domain, keyAuth)
-
-	authZone, err := getZone(fqdn)
-	if err != nil {
-		return fmt.Errorf("bunny:  failed to find zone: fqdn=%s: %w", fqdn, err)
-	}
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
 
 	ctx := context.Background()
 
-	zone, err := d.findZone(ctx, authZone)
-	if err != nil {
-		return fmt.Errorf("bunny: %w", err)
-	}
+	d.recordIDsMu.Lock()
+	ref, ok := d.recordIDs[token]
+	d.recordIDsMu.Unlock()
 
-	subDomain, err := dns01.ExtractSubDomain(fqdn, authZone)
-	if err != nil {
-		return fmt.Errorf("bunny: %w", err)
-	}
-
-	var record *bunny.DNSRecord
-	for _, r := range zone.Records {
-		if deref(r.Name) == subDomain && deref(r.Type) == bunny.DNSRecordTypeTXT {
-			r := r
-			record = &r
-			break
+	if !ok {
+		// the record has not been created by this provider instance (e.g. another process): it's searched by name and value.
+		var err error
+		ref, err = d.findRecord(ctx, fqdn, value)
+		if err != nil {
+			return fmt.Errorf("bunny: %w", err)
 		}
 	}
 
-	if record == nil {
-		return fmt.Errorf("bunny: could not find TXT record zone=%d, subdomain=%s", deref(zone.ID), subDomain)
+	err := d.client.DeleteRecord(ctx, ref.zoneID, ref.recordID)
+	if err != nil {
+		return fmt.Errorf("bunny: failed to delete TXT record: fqdn=%s, zoneID=%d, recordID=%d: %w", fqdn, ref.zoneID, ref.recordID, err)
 	}
 
-	if err := d.client.DNSZone.DeleteDNSRecord(ctx, deref(zone.ID), deref(record.ID)); err != nil {
-		return fmt.Errorf("bunny: failed to delete TXT record: id=%d, name=%s: %w", deref(record.ID), deref(record.Name), err)
-	}
+	d.recordIDsMu.Lock()
+	delete(d.recordIDs, token)
+	d.recordIDsMu.Unlock()
 
 	return nil
 }
 
-func (d *DNSProvider) findZone(ctx context.Context, authZone string) (*bunny.DNSZone, error) {
-	zones, err := d.client.DNSZone.List(ctx, nil)
+func (d *DNSProvider) findRecord(ctx context.Context, fqdn, value string) (recordRef, error) {
+	zone, err := d.client.FindZone(ctx, fqdn)
 	if err != nil {
-		return nil, err
+		return recordRef{}, fmt.Errorf("failed to find zone: fqdn=%s: %w", fqdn, err)
 	}
 
-	var zone *bunny.DNSZone
-	for _, item := range zones.Items {
-		if item != nil && deref(item.Domain) == authZone {
-			zone = item
-			break
-		}
-	}
-
-	if zone == nil {
-		return nil, fmt.Errorf("could not find DNSZone zone=%s", authZone)
-	}
-
-	return zone, nil
-}
-
-func getZone(fqdn string) (string, error) {
-	authZone, err := dns01.FindZoneByFqdn(fqdn)
+	subDomain, err := dns01.ExtractSubDomain(fqdn, zone.Domain)
 	if err != nil {
-		return "", err
+		return recordRef{}, err
 	}
 
-	return dns01.UnFqdn(authZone), nil
-}
-
-func pointer[T string | int | int32 | int64](v T) *T { return &v }
-
-func deref[T string | int | int32 | int64](v *T) T {
-	if v == nil {
-		var zero T
-		return zero
+	for _, record := range zone.Records {
+		if record.Type == internal.RecordTypeTXT && record.Name == subDomain && record.Value == value {
+			return recordRef{zoneID: zone.ID, recordID: record.ID}, nil
+		}
 	}
 
-	return *v
+	return recordRef{}, fmt.Errorf("could not find TXT record: zoneID=%d, subdomain=%s", zone.ID, subDomain)
 }
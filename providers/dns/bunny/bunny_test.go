@@ -1,9 +1,17 @@
 package bunny
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-acme/lego/v4/providers/dns/bunny/internal"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -98,6 +106,77 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func TestDNSProvider_Present(t *testing.T) {
+	provider, zone := setupTest(t)
+
+	err := provider.Present("sub.example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	expected := []internal.Record{{
+		ID:    1,
+		Type:  internal.RecordTypeTXT,
+		TTL:   minTTL,
+		Value: "ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY",
+		Name:  "_acme-challenge.sub",
+	}}
+
+	assert.Equal(t, expected, zone.getRecords())
+}
+
+func TestDNSProvider_Present_tooManyRequests(t *testing.T) {
+	provider, zone := setupTest(t)
+
+	zone.rejections = 2
+
+	err := provider.Present("example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	assert.Len(t, zone.getRecords(), 1)
+	assert.Equal(t, 3, zone.calls)
+}
+
+func TestDNSProvider_Present_tooManyRequests_maxRetries(t *testing.T) {
+	provider, zone := setupTest(t)
+
+	zone.rejections = 10
+
+	err := provider.Present("example.com", "token", "123d==")
+	require.EqualError(t, err, "bunny: failed to add TXT record: fqdn=_acme-challenge.example.com., zoneID=1: unexpected status code: 429: slow down")
+
+	assert.Empty(t, zone.getRecords())
+	assert.Equal(t, 4, zone.calls)
+}
+
+func TestDNSProvider_CleanUp(t *testing.T) {
+	provider, zone := setupTest(t)
+
+	err := provider.Present("example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	err = provider.CleanUp("example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	assert.Empty(t, zone.getRecords())
+}
+
+func TestDNSProvider_CleanUp_unknownToken(t *testing.T) {
+	provider, zone := setupTest(t)
+
+	zone.records = []internal.Record{
+		{ID: 10, Type: internal.RecordTypeTXT, TTL: minTTL, Value: "other", Name: "_acme-challenge"},
+		{ID: 11, Type: internal.RecordTypeTXT, TTL: minTTL, Value: "ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY", Name: "_acme-challenge"},
+	}
+
+	err := provider.CleanUp("example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	expected := []internal.Record{
+		{ID: 10, Type: internal.RecordTypeTXT, TTL: minTTL, Value: "other", Name: "_acme-challenge"},
+	}
+
+	assert.Equal(t, expected, zone.getRecords())
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
@@ -123,3 +202,127 @@ func TestLiveCleanUp(t *testing.T) {
 	err = provider.CleanUp(envTest.GetDomain(), "", "123d==")
 	require.NoError(t, err)
 }
+
+func setupTest(t *testing.T) (*DNSProvider, *fakeZone) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	zone := &fakeZone{}
+
+	mux.HandleFunc("/dnszone", zone.list)
+	mux.HandleFunc("/dnszone/1/records", zone.add)
+	mux.HandleFunc("/dnszone/1/records/", zone.remove)
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+	config.BaseURL = server.URL
+	config.HTTPClient = server.Client()
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	return provider, zone
+}
+
+// fakeZone is a fake Bunny API serving the zones example.org and example.com (ID 1), on 2 pages.
+type fakeZone struct {
+	mu      sync.Mutex
+	records []internal.Record
+	lastID  int64
+
+	// rejections is the number of record creations rejected by the rate limits.
+	rejections int
+	calls      int
+}
+
+func (f *fakeZone) getRecords() []internal.Record {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]internal.Record{}, f.records...)
+}
+
+func (f *fakeZone) list(rw http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result internal.ZonesResponse
+
+	switch req.URL.Query().Get("page") {
+	case "1":
+		result = internal.ZonesResponse{
+			Items:        []internal.Zone{{ID: 2, Domain: "example.org"}},
+			CurrentPage:  1,
+			TotalItems:   2,
+			HasMoreItems: true,
+		}
+	case "2":
+		result = internal.ZonesResponse{
+			Items:       []internal.Zone{{ID: 1, Domain: "example.com", Records: f.records}},
+			CurrentPage: 2,
+			TotalItems:  2,
+		}
+	default:
+		http.Error(rw, "unexpected page", http.StatusBadRequest)
+		return
+	}
+
+	_ = json.NewEncoder(rw).Encode(result)
+}
+
+func (f *fakeZone) add(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPut {
+		http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var record internal.Record
+	err := json.NewDecoder(req.Body).Decode(&record)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+
+	if f.calls <= f.rejections {
+		rw.Header().Set("Retry-After", "0")
+		http.Error(rw, "slow down", http.StatusTooManyRequests)
+		return
+	}
+
+	f.lastID++
+	record.ID = f.lastID
+	f.records = append(f.records, record)
+
+	rw.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(rw).Encode(record)
+}
+
+func (f *fakeZone) remove(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodDelete {
+		http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(req.URL.Path, "/dnszone/1/records/")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, record := range f.records {
+		if fmt.Sprint(record.ID) == id {
+			f.records = append(f.records[:i], f.records[i+1:]...)
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	http.Error(rw, "record not found", http.StatusNotFound)
+}
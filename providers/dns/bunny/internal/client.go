@@ -0,0 +1,217 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/providers/dns/internal/useragent"
+)
+
+// DefaultBaseURL represents the API endpoint to call.
+const DefaultBaseURL = "https://api.bunny.net"
+
+const authHeader = "AccessKey"
+
+// zonesPerPage is the size of the pages of zones (the maximum allowed by the API).
+const zonesPerPage = 1000
+
+// Client the Bunny API client.
+type Client struct {
+	HTTPClient *http.Client
+	BaseURL    *url.URL
+
+	apiKey string
+}
+
+// NewClient creates a new Client.
+// If baseURL is empty, the DefaultBaseURL is used.
+func NewClient(apiKey, baseURL string) (*Client, error) {
+	if apiKey == "" {
+		return nil, errors.New("credentials missing")
+	}
+
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	endpoint, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+
+	return &Client{
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		BaseURL:    endpoint,
+		apiKey:     apiKey,
+	}, nil
+}
+
+// ListZones gets all the DNS zones, the pages are followed while the API reports more items.
+// https://docs.bunny.net/reference/dnszonepublic_index
+func (c *Client) ListZones(ctx context.Context) ([]Zone, error) {
+	var zones []Zone
+
+	for page := 1; ; page++ {
+		endpoint := c.BaseURL.JoinPath("dnszone")
+
+		query := endpoint.Query()
+		query.Set("page", strconv.Itoa(page))
+		query.Set("perPage", strconv.Itoa(zonesPerPage))
+		endpoint.RawQuery = query.Encode()
+
+		var result ZonesResponse
+
+		err := c.do(ctx, http.MethodGet, endpoint, nil, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		zones = append(zones, result.Items...)
+
+		// an empty page stops the pagination, even if the API reports more items.
+		if !result.HasMoreItems || len(result.Items) == 0 {
+			return zones, nil
+		}
+	}
+}
+
+// FindZone finds the zone of the FQDN: the zone with the longest domain matching the FQDN.
+func (c *Client) FindZone(ctx context.Context, fqdn string) (*Zone, error) {
+	zones, err := c.ListZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(fqdn, "."))
+
+	var zone *Zone
+
+	for i := range zones {
+		domain := strings.ToLower(strings.TrimSuffix(zones[i].Domain, "."))
+
+		if name != domain && !strings.HasSuffix(name, "."+domain) {
+			continue
+		}
+
+		if zone == nil || len(domain) > len(zone.Domain) {
+			zone = &zones[i]
+		}
+	}
+
+	if zone == nil {
+		return nil, fmt.Errorf("no zone found for %s", fqdn)
+	}
+
+	return zone, nil
+}
+
+// AddRecord adds a record to a zone.
+// https://docs.bunny.net/reference/dnszonepublic_addrecord
+func (c *Client) AddRecord(ctx context.Context, zoneID int64, record Record) (*Record, error) {
+	endpoint := c.BaseURL.JoinPath("dnszone", strconv.FormatInt(zoneID, 10), "records")
+
+	var result Record
+
+	err := c.do(ctx, http.MethodPut, endpoint, record, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// DeleteRecord deletes a record of a zone.
+// https://docs.bunny.net/reference/dnszonepublic_deleterecord
+func (c *Client) DeleteRecord(ctx context.Context, zoneID, recordID int64) error {
+	endpoint := c.BaseURL.JoinPath("dnszone", strconv.FormatInt(zoneID, 10), "records", strconv.FormatInt(recordID, 10))
+
+	return c.do(ctx, http.MethodDelete, endpoint, nil, nil)
+}
+
+// do sends the request, and decodes the response into result.
+func (c *Client) do(ctx context.Context, method string, endpoint *url.URL, payload, result any) error {
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+
+	req, err := c.makeRequest(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call API: %w", err)
+	}
+
+	return handleResponse(resp, result)
+}
+
+func (c *Client) makeRequest(ctx context.Context, method string, endpoint *url.URL, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(authHeader, c.apiKey)
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	useragent.SetHeader(req.Header)
+
+	return req, nil
+}
+
+func handleResponse(resp *http.Response, result any) error {
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		return readError(resp)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	err := json.NewDecoder(resp.Body).Decode(result)
+	if err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
+
+func readError(resp *http.Response) error {
+	bodyBytes, _ := io.ReadAll(resp.Body)
+
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	err := json.Unmarshal(bodyBytes, apiErr)
+	if err != nil || apiErr.ErrorKey == "" && apiErr.Message == "" {
+		return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+	}
+
+	return apiErr
+}
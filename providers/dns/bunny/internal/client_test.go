@@ -0,0 +1,207 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest(t *testing.T) (*http.ServeMux, *Client) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient("secret", server.URL)
+	require.NoError(t, err)
+
+	client.HTTPClient = server.Client()
+
+	return mux, client
+}
+
+func TestClient_ListZones(t *testing.T) {
+	mux, client := setupTest(t)
+
+	mux.HandleFunc("/dnszone", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get(authHeader) != "secret" {
+			http.Error(rw, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+
+		switch req.URL.Query().Get("page") {
+		case "1":
+			writeFixture(rw, http.StatusOK, "list_zones_page1.json")
+		case "2":
+			writeFixture(rw, http.StatusOK, "list_zones_page2.json")
+		default:
+			http.Error(rw, "unexpected page", http.StatusBadRequest)
+		}
+	})
+
+	zones, err := client.ListZones(context.Background())
+	require.NoError(t, err)
+
+	expected := []Zone{
+		{ID: 1, Domain: "example.com", Records: []Record{}},
+		{ID: 2, Domain: "example.org", Records: []Record{}},
+		{ID: 3, Domain: "sub.example.com", Records: []Record{{ID: 10, Type: RecordTypeTXT, TTL: 60, Value: "txtxtxt", Name: "_acme-challenge"}}},
+	}
+
+	assert.Equal(t, expected, zones)
+}
+
+func TestClient_ListZones_error(t *testing.T) {
+	mux, client := setupTest(t)
+
+	mux.HandleFunc("/dnszone", mockHandler(http.MethodGet, http.StatusBadRequest, "error.json"))
+
+	_, err := client.ListZones(context.Background())
+	require.EqualError(t, err, "400: dnszone.record.invalid (Value): The record value is invalid.")
+}
+
+func TestClient_FindZone(t *testing.T) {
+	mux, client := setupTest(t)
+
+	mux.HandleFunc("/dnszone", func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("page") == "1" {
+			writeFixture(rw, http.StatusOK, "list_zones_page1.json")
+			return
+		}
+
+		writeFixture(rw, http.StatusOK, "list_zones_page2.json")
+	})
+
+	testCases := []struct {
+		fqdn     string
+		expected int64
+	}{
+		{fqdn: "_acme-challenge.example.com.", expected: 1},
+		{fqdn: "_acme-challenge.EXAMPLE.org.", expected: 2},
+		{fqdn: "_acme-challenge.sub.example.com.", expected: 3},
+		{fqdn: "_acme-challenge.a.sub.example.com", expected: 3},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.fqdn, func(t *testing.T) {
+			zone, err := client.FindZone(context.Background(), test.fqdn)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, zone.ID)
+		})
+	}
+
+	_, err := client.FindZone(context.Background(), "_acme-challenge.notexample.com.")
+	require.EqualError(t, err, "no zone found for _acme-challenge.notexample.com.")
+}
+
+func TestClient_AddRecord(t *testing.T) {
+	mux, client := setupTest(t)
+
+	mux.HandleFunc("/dnszone/1/records", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPut {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		var record Record
+		err := json.NewDecoder(req.Body).Decode(&record)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		expected := Record{Type: RecordTypeTXT, TTL: 60, Value: "txtxtxt", Name: "_acme-challenge"}
+		if record != expected {
+			http.Error(rw, fmt.Sprintf("unexpected record: %+v", record), http.StatusBadRequest)
+			return
+		}
+
+		writeFixture(rw, http.StatusCreated, "add_record.json")
+	})
+
+	record, err := client.AddRecord(context.Background(), 1, Record{Type: RecordTypeTXT, TTL: 60, Value: "txtxtxt", Name: "_acme-challenge"})
+	require.NoError(t, err)
+
+	expected := &Record{ID: 42, Type: RecordTypeTXT, TTL: 60, Value: "txtxtxt", Name: "_acme-challenge"}
+
+	assert.Equal(t, expected, record)
+}
+
+func TestClient_AddRecord_error(t *testing.T) {
+	mux, client := setupTest(t)
+
+	mux.HandleFunc("/dnszone/1/records", mockHandler(http.MethodPut, http.StatusBadRequest, "error.json"))
+
+	_, err := client.AddRecord(context.Background(), 1, Record{Type: RecordTypeTXT, Value: "txtxtxt", Name: "_acme-challenge"})
+	require.EqualError(t, err, "400: dnszone.record.invalid (Value): The record value is invalid.")
+}
+
+func TestClient_DeleteRecord(t *testing.T) {
+	mux, client := setupTest(t)
+
+	mux.HandleFunc("/dnszone/1/records/42", mockHandler(http.MethodDelete, http.StatusNoContent, ""))
+
+	err := client.DeleteRecord(context.Background(), 1, 42)
+	require.NoError(t, err)
+}
+
+func TestClient_DeleteRecord_error(t *testing.T) {
+	mux, client := setupTest(t)
+
+	mux.HandleFunc("/dnszone/1/records/42", func(rw http.ResponseWriter, _ *http.Request) {
+		http.Error(rw, "not found", http.StatusNotFound)
+	})
+
+	err := client.DeleteRecord(context.Background(), 1, 42)
+	require.EqualError(t, err, "unexpected status code: 404: not found")
+}
+
+func mockHandler(method string, statusCode int, filename string) func(http.ResponseWriter, *http.Request) {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != method {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if filename == "" {
+			rw.WriteHeader(statusCode)
+			return
+		}
+
+		writeFixture(rw, statusCode, filename)
+	}
+}
+
+func writeFixture(rw http.ResponseWriter, statusCode int, filename string) {
+	file, err := os.Open(filepath.FromSlash(path.Join("./fixtures", filename)))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	rw.WriteHeader(statusCode)
+
+	_, err = io.Copy(rw, file)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,52 @@
+package internal
+
+import "fmt"
+
+// RecordTypeTXT is the type of the TXT records.
+const RecordTypeTXT = 3
+
+// APIError an error returned by the API.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	ErrorKey   string `json:"ErrorKey,omitempty"`
+	Field      string `json:"Field,omitempty"`
+	Message    string `json:"Message,omitempty"`
+}
+
+func (a APIError) Error() string {
+	msg := fmt.Sprintf("%d: %s", a.StatusCode, a.ErrorKey)
+
+	if a.Field != "" {
+		msg += fmt.Sprintf(" (%s)", a.Field)
+	}
+
+	if a.Message != "" {
+		msg += ": " + a.Message
+	}
+
+	return msg
+}
+
+// ZonesResponse a page of DNS zones.
+type ZonesResponse struct {
+	Items        []Zone `json:"Items"`
+	CurrentPage  int    `json:"CurrentPage"`
+	TotalItems   int    `json:"TotalItems"`
+	HasMoreItems bool   `json:"HasMoreItems"`
+}
+
+// Zone a DNS zone.
+type Zone struct {
+	ID      int64    `json:"Id"`
+	Domain  string   `json:"Domain"`
+	Records []Record `json:"Records,omitempty"`
+}
+
+// Record a DNS record.
+type Record struct {
+	ID    int64  `json:"Id,omitempty"`
+	Type  int    `json:"Type"`
+	TTL   int    `json:"Ttl,omitempty"`
+	Value string `json:"Value"`
+	Name  string `json:"Name"`
+}
@@ -21,6 +21,10 @@ type Client struct {
 	authPassword string
 	HTTPClient   *http.Client
 	BaseURL      *url.URL
+
+	// findZoneByFqdn determines the zone apex for the given fqdn.
+	// It is overridden during tests.
+	findZoneByFqdn func(fqdn string) (string, error)
 }
 
 // NewClient creates a ClouDNS client.
@@ -39,17 +43,18 @@ func NewClient(authID, subAuthID, authPassword string) (*Client, error) {
 	}
 
 	return &Client{
-		authID:       authID,
-		subAuthID:    subAuthID,
-		authPassword: authPassword,
-		HTTPClient:   &http.Client{},
-		BaseURL:      baseURL,
+		authID:         authID,
+		subAuthID:      subAuthID,
+		authPassword:   authPassword,
+		HTTPClient:     &http.Client{},
+		BaseURL:        baseURL,
+		findZoneByFqdn: dns01.FindZoneByFqdn,
 	}, nil
 }
 
 // GetZone Get domain name information for a FQDN.
 func (c *Client) GetZone(authFQDN string) (*Zone, error) {
-	authZone, err := dns01.FindZoneByFqdn(authFQDN)
+	authZone, err := c.findZoneByFqdn(authFQDN)
 	if err != nil {
 		return nil, err
 	}
@@ -180,7 +185,7 @@ func (c *Client) AddTxtRecord(zoneName, fqdn, value string, ttl int) error {
 
 	raw, err := c.doRequest(http.MethodPost, endpoint)
 	if err != nil {
-		return err
+		return wrapAPIError("failed to add TXT record", err)
 	}
 
 	resp := apiResponse{}
@@ -189,7 +194,7 @@ func (c *Client) AddTxtRecord(zoneName, fqdn, value string, ttl int) error {
 	}
 
 	if resp.Status != "Success" {
-		return fmt.Errorf("failed to add TXT record: %s %s", resp.Status, resp.StatusDescription)
+		return fmt.Errorf("failed to add TXT record: %w", &APIError{Status: resp.Status, StatusDescription: resp.StatusDescription})
 	}
 
 	return nil
@@ -206,7 +211,7 @@ func (c *Client) RemoveTxtRecord(recordID int, zoneName string) error {
 
 	raw, err := c.doRequest(http.MethodPost, endpoint)
 	if err != nil {
-		return err
+		return wrapAPIError("failed to remove TXT record", err)
 	}
 
 	resp := apiResponse{}
@@ -215,7 +220,7 @@ func (c *Client) RemoveTxtRecord(recordID int, zoneName string) error {
 	}
 
 	if resp.Status != "Success" {
-		return fmt.Errorf("failed to remove TXT record: %s %s", resp.Status, resp.StatusDescription)
+		return fmt.Errorf("failed to remove TXT record: %w", &APIError{Status: resp.Status, StatusDescription: resp.StatusDescription})
 	}
 
 	return nil
@@ -276,7 +281,33 @@ func (c *Client) doRequest(method string, uri *url.URL) (json.RawMessage, error)
 		return nil, fmt.Errorf("invalid code (%d), error: %s", resp.StatusCode, content)
 	}
 
-	return content, nil
+	return content, checkStatus(content)
+}
+
+// checkStatus detects the errors returned inside the status wrapper of the API, with an HTTP status 200.
+func checkStatus(content []byte) error {
+	if len(content) == 0 || content[0] != '{' {
+		return nil
+	}
+
+	var resp apiResponse
+
+	// the successful responses are not always a status wrapper (e.g. a map of records): the unmarshal errors are ignored.
+	if json.Unmarshal(content, &resp) != nil || resp.Status != statusFailed {
+		return nil
+	}
+
+	return &APIError{Status: resp.Status, StatusDescription: resp.StatusDescription}
+}
+
+// wrapAPIError adds the message to the errors returned inside the status wrapper of the API.
+func wrapAPIError(msg string, err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+
+	return err
 }
 
 func (c *Client) buildRequest(method string, uri *url.URL) (*http.Request, error) {
@@ -112,6 +112,14 @@ func TestClient_GetZone(t *testing.T) {
 				errorMsg: "failed to unmarshal zone: json: cannot unmarshal array into Go value of type internal.Zone",
 			},
 		},
+		{
+			desc:        "failed status",
+			authFQDN:    "_acme-challenge.foo.com.",
+			apiResponse: `{"status":"Failed","statusDescription":"Invalid authentication, incorrect auth-id or auth-password."}`,
+			expected: expected{
+				errorMsg: "Failed Invalid authentication, incorrect auth-id or auth-password.",
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -123,6 +131,9 @@ func TestClient_GetZone(t *testing.T) {
 			require.NoError(t, err)
 
 			client.BaseURL, _ = url.Parse(server.URL)
+			client.findZoneByFqdn = func(_ string) (string, error) {
+				return "foo.com.", nil
+			}
 
 			zone, err := client.GetZone(test.authFQDN)
 
@@ -225,6 +236,15 @@ func TestClient_FindTxtRecord(t *testing.T) {
 				errorMsg: "failed to unmarshall TXT records: json: cannot unmarshal array into Go value of type map[string]internal.TXTRecord: [{}]",
 			},
 		},
+		{
+			desc:        "failed status",
+			authFQDN:    "_acme-challenge.example.com.",
+			zoneName:    "example.com",
+			apiResponse: `{"status":"Failed","statusDescription":"Missing domain-name param."}`,
+			expected: expected{
+				errorMsg: "Failed Missing domain-name param.",
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -594,6 +614,13 @@ func TestClient_GetUpdateStatus(t *testing.T) {
 			apiResponse: `[x]`,
 			expected:    expected{errorMsg: "failed to unmarshal UpdateRecord: invalid character 'x' looking for beginning of value: [x]"},
 		},
+		{
+			desc:        "failed status",
+			authFQDN:    "_acme-challenge.foo.com.",
+			zoneName:    "test-zone",
+			apiResponse: `{"status":"Failed","statusDescription":"Invalid authentication, incorrect auth-id or auth-password."}`,
+			expected:    expected{errorMsg: "Failed Invalid authentication, incorrect auth-id or auth-password."},
+		},
 	}
 
 	for _, test := range testCases {
@@ -1,10 +1,23 @@
 package internal
 
+const statusFailed = "Failed"
+
 type apiResponse struct {
 	Status            string `json:"status"`
 	StatusDescription string `json:"statusDescription"`
 }
 
+// APIError is the error returned by the API inside a status wrapper (`{"status": "Failed", "statusDescription": "..."}`),
+// with an HTTP status 200.
+type APIError struct {
+	Status            string
+	StatusDescription string
+}
+
+func (a *APIError) Error() string {
+	return a.Status + " " + a.StatusDescription
+}
+
 // Zone is a zone.
 type Zone struct {
 	Name   string
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-acme/lego/v4/challenge/dns01"
@@ -24,6 +25,7 @@ const (
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
 	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
+	EnvMaxRetries         = envNamespace + "MAX_RETRIES"
 )
 
 // https://github.com/desec-io/desec-stack/issues/216
@@ -37,6 +39,11 @@ type Config struct {
 	PollingInterval    time.Duration
 	TTL                int
 	HTTPClient         *http.Client
+
+	// MaxRetries is the maximum number of retries of an API request,
+	// the requests rejected by the rate limits of deSEC (429) are retried with a backoff.
+	// https://desec.readthedocs.io/en/latest/rate-limits.html
+	MaxRetries int
 }
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
@@ -48,6 +55,7 @@ func NewDefaultConfig() *Config {
 		HTTPClient: &http.Client{
 			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
+		MaxRetries: env.GetOrDefaultInt(EnvMaxRetries, 5),
 	}
 }
 
@@ -86,6 +94,7 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		opts.HTTPClient = config.HTTPClient
 	}
 	opts.Logger = log.Default()
+	opts.RetryMax = config.MaxRetries
 
 	client := desec.New(config.Token, opts)
 
@@ -104,7 +113,7 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	fqdn, value := dns01.GetRecord(domain, keyAuth)
 	quotedValue := fmt.Sprintf(`%q`, value)
 
-	authZone, err := dns01.FindZoneByFqdn(fqdn)
+	authZone, err := d.findZone(ctx, fqdn)
 	if err != nil {
 		return fmt.Errorf("desec: could not find zone for domain %q and fqdn %q : %w", domain, fqdn, err)
 	}
@@ -154,7 +163,7 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	ctx := context.Background()
 	fqdn, value := dns01.GetRecord(domain, keyAuth)
 
-	authZone, err := dns01.FindZoneByFqdn(fqdn)
+	authZone, err := d.findZone(ctx, fqdn)
 	if err != nil {
 		return fmt.Errorf("desec: could not find zone for domain %q and fqdn %q : %w", domain, fqdn, err)
 	}
@@ -185,3 +194,31 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	return nil
 }
+
+// findZone returns the domain of the account which is the longest match of the FQDN.
+// https://desec.readthedocs.io/en/latest/dns/domains.html#listing-domains
+func (d *DNSProvider) findZone(ctx context.Context, fqdn string) (string, error) {
+	domains, err := d.client.Domains.GetAll(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list domains: %w", err)
+	}
+
+	name := dns01.UnFqdn(fqdn)
+
+	var zone string
+	for _, domain := range domains {
+		if name != domain.Name && !strings.HasSuffix(name, "."+domain.Name) {
+			continue
+		}
+
+		if len(domain.Name) > len(zone) {
+			zone = domain.Name
+		}
+	}
+
+	if zone == "" {
+		return "", errors.New("no matching domain in the account")
+	}
+
+	return dns01.ToFqdn(zone), nil
+}
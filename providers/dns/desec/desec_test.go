@@ -1,10 +1,17 @@
 package desec
 
 import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/nrdcg/desec"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -12,6 +19,56 @@ const envDomain = envNamespace + "DOMAIN"
 
 var envTest = tester.NewEnvTest(EnvToken).WithDomain(envDomain)
 
+func setupTest(t *testing.T) (*DNSProvider, *http.ServeMux) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/domains/", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, "invalid method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		writeJSON(rw, []desec.Domain{{Name: "example.com"}, {Name: "sub.example.com"}, {Name: "other.com"}})
+	})
+
+	config := NewDefaultConfig()
+	config.Token = "secret"
+	config.HTTPClient = server.Client()
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client.BaseURL = server.URL + "/"
+
+	return provider, mux
+}
+
+func writeJSON(rw http.ResponseWriter, data interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(rw).Encode(data)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func readRRSet(t *testing.T, req *http.Request) desec.RRSet {
+	t.Helper()
+
+	raw, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+
+	var rrSet desec.RRSet
+	err = json.Unmarshal(raw, &rrSet)
+	require.NoError(t, err)
+
+	return rrSet
+}
+
 func TestNewDNSProvider(t *testing.T) {
 	testCases := []struct {
 		desc     string
@@ -87,6 +144,122 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func TestDNSProvider_Present_create(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	var created desec.RRSet
+
+	mux.HandleFunc("/domains/sub.example.com/rrsets/_acme-challenge.www/TXT/", func(rw http.ResponseWriter, _ *http.Request) {
+		http.Error(rw, `{"detail": "Not found."}`, http.StatusNotFound)
+	})
+
+	mux.HandleFunc("/domains/sub.example.com/rrsets/", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, "invalid method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		created = readRRSet(t, req)
+
+		rw.WriteHeader(http.StatusCreated)
+		writeJSON(rw, created)
+	})
+
+	err := provider.Present("www.sub.example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.Equal(t, "sub.example.com", created.Domain)
+	assert.Equal(t, "_acme-challenge.www", created.SubName)
+	assert.Equal(t, "TXT", created.Type)
+	assert.Equal(t, []string{`"ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY"`}, created.Records)
+}
+
+func TestDNSProvider_Present_update(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	var updated desec.RRSet
+
+	mux.HandleFunc("/domains/example.com/rrsets/_acme-challenge/TXT/", func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			writeJSON(rw, desec.RRSet{Domain: "example.com", SubName: "_acme-challenge", Type: "TXT", Records: []string{`"existing"`}})
+		case http.MethodPatch:
+			updated = readRRSet(t, req)
+			writeJSON(rw, updated)
+		default:
+			http.Error(rw, "invalid method", http.StatusMethodNotAllowed)
+		}
+	})
+
+	err := provider.Present("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`"existing"`, `"ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY"`}, updated.Records)
+}
+
+func TestDNSProvider_Present_unknownDomain(t *testing.T) {
+	provider, _ := setupTest(t)
+
+	err := provider.Present("example.org", "", "123d==")
+	require.EqualError(t, err, `desec: could not find zone for domain "example.org" and fqdn "_acme-challenge.example.org." : no matching domain in the account`)
+}
+
+func TestDNSProvider_Present_rateLimited(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	var calls int32
+
+	mux.HandleFunc("/domains/example.com/rrsets/_acme-challenge/TXT/", func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			rw.Header().Set("Retry-After", "0")
+			http.Error(rw, `{"detail": "Request was throttled."}`, http.StatusTooManyRequests)
+			return
+		}
+
+		switch req.Method {
+		case http.MethodGet:
+			writeJSON(rw, desec.RRSet{Domain: "example.com", SubName: "_acme-challenge", Type: "TXT", Records: []string{}})
+		case http.MethodPatch:
+			writeJSON(rw, readRRSet(t, req))
+		default:
+			http.Error(rw, "invalid method", http.StatusMethodNotAllowed)
+		}
+	})
+
+	err := provider.Present("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+}
+
+func TestDNSProvider_CleanUp(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	var updated desec.RRSet
+
+	mux.HandleFunc("/domains/example.com/rrsets/_acme-challenge/TXT/", func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			writeJSON(rw, desec.RRSet{
+				Domain:  "example.com",
+				SubName: "_acme-challenge",
+				Type:    "TXT",
+				Records: []string{`"existing"`, `"ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY"`},
+			})
+		case http.MethodPatch:
+			updated = readRRSet(t, req)
+			writeJSON(rw, updated)
+		default:
+			http.Error(rw, "invalid method", http.StatusMethodNotAllowed)
+		}
+	})
+
+	err := provider.CleanUp("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`"existing"`}, updated.Records)
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
@@ -0,0 +1,201 @@
+// Package file implements a DNS provider which writes the DNS records to a local directory, to be synchronized by an external system.
+package file
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/internal/atomicfile"
+	"github.com/go-acme/lego/v4/log"
+	"github.com/go-acme/lego/v4/platform/config/env"
+	"github.com/go-acme/lego/v4/platform/wait"
+)
+
+// Environment variables names.
+const (
+	envNamespace = "FILE_"
+
+	EnvDirectory = envNamespace + "DIRECTORY"
+	EnvHook      = envNamespace + "HOOK"
+
+	EnvSignalTimeout      = envNamespace + "SIGNAL_TIMEOUT"
+	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
+	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
+)
+
+// signalSuffix is the suffix of the file created by the external system when the DNS record is published.
+const signalSuffix = ".ready"
+
+// Record is the JSON document written for each challenge.
+type Record struct {
+	Domain           string `json:"domain"`
+	FQDN             string `json:"fqdn"`
+	Value            string `json:"value"`
+	Token            string `json:"token"`
+	KeyAuthorization string `json:"keyAuthorization"`
+}
+
+// Config is used to configure the creation of the DNSProvider.
+type Config struct {
+	Directory          string
+	Hook               string
+	SignalTimeout      time.Duration
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+}
+
+// NewDefaultConfig returns a default configuration for the DNSProvider.
+func NewDefaultConfig() *Config {
+	return &Config{
+		SignalTimeout:      env.GetOrDefaultSecond(EnvSignalTimeout, 10*time.Minute),
+		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+	}
+}
+
+// DNSProvider implements the challenge.Provider interface.
+type DNSProvider struct {
+	config *Config
+}
+
+// NewDNSProvider returns a DNSProvider instance configured for writing the DNS records to a local directory.
+// The directory is read from the environment variable FILE_DIRECTORY.
+func NewDNSProvider() (*DNSProvider, error) {
+	values, err := env.Get(EnvDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("file: %w", err)
+	}
+
+	config := NewDefaultConfig()
+	config.Directory = values[EnvDirectory]
+	config.Hook = env.GetOrFile(EnvHook)
+
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig return a DNSProvider instance configured for writing the DNS records to a local directory.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("file: the configuration of the DNS provider is nil")
+	}
+
+	if config.Directory == "" {
+		return nil, errors.New("file: missing directory")
+	}
+
+	info, err := os.Stat(config.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("file: %w", err)
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("file: %s is not a directory", config.Directory)
+	}
+
+	return &DNSProvider{config: config}, nil
+}
+
+// Present writes the DNS record to a JSON file, and waits for the external system to publish it.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+
+	record := Record{
+		Domain:           domain,
+		FQDN:             fqdn,
+		Value:            value,
+		Token:            token,
+		KeyAuthorization: keyAuth,
+	}
+
+	path := d.recordPath(fqdn, token)
+
+	err := writeRecord(path, record)
+	if err != nil {
+		return fmt.Errorf("file: %w", err)
+	}
+
+	log.Infof("file: the DNS record for %s has been written to %s", fqdn, path)
+
+	if d.config.Hook != "" {
+		err = d.runHook(path)
+		if err != nil {
+			return fmt.Errorf("file: hook: %w", err)
+		}
+
+		return nil
+	}
+
+	signal := path + signalSuffix
+
+	err = wait.For("file: signal "+signal, d.config.SignalTimeout, d.config.PollingInterval, func() (bool, error) {
+		_, errS := os.Stat(signal)
+		if errS != nil {
+			return false, errS
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("file: %w", err)
+	}
+
+	return nil
+}
+
+// CleanUp removes the JSON file of the DNS record and the signal file.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	fqdn, _ := dns01.GetRecord(domain, keyAuth)
+
+	path := d.recordPath(fqdn, token)
+
+	for _, p := range []string{path, path + signalSuffix} {
+		err := os.Remove(p)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation.
+// Adjusting here to cope with spikes in propagation times.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.config.PropagationTimeout, d.config.PollingInterval
+}
+
+// recordPath returns the path of the JSON file of a challenge.
+// The token is part of the file name because several challenges can share the same FQDN (e.g. wildcard and apex domains).
+func (d *DNSProvider) recordPath(fqdn, token string) string {
+	return filepath.Join(d.config.Directory, fmt.Sprintf("%s_%s.json", dns01.UnFqdn(fqdn), token))
+}
+
+// runHook runs the hook with the path of the JSON file as argument,
+// the hook must exit when the DNS record is published.
+func (d *DNSProvider) runHook(path string) error {
+	cmd := exec.Command(d.config.Hook, path)
+
+	output, err := cmd.CombinedOutput()
+	if len(output) > 0 {
+		log.Println(string(output))
+	}
+
+	return err
+}
+
+// writeRecord writes the JSON document atomically (a temporary file is renamed),
+// to avoid the external system reading a partial file.
+func writeRecord(path string, record Record) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(path, data)
+}
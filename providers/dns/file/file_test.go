@@ -0,0 +1,200 @@
+package file
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var envTest = tester.NewEnvTest(EnvDirectory, EnvHook)
+
+func TestNewDNSProvider(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		envVars  map[string]string
+		expected string
+	}{
+		{
+			desc: "success",
+			envVars: map[string]string{
+				EnvDirectory: t.TempDir(),
+			},
+		},
+		{
+			desc: "missing directory",
+			envVars: map[string]string{
+				EnvDirectory: "",
+			},
+			expected: "file: some credentials information are missing: FILE_DIRECTORY",
+		},
+		{
+			desc: "not a directory",
+			envVars: map[string]string{
+				EnvDirectory: "file.go",
+			},
+			expected: "file: file.go is not a directory",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			defer envTest.RestoreEnv()
+			envTest.ClearEnv()
+
+			envTest.Apply(test.envVars)
+
+			p, err := NewDNSProvider()
+
+			if test.expected == "" {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewDNSProviderConfig(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		directory string
+		expected  string
+	}{
+		{
+			desc:      "success",
+			directory: t.TempDir(),
+		},
+		{
+			desc:     "missing directory",
+			expected: "file: missing directory",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.Directory = test.directory
+
+			p, err := NewDNSProviderConfig(config)
+
+			if test.expected == "" {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_Present_signal(t *testing.T) {
+	dir := t.TempDir()
+
+	config := NewDefaultConfig()
+	config.Directory = dir
+	config.SignalTimeout = 5 * time.Second
+	config.PollingInterval = 10 * time.Millisecond
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "_acme-challenge.example.com_token.json")
+
+	// simulates the external system.
+	go func() {
+		for {
+			if _, errS := os.Stat(path); errS == nil {
+				_ = os.WriteFile(path+signalSuffix, nil, 0o600)
+				return
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	err = provider.Present("example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var record Record
+	err = json.Unmarshal(data, &record)
+	require.NoError(t, err)
+
+	expected := Record{
+		Domain:           "example.com",
+		FQDN:             "_acme-challenge.example.com.",
+		Value:            "pW9ZKG0xz_PCriK-nCMOjADy9eJcgGWIzkkj2fN4uZM",
+		Token:            "token",
+		KeyAuthorization: "keyAuth",
+	}
+	assert.Equal(t, expected, record)
+
+	err = provider.CleanUp("example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestDNSProvider_Present_signalTimeout(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Directory = t.TempDir()
+	config.SignalTimeout = 50 * time.Millisecond
+	config.PollingInterval = 10 * time.Millisecond
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = provider.Present("example.com", "token", "keyAuth")
+	require.Error(t, err)
+}
+
+func TestDNSProvider_Present_hook(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		hook        string
+		expectError bool
+	}{
+		{
+			desc: "success",
+			hook: "true",
+		},
+		{
+			desc:        "hook error",
+			hook:        "false",
+			expectError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.Directory = t.TempDir()
+			config.Hook = test.hook
+
+			provider, err := NewDNSProviderConfig(config)
+			require.NoError(t, err)
+
+			err = provider.Present("example.com", "token", "keyAuth")
+			if test.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.FileExists(t, filepath.Join(config.Directory, "_acme-challenge.example.com_token.json"))
+		})
+	}
+}
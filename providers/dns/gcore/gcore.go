@@ -1,3 +1,4 @@
+// Package gcore implements a DNS provider for solving the DNS-01 challenge using Gcore DNS.
 package gcore
 
 import (
@@ -16,6 +17,9 @@ import (
 const (
 	defaultPropagationTimeout = 360 * time.Second
 	defaultPollingInterval    = 20 * time.Second
+
+	// minTTL the minimum TTL of the records accepted by the provider.
+	minTTL = 120
 )
 
 // Environment variables names.
@@ -42,7 +46,7 @@ type Config struct {
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
+		TTL:                env.GetOrDefaultInt(EnvTTL, minTTL),
 		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, defaultPropagationTimeout),
 		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, defaultPollingInterval),
 		HTTPClient: &http.Client{
@@ -57,7 +61,7 @@ type DNSProvider struct {
 	client *internal.Client
 }
 
-// NewDNSProvider returns an instance of DNSProvider configured for G-Core Labs DNS API.
+// NewDNSProvider returns an instance of DNSProvider configured for Gcore DNS API.
 func NewDNSProvider() (*DNSProvider, error) {
 	values, err := env.Get(EnvPermanentAPIToken)
 	if err != nil {
@@ -70,7 +74,7 @@ func NewDNSProvider() (*DNSProvider, error) {
 	return NewDNSProviderConfig(config)
 }
 
-// NewDNSProviderConfig return a DNSProvider instance configured for  G-Core Labs DNS API.
+// NewDNSProviderConfig return a DNSProvider instance configured for Gcore DNS API.
 func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 	if config == nil {
 		return nil, errors.New("gcore: the configuration of the DNS provider is nil")
@@ -80,6 +84,10 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		return nil, errors.New("gcore: incomplete credentials provided")
 	}
 
+	if config.TTL < minTTL {
+		return nil, fmt.Errorf("gcore: invalid TTL, TTL (%d) must be greater than %d", config.TTL, minTTL)
+	}
+
 	client := internal.NewClient(config.APIToken)
 
 	if config.HTTPClient != nil {
@@ -112,8 +120,9 @@ func (d *DNSProvider) Present(domain, _, keyAuth string) error {
 }
 
 // CleanUp removes the record matching the specified parameters.
+// The other values of the RRSet (e.g. of another challenge on the same FQDN) are kept.
 func (d *DNSProvider) CleanUp(domain, _, keyAuth string) error {
-	fqdn, _ := dns01.GetRecord(domain, keyAuth)
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
 
 	ctx := context.Background()
 
@@ -122,7 +131,7 @@ func (d *DNSProvider) CleanUp(domain, _, keyAuth string) error {
 		return fmt.Errorf("gcore: %w", err)
 	}
 
-	err = d.client.DeleteRRSet(ctx, zone, dns01.UnFqdn(fqdn))
+	err = d.client.RemoveRRSetValue(ctx, zone, dns01.UnFqdn(fqdn), value)
 	if err != nil {
 		return fmt.Errorf("gcore: remove txt record: %w", err)
 	}
@@ -136,19 +145,25 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 	return d.config.PropagationTimeout, d.config.PollingInterval
 }
 
+// guessZone returns the most specific zone of the account containing the FQDN.
 func (d *DNSProvider) guessZone(ctx context.Context, fqdn string) (string, error) {
-	var lastErr error
+	zones, err := d.client.ListZones(ctx)
+	if err != nil {
+		return "", err
+	}
 
-	for _, zone := range extractAllZones(fqdn) {
-		dnsZone, err := d.client.GetZone(ctx, zone)
-		if err == nil {
-			return dnsZone.Name, nil
-		}
+	names := make(map[string]string, len(zones))
+	for _, zone := range zones {
+		names[strings.ToLower(dns01.UnFqdn(zone.Name))] = zone.Name
+	}
 
-		lastErr = err
+	for _, candidate := range extractAllZones(strings.ToLower(fqdn)) {
+		if name, ok := names[candidate]; ok {
+			return name, nil
+		}
 	}
 
-	return "", fmt.Errorf("zone %q not found: %w", fqdn, lastErr)
+	return "", fmt.Errorf("zone %q not found", fqdn)
 }
 
 func extractAllZones(fqdn string) []string {
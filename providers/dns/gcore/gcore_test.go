@@ -1,9 +1,18 @@
 package gcore
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-acme/lego/v4/providers/dns/gcore/internal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -56,22 +65,32 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	testCases := []struct {
 		desc     string
 		apiToken string
+		ttl      int
 		expected string
 	}{
 		{
 			desc:     "success",
 			apiToken: "A",
+			ttl:      minTTL,
 		},
 		{
 			desc:     "missing credentials",
+			ttl:      minTTL,
 			expected: "gcore: incomplete credentials provided",
 		},
+		{
+			desc:     "TTL too low",
+			apiToken: "A",
+			ttl:      60,
+			expected: "gcore: invalid TTL, TTL (60) must be greater than 120",
+		},
 	}
 
 	for _, test := range testCases {
 		t.Run(test.desc, func(t *testing.T) {
 			config := NewDefaultConfig()
 			config.APIToken = test.apiToken
+			config.TTL = test.ttl
 
 			p, err := NewDNSProviderConfig(config)
 
@@ -87,6 +106,83 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func setupTest(t *testing.T) (*DNSProvider, *fakeAPI) {
+	t.Helper()
+
+	api := &fakeAPI{rrsets: map[string]internal.RRSet{}}
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	config := NewDefaultConfig()
+	config.APIToken = "secret"
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client.BaseURL, _ = url.Parse(server.URL)
+
+	return provider, api
+}
+
+func TestDNSProvider_Present(t *testing.T) {
+	provider, api := setupTest(t)
+
+	err := provider.Present("www.example.com", "", "123d==")
+	require.NoError(t, err)
+
+	_, value := dns01.GetRecord("www.example.com", "123d==")
+
+	expected := map[string]internal.RRSet{
+		"example.com/_acme-challenge.www.example.com": {TTL: minTTL, Records: []internal.Records{{Content: []string{value}}}},
+	}
+
+	assert.Equal(t, expected, api.rrsets)
+
+	// idempotent.
+	err = provider.Present("www.example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, api.rrsets)
+}
+
+func TestDNSProvider_Present_zoneNotFound(t *testing.T) {
+	provider, _ := setupTest(t)
+
+	err := provider.Present("example.net", "", "123d==")
+	require.EqualError(t, err, `gcore: zone "_acme-challenge.example.net." not found`)
+}
+
+func TestDNSProvider_wildcardAndApex(t *testing.T) {
+	provider, api := setupTest(t)
+
+	// the wildcard and the apex share the same RRSet.
+	err := provider.Present("example.com", "", "wildcard")
+	require.NoError(t, err)
+
+	err = provider.Present("example.com", "", "apex")
+	require.NoError(t, err)
+
+	rrset := api.rrsets["example.com/_acme-challenge.example.com"]
+	require.Len(t, rrset.Records, 2)
+
+	err = provider.CleanUp("example.com", "", "wildcard")
+	require.NoError(t, err)
+
+	_, value := dns01.GetRecord("example.com", "apex")
+
+	expected := map[string]internal.RRSet{
+		"example.com/_acme-challenge.example.com": {TTL: minTTL, Records: []internal.Records{{Content: []string{value}}}},
+	}
+
+	assert.Equal(t, expected, api.rrsets)
+
+	err = provider.CleanUp("example.com", "", "apex")
+	require.NoError(t, err)
+
+	assert.Empty(t, api.rrsets)
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
@@ -140,3 +236,62 @@ func Test_extractAllZones(t *testing.T) {
 		})
 	}
 }
+
+// fakeAPI an in-memory Gcore DNS API, with the zones "example.com" and "sub.example.com".
+type fakeAPI struct {
+	mu     sync.Mutex
+	rrsets map[string]internal.RRSet
+}
+
+func (f *fakeAPI) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if req.Header.Get("Authorization") != "APIKey secret" {
+		http.Error(rw, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	if req.URL.Path == "/v2/zones" {
+		zones := []internal.Zone{{Name: "example.com"}, {Name: "sub.example.com"}}
+		_ = json.NewEncoder(rw).Encode(internal.ListZonesResponse{Zones: zones, TotalAmount: len(zones)})
+
+		return
+	}
+
+	// /v2/zones/{zone}/{name}/TXT
+	var zone, name string
+	if _, err := fmt.Sscanf(strings.ReplaceAll(req.URL.Path, "/", " "), " v2 zones %s %s TXT", &zone, &name); err != nil {
+		http.NotFound(rw, req)
+		return
+	}
+
+	key := zone + "/" + name
+
+	switch req.Method {
+	case http.MethodGet:
+		rrset, ok := f.rrsets[key]
+		if !ok {
+			http.NotFound(rw, req)
+			return
+		}
+
+		_ = json.NewEncoder(rw).Encode(rrset)
+
+	case http.MethodPost, http.MethodPut:
+		var rrset internal.RRSet
+		err := json.NewDecoder(req.Body).Decode(&rrset)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		f.rrsets[key] = rrset
+
+	case http.MethodDelete:
+		delete(f.rrsets, key)
+
+	default:
+		http.Error(rw, "unsupported method", http.StatusMethodNotAllowed)
+	}
+}
@@ -8,20 +8,22 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const (
-	defaultBaseURL = "https://api.gcorelabs.com/dns"
+	defaultBaseURL = "https://api.gcore.com/dns"
 	tokenHeader    = "APIKey"
 	txtRecordType  = "TXT"
+	zonesPageSize  = 100
 )
 
 // Client for DNS API.
 type Client struct {
 	HTTPClient *http.Client
-	baseURL    *url.URL
+	BaseURL    *url.URL
 	token      string
 }
 
@@ -31,15 +33,42 @@ func NewClient(token string) *Client {
 
 	return &Client{
 		token:      token,
-		baseURL:    baseURL,
+		BaseURL:    baseURL,
 		HTTPClient: &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
+// ListZones gets all the zones.
+// https://dnsapi.gcorelabs.com/docs#operation/Zones
+func (c *Client) ListZones(ctx context.Context) ([]Zone, error) {
+	var zones []Zone
+
+	for {
+		endpoint := c.BaseURL.JoinPath("v2", "zones")
+
+		query := endpoint.Query()
+		query.Set("limit", strconv.Itoa(zonesPageSize))
+		query.Set("offset", strconv.Itoa(len(zones)))
+		endpoint.RawQuery = query.Encode()
+
+		var result ListZonesResponse
+		err := c.do(ctx, http.MethodGet, endpoint, nil, &result)
+		if err != nil {
+			return nil, fmt.Errorf("list zones: %w", err)
+		}
+
+		zones = append(zones, result.Zones...)
+
+		if len(result.Zones) == 0 || len(zones) >= result.TotalAmount {
+			return zones, nil
+		}
+	}
+}
+
 // GetZone gets zone information.
 // https://dnsapi.gcorelabs.com/docs#operation/Zone
 func (c *Client) GetZone(ctx context.Context, name string) (Zone, error) {
-	endpoint := c.baseURL.JoinPath("v2", "zones", name)
+	endpoint := c.BaseURL.JoinPath("v2", "zones", name)
 
 	zone := Zone{}
 	err := c.do(ctx, http.MethodGet, endpoint, nil, &zone)
@@ -53,7 +82,7 @@ func (c *Client) GetZone(ctx context.Context, name string) (Zone, error) {
 // GetRRSet gets RRSet item.
 // https://dnsapi.gcorelabs.com/docs#operation/RRSet
 func (c *Client) GetRRSet(ctx context.Context, zone, name string) (RRSet, error) {
-	endpoint := c.baseURL.JoinPath("v2", "zones", zone, name, txtRecordType)
+	endpoint := c.BaseURL.JoinPath("v2", "zones", zone, name, txtRecordType)
 
 	var result RRSet
 	err := c.do(ctx, http.MethodGet, endpoint, nil, &result)
@@ -67,7 +96,7 @@ func (c *Client) GetRRSet(ctx context.Context, zone, name string) (RRSet, error)
 // DeleteRRSet removes RRSet record.
 // https://dnsapi.gcorelabs.com/docs#operation/DeleteRRSet
 func (c *Client) DeleteRRSet(ctx context.Context, zone, name string) error {
-	endpoint := c.baseURL.JoinPath("v2", "zones", zone, name, txtRecordType)
+	endpoint := c.BaseURL.JoinPath("v2", "zones", zone, name, txtRecordType)
 
 	err := c.do(ctx, http.MethodDelete, endpoint, nil, nil)
 	if err != nil {
@@ -84,11 +113,17 @@ func (c *Client) DeleteRRSet(ctx context.Context, zone, name string) error {
 }
 
 // AddRRSet adds TXT record (create or update).
+// The values of a RRSet share the same record: the value is added to the existing values.
+// Adding a value which already exists is a no-op.
 func (c *Client) AddRRSet(ctx context.Context, zone, recordName, value string, ttl int) error {
 	record := RRSet{TTL: ttl, Records: []Records{{Content: []string{value}}}}
 
 	txt, err := c.GetRRSet(ctx, zone, recordName)
 	if err == nil && len(txt.Records) > 0 {
+		if txt.contains(value) {
+			return nil
+		}
+
 		record.Records = append(record.Records, txt.Records...)
 		return c.updateRRSet(ctx, zone, recordName, record)
 	}
@@ -96,16 +131,47 @@ func (c *Client) AddRRSet(ctx context.Context, zone, recordName, value string, t
 	return c.createRRSet(ctx, zone, recordName, record)
 }
 
+// RemoveRRSetValue removes a value of a TXT RRSet,
+// the RRSet is removed when it has no other value.
+func (c *Client) RemoveRRSetValue(ctx context.Context, zone, recordName, value string) error {
+	txt, err := c.GetRRSet(ctx, zone, recordName)
+	if err != nil {
+		statusErr := new(APIError)
+		if errors.As(err, statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return nil
+		}
+
+		return err
+	}
+
+	var records []Records
+	for _, r := range txt.Records {
+		if !r.matches(value) {
+			records = append(records, r)
+		}
+	}
+
+	if len(records) == len(txt.Records) {
+		return nil
+	}
+
+	if len(records) == 0 {
+		return c.DeleteRRSet(ctx, zone, recordName)
+	}
+
+	return c.updateRRSet(ctx, zone, recordName, RRSet{TTL: txt.TTL, Records: records})
+}
+
 // https://dnsapi.gcorelabs.com/docs#operation/CreateRRSet
 func (c *Client) createRRSet(ctx context.Context, zone, name string, record RRSet) error {
-	endpoint := c.baseURL.JoinPath("v2", "zones", zone, name, txtRecordType)
+	endpoint := c.BaseURL.JoinPath("v2", "zones", zone, name, txtRecordType)
 
 	return c.do(ctx, http.MethodPost, endpoint, record, nil)
 }
 
 // https://dnsapi.gcorelabs.com/docs#operation/UpdateRRSet
 func (c *Client) updateRRSet(ctx context.Context, zone, name string, record RRSet) error {
-	endpoint := c.baseURL.JoinPath("v2", "zones", zone, name, txtRecordType)
+	endpoint := c.BaseURL.JoinPath("v2", "zones", zone, name, txtRecordType)
 
 	return c.do(ctx, http.MethodPut, endpoint, record, nil)
 }
@@ -30,11 +30,42 @@ func setupTest(t *testing.T) (*http.ServeMux, *Client) {
 	t.Cleanup(server.Close)
 
 	client := NewClient(testToken)
-	client.baseURL, _ = url.Parse(server.URL)
+	client.BaseURL, _ = url.Parse(server.URL)
 
 	return mux, client
 }
 
+func TestClient_ListZones(t *testing.T) {
+	mux, client := setupTest(t)
+
+	mux.Handle("/v2/zones", validationHandler{
+		method: http.MethodGet,
+		next: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Query().Get("limit") != "100" {
+				http.Error(rw, "wrong limit", http.StatusBadRequest)
+				return
+			}
+
+			// 3 zones in 2 pages.
+			var zones []Zone
+			switch req.URL.Query().Get("offset") {
+			case "0":
+				zones = []Zone{{Name: "example.com"}, {Name: "example.org"}}
+			case "2":
+				zones = []Zone{{Name: "example.net"}}
+			}
+
+			handleJSONResponse(ListZonesResponse{Zones: zones, TotalAmount: 3}).ServeHTTP(rw, req)
+		}),
+	})
+
+	zones, err := client.ListZones(context.Background())
+	require.NoError(t, err)
+
+	expected := []Zone{{Name: "example.com"}, {Name: "example.org"}, {Name: "example.net"}}
+	assert.Equal(t, expected, zones)
+}
+
 func TestClient_GetZone(t *testing.T) {
 	mux, client := setupTest(t)
 
@@ -167,6 +198,22 @@ func TestClient_AddRRSet(t *testing.T) {
 				}),
 			},
 		},
+		{
+			desc:       "value already present",
+			zone:       "test.example.com",
+			recordName: "my.test.example.com",
+			value:      testRecordContent,
+			handlers: map[string]http.Handler{
+				// only GetRRSet is allowed.
+				"/v2/zones/test.example.com/my.test.example.com/" + txtRecordType: validationHandler{
+					method: http.MethodGet,
+					next: handleJSONResponse(RRSet{
+						TTL:     testTTL,
+						Records: []Records{{Content: []string{testRecordContent2}}, {Content: []string{`"` + testRecordContent + `"`}}},
+					}),
+				},
+			},
+		},
 		{
 			desc:       "not in the zone",
 			zone:       "test.example.com",
@@ -195,6 +242,67 @@ func TestClient_AddRRSet(t *testing.T) {
 	}
 }
 
+func TestClient_RemoveRRSetValue(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		records  []Records
+		expected []string
+	}{
+		{
+			desc:     "other values",
+			records:  []Records{{Content: []string{testRecordContent}}, {Content: []string{testRecordContent2}}},
+			expected: []string{http.MethodGet, http.MethodPut},
+		},
+		{
+			desc:     "last value",
+			records:  []Records{{Content: []string{testRecordContent}}},
+			expected: []string{http.MethodGet, http.MethodDelete},
+		},
+		{
+			desc:     "unknown value",
+			records:  []Records{{Content: []string{testRecordContent2}}},
+			expected: []string{http.MethodGet},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mux, client := setupTest(t)
+
+			var methods []string
+
+			mux.HandleFunc("/v2/zones/test.example.com/my.test.example.com/"+txtRecordType, func(rw http.ResponseWriter, req *http.Request) {
+				methods = append(methods, req.Method)
+
+				switch req.Method {
+				case http.MethodGet:
+					handleJSONResponse(RRSet{TTL: testTTL, Records: test.records}).ServeHTTP(rw, req)
+				case http.MethodPut:
+					handleAddRRSet([]Records{{Content: []string{testRecordContent2}}}).ServeHTTP(rw, req)
+				case http.MethodDelete:
+				default:
+					http.Error(rw, "wrong method", http.StatusMethodNotAllowed)
+				}
+			})
+
+			err := client.RemoveRRSetValue(context.Background(), "test.example.com", "my.test.example.com", testRecordContent)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, methods)
+		})
+	}
+}
+
+func TestClient_RemoveRRSetValue_notFound(t *testing.T) {
+	_, client := setupTest(t)
+
+	err := client.RemoveRRSetValue(context.Background(), "test.example.com", "my.test.example.com", testRecordContent)
+	require.NoError(t, err)
+}
+
 type validationHandler struct {
 	method string
 	next   http.Handler
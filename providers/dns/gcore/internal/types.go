@@ -1,6 +1,14 @@
 package internal
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
+
+type ListZonesResponse struct {
+	Zones       []Zone `json:"zones"`
+	TotalAmount int    `json:"total_amount"`
+}
 
 type Zone struct {
 	Name string `json:"name"`
@@ -11,10 +19,26 @@ type RRSet struct {
 	Records []Records `json:"resource_records"`
 }
 
+func (r RRSet) contains(value string) bool {
+	for _, record := range r.Records {
+		if record.matches(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
 type Records struct {
 	Content []string `json:"content"`
 }
 
+// matches returns true if the content of the TXT record is the value.
+// The API may return the content enclosed in double quotes.
+func (r Records) matches(value string) bool {
+	return len(r.Content) > 0 && strings.Trim(strings.Join(r.Content, ""), `"`) == value
+}
+
 type APIError struct {
 	StatusCode int    `json:"-"`
 	Message    string `json:"error,omitempty"`
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/go-acme/lego/v4/challenge/dns01"
@@ -24,6 +25,7 @@ const (
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
 	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
+	EnvPresentDelay       = envNamespace + "PRESENT_DELAY"
 )
 
 // Config is used to configure the creation of the DNSProvider.
@@ -33,6 +35,10 @@ type Config struct {
 	PollingInterval    time.Duration
 	TTL                int
 	HTTPClient         *http.Client
+
+	// PresentDelay is the delay after the creation of the TXT record, before the propagation check.
+	// The API of Hetzner is eventually consistent: a record just created can be missing from the nameservers for a short time.
+	PresentDelay time.Duration
 }
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
@@ -44,6 +50,7 @@ func NewDefaultConfig() *Config {
 		HTTPClient: &http.Client{
 			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
+		PresentDelay: env.GetOrDefaultSecond(EnvPresentDelay, 0),
 	}
 }
 
@@ -51,6 +58,13 @@ func NewDefaultConfig() *Config {
 type DNSProvider struct {
 	config *Config
 	client *internal.Client
+
+	// findZoneByFqdn determines the DNS zone of an fqdn. It is overridden during tests.
+	findZoneByFqdn func(fqdn string) (string, error)
+
+	// zone IDs by zone name, resolved once for the run.
+	zoneIDs   map[string]string
+	zoneIDsMu sync.Mutex
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for hetzner.
@@ -87,7 +101,12 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		client.HTTPClient = config.HTTPClient
 	}
 
-	return &DNSProvider{config: config, client: client}, nil
+	return &DNSProvider{
+		config:         config,
+		client:         client,
+		findZoneByFqdn: dns01.FindZoneByFqdn,
+		zoneIDs:        make(map[string]string),
+	}, nil
 }
 
 // Timeout returns the timeout and interval to use when checking for DNS
@@ -100,12 +119,12 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	fqdn, value := dns01.GetRecord(domain, keyAuth)
 
-	zone, err := getZone(fqdn)
+	zone, err := d.getZone(fqdn)
 	if err != nil {
 		return fmt.Errorf("hetzner: failed to find zone: fqdn=%s: %w", fqdn, err)
 	}
 
-	zoneID, err := d.client.GetZoneID(zone)
+	zoneID, err := d.getZoneID(zone)
 	if err != nil {
 		return fmt.Errorf("hetzner: %w", err)
 	}
@@ -127,6 +146,10 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		return fmt.Errorf("hetzner: failed to add TXT record: fqdn=%s, zoneID=%s: %w", fqdn, zoneID, err)
 	}
 
+	if d.config.PresentDelay > 0 {
+		time.Sleep(d.config.PresentDelay)
+	}
+
 	return nil
 }
 
@@ -134,12 +157,12 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	fqdn, value := dns01.GetRecord(domain, keyAuth)
 
-	zone, err := getZone(fqdn)
+	zone, err := d.getZone(fqdn)
 	if err != nil {
 		return fmt.Errorf("hetzner: failed to find zone: fqdn=%s: %w", fqdn, err)
 	}
 
-	zoneID, err := d.client.GetZoneID(zone)
+	zoneID, err := d.getZoneID(zone)
 	if err != nil {
 		return fmt.Errorf("hetzner: %w", err)
 	}
@@ -161,11 +184,30 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	return nil
 }
 
-func getZone(fqdn string) (string, error) {
-	authZone, err := dns01.FindZoneByFqdn(fqdn)
+func (d *DNSProvider) getZone(fqdn string) (string, error) {
+	authZone, err := d.findZoneByFqdn(fqdn)
 	if err != nil {
 		return "", err
 	}
 
 	return dns01.UnFqdn(authZone), nil
 }
+
+// getZoneID gets the ID of the zone, the IDs are cached for the lifetime of the provider.
+func (d *DNSProvider) getZoneID(zone string) (string, error) {
+	d.zoneIDsMu.Lock()
+	defer d.zoneIDsMu.Unlock()
+
+	if zoneID, ok := d.zoneIDs[zone]; ok {
+		return zoneID, nil
+	}
+
+	zoneID, err := d.client.GetZoneID(zone)
+	if err != nil {
+		return "", err
+	}
+
+	d.zoneIDs[zone] = zoneID
+
+	return zoneID, nil
+}
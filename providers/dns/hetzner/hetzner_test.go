@@ -1,9 +1,18 @@
 package hetzner
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-acme/lego/v4/providers/dns/hetzner/internal"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -98,6 +107,51 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func TestDNSProvider_Present_CleanUp(t *testing.T) {
+	provider, api := setupTest(t)
+
+	err := provider.Present("example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	expected := []internal.DNSRecord{{
+		ID:     "1",
+		Name:   "_acme-challenge",
+		Type:   "TXT",
+		Value:  "ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY",
+		TTL:    minTTL,
+		ZoneID: "zoneA",
+	}}
+
+	assert.Equal(t, expected, api.getRecords())
+
+	err = provider.Present("www.example.com", "token2", "123d==")
+	require.NoError(t, err)
+
+	err = provider.CleanUp("example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	err = provider.CleanUp("www.example.com", "token2", "123d==")
+	require.NoError(t, err)
+
+	assert.Empty(t, api.getRecords())
+
+	// the zone ID is resolved once for the run.
+	assert.Equal(t, 1, api.getZoneCalls())
+}
+
+func TestDNSProvider_Present_delay(t *testing.T) {
+	provider, _ := setupTest(t)
+
+	provider.config.PresentDelay = 50 * time.Millisecond
+
+	start := time.Now()
+
+	err := provider.Present("example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, time.Since(start), provider.config.PresentDelay)
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
@@ -123,3 +177,120 @@ func TestLiveCleanUp(t *testing.T) {
 	err = provider.CleanUp(envTest.GetDomain(), "", "123d==")
 	require.NoError(t, err)
 }
+
+func setupTest(t *testing.T) (*DNSProvider, *fakeAPI) {
+	t.Helper()
+
+	api := &fakeAPI{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/zones", api.handleZones)
+	mux.HandleFunc("/api/v1/records", api.handleRecords)
+	mux.HandleFunc("/api/v1/records/", api.handleDeleteRecord)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client.BaseURL = server.URL
+	provider.findZoneByFqdn = func(_ string) (string, error) {
+		return "example.com.", nil
+	}
+
+	return provider, api
+}
+
+// fakeAPI is a fake Hetzner DNS API serving the zone example.com (ID: zoneA).
+type fakeAPI struct {
+	mu        sync.Mutex
+	records   []internal.DNSRecord
+	lastID    int
+	zoneCalls int
+}
+
+func (f *fakeAPI) getRecords() []internal.DNSRecord {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]internal.DNSRecord{}, f.records...)
+}
+
+func (f *fakeAPI) getZoneCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.zoneCalls
+}
+
+func (f *fakeAPI) handleZones(rw http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	f.zoneCalls++
+	f.mu.Unlock()
+
+	if req.URL.Query().Get("name") != "example.com" {
+		http.Error(rw, "zone not found", http.StatusNotFound)
+		return
+	}
+
+	_ = json.NewEncoder(rw).Encode(internal.Zones{Zones: []internal.Zone{{ID: "zoneA", Name: "example.com"}}})
+}
+
+func (f *fakeAPI) handleRecords(rw http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch req.Method {
+	case http.MethodGet:
+		if req.URL.Query().Get("zone_id") != "zoneA" {
+			http.Error(rw, "invalid zone ID", http.StatusNotFound)
+			return
+		}
+
+		_ = json.NewEncoder(rw).Encode(internal.DNSRecords{Records: f.records})
+
+	case http.MethodPost:
+		var record internal.DNSRecord
+		err := json.NewDecoder(req.Body).Decode(&record)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		f.lastID++
+		record.ID = fmt.Sprint(f.lastID)
+		f.records = append(f.records, record)
+
+		_ = json.NewEncoder(rw).Encode(struct {
+			Record internal.DNSRecord `json:"record"`
+		}{Record: record})
+
+	default:
+		http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeAPI) handleDeleteRecord(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodDelete {
+		http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(req.URL.Path, "/api/v1/records/")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, record := range f.records {
+		if record.ID == id {
+			f.records = append(f.records[:i], f.records[i+1:]...)
+			return
+		}
+	}
+
+	http.Error(rw, "record not found", http.StatusNotFound)
+}
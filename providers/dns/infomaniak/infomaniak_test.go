@@ -1,10 +1,17 @@
 package infomaniak
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-acme/lego/v4/providers/dns/infomaniak/internal"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -95,6 +102,80 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func TestDNSProvider_PresentCleanUp(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/1/product", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		data := "[]"
+		if req.URL.Query().Get("customer_name") == "example.com" {
+			data = `[{"id":666,"customer_name":"example.com"}]`
+		}
+
+		_, _ = fmt.Fprintf(rw, `{"result":"success","data":%s}`, data)
+	})
+
+	var deleted []string
+
+	mux.HandleFunc("/1/domain/666/dns/record", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		var record internal.Record
+		if err := json.NewDecoder(req.Body).Decode(&record); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if record.Source != "_acme-challenge.www" || record.Type != "TXT" {
+			http.Error(rw, fmt.Sprintf("invalid record: %+v", record), http.StatusBadRequest)
+			return
+		}
+
+		_, _ = rw.Write([]byte(`{"result":"success","data":"42"}`))
+	})
+
+	mux.HandleFunc("/1/domain/666/dns/record/", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodDelete {
+			http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		deleted = append(deleted, strings.TrimPrefix(req.URL.Path, "/1/domain/666/dns/record/"))
+
+		_, _ = rw.Write([]byte(`{"result":"success","data":true}`))
+	})
+
+	config := NewDefaultConfig()
+	config.APIEndpoint = server.URL
+	config.AccessToken = "secret"
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = provider.Present("www.example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	err = provider.CleanUp("www.example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	// the record is deleted with the ID returned on creation.
+	assert.Equal(t, []string{"42"}, deleted)
+	assert.Empty(t, provider.recordIDs)
+	assert.Empty(t, provider.domainIDs)
+
+	err = provider.CleanUp("www.example.com", "token", "123d==")
+	require.EqualError(t, err, "infomaniak: unknown record ID for '_acme-challenge.www.example.com.'")
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
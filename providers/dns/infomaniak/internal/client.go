@@ -164,6 +164,10 @@ func (c *Client) do(req *http.Request) (*APIResponse, error) {
 	}
 
 	if resp.Result != "success" {
+		if resp.ErrResponse == nil {
+			return nil, fmt.Errorf("%d: unexpected API result (%s)", rawResp.StatusCode, resp.Result)
+		}
+
 		return nil, fmt.Errorf("%d: unexpected API result (%s): %w", rawResp.StatusCode, resp.Result, resp.ErrResponse)
 	}
 
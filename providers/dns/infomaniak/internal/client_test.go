@@ -155,3 +155,15 @@ func TestClient_DeleteDNSRecord(t *testing.T) {
 	err := client.DeleteDNSRecord(123, "456")
 	require.NoError(t, err)
 }
+
+func TestClient_DeleteDNSRecord_error(t *testing.T) {
+	client, mux := setupTest(t)
+
+	mux.HandleFunc("/1/domain/666/dns/record/123", func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+		_, _ = rw.Write([]byte(`{"result":"error"}`))
+	})
+
+	err := client.DeleteDNSRecord(666, "123")
+	require.EqualError(t, err, "404: unexpected API result (error)")
+}
@@ -0,0 +1,60 @@
+// Package ratelimit provides an HTTP transport limiting the rate of the requests sent to an API.
+package ratelimit
+
+import (
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// Transport is an http.RoundTripper that limits the rate of the requests.
+type Transport struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+// NewTransport creates a Transport allowing rps requests per second, with bursts of at most burst requests.
+// If base is nil, http.DefaultTransport is used.
+// A burst lower than 1 is replaced by 1.
+func NewTransport(base http.RoundTripper, rps float64, burst int) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Transport{
+		base:    base,
+		limiter: rate.NewLimiter(rate.Limit(rps), burst),
+	}
+}
+
+// RoundTrip waits for the rate limiter, then sends the request with the base transport.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	err := t.limiter.Wait(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// Wrap returns a copy of the client whose requests are limited to rps requests per second,
+// with bursts of at most burst requests.
+// If rps is lower or equal to 0, the client is returned unchanged.
+func Wrap(client *http.Client, rps float64, burst int) *http.Client {
+	if rps <= 0 {
+		return client
+	}
+
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	wrapped := *client
+	wrapped.Transport = NewTransport(client.Transport, rps, burst)
+
+	return &wrapped
+}
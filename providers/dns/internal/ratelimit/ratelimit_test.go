@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// 20 requests per second: one request every 50ms after the burst.
+	client := Wrap(server.Client(), 20, 2)
+
+	var dates []time.Time
+	for i := 0; i < 6; i++ {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+
+		_ = resp.Body.Close()
+
+		dates = append(dates, time.Now())
+	}
+
+	// the burst
+	assert.Less(t, dates[1].Sub(dates[0]), 25*time.Millisecond)
+
+	for i := 2; i < len(dates); i++ {
+		assert.GreaterOrEqual(t, dates[i].Sub(dates[i-1]), 40*time.Millisecond, "request %d", i)
+	}
+}
+
+func TestWrap_disabled(t *testing.T) {
+	client := &http.Client{Timeout: time.Second}
+
+	assert.Same(t, client, Wrap(client, 0, 10))
+}
+
+func TestWrap_keepClient(t *testing.T) {
+	client := &http.Client{Timeout: time.Second}
+
+	wrapped := Wrap(client, 10, 1)
+
+	assert.NotSame(t, client, wrapped)
+	assert.Nil(t, client.Transport)
+	assert.Equal(t, time.Second, wrapped.Timeout)
+	assert.IsType(t, &Transport{}, wrapped.Transport)
+}
@@ -0,0 +1,209 @@
+// Package retry provides the retry of the HTTP requests rejected by the transient errors of an API.
+package retry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme/api"
+)
+
+// Policy defines how the transient API errors are retried.
+type Policy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// A value lower than 2 disables the retries.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry, doubled for each following retry.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between two attempts, including the delay coming from a Retry-After header.
+	MaxDelay time.Duration
+
+	// StatusCodes are the status codes of the retried responses (429 and 5xx if empty).
+	StatusCodes []int
+
+	// Replayable reports whether a request can be sent again after a retryable response other than a 429:
+	// the API may have applied a request before failing (e.g. a creation, sending it again duplicates the resource).
+	// If nil, all the requests are replayable except the POST requests.
+	// The requests rejected with a 429 are always retried.
+	Replayable func(req *http.Request) bool
+}
+
+// DefaultPolicy returns the default retry policy.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: 5,
+		BaseDelay:   1 * time.Second,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// IsRetryable returns true if a response with the status code is retried.
+func (p Policy) IsRetryable(statusCode int) bool {
+	if len(p.StatusCodes) == 0 {
+		return IsTransient(statusCode)
+	}
+
+	for _, code := range p.StatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p Policy) isReplayable(req *http.Request, statusCode int) bool {
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	if p.Replayable == nil {
+		return req.Method != http.MethodPost
+	}
+
+	return p.Replayable(req)
+}
+
+// IsTransient returns true for the status codes of the transient errors (429 and 5xx).
+func IsTransient(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// Do sends the request with the client, and retries it while the API returns transient errors.
+// The returned response is the response of the last attempt.
+func Do(client *http.Client, req *http.Request, policy Policy) (*http.Response, error) {
+	return do(client.Do, req, policy)
+}
+
+// Transport is an http.RoundTripper that retries the requests according to a retry policy.
+type Transport struct {
+	base   http.RoundTripper
+	policy Policy
+}
+
+// NewTransport creates a Transport retrying the requests according to the policy.
+// If base is nil, http.DefaultTransport is used.
+func NewTransport(base http.RoundTripper, policy Policy) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{base: base, policy: policy}
+}
+
+// RoundTrip sends the request with the base transport, and retries it while the API returns transient errors.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return do(t.base.RoundTrip, req, t.policy)
+}
+
+// Wrap returns a copy of the client whose requests are retried according to the policy.
+// If the policy disables the retries, the client is returned unchanged.
+func Wrap(client *http.Client, policy Policy) *http.Client {
+	if policy.MaxAttempts < 2 {
+		return client
+	}
+
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	wrapped := *client
+	wrapped.Transport = NewTransport(client.Transport, policy)
+
+	return &wrapped
+}
+
+func do(send func(*http.Request) (*http.Response, error), req *http.Request, policy Policy) (*http.Response, error) {
+	r := req
+
+	for attempt := 1; ; attempt++ {
+		resp, err := send(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if attempt >= policy.MaxAttempts || !policy.IsRetryable(resp.StatusCode) ||
+			!policy.isReplayable(req, resp.StatusCode) || !rewindable(req) {
+			return resp, nil
+		}
+
+		delay := policy.delay(attempt, resp.Header.Get("Retry-After"))
+
+		// drains the body to allow the reuse of the connection.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		err = sleep(req.Context(), delay)
+		if err != nil {
+			return nil, err
+		}
+
+		r = req.Clone(req.Context())
+
+		if req.GetBody != nil {
+			r.Body, err = req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+		}
+	}
+}
+
+// rewindable returns true if the request can be sent again: its body is empty or can be rewound.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// delay computes the delay before the next attempt.
+// The Retry-After header is honored when present, otherwise an exponential backoff with jitter is used.
+func (p Policy) delay(attempt int, retryAfter string) time.Duration {
+	if d, ok := api.ParseRetryAfter(retryAfter); ok {
+		return p.clamp(d)
+	}
+
+	d := p.BaseDelay << (attempt - 1)
+	if d < p.BaseDelay {
+		// overflow
+		d = p.MaxDelay
+	}
+
+	d = p.clamp(d)
+	if d <= 0 {
+		return 0
+	}
+
+	// full jitter in [d/2, d].
+	half := d / 2
+
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+func (p Policy) clamp(d time.Duration) time.Duration {
+	if d > p.MaxDelay {
+		return p.MaxDelay
+	}
+
+	return d
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,196 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrap(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		policy        Policy
+		method        string
+		statusCode    int
+		expectedCalls int
+	}{
+		{
+			desc:          "retried",
+			policy:        Policy{MaxAttempts: 3},
+			method:        http.MethodPut,
+			statusCode:    http.StatusBadGateway,
+			expectedCalls: 3,
+		},
+		{
+			desc:          "not retried",
+			policy:        Policy{MaxAttempts: 3},
+			method:        http.MethodPut,
+			statusCode:    http.StatusBadRequest,
+			expectedCalls: 1,
+		},
+		{
+			desc:          "custom status codes",
+			policy:        Policy{MaxAttempts: 3, StatusCodes: []int{http.StatusServiceUnavailable}},
+			method:        http.MethodPut,
+			statusCode:    http.StatusBadGateway,
+			expectedCalls: 1,
+		},
+		{
+			desc:          "disabled",
+			policy:        Policy{MaxAttempts: 1},
+			method:        http.MethodPut,
+			statusCode:    http.StatusBadGateway,
+			expectedCalls: 1,
+		},
+		{
+			desc:          "POST not replayable",
+			policy:        Policy{MaxAttempts: 3},
+			method:        http.MethodPost,
+			statusCode:    http.StatusBadGateway,
+			expectedCalls: 1,
+		},
+		{
+			desc:          "POST rate limited",
+			policy:        Policy{MaxAttempts: 3},
+			method:        http.MethodPost,
+			statusCode:    http.StatusTooManyRequests,
+			expectedCalls: 3,
+		},
+		{
+			desc:          "custom replayable",
+			policy:        Policy{MaxAttempts: 3, Replayable: func(*http.Request) bool { return true }},
+			method:        http.MethodPost,
+			statusCode:    http.StatusBadGateway,
+			expectedCalls: 3,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			var bodies []string
+
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				calls++
+
+				raw, err := io.ReadAll(req.Body)
+				if err != nil {
+					http.Error(rw, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				bodies = append(bodies, string(raw))
+
+				rw.WriteHeader(test.statusCode)
+			}))
+			t.Cleanup(server.Close)
+
+			client := Wrap(server.Client(), test.policy)
+
+			req, err := http.NewRequest(test.method, server.URL, strings.NewReader("content"))
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+
+			_ = resp.Body.Close()
+
+			assert.Equal(t, test.statusCode, resp.StatusCode)
+			assert.Equal(t, test.expectedCalls, calls)
+
+			for _, body := range bodies {
+				assert.Equal(t, "content", body)
+			}
+		})
+	}
+}
+
+func TestDo_contextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, http.NoBody)
+	require.NoError(t, err)
+
+	start := time.Now()
+
+	_, err = Do(server.Client(), req, Policy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestPolicy_delay(t *testing.T) {
+	policy := Policy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	testCases := []struct {
+		desc       string
+		attempt    int
+		retryAfter string
+		min, max   time.Duration
+	}{
+		{
+			desc:    "first retry",
+			attempt: 1,
+			min:     500 * time.Millisecond,
+			max:     time.Second,
+		},
+		{
+			desc:    "third retry",
+			attempt: 3,
+			min:     2 * time.Second,
+			max:     4 * time.Second,
+		},
+		{
+			desc:    "capped",
+			attempt: 10,
+			min:     5 * time.Second,
+			max:     10 * time.Second,
+		},
+		{
+			desc:       "Retry-After seconds",
+			attempt:    1,
+			retryAfter: "7",
+			min:        7 * time.Second,
+			max:        7 * time.Second,
+		},
+		{
+			desc:       "Retry-After capped",
+			attempt:    1,
+			retryAfter: "120",
+			min:        10 * time.Second,
+			max:        10 * time.Second,
+		},
+		{
+			desc:       "invalid Retry-After",
+			attempt:    1,
+			retryAfter: "foo",
+			min:        500 * time.Millisecond,
+			max:        time.Second,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			d := policy.delay(test.attempt, test.retryAfter)
+
+			assert.GreaterOrEqual(t, d, test.min)
+			assert.LessOrEqual(t, d, test.max)
+		})
+	}
+}
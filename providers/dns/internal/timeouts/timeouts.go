@@ -0,0 +1,75 @@
+// Package timeouts defines the timeouts shared by the DNS providers:
+// the timeout of the connections, of the API requests, and of the whole challenge step (Present or CleanUp).
+package timeouts
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-acme/lego/v4/log"
+)
+
+// Config is the timeout configuration of a DNS provider.
+// A zero value disables the corresponding timeout.
+type Config struct {
+	// ConnectTimeout is the maximum duration to establish a connection to the API.
+	ConnectTimeout time.Duration
+	// RequestTimeout is the maximum duration of an API request, including the connection, the redirects, and the reading of the response body.
+	RequestTimeout time.Duration
+	// ChallengeTimeout is the maximum duration of a challenge step (Present or CleanUp), including all the API requests.
+	ChallengeTimeout time.Duration
+}
+
+// Wrap returns a copy of the client using the connection and request timeouts.
+// The request timeout is only applied if the client has no timeout.
+//
+// The connection timeout is only applied if the transport of the client is nil (http.DefaultTransport is used) or an *http.Transport:
+// a custom transport is kept unchanged.
+func (c Config) Wrap(client *http.Client) *http.Client {
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	wrapped := *client
+
+	if wrapped.Timeout == 0 {
+		wrapped.Timeout = c.RequestTimeout
+	}
+
+	if c.ConnectTimeout <= 0 {
+		return &wrapped
+	}
+
+	var transport *http.Transport
+
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		log.Warnf("the connection timeout is not applied: the transport of the HTTP client is not an *http.Transport (%T)", t)
+		return &wrapped
+	}
+
+	transport.DialContext = (&net.Dialer{
+		Timeout:   c.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+
+	wrapped.Transport = transport
+
+	return &wrapped
+}
+
+// Context returns a context canceled at the end of the challenge timeout.
+// If the challenge timeout is not defined, the context is only canceled by the returned function.
+func (c Config) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	if c.ChallengeTimeout <= 0 {
+		return context.WithCancel(parent)
+	}
+
+	return context.WithTimeout(parent, c.ChallengeTimeout)
+}
@@ -0,0 +1,131 @@
+package timeouts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestConfig_Wrap_requestTimeout(t *testing.T) {
+	done := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(done) })
+
+	config := Config{ConnectTimeout: time.Second, RequestTimeout: 100 * time.Millisecond}
+
+	client := config.Wrap(server.Client())
+
+	assert.Equal(t, 100*time.Millisecond, client.Timeout)
+	assert.Zero(t, server.Client().Timeout, "the original client must not be modified")
+
+	start := time.Now()
+
+	_, err := client.Get(server.URL)
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "Client.Timeout exceeded")
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestConfig_Wrap(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		config          Config
+		client          *http.Client
+		expectedTimeout time.Duration
+		customTransport bool
+	}{
+		{
+			desc:            "nil client",
+			config:          Config{ConnectTimeout: time.Second, RequestTimeout: 10 * time.Second},
+			expectedTimeout: 10 * time.Second,
+		},
+		{
+			desc:            "client timeout is kept",
+			config:          Config{RequestTimeout: 10 * time.Second},
+			client:          &http.Client{Timeout: 5 * time.Second},
+			expectedTimeout: 5 * time.Second,
+		},
+		{
+			desc:   "no timeouts",
+			client: &http.Client{},
+		},
+		{
+			desc:            "unsupported transport without connection timeout",
+			config:          Config{RequestTimeout: 10 * time.Second},
+			client:          &http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)},
+			expectedTimeout: 10 * time.Second,
+		},
+		{
+			desc:            "unsupported transport",
+			config:          Config{ConnectTimeout: time.Second, RequestTimeout: 10 * time.Second},
+			client:          &http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)},
+			expectedTimeout: 10 * time.Second,
+			customTransport: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := test.config.Wrap(test.client)
+
+			assert.Equal(t, test.expectedTimeout, client.Timeout)
+
+			if test.customTransport {
+				// the custom transport is kept, without connection timeout.
+				_, ok := client.Transport.(roundTripperFunc)
+				assert.True(t, ok)
+				return
+			}
+
+			if test.config.ConnectTimeout > 0 {
+				transport, ok := client.Transport.(*http.Transport)
+				require.True(t, ok)
+				assert.NotNil(t, transport.DialContext)
+			}
+		})
+	}
+}
+
+func TestConfig_Context(t *testing.T) {
+	ctx, cancel := Config{ChallengeTimeout: 10 * time.Millisecond}.Context(context.Background())
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+		require.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("the challenge timeout has not been applied")
+	}
+
+	ctx, cancel = Config{}.Context(context.Background())
+
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline)
+
+	cancel()
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+}
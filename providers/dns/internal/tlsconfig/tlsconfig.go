@@ -0,0 +1,54 @@
+// Package tlsconfig customizes the TLS configuration of the HTTP clients of the DNS providers,
+// e.g. to reach a self-hosted API using a self-signed certificate.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+)
+
+// Wrap returns a copy of the client using the TLS configuration for its connections.
+// If tlsConfig is nil, the client is returned unchanged.
+//
+// The transport of the client must be nil (http.DefaultTransport is used) or an *http.Transport.
+// The TLS configuration replaces the TLS configuration of the transport.
+func Wrap(client *http.Client, tlsConfig *tls.Config) (*http.Client, error) {
+	if tlsConfig == nil {
+		return client, nil
+	}
+
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	var transport *http.Transport
+
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, errors.New("the TLS configuration cannot be applied: the transport of the HTTP client is not an *http.Transport")
+	}
+
+	transport.TLSClientConfig = tlsConfig.Clone()
+
+	wrapped := *client
+	wrapped.Transport = transport
+
+	return &wrapped, nil
+}
+
+// InsecureSkipVerify returns a TLS configuration disabling the verification of the certificates of the servers,
+// if insecure is true, otherwise nil.
+// It must only be used for trusted networks: the connections are vulnerable to man-in-the-middle attacks.
+func InsecureSkipVerify(insecure bool) *tls.Config {
+	if !insecure {
+		return nil
+	}
+
+	//nolint:gosec // explicit opt-in of the user.
+	return &tls.Config{InsecureSkipVerify: true}
+}
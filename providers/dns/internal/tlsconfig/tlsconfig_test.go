@@ -0,0 +1,105 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrap(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	testCases := []struct {
+		desc        string
+		tlsConfig   *tls.Config
+		expectedErr bool
+	}{
+		{
+			desc:        "default (self-signed certificate rejected)",
+			expectedErr: true,
+		},
+		{
+			desc:      "insecure skip verify",
+			tlsConfig: InsecureSkipVerify(true),
+		},
+		{
+			desc:      "custom root CAs",
+			tlsConfig: &tls.Config{RootCAs: rootCAs},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client, err := Wrap(&http.Client{Timeout: 5 * time.Second}, test.tlsConfig)
+			require.NoError(t, err)
+
+			resp, err := client.Get(server.URL)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		})
+	}
+}
+
+func TestWrap_keepsClient(t *testing.T) {
+	base := &http.Transport{MaxIdleConns: 42}
+	client := &http.Client{Timeout: 5 * time.Second, Transport: base}
+
+	wrapped, err := Wrap(client, InsecureSkipVerify(true))
+	require.NoError(t, err)
+
+	assert.Equal(t, 5*time.Second, wrapped.Timeout)
+
+	transport, ok := wrapped.Transport.(*http.Transport)
+	require.True(t, ok)
+
+	assert.Equal(t, 42, transport.MaxIdleConns)
+	assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+
+	// the original client is not modified.
+	assert.Same(t, base, client.Transport)
+	assert.False(t, base.TLSClientConfig != nil && base.TLSClientConfig.InsecureSkipVerify)
+}
+
+func TestWrap_unsupportedTransport(t *testing.T) {
+	client := &http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) { return nil, nil })}
+
+	_, err := Wrap(client, InsecureSkipVerify(true))
+	require.EqualError(t, err, "the TLS configuration cannot be applied: the transport of the HTTP client is not an *http.Transport")
+}
+
+func TestWrap_nilConfig(t *testing.T) {
+	client := &http.Client{}
+
+	wrapped, err := Wrap(client, nil)
+	require.NoError(t, err)
+
+	assert.Same(t, client, wrapped)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
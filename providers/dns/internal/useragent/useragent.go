@@ -0,0 +1,28 @@
+// Package useragent provides the User-Agent of the requests sent by the DNS providers.
+package useragent
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// EnvSuffix is the environment variable used to define a suffix appended to the User-Agent.
+// The same environment variable is used for the requests sent to the ACME server.
+const EnvSuffix = "LEGO_USER_AGENT_SUFFIX"
+
+// defaultUserAgent the User-Agent of the DNS providers without suffix.
+var defaultUserAgent = fmt.Sprintf("goacme-lego (%s; %s)", runtime.GOOS, runtime.GOARCH)
+
+// Get returns the User-Agent of the DNS providers,
+// followed by the suffix defined by the LEGO_USER_AGENT_SUFFIX environment variable.
+func Get() string {
+	return strings.TrimSpace(defaultUserAgent + " " + os.Getenv(EnvSuffix))
+}
+
+// SetHeader sets the User-Agent header.
+func SetHeader(h http.Header) {
+	h.Set("User-Agent", Get())
+}
@@ -0,0 +1,37 @@
+package useragent
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetHeader(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		suffix   string
+		expected string
+	}{
+		{
+			desc:     "default",
+			expected: defaultUserAgent,
+		},
+		{
+			desc:     "suffix",
+			suffix:   "operator/42",
+			expected: defaultUserAgent + " operator/42",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Setenv(EnvSuffix, test.suffix)
+
+			h := http.Header{}
+			SetHeader(h)
+
+			assert.Equal(t, test.expected, h.Get("User-Agent"))
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package ionos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/platform/wait"
+	"github.com/miekg/dns"
+)
+
+// resolver queries the authoritative nameservers of a zone.
+type resolver interface {
+	// LookupNS returns the addresses (host:port) of the nameservers of a zone.
+	LookupNS(ctx context.Context, zone string) ([]string, error)
+
+	// LookupTXT returns the values of the TXT records of a FQDN, asking directly a nameserver.
+	LookupTXT(ctx context.Context, nameserver, fqdn string) ([]string, error)
+}
+
+// waitAuthoritativePropagation waits until the TXT record is visible on all the authoritative nameservers of the zone.
+// The wait is also bounded by the context (e.g. the challenge timeout).
+func (d *DNSProvider) waitAuthoritativePropagation(ctx context.Context, zone, fqdn, value string) error {
+	nameservers, err := d.lookupNameservers(ctx, zone)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("ionos: propagation of %s on the authoritative nameservers", fqdn)
+
+	return wait.ForContext(ctx, msg, d.config.AuthoritativeCheckTimeout, d.config.PollingInterval, func() (bool, error) {
+		for _, ns := range nameservers {
+			values, errL := d.resolver.LookupTXT(ctx, ns, fqdn)
+			if errL != nil {
+				return false, fmt.Errorf("nameserver %s: %w", ns, errL)
+			}
+
+			if !contains(values, value) {
+				return false, fmt.Errorf("nameserver %s: TXT record not found", ns)
+			}
+		}
+
+		return true, nil
+	})
+}
+
+// AuthoritativeNameservers returns, when the authoritative check is enabled, the nameservers of the zone of the FQDN:
+// the propagation check of the challenge queries the nameservers checked by Present (see dns01.AuthoritativeNameserversProvider).
+// Otherwise, the list is empty and the propagation check uses its own lookup of the nameservers.
+func (d *DNSProvider) AuthoritativeNameservers(fqdn string) ([]string, error) {
+	if !d.config.AuthoritativeCheck {
+		return nil, nil
+	}
+
+	ctx, cancel := d.config.Timeouts.Context(context.Background())
+	defer cancel()
+
+	zone, err := d.client.FindZoneByName(ctx, fqdn)
+	if err != nil {
+		return nil, fmt.Errorf("ionos: failed to find zone: %w", err)
+	}
+
+	nameservers, err := d.lookupNameservers(ctx, zone.Name)
+	if err != nil {
+		return nil, fmt.Errorf("ionos: %w", err)
+	}
+
+	return nameservers, nil
+}
+
+func (d *DNSProvider) lookupNameservers(ctx context.Context, zone string) ([]string, error) {
+	nameservers, err := d.resolver.LookupNS(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nameservers (zone=%s): %w", zone, err)
+	}
+
+	if len(nameservers) == 0 {
+		return nil, fmt.Errorf("no nameservers found (zone=%s)", zone)
+	}
+
+	return nameservers, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dnsResolver the default resolver.
+type dnsResolver struct {
+	timeout time.Duration
+}
+
+func (r dnsResolver) LookupNS(ctx context.Context, zone string) ([]string, error) {
+	records, err := net.DefaultResolver.LookupNS(ctx, dns01.UnFqdn(zone))
+	if err != nil {
+		return nil, err
+	}
+
+	var nameservers []string
+	for _, record := range records {
+		nameservers = append(nameservers, net.JoinHostPort(dns01.UnFqdn(record.Host), "53"))
+	}
+
+	return nameservers, nil
+}
+
+func (r dnsResolver) LookupTXT(ctx context.Context, nameserver, fqdn string) ([]string, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns01.ToFqdn(fqdn), dns.TypeTXT)
+	m.RecursionDesired = false
+
+	client := &dns.Client{Net: "udp", Timeout: r.timeout}
+
+	in, _, err := client.ExchangeContext(ctx, m, nameserver)
+	if in != nil && in.Truncated {
+		client.Net = "tcp"
+		in, _, err = client.ExchangeContext(ctx, m, nameserver)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if in == nil {
+		return nil, errors.New("empty response")
+	}
+
+	if in.Rcode != dns.RcodeSuccess && in.Rcode != dns.RcodeNameError {
+		return nil, fmt.Errorf("unexpected response code: %s", dns.RcodeToString[in.Rcode])
+	}
+
+	var values []string
+	for _, rr := range in.Answer {
+		if txt, ok := rr.(*dns.TXT); ok {
+			values = append(values, strings.Join(txt.Txt, ""))
+		}
+	}
+
+	return values, nil
+}
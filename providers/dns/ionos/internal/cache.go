@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// zonesCache is shared by the clients:
+// the zones are fetched once for all the challenges of a run using the same API key.
+var zonesCache = newZoneCache()
+
+// zoneCache is an in-memory cache of the zone lists, by API key and API endpoint.
+type zoneCache struct {
+	mu      sync.Mutex
+	entries map[string]*zoneCacheEntry
+}
+
+type zoneCacheEntry struct {
+	// mu is held during the fetch of the zones, so the concurrent lookups wait for a single call.
+	mu        sync.Mutex
+	zones     []Zone
+	expiresAt time.Time
+}
+
+func newZoneCache() *zoneCache {
+	return &zoneCache{entries: map[string]*zoneCacheEntry{}}
+}
+
+// get returns the cached zones of the key, or fetches them if they are missing or expired.
+// The errors are not cached.
+func (c *zoneCache) get(key string, ttl time.Duration, fetch func() ([]Zone, error)) ([]Zone, error) {
+	c.mu.Lock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &zoneCacheEntry{}
+		c.entries[key] = entry
+	}
+
+	c.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.zones != nil && time.Now().Before(entry.expiresAt) {
+		return entry.zones, nil
+	}
+
+	zones, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	if zones == nil {
+		zones = []Zone{}
+	}
+
+	entry.zones = zones
+	entry.expiresAt = time.Now().Add(ttl)
+
+	return zones, nil
+}
+
+// listZonesCached gets all zones, through the cache when ZonesCacheTTL is greater than 0.
+func (c *Client) listZonesCached(ctx context.Context) ([]Zone, error) {
+	if c.ZonesCacheTTL <= 0 {
+		return c.ListZones(ctx)
+	}
+
+	return zonesCache.get(c.apiKey+"@"+c.BaseURL.String(), c.ZonesCacheTTL, func() ([]Zone, error) {
+		return c.ListZones(ctx)
+	})
+}
@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/providers/dns/internal/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_FindZoneByName_cache(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		ttl      time.Duration
+		wait     time.Duration
+		expected int32
+	}{
+		{
+			desc:     "within the TTL",
+			ttl:      time.Minute,
+			expected: 1,
+		},
+		{
+			desc:     "expired",
+			ttl:      10 * time.Millisecond,
+			wait:     20 * time.Millisecond,
+			expected: 2,
+		},
+		{
+			desc:     "disabled",
+			expected: 20,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mux, client := setupTest(t)
+
+			client.ZonesCacheTTL = test.ttl
+
+			var calls int32
+
+			handler := mockHandler(http.MethodGet, http.StatusOK, "list_zones.json")
+
+			mux.HandleFunc("/v1/zones", func(rw http.ResponseWriter, req *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				handler(rw, req)
+			})
+
+			// concurrent lookups, as with the challenges of several subdomains.
+			lookup := func() {
+				var wg sync.WaitGroup
+
+				for i := 0; i < 10; i++ {
+					wg.Add(1)
+
+					go func() {
+						defer wg.Done()
+
+						zone, err := client.FindZoneByName(context.Background(), "_acme-challenge.a.test.com.")
+						assert.NoError(t, err)
+						assert.Equal(t, "test.com", zone.Name)
+					}()
+				}
+
+				wg.Wait()
+			}
+
+			lookup()
+
+			time.Sleep(test.wait)
+
+			lookup()
+
+			assert.Equal(t, test.expected, atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestClient_FindZoneByName_cacheSharedByAPIKey(t *testing.T) {
+	mux, client := setupTest(t)
+
+	client.ZonesCacheTTL = time.Minute
+
+	var calls int32
+
+	handler := mockHandler(http.MethodGet, http.StatusOK, "list_zones.json")
+
+	mux.HandleFunc("/v1/zones", func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		handler(rw, req)
+	})
+
+	_, err := client.FindZoneByName(context.Background(), "_acme-challenge.a.test.com.")
+	require.NoError(t, err)
+
+	// same API key and base URL.
+	other, err := NewClient("secret", client.BaseURL.String())
+	require.NoError(t, err)
+
+	other.ZonesCacheTTL = time.Minute
+
+	_, err = other.FindZoneByName(context.Background(), "_acme-challenge.b.test.com.")
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// another API key.
+	another, err := NewClient("other", client.BaseURL.String())
+	require.NoError(t, err)
+
+	another.ZonesCacheTTL = time.Minute
+
+	_, err = another.FindZoneByName(context.Background(), "_acme-challenge.c.test.com.")
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestClient_FindZoneByName_cacheError(t *testing.T) {
+	mux, client := setupTest(t)
+
+	client.ZonesCacheTTL = time.Minute
+	client.RetryPolicy = retry.Policy{}
+
+	var calls int32
+
+	mux.HandleFunc("/v1/zones", func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			http.Error(rw, "error", http.StatusInternalServerError)
+			return
+		}
+
+		mockHandler(http.MethodGet, http.StatusOK, "list_zones.json")(rw, req)
+	})
+
+	_, err := client.FindZoneByName(context.Background(), "_acme-challenge.a.test.com.")
+	require.Error(t, err)
+
+	// the error is not cached.
+	zone, err := client.FindZoneByName(context.Background(), "_acme-challenge.a.test.com.")
+	require.NoError(t, err)
+
+	assert.Equal(t, "test.com", zone.Name)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
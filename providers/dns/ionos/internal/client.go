@@ -8,62 +8,162 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/go-acme/lego/v4/providers/dns/internal/retry"
+	"github.com/go-acme/lego/v4/providers/dns/internal/useragent"
 	querystring "github.com/google/go-querystring/query"
 )
 
-// defaultBaseURL represents the API endpoint to call.
-const defaultBaseURL = "https://api.hosting.ionos.com/dns"
+// DefaultBaseURL represents the API endpoint to call.
+const DefaultBaseURL = "https://api.hosting.ionos.com/dns"
+
+// DefaultTransport is the transport of the default HTTP clients, shared by the clients to reuse the connections.
+// The providers share a copy of it by connection timeout, a TLS configuration gives a dedicated copy (see ionos.NewDNSProviderConfig).
+var DefaultTransport = newTransport()
+
+var linkExpr = regexp.MustCompile(`<(.+?)>(?:;[^;]+)*?;\s*rel="(.+?)"`)
 
 // Client Ionos API client.
 type Client struct {
-	HTTPClient *http.Client
-	BaseURL    *url.URL
+	HTTPClient  *http.Client
+	BaseURL     *url.URL
+	RetryPolicy retry.Policy
+
+	// ZonesCacheTTL is the duration during which the zones found by FindZoneByName are reused,
+	// by all the clients with the same API key and base URL (0 disables the cache).
+	ZonesCacheTTL time.Duration
 
 	apiKey string
 }
 
 // NewClient creates a new Client.
-func NewClient(apiKey string) (*Client, error) {
-	baseURL, err := url.Parse(defaultBaseURL)
+// If baseURL is empty, the DefaultBaseURL is used.
+func NewClient(apiKey, baseURL string) (*Client, error) {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	endpoint, err := url.Parse(baseURL)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: the scheme must be http or https", baseURL)
+	}
+
+	if endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: missing host", baseURL)
 	}
 
 	return &Client{
-		HTTPClient: http.DefaultClient,
-		BaseURL:    baseURL,
-		apiKey:     apiKey,
+		HTTPClient:  &http.Client{Timeout: 30 * time.Second, Transport: DefaultTransport},
+		BaseURL:     endpoint,
+		RetryPolicy: retry.DefaultPolicy(),
+		apiKey:      apiKey,
 	}, nil
 }
 
 // ListZones gets all zones.
+// The pagination is followed through the `next` relation of the Link header.
 func (c *Client) ListZones(ctx context.Context) ([]Zone, error) {
 	endpoint := c.BaseURL.JoinPath("v1", "zones")
 
+	var zones []Zone
+
+	visited := map[string]struct{}{}
+
+	for endpoint != nil {
+		if _, ok := visited[endpoint.String()]; ok {
+			return nil, fmt.Errorf("pagination loop detected: %s", endpoint)
+		}
+
+		visited[endpoint.String()] = struct{}{}
+
+		page, next, err := c.listZonesPage(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		zones = append(zones, page...)
+
+		endpoint = next
+	}
+
+	return zones, nil
+}
+
+// listZonesPage gets a page of zones, and the URL of the next page if any.
+func (c *Client) listZonesPage(ctx context.Context, endpoint *url.URL) ([]Zone, *url.URL, error) {
 	req, err := c.makeRequest(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := retry.Do(c.HTTPClient, req, c.RetryPolicy)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call API: %w", err)
+		return nil, nil, fmt.Errorf("failed to call API: %w", err)
 	}
 
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, readError(resp.Body, resp.StatusCode)
+		return nil, nil, readError(resp.Body, resp.StatusCode)
 	}
 
 	var zones []Zone
 	err = json.NewDecoder(resp.Body).Decode(&zones)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	return zones, nil
+	link := getLink(resp.Header, "next")
+	if link == "" {
+		return zones, nil, nil
+	}
+
+	next, err := endpoint.Parse(link)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse next page link: %w", err)
+	}
+
+	return zones, next, nil
+}
+
+// FindZoneByName finds the zone of a FQDN.
+// The labels of the FQDN are walked from the most-specific to the least-specific,
+// so the longest matching zone is returned.
+// A ZoneNotFoundError is returned if no zone matches.
+// The zones are cached when ZonesCacheTTL is greater than 0.
+func (c *Client) FindZoneByName(ctx context.Context, fqdn string) (Zone, error) {
+	zones, err := c.listZonesCached(ctx)
+	if err != nil {
+		return Zone{}, err
+	}
+
+	index := make(map[string]Zone, len(zones))
+	for _, zone := range zones {
+		if zone.Name == "" {
+			continue
+		}
+
+		index[normalizeName(zone.Name)] = zone
+	}
+
+	name := normalizeName(fqdn)
+
+	for name != "" {
+		if zone, ok := index[name]; ok {
+			return zone, nil
+		}
+
+		_, name, _ = strings.Cut(name, ".")
+	}
+
+	return Zone{}, &ZoneNotFoundError{FQDN: fqdn}
 }
 
 // ReplaceRecords replaces some records of a zones.
@@ -80,7 +180,7 @@ func (c *Client) ReplaceRecords(ctx context.Context, zoneID string, records []Re
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := retry.Do(c.HTTPClient, req, c.RetryPolicy)
 	if err != nil {
 		return fmt.Errorf("failed to call API: %w", err)
 	}
@@ -112,7 +212,7 @@ func (c *Client) GetRecords(ctx context.Context, zoneID string, filter *RecordsF
 		req.URL.RawQuery = v.Encode()
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := retry.Do(c.HTTPClient, req, c.RetryPolicy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call API: %w", err)
 	}
@@ -141,7 +241,7 @@ func (c *Client) RemoveRecord(ctx context.Context, zoneID, recordID string) erro
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := retry.Do(c.HTTPClient, req, c.RetryPolicy)
 	if err != nil {
 		return fmt.Errorf("failed to call API: %w", err)
 	}
@@ -165,6 +265,8 @@ func (c *Client) makeRequest(ctx context.Context, method string, endpoint *url.U
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-API-Key", c.apiKey)
 
+	useragent.SetHeader(req.Header)
+
 	return req, nil
 }
 
@@ -173,11 +275,37 @@ func readError(body io.Reader, statusCode int) error {
 
 	cErr := &ClientError{StatusCode: statusCode}
 
-	err := json.Unmarshal(bodyBytes, &cErr.errors)
+	err := json.Unmarshal(bodyBytes, &cErr.Errors)
 	if err != nil {
-		cErr.message = string(bodyBytes)
+		cErr.Body = string(bodyBytes)
 		return cErr
 	}
 
 	return cErr
 }
+
+// getLink gets a rel from the Link header.
+func getLink(header http.Header, rel string) string {
+	for _, link := range header.Values("Link") {
+		for _, m := range linkExpr.FindAllStringSubmatch(link, -1) {
+			if len(m) == 3 && m[2] == rel {
+				return m[1]
+			}
+		}
+	}
+
+	return ""
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 10
+	transport.TLSHandshakeTimeout = 10 * time.Second
+	transport.ResponseHeaderTimeout = 30 * time.Second
+
+	return transport
+}
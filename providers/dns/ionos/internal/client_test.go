@@ -2,20 +2,94 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
-	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/go-acme/lego/v4/providers/dns/internal/retry"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+func TestNewClient(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		baseURL  string
+		expected string
+		err      string
+	}{
+		{
+			desc:     "default",
+			expected: DefaultBaseURL,
+		},
+		{
+			desc:     "custom",
+			baseURL:  "https://staging.example.com/dns",
+			expected: "https://staging.example.com/dns",
+		},
+		{
+			desc:    "missing scheme",
+			baseURL: "staging.example.com/dns",
+			err:     `invalid base URL "staging.example.com/dns": the scheme must be http or https`,
+		},
+		{
+			desc:    "missing host",
+			baseURL: "https:///dns",
+			err:     `invalid base URL "https:///dns": missing host`,
+		},
+		{
+			desc:    "unparsable",
+			baseURL: "https://example.com/%zz",
+			err:     `invalid base URL "https://example.com/%zz": parse "https://example.com/%zz": invalid URL escape "%zz"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client, err := NewClient("secret", test.baseURL)
+
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, client.BaseURL.String())
+		})
+	}
+}
+
+func TestNewClient_httpClient(t *testing.T) {
+	client, err := NewClient("secret", "")
+	require.NoError(t, err)
+
+	require.NotNil(t, client.HTTPClient)
+	assert.NotSame(t, http.DefaultClient, client.HTTPClient)
+	assert.NotZero(t, client.HTTPClient.Timeout)
+	assert.Same(t, DefaultTransport, client.HTTPClient.Transport)
+}
+
+func TestClient_customHTTPClient(t *testing.T) {
+	mux, client := setupTest(t)
+
+	mux.HandleFunc("/v1/zones", mockHandler(http.MethodGet, http.StatusOK, "list_zones.json"))
+
+	transport := &countingTransport{}
+	client.HTTPClient = &http.Client{Transport: transport}
+
+	_, err := client.ListZones(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, transport.calls)
+}
+
 func TestClient_ListZones(t *testing.T) {
 	mux, client := setupTest(t)
 
@@ -33,6 +107,78 @@ func TestClient_ListZones(t *testing.T) {
 	assert.Equal(t, expected, zones)
 }
 
+func TestClient_userAgentSuffix(t *testing.T) {
+	t.Setenv("LEGO_USER_AGENT_SUFFIX", "operator/42")
+
+	mux, client := setupTest(t)
+
+	var ua string
+
+	handler := mockHandler(http.MethodGet, http.StatusOK, "list_zones.json")
+
+	mux.HandleFunc("/v1/zones", func(rw http.ResponseWriter, req *http.Request) {
+		ua = req.Header.Get("User-Agent")
+		handler(rw, req)
+	})
+
+	_, err := client.ListZones(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(ua, "goacme-lego ("), "User-Agent: %s", ua)
+	assert.True(t, strings.HasSuffix(ua, ") operator/42"), "User-Agent: %s", ua)
+}
+
+func TestClient_ListZones_pagination(t *testing.T) {
+	mux, client := setupTest(t)
+
+	page1 := mockHandler(http.MethodGet, http.StatusOK, "list_zones_page1.json")
+	page2 := mockHandler(http.MethodGet, http.StatusOK, "list_zones_page2.json")
+
+	mux.HandleFunc("/v1/zones", func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Query().Get("page") {
+		case "":
+			rw.Header().Set("Link", `</v1/zones?page=2>; rel="next"`)
+			page1(rw, req)
+		case "2":
+			page2(rw, req)
+		default:
+			http.Error(rw, "unknown page", http.StatusNotFound)
+		}
+	})
+
+	zones, err := client.ListZones(context.Background())
+	require.NoError(t, err)
+
+	expected := []Zone{
+		{
+			ID:   "11af3414-ebba-11e9-8df5-66fbe8a334b4",
+			Name: "test.com",
+			Type: "NATIVE",
+		},
+		{
+			ID:   "22bf3414-ebba-11e9-8df5-66fbe8a334b4",
+			Name: "example.org",
+			Type: "NATIVE",
+		},
+	}
+
+	assert.Equal(t, expected, zones)
+}
+
+func TestClient_ListZones_pagination_loop(t *testing.T) {
+	mux, client := setupTest(t)
+
+	page := mockHandler(http.MethodGet, http.StatusOK, "list_zones_page1.json")
+
+	mux.HandleFunc("/v1/zones", func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Link", `</v1/zones>; rel="next"`)
+		page(rw, req)
+	})
+
+	_, err := client.ListZones(context.Background())
+	require.Error(t, err)
+}
+
 func TestClient_ListZones_error(t *testing.T) {
 	mux, client := setupTest(t)
 
@@ -48,6 +194,99 @@ func TestClient_ListZones_error(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, cErr.StatusCode)
 }
 
+func TestClient_FindZoneByName(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		fqdn     string
+		expected string
+	}{
+		{
+			desc:     "apex",
+			fqdn:     "example.com.",
+			expected: "example.com",
+		},
+		{
+			desc:     "apex without trailing dot",
+			fqdn:     "test.com",
+			expected: "test.com",
+		},
+		{
+			desc:     "subdomain",
+			fqdn:     "_acme-challenge.www.example.com.",
+			expected: "example.com",
+		},
+		{
+			desc:     "longest match",
+			fqdn:     "_acme-challenge.foo.sub.example.com.",
+			expected: "sub.example.com",
+		},
+		{
+			desc:     "case insensitive",
+			fqdn:     "_acme-challenge.Sub.Example.COM.",
+			expected: "sub.example.com",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			mux, client := setupTest(t)
+
+			mux.HandleFunc("/v1/zones", mockHandler(http.MethodGet, http.StatusOK, "list_zones_multiple.json"))
+
+			zone, err := client.FindZoneByName(context.Background(), test.fqdn)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, zone.Name)
+		})
+	}
+}
+
+func TestClient_FindZoneByName_notFound(t *testing.T) {
+	testCases := []struct {
+		desc string
+		fqdn string
+	}{
+		{
+			desc: "unknown domain",
+			fqdn: "_acme-challenge.example.org.",
+		},
+		{
+			desc: "same suffix but different domain",
+			fqdn: "_acme-challenge.notexample.com.",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			mux, client := setupTest(t)
+
+			mux.HandleFunc("/v1/zones", mockHandler(http.MethodGet, http.StatusOK, "list_zones_multiple.json"))
+
+			_, err := client.FindZoneByName(context.Background(), test.fqdn)
+			require.Error(t, err)
+
+			var nfErr *ZoneNotFoundError
+			require.ErrorAs(t, err, &nfErr)
+			assert.Equal(t, test.fqdn, nfErr.FQDN)
+		})
+	}
+}
+
+func TestClient_FindZoneByName_error(t *testing.T) {
+	mux, client := setupTest(t)
+
+	mux.HandleFunc("/v1/zones", mockHandler(http.MethodGet, http.StatusUnauthorized, "list_zones_error.json"))
+
+	_, err := client.FindZoneByName(context.Background(), "example.com.")
+	require.Error(t, err)
+
+	var nfErr *ZoneNotFoundError
+	assert.False(t, errors.As(err, &nfErr))
+
+	var cErr *ClientError
+	assert.ErrorAs(t, err, &cErr)
+}
+
 func TestClient_GetRecords(t *testing.T) {
 	mux, client := setupTest(t)
 
@@ -135,8 +374,41 @@ func TestClient_ReplaceRecords_error(t *testing.T) {
 	require.Error(t, err)
 
 	var cErr *ClientError
-	assert.ErrorAs(t, err, &cErr)
+	require.ErrorAs(t, err, &cErr)
 	assert.Equal(t, http.StatusBadRequest, cErr.StatusCode)
+
+	expected := []Error{
+		{Code: "INVALID_RECORD", Message: "string"},
+		{Code: "UNAUTHORIZED", Message: "The customer is not authorized to do this operation."},
+		{Code: "INTERNAL_SERVER_ERROR"},
+	}
+	assert.Equal(t, expected, cErr.Errors)
+	assert.Empty(t, cErr.Body)
+
+	assert.True(t, cErr.HasCode("UNAUTHORIZED"))
+	assert.False(t, cErr.HasCode("RECORD_NOT_FOUND"))
+
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "INVALID_RECORD", apiErr.Code)
+}
+
+func TestClient_ReplaceRecords_error_raw(t *testing.T) {
+	mux, client := setupTest(t)
+
+	mux.HandleFunc("/v1/zones/azone01", func(rw http.ResponseWriter, _ *http.Request) {
+		http.Error(rw, "Bad Request", http.StatusBadRequest)
+	})
+
+	err := client.ReplaceRecords(context.Background(), "azone01", nil)
+	require.EqualError(t, err, "400: Bad Request\n: ")
+
+	var cErr *ClientError
+	require.ErrorAs(t, err, &cErr)
+	assert.Equal(t, http.StatusBadRequest, cErr.StatusCode)
+	assert.Empty(t, cErr.Errors)
+	assert.Equal(t, "Bad Request\n", cErr.Body)
+	assert.False(t, cErr.HasCode("UNAUTHORIZED"))
 }
 
 func setupTest(t *testing.T) (*http.ServeMux, *Client) {
@@ -146,10 +418,10 @@ func setupTest(t *testing.T) (*http.ServeMux, *Client) {
 	server := httptest.NewServer(mux)
 	t.Cleanup(server.Close)
 
-	client, err := NewClient("secret")
+	client, err := NewClient("secret", server.URL)
 	require.NoError(t, err)
 
-	client.BaseURL, _ = url.Parse(server.URL)
+	client.RetryPolicy = retry.Policy{MaxAttempts: 3}
 
 	return mux, client
 }
@@ -182,3 +454,13 @@ func mockHandler(method string, statusCode int, filename string) func(http.Respo
 		}
 	}
 }
+
+type countingTransport struct {
+	calls int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.calls++
+
+	return http.DefaultTransport.RoundTrip(req)
+}
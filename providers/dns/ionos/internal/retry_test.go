@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/providers/dns/internal/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ListZones_retry(t *testing.T) {
+	mux, client := setupTest(t)
+
+	var calls int
+	ok := mockHandler(http.MethodGet, http.StatusOK, "list_zones.json")
+
+	mux.HandleFunc("/v1/zones", func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+
+		if calls < 3 {
+			rw.Header().Set("Retry-After", "0")
+			rw.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		ok(rw, req)
+	})
+
+	zones, err := client.ListZones(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, calls)
+	assert.Len(t, zones, 1)
+}
+
+func TestClient_ListZones_retry_exhausted(t *testing.T) {
+	mux, client := setupTest(t)
+
+	var calls int
+	ko := mockHandler(http.MethodGet, http.StatusServiceUnavailable, "list_zones_error.json")
+
+	mux.HandleFunc("/v1/zones", func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+		ko(rw, req)
+	})
+
+	_, err := client.ListZones(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, client.RetryPolicy.MaxAttempts, calls)
+
+	var cErr *ClientError
+	require.ErrorAs(t, err, &cErr)
+	assert.Equal(t, http.StatusServiceUnavailable, cErr.StatusCode)
+	assert.True(t, cErr.Retryable())
+}
+
+func TestClient_ListZones_noRetry(t *testing.T) {
+	mux, client := setupTest(t)
+
+	var calls int
+	ko := mockHandler(http.MethodGet, http.StatusUnauthorized, "list_zones_error.json")
+
+	mux.HandleFunc("/v1/zones", func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+		ko(rw, req)
+	})
+
+	_, err := client.ListZones(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, 1, calls)
+
+	var cErr *ClientError
+	require.ErrorAs(t, err, &cErr)
+	assert.False(t, cErr.Retryable())
+}
+
+func TestClient_ReplaceRecords_retry_body(t *testing.T) {
+	mux, client := setupTest(t)
+
+	var bodies []string
+
+	mux.HandleFunc("/v1/zones/azone01", func(rw http.ResponseWriter, req *http.Request) {
+		raw, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		bodies = append(bodies, string(raw))
+
+		if len(bodies) == 1 {
+			rw.WriteHeader(http.StatusBadGateway)
+		}
+	})
+
+	records := []Record{{Name: "string", Content: "string", Type: "TXT"}}
+
+	err := client.ReplaceRecords(context.Background(), "azone01", records)
+	require.NoError(t, err)
+
+	require.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0], bodies[1])
+	assert.NotEmpty(t, bodies[1])
+}
+
+func TestClient_retry_contextCanceled(t *testing.T) {
+	mux, client := setupTest(t)
+
+	client.RetryPolicy = retry.Policy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}
+
+	mux.HandleFunc("/v1/zones", func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	_, err := client.ListZones(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
@@ -3,23 +3,29 @@ package internal
 import (
 	"fmt"
 	"strconv"
+
+	"github.com/go-acme/lego/v4/providers/dns/internal/retry"
 )
 
 // ClientError a detailed error.
 type ClientError struct {
-	errors     []Error
 	StatusCode int
-	message    string
+
+	// Errors contains the errors returned by the API.
+	Errors []Error
+
+	// Body contains the raw body of the response when it's not a list of errors.
+	Body string
 }
 
 func (f ClientError) Error() string {
 	msg := strconv.Itoa(f.StatusCode) + ": "
 
-	if f.message != "" {
-		msg += f.message + ": "
+	if f.Body != "" {
+		msg += f.Body + ": "
 	}
 
-	for i, e := range f.errors {
+	for i, e := range f.Errors {
 		if i != 0 {
 			msg += ", "
 		}
@@ -31,11 +37,37 @@ func (f ClientError) Error() string {
 }
 
 func (f ClientError) Unwrap() error {
-	if len(f.errors) == 0 {
+	if len(f.Errors) == 0 {
 		return nil
 	}
 
-	return &f.errors[0]
+	return &f.Errors[0]
+}
+
+// Retryable returns true if the request failed because of a transient error (429 and 5xx).
+// It allows to handle the errors of the API like the ACME problems (acme.RetryableError).
+func (f ClientError) Retryable() bool {
+	return retry.IsTransient(f.StatusCode)
+}
+
+// HasCode checks if one of the errors returned by the API has the given code.
+func (f ClientError) HasCode(code string) bool {
+	for _, e := range f.Errors {
+		if e.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ZoneNotFoundError is returned when no zone matches a FQDN.
+type ZoneNotFoundError struct {
+	FQDN string
+}
+
+func (e *ZoneNotFoundError) Error() string {
+	return fmt.Sprintf("no matching zone found for %s", e.FQDN)
 }
 
 // Error defines model for error.
@@ -3,56 +3,124 @@ package ionos
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/platform/config/env"
+	"github.com/go-acme/lego/v4/providers/dns/internal/ratelimit"
+	"github.com/go-acme/lego/v4/providers/dns/internal/timeouts"
+	"github.com/go-acme/lego/v4/providers/dns/internal/tlsconfig"
 	"github.com/go-acme/lego/v4/providers/dns/ionos/internal"
 )
 
-const minTTL = 300
+// TTL limits of the IONOS API, the API rejects the records outside these limits.
+const (
+	minTTL = 300
+	maxTTL = 86400
+)
+
+// sharedTransports are the transports derived from internal.DefaultTransport by connection timeout,
+// shared by the providers to reuse the connections.
+var (
+	sharedTransports   = map[time.Duration]http.RoundTripper{}
+	sharedTransportsMu sync.Mutex
+)
 
 // Environment variables names.
 const (
 	envNamespace = "IONOS_"
 
 	EnvAPIKey = envNamespace + "API_KEY"
+	EnvAPIURL = envNamespace + "API_URL"
 
 	EnvTTL                = envNamespace + "TTL"
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
 	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
+
+	EnvConnectTimeout   = envNamespace + "CONNECT_TIMEOUT"
+	EnvChallengeTimeout = envNamespace + "CHALLENGE_TIMEOUT"
+
+	EnvAuthoritativeCheck        = envNamespace + "AUTHORITATIVE_CHECK"
+	EnvAuthoritativeCheckTimeout = envNamespace + "AUTHORITATIVE_CHECK_TIMEOUT"
+
+	EnvRateLimit      = envNamespace + "RATE_LIMIT"
+	EnvRateLimitBurst = envNamespace + "RATE_LIMIT_BURST"
+
+	EnvZonesCacheTTL = envNamespace + "ZONES_CACHE_TTL"
+
+	EnvTLSInsecureSkipVerify = envNamespace + "TLS_INSECURE_SKIP_VERIFY"
 )
 
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
 	APIKey             string
+	BaseURL            string
 	PropagationTimeout time.Duration
 	PollingInterval    time.Duration
 	TTL                int
 	HTTPClient         *http.Client
+
+	// Timeouts are the connection, request, and challenge timeouts.
+	// The request timeout is applied to HTTPClient if it has no timeout.
+	Timeouts timeouts.Config
+
+	// AuthoritativeCheck enables the check of the TXT record on the authoritative nameservers of the zone,
+	// before the end of the Present step. The propagation check of the challenge then queries the same nameservers.
+	AuthoritativeCheck        bool
+	AuthoritativeCheckTimeout time.Duration
+
+	// RateLimit is the maximum number of API requests per second (0 disables the limit).
+	RateLimit      float64
+	RateLimitBurst int
+
+	// ZonesCacheTTL is the duration during which the list of zones is reused by the challenges (0 disables the cache).
+	ZonesCacheTTL time.Duration
+
+	// TLSConfig is the TLS configuration used to reach the API (e.g. a proxy with a self-signed certificate).
+	// It's applied to the transport of HTTPClient, which must be nil or an *http.Transport.
+	// Optional.
+	TLSConfig *tls.Config
 }
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
 	return &Config{
+		BaseURL:            env.GetOrDefaultString(EnvAPIURL, internal.DefaultBaseURL),
 		TTL:                env.GetOrDefaultInt(EnvTTL, minTTL),
 		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
 		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
-		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+		HTTPClient:         &http.Client{Transport: internal.DefaultTransport},
+		Timeouts: timeouts.Config{
+			ConnectTimeout:   env.GetOrDefaultSecond(EnvConnectTimeout, 10*time.Second),
+			RequestTimeout:   env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			ChallengeTimeout: env.GetOrDefaultSecond(EnvChallengeTimeout, 0),
 		},
+		AuthoritativeCheck:        env.GetOrDefaultBool(EnvAuthoritativeCheck, false),
+		AuthoritativeCheckTimeout: env.GetOrDefaultSecond(EnvAuthoritativeCheckTimeout, 2*time.Minute),
+		RateLimit:                 env.GetOrDefaultFloat(EnvRateLimit, 0),
+		RateLimitBurst:            env.GetOrDefaultInt(EnvRateLimitBurst, 1),
+		ZonesCacheTTL:             env.GetOrDefaultSecond(EnvZonesCacheTTL, time.Minute),
+		TLSConfig:                 tlsconfig.InsecureSkipVerify(env.GetOrDefaultBool(EnvTLSInsecureSkipVerify, false)),
 	}
 }
 
 // DNSProvider implements the challenge.Provider interface.
 type DNSProvider struct {
-	config *Config
-	client *internal.Client
+	config   *Config
+	client   *internal.Client
+	resolver resolver
+
+	// values presented by FQDN, the boolean is false when the value has been cleaned up.
+	values   map[string]map[string]bool
+	valuesMu sync.Mutex
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for Ionos.
@@ -83,7 +151,11 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		return nil, fmt.Errorf("ionos: invalid TTL, TTL (%d) must be greater than %d", config.TTL, minTTL)
 	}
 
-	client, err := internal.NewClient(config.APIKey)
+	if config.TTL > maxTTL {
+		return nil, fmt.Errorf("ionos: invalid TTL, TTL (%d) must be lower than %d", config.TTL, maxTTL)
+	}
+
+	client, err := internal.NewClient(config.APIKey, config.BaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("ionos: %w", err)
 	}
@@ -92,12 +164,49 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		client.HTTPClient = config.HTTPClient
 	}
 
+	client.HTTPClient, err = wrapHTTPClient(client.HTTPClient, config)
+	if err != nil {
+		return nil, fmt.Errorf("ionos: %w", err)
+	}
+
+	client.HTTPClient = ratelimit.Wrap(client.HTTPClient, config.RateLimit, config.RateLimitBurst)
+
+	client.ZonesCacheTTL = config.ZonesCacheTTL
+
 	return &DNSProvider{
-		config: config,
-		client: client,
+		config:   config,
+		client:   client,
+		resolver: dnsResolver{timeout: 10 * time.Second},
+		values:   map[string]map[string]bool{},
 	}, nil
 }
 
+// wrapHTTPClient applies the timeouts and the TLS configuration to the HTTP client.
+// The transport of the default HTTP client stays shared when no TLS configuration is defined.
+func wrapHTTPClient(client *http.Client, config *Config) (*http.Client, error) {
+	if client.Transport != internal.DefaultTransport || config.TLSConfig != nil {
+		return tlsconfig.Wrap(config.Timeouts.Wrap(client), config.TLSConfig)
+	}
+
+	sharedTransportsMu.Lock()
+	defer sharedTransportsMu.Unlock()
+
+	transport, ok := sharedTransports[config.Timeouts.ConnectTimeout]
+	if !ok {
+		transport = config.Timeouts.Wrap(&http.Client{Transport: internal.DefaultTransport}).Transport
+		sharedTransports[config.Timeouts.ConnectTimeout] = transport
+	}
+
+	wrapped := *client
+	wrapped.Transport = transport
+
+	if wrapped.Timeout == 0 {
+		wrapped.Timeout = config.Timeouts.RequestTimeout
+	}
+
+	return &wrapped, nil
+}
+
 // Timeout returns the timeout and interval to use when checking for DNS propagation.
 // Adjusting here to cope with spikes in propagation times.
 func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
@@ -105,98 +214,275 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 }
 
 // Present creates a TXT record using the specified parameters.
-func (d *DNSProvider) Present(domain, _, keyAuth string) error {
-	fqdn, value := dns01.GetRecord(domain, keyAuth)
+// All the values presented for the same FQDN are written together with one call,
+// to not lose a value when several challenges share the same FQDN (e.g. wildcard and apex).
+// Presenting a value which already exists is a no-op.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	return d.PresentContext(context.Background(), domain, token, keyAuth)
+}
+
+// PresentContext is like Present, the API calls are aborted when the context is canceled.
+func (d *DNSProvider) PresentContext(parent context.Context, domain, _, keyAuth string) error {
+	info := dns01.GetChallengeInfo(dns01.ChallengePrefix(parent), domain, keyAuth)
+	fqdn, value := info.EffectiveFQDN, info.Value
 
-	ctx := context.Background()
+	ctx, cancel := d.config.Timeouts.Context(parent)
+	defer cancel()
 
-	zones, err := d.client.ListZones(ctx)
+	zone, err := d.client.FindZoneByName(ctx, fqdn)
 	if err != nil {
-		return fmt.Errorf("ionos: failed to get zones: %w", err)
+		return fmt.Errorf("ionos: failed to find zone: %w", err)
 	}
 
-	// TODO(ldez) replace domain by FQDN to follow CNAME.
-	zone := findZone(zones, domain)
-	if zone == nil {
-		return errors.New("ionos: no matching zone found for domain")
+	d.valuesMu.Lock()
+
+	err = d.writeValues(ctx, zone.ID, fqdn, value)
+
+	d.valuesMu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("ionos: %w", err)
 	}
 
-	filter := &internal.RecordsFilter{
-		Suffix:     dns01.UnFqdn(fqdn),
-		RecordType: "TXT",
+	if d.config.AuthoritativeCheck {
+		err = d.waitAuthoritativePropagation(ctx, zone.Name, fqdn, value)
+		if err != nil {
+			return fmt.Errorf("ionos: %w", err)
+		}
 	}
 
-	records, err := d.client.GetRecords(ctx, zone.ID, filter)
+	return nil
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+// The values presented for the same FQDN are removed together, when the last one is cleaned up.
+// Only the values added by the provider are removed, the other TXT records are kept.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	return d.CleanUpContext(context.Background(), domain, token, keyAuth)
+}
+
+// CleanUpContext is like CleanUp, the API calls are aborted when the context is canceled.
+func (d *DNSProvider) CleanUpContext(parent context.Context, domain, _, keyAuth string) error {
+	info := dns01.GetChallengeInfo(dns01.ChallengePrefix(parent), domain, keyAuth)
+	fqdn, value := info.EffectiveFQDN, info.Value
+
+	ctx, cancel := d.config.Timeouts.Context(parent)
+	defer cancel()
+
+	zone, err := d.client.FindZoneByName(ctx, fqdn)
 	if err != nil {
-		return fmt.Errorf("ionos: failed to get records (zone=%s): %w", zone.ID, err)
+		return fmt.Errorf("ionos: failed to find zone: %w", err)
 	}
 
-	records = append(records, internal.Record{
-		Name:    dns01.UnFqdn(fqdn),
-		Content: value,
-		TTL:     d.config.TTL,
-		Type:    "TXT",
-	})
+	d.valuesMu.Lock()
+	defer d.valuesMu.Unlock()
+
+	toRemove := d.releaseValue(fqdn, value)
+	if len(toRemove) == 0 {
+		return nil
+	}
 
-	err = d.client.ReplaceRecords(ctx, zone.ID, records)
+	records, err := d.getTXTRecords(ctx, zone.ID, fqdn)
 	if err != nil {
-		return fmt.Errorf("ionos: failed to create/update records (zone=%s): %w", zone.ID, err)
+		return fmt.Errorf("ionos: %w", err)
+	}
+
+	for _, record := range records {
+		if _, ok := toRemove[record.Content]; !ok {
+			continue
+		}
+
+		err := d.client.RemoveRecord(ctx, zone.ID, record.ID)
+		if err != nil {
+			return fmt.Errorf("ionos: failed to remove record (zone=%s, record=%s): %w", zone.ID, record.ID, err)
+		}
 	}
 
+	// the values are only forgotten when all the records are removed:
+	// after a failure, a new call (e.g. dns01.CleanUpRetry) removes all the values again.
+	delete(d.values, fqdn)
+
 	return nil
 }
 
-// CleanUp removes the TXT record matching the specified parameters.
-func (d *DNSProvider) CleanUp(domain, _, keyAuth string) error {
-	fqdn, value := dns01.GetRecord(domain, keyAuth)
+// ListChallengeRecords returns the challenge TXT records of the zone, the records starting with the prefix.
+// It allows to find the records left by a crashed process (see dns01.CleanUpChallengeRecords).
+func (d *DNSProvider) ListChallengeRecords(zone, prefix string) ([]dns01.ChallengeRecord, error) {
+	ctx, cancel := d.config.Timeouts.Context(context.Background())
+	defer cancel()
 
-	ctx := context.Background()
+	z, err := d.getZone(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("ionos: %w", err)
+	}
 
-	zones, err := d.client.ListZones(ctx)
+	records, err := d.client.GetRecords(ctx, z.ID, &internal.RecordsFilter{RecordType: "TXT"})
+	if err != nil {
+		return nil, fmt.Errorf("ionos: failed to get records (zone=%s): %w", z.ID, err)
+	}
+
+	var challengeRecords []dns01.ChallengeRecord
+	for _, record := range records {
+		if record.Type != "TXT" || !dns01.IsChallengeRecord(prefix, record.Name) {
+			continue
+		}
+
+		challengeRecords = append(challengeRecords, dns01.ChallengeRecord{
+			ID:    record.ID,
+			FQDN:  dns01.ToFqdn(record.Name),
+			Value: record.Content,
+		})
+	}
+
+	return challengeRecords, nil
+}
+
+// RemoveChallengeRecord removes a record returned by ListChallengeRecords.
+func (d *DNSProvider) RemoveChallengeRecord(zone string, record dns01.ChallengeRecord) error {
+	ctx, cancel := d.config.Timeouts.Context(context.Background())
+	defer cancel()
+
+	z, err := d.getZone(ctx, zone)
+	if err != nil {
+		return fmt.Errorf("ionos: %w", err)
+	}
+
+	err = d.client.RemoveRecord(ctx, z.ID, record.ID)
+	if err != nil {
+		return fmt.Errorf("ionos: failed to remove record (zone=%s, record=%s): %w", z.ID, record.ID, err)
+	}
+
+	return nil
+}
+
+// CredentialsValid checks that the API key is accepted, by listing the zones (see dns01.CheckCredentials).
+func (d *DNSProvider) CredentialsValid(ctx context.Context) error {
+	ctx, cancel := d.config.Timeouts.Context(ctx)
+	defer cancel()
+
+	_, err := d.client.ListZones(ctx)
 	if err != nil {
 		return fmt.Errorf("ionos: failed to get zones: %w", err)
 	}
 
-	// TODO(ldez) replace domain by FQDN to follow CNAME.
-	zone := findZone(zones, domain)
-	if zone == nil {
-		return errors.New("ionos: no matching zone found for domain")
+	return nil
+}
+
+// getZone returns the zone named zone, the parent zones are not matched.
+func (d *DNSProvider) getZone(ctx context.Context, zone string) (internal.Zone, error) {
+	z, err := d.client.FindZoneByName(ctx, zone)
+	if err != nil {
+		return internal.Zone{}, fmt.Errorf("failed to find zone: %w", err)
 	}
 
-	filter := &internal.RecordsFilter{
-		Suffix:     dns01.UnFqdn(fqdn),
-		RecordType: "TXT",
+	if !strings.EqualFold(dns01.UnFqdn(z.Name), dns01.UnFqdn(zone)) {
+		return internal.Zone{}, fmt.Errorf("failed to find zone: %s is not a zone (parent zone: %s)", dns01.UnFqdn(zone), z.Name)
 	}
 
-	records, err := d.client.GetRecords(ctx, zone.ID, filter)
+	return z, nil
+}
+
+// writeValues writes, in one call, the existing TXT records of the FQDN and all the values presented for it.
+// The caller must hold valuesMu.
+func (d *DNSProvider) writeValues(ctx context.Context, zoneID, fqdn, value string) error {
+	records, err := d.getTXTRecords(ctx, zoneID, fqdn)
 	if err != nil {
-		return fmt.Errorf("ionos: failed to get records (zone=%s): %w", zone.ID, err)
+		return err
 	}
 
+	if d.values[fqdn] == nil {
+		d.values[fqdn] = map[string]bool{}
+	}
+
+	_, known := d.values[fqdn][value]
+
+	d.values[fqdn][value] = true
+
+	existing := map[string]struct{}{}
 	for _, record := range records {
-		if record.Name == dns01.UnFqdn(fqdn) && record.Content == value {
-			err := d.client.RemoveRecord(ctx, zone.ID, record.ID)
-			if err != nil {
-				return fmt.Errorf("ionos: failed to remove record (zone=%s, record=%s): %w", zone.ID, record.ID, err)
-			}
-			return nil
+		existing[record.Content] = struct{}{}
+	}
+
+	var values []string
+	for v := range d.values[fqdn] {
+		if _, ok := existing[v]; !ok {
+			values = append(values, v)
+		}
+	}
+
+	if len(values) == 0 {
+		// all the values are already present (e.g. a stale record left by a previous run): nothing to write.
+		return nil
+	}
+
+	sort.Strings(values)
+
+	for _, v := range values {
+		records = append(records, internal.Record{
+			Name:    dns01.UnFqdn(fqdn),
+			Content: v,
+			TTL:     d.config.TTL,
+			Type:    "TXT",
+		})
+	}
+
+	err = d.client.ReplaceRecords(ctx, zoneID, records)
+	if err != nil {
+		if !known {
+			delete(d.values[fqdn], value)
 		}
+
+		return fmt.Errorf("failed to create/update records (zone=%s): %w", zoneID, err)
 	}
 
 	return nil
 }
 
-func findZone(zones []internal.Zone, domain string) *internal.Zone {
-	var result *internal.Zone
+// releaseValue marks a value as cleaned up, and returns the values to remove.
+// The values of a FQDN are only returned when all of them are cleaned up,
+// they are kept until the removal of the records succeeds.
+// The caller must hold valuesMu.
+func (d *DNSProvider) releaseValue(fqdn, value string) map[string]struct{} {
+	values := d.values[fqdn]
+
+	if _, ok := values[value]; !ok {
+		// unknown value (e.g. presented by another process).
+		return map[string]struct{}{value: {}}
+	}
+
+	values[value] = false
+
+	toRemove := map[string]struct{}{}
+	for v, inUse := range values {
+		if inUse {
+			return nil
+		}
+
+		toRemove[v] = struct{}{}
+	}
+
+	return toRemove
+}
+
+func (d *DNSProvider) getTXTRecords(ctx context.Context, zoneID, fqdn string) ([]internal.Record, error) {
+	name := dns01.UnFqdn(fqdn)
 
-	for _, zone := range zones {
-		zone := zone
-		if zone.Name != "" && strings.HasSuffix(domain, zone.Name) {
-			if result == nil || len(zone.Name) > len(result.Name) {
-				result = &zone
-			}
+	filter := &internal.RecordsFilter{
+		RecordName: name,
+		RecordType: "TXT",
+	}
+
+	records, err := d.client.GetRecords(ctx, zoneID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get records (zone=%s): %w", zoneID, err)
+	}
+
+	var txtRecords []internal.Record
+	for _, record := range records {
+		if record.Name == name && record.Type == "TXT" {
+			txtRecords = append(txtRecords, record)
 		}
 	}
 
-	return result
+	return txtRecords, nil
 }
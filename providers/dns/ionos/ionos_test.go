@@ -1,18 +1,54 @@
 package ionos
 
 import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-acme/lego/v4/providers/dns/internal/retry"
+	"github.com/go-acme/lego/v4/providers/dns/ionos/internal"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 const envDomain = envNamespace + "DOMAIN"
 
 var envTest = tester.NewEnvTest(
-	EnvAPIKey).
+	EnvAPIKey,
+	EnvAPIURL).
 	WithDomain(envDomain)
 
+func setupTest(t *testing.T) (*DNSProvider, *http.ServeMux) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+	config.BaseURL = server.URL
+	config.HTTPClient = server.Client()
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client.RetryPolicy = retry.Policy{}
+
+	return provider, mux
+}
+
 func TestNewDNSProvider(t *testing.T) {
 	testCases := []struct {
 		desc     string
@@ -32,6 +68,21 @@ func TestNewDNSProvider(t *testing.T) {
 			},
 			expected: "ionos: some credentials information are missing: IONOS_API_KEY",
 		},
+		{
+			desc: "custom API URL",
+			envVars: map[string]string{
+				EnvAPIKey: "123",
+				EnvAPIURL: "https://staging.example.com/dns",
+			},
+		},
+		{
+			desc: "invalid API URL",
+			envVars: map[string]string{
+				EnvAPIKey: "123",
+				EnvAPIURL: "staging.example.com",
+			},
+			expected: `ionos: invalid base URL "staging.example.com": the scheme must be http or https`,
+		},
 	}
 
 	for _, test := range testCases {
@@ -78,10 +129,24 @@ func TestNewDNSProviderConfig(t *testing.T) {
 			tll:      30,
 			expected: "ionos: invalid TTL, TTL (30) must be greater than 300",
 		},
+		{
+			desc:   "max TTL",
+			apiKey: "123",
+			tll:    maxTTL,
+		},
+		{
+			desc:     "TTL too high",
+			apiKey:   "123",
+			tll:      maxTTL + 1,
+			expected: "ionos: invalid TTL, TTL (86401) must be lower than 86400",
+		},
 	}
 
 	for _, test := range testCases {
 		t.Run(test.desc, func(t *testing.T) {
+			defer envTest.RestoreEnv()
+			envTest.ClearEnv()
+
 			config := NewDefaultConfig()
 			config.APIKey = test.apiKey
 			config.TTL = test.tll
@@ -100,6 +165,472 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func TestNewDNSProviderConfig_defaultTransport(t *testing.T) {
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	// the settings of the default transport are kept when the connection timeout is applied.
+	transport, ok := provider.client.HTTPClient.Transport.(*http.Transport)
+	require.True(t, ok)
+
+	assert.Equal(t, internal.DefaultTransport.MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, internal.DefaultTransport.ResponseHeaderTimeout, transport.ResponseHeaderTimeout)
+	assert.Equal(t, config.Timeouts.RequestTimeout, provider.client.HTTPClient.Timeout)
+
+	// the transport is shared by the providers with the same connection timeout.
+	config = NewDefaultConfig()
+	config.APIKey = "secret"
+
+	other, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	assert.Same(t, transport, other.client.HTTPClient.Transport)
+
+	config = NewDefaultConfig()
+	config.APIKey = "secret"
+	config.Timeouts.ConnectTimeout = 5 * time.Second
+
+	withTimeout, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	assert.NotSame(t, transport, withTimeout.client.HTTPClient.Transport)
+
+	config = NewDefaultConfig()
+	config.APIKey = "secret"
+	config.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+
+	withTLS, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	assert.NotSame(t, transport, withTLS.client.HTTPClient.Transport)
+}
+
+func TestDNSProvider_Present(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	provider.config.TTL = 600
+
+	zone := newFakeZone(mux)
+
+	err := provider.Present("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	_, value := dns01.GetRecord("example.com", "123d==")
+
+	expected := []internal.Record{
+		{ID: "1", Name: "_acme-challenge.example.com", Content: value, TTL: 600, Type: "TXT"},
+	}
+
+	assert.Equal(t, expected, zone.records)
+}
+
+func TestDNSProvider_Present_tlsConfig(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+
+	zone := newFakeZone(mux)
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+	config.BaseURL = server.URL
+	config.ZonesCacheTTL = 0
+
+	// the self-signed certificate of the server is rejected by default.
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client.RetryPolicy = retry.Policy{}
+
+	err = provider.Present("example.com", "", "123d==")
+	require.Error(t, err)
+
+	config.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+
+	provider, err = NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client.RetryPolicy = retry.Policy{}
+
+	err = provider.Present("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.Len(t, zone.records, 1)
+}
+
+func TestDNSProvider_Present_customTransport(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	zone := newFakeZone(mux)
+
+	var calls atomic.Int32
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+	config.BaseURL = server.URL
+	config.ZonesCacheTTL = 0
+	// the connection timeout can't be applied to a custom transport: it's skipped.
+	config.HTTPClient = &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls.Add(1)
+			return http.DefaultTransport.RoundTrip(req)
+		}),
+	}
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = provider.Present("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.Len(t, zone.records, 1)
+	assert.NotZero(t, calls.Load())
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestDNSProvider_Present_requestTimeout(t *testing.T) {
+	done := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/zones", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(done) })
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+	config.BaseURL = server.URL
+	config.HTTPClient = server.Client()
+	config.ZonesCacheTTL = 0
+	config.Timeouts.RequestTimeout = 100 * time.Millisecond
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client.RetryPolicy = retry.Policy{}
+
+	start := time.Now()
+
+	err = provider.Present("example.com", "", "123d==")
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "Client.Timeout exceeded")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestDNSProvider_Present_challengeTimeout(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	newFakeZone(mux)
+
+	provider.resolver = &stubResolver{
+		nameservers: []string{"ns1.example.net:53"},
+		value:       "other",
+	}
+	provider.config.AuthoritativeCheck = true
+	provider.config.AuthoritativeCheckTimeout = time.Minute
+	provider.config.PollingInterval = 10 * time.Millisecond
+	provider.config.Timeouts.ChallengeTimeout = 100 * time.Millisecond
+
+	start := time.Now()
+
+	err := provider.Present("example.com", "", "123d==")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestDNSProvider_AuthoritativeNameservers(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	newFakeZone(mux)
+
+	stub := &stubResolver{nameservers: []string{"ns1.example.net:53", "ns2.example.net:53"}}
+	provider.resolver = stub
+
+	var _ dns01.AuthoritativeNameserversProvider = provider
+
+	// disabled: the propagation check uses its own lookup.
+	nameservers, err := provider.AuthoritativeNameservers("_acme-challenge.www.example.com.")
+	require.NoError(t, err)
+	assert.Empty(t, nameservers)
+
+	provider.config.AuthoritativeCheck = true
+
+	nameservers, err = provider.AuthoritativeNameservers("_acme-challenge.www.example.com.")
+	require.NoError(t, err)
+
+	assert.Equal(t, stub.nameservers, nameservers)
+	assert.Equal(t, "example.com", stub.zone)
+}
+
+func TestDNSProvider_wildcardAndApex(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	zone := newFakeZone(mux)
+	zone.records = []internal.Record{
+		{ID: "existing", Name: "_acme-challenge.example.com", Content: "foo", TTL: 3600, Type: "TXT"},
+		{ID: "other", Name: "example.com", Content: "bar", TTL: 3600, Type: "TXT"},
+	}
+	zone.nextID = 1
+
+	// the wildcard prefix is removed before calling the provider.
+	_, valueApex := dns01.GetRecord("example.com", "apex")
+	_, valueWildcard := dns01.GetRecord("example.com", "wildcard")
+
+	err := provider.Present("example.com", "", "apex")
+	require.NoError(t, err)
+
+	err = provider.Present("example.com", "", "wildcard")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, zone.patches)
+	assert.ElementsMatch(t, []string{"foo", "bar", valueApex, valueWildcard}, zone.contents())
+
+	// the values are removed together when the last one is cleaned up.
+	err = provider.CleanUp("example.com", "", "apex")
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"foo", "bar", valueApex, valueWildcard}, zone.contents())
+
+	err = provider.CleanUp("example.com", "", "wildcard")
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"foo", "bar"}, zone.contents())
+	assert.Empty(t, provider.values)
+}
+
+func TestDNSProvider_CleanUp_retryAfterFailure(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	zone := newFakeZone(mux)
+
+	_, valueApex := dns01.GetRecord("example.com", "apex")
+	_, valueWildcard := dns01.GetRecord("example.com", "wildcard")
+
+	err := provider.Present("example.com", "", "apex")
+	require.NoError(t, err)
+
+	err = provider.Present("example.com", "", "wildcard")
+	require.NoError(t, err)
+
+	err = provider.CleanUp("example.com", "", "apex")
+	require.NoError(t, err)
+
+	// the removal of the first record fails.
+	zone.failedRemovals = 1
+
+	err = provider.CleanUp("example.com", "", "wildcard")
+	require.Error(t, err)
+
+	assert.ElementsMatch(t, []string{valueApex, valueWildcard}, zone.contents())
+	assert.NotEmpty(t, provider.values)
+
+	// the retry removes all the values of the FQDN, not only the value of the current challenge.
+	err = provider.CleanUp("example.com", "", "wildcard")
+	require.NoError(t, err)
+
+	assert.Empty(t, zone.contents())
+	assert.Empty(t, provider.values)
+}
+
+func TestDNSProvider_Present_staleRecord(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	_, value := dns01.GetRecord("example.com", "123d==")
+
+	// the record has been left by a previous run which didn't clean up.
+	zone := newFakeZone(mux)
+	zone.records = []internal.Record{
+		{ID: "existing", Name: "_acme-challenge.example.com", Content: "foo", TTL: 3600, Type: "TXT"},
+		{ID: "stale", Name: "_acme-challenge.example.com", Content: value, TTL: 3600, Type: "TXT"},
+	}
+
+	err := provider.Present("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, zone.patches)
+	assert.Equal(t, []string{"foo", value}, zone.contents())
+
+	// presenting again is still a no-op.
+	err = provider.Present("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, zone.patches)
+
+	err = provider.CleanUp("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"foo"}, zone.contents())
+}
+
+func TestDNSProvider_CleanUp_unknownValue(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	_, value := dns01.GetRecord("example.com", "123d==")
+
+	zone := newFakeZone(mux)
+	zone.records = []internal.Record{
+		{ID: "existing", Name: "_acme-challenge.example.com", Content: "foo", Type: "TXT"},
+		{ID: "challenge", Name: "_acme-challenge.example.com", Content: value, Type: "TXT"},
+	}
+
+	err := provider.CleanUp("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"foo"}, zone.contents())
+}
+
+func TestDNSProvider_Present_authoritativeCheck(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	newFakeZone(mux)
+
+	_, value := dns01.GetRecord("example.com", "123d==")
+
+	stub := &stubResolver{
+		nameservers: []string{"ns1.example.net:53", "ns2.example.net:53"},
+		visibleAfter: map[string]int{
+			"ns1.example.net:53": 1,
+			"ns2.example.net:53": 3,
+		},
+		value: value,
+	}
+
+	provider.resolver = stub
+	provider.config.AuthoritativeCheck = true
+	provider.config.AuthoritativeCheckTimeout = 5 * time.Second
+	provider.config.PollingInterval = 10 * time.Millisecond
+
+	err := provider.Present("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", stub.zone)
+	assert.GreaterOrEqual(t, stub.calls["ns2.example.net:53"], 3)
+}
+
+func TestDNSProvider_Present_authoritativeCheck_timeout(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	newFakeZone(mux)
+
+	provider.resolver = &stubResolver{
+		nameservers: []string{"ns1.example.net:53"},
+		value:       "other",
+	}
+	provider.config.AuthoritativeCheck = true
+	provider.config.AuthoritativeCheckTimeout = 50 * time.Millisecond
+	provider.config.PollingInterval = 10 * time.Millisecond
+
+	err := provider.Present("example.com", "", "123d==")
+	require.EqualError(t, err, "ionos: time limit exceeded: last error: nameserver ns1.example.net:53: TXT record not found")
+}
+
+func TestDNSProvider_CleanUpChallengeRecords(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	zone := newFakeZone(mux)
+	zone.records = []internal.Record{
+		{ID: "1", Name: "_acme-challenge.example.com", Content: "stale1", TTL: 300, Type: "TXT"},
+		{ID: "2", Name: "_acme-challenge.example.com", Content: "stale2", TTL: 300, Type: "TXT"},
+		{ID: "3", Name: "_acme-challenge.www.example.com", Content: "stale3", TTL: 300, Type: "TXT"},
+		{ID: "4", Name: "example.com", Content: "v=spf1 -all", TTL: 300, Type: "TXT"},
+		{ID: "5", Name: "_acme-challenge.example.com", Content: "192.0.2.1", TTL: 300, Type: "A"},
+	}
+	zone.nextID = 6
+
+	expected := []dns01.ChallengeRecord{
+		{ID: "1", FQDN: "_acme-challenge.example.com.", Value: "stale1"},
+		{ID: "2", FQDN: "_acme-challenge.example.com.", Value: "stale2"},
+		{ID: "3", FQDN: "_acme-challenge.www.example.com.", Value: "stale3"},
+	}
+
+	// dry run.
+	records, err := dns01.CleanUpChallengeRecords(provider, "example.com", "", true)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, records)
+	assert.Len(t, zone.records, 5)
+
+	records, err = dns01.CleanUpChallengeRecords(provider, "example.com", "", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, records)
+	assert.Equal(t, []string{"v=spf1 -all", "192.0.2.1"}, zone.contents())
+}
+
+func TestDNSProvider_CleanUpChallengeRecords_customPrefix(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	zone := newFakeZone(mux)
+	zone.records = []internal.Record{
+		{ID: "1", Name: "_acme-challenge.example.com", Content: "stale1", TTL: 300, Type: "TXT"},
+		{ID: "2", Name: "_custom.example.com", Content: "stale2", TTL: 300, Type: "TXT"},
+	}
+	zone.nextID = 3
+
+	records, err := dns01.CleanUpChallengeRecords(provider, "example.com", "_custom", false)
+	require.NoError(t, err)
+
+	expected := []dns01.ChallengeRecord{
+		{ID: "2", FQDN: "_custom.example.com.", Value: "stale2"},
+	}
+
+	assert.Equal(t, expected, records)
+	assert.Equal(t, []string{"stale1"}, zone.contents())
+}
+
+func TestDNSProvider_ListChallengeRecords_notAZone(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	newFakeZone(mux)
+
+	_, err := provider.ListChallengeRecords("www.example.com.", dns01.DefaultChallengePrefix)
+	require.EqualError(t, err, "ionos: failed to find zone: www.example.com is not a zone (parent zone: example.com)")
+}
+
+func TestDNSProvider_CredentialsValid(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	newFakeZone(mux)
+
+	checked, err := dns01.CheckCredentials(context.Background(), provider)
+	require.NoError(t, err)
+
+	assert.True(t, checked)
+}
+
+func TestDNSProvider_CredentialsValid_unauthorized(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	mux.HandleFunc("/v1/zones", func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusUnauthorized)
+	})
+
+	checked, err := dns01.CheckCredentials(context.Background(), provider)
+	require.ErrorContains(t, err, "dns01: invalid credentials: ionos: failed to get zones: ")
+
+	assert.True(t, checked)
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
@@ -125,3 +656,139 @@ func TestLiveCleanUp(t *testing.T) {
 	err = provider.CleanUp(envTest.GetDomain(), "", "123d==")
 	require.NoError(t, err)
 }
+
+// fakeZone an in-memory zone "example.com" (ID: azone01).
+type fakeZone struct {
+	mu      sync.Mutex
+	records []internal.Record
+	nextID  int
+	patches int
+
+	// failedRemovals is the number of the next record removals which fail.
+	failedRemovals int
+}
+
+func newFakeZone(mux *http.ServeMux) *fakeZone {
+	zone := &fakeZone{nextID: 1}
+
+	mux.HandleFunc("/v1/zones", func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(rw, `[{"id":"azone01","name":"example.com","type":"NATIVE"}]`)
+	})
+
+	mux.HandleFunc("/v1/zones/azone01", func(rw http.ResponseWriter, req *http.Request) {
+		zone.mu.Lock()
+		defer zone.mu.Unlock()
+
+		switch req.Method {
+		case http.MethodGet:
+			query := req.URL.Query()
+
+			var records []internal.Record
+			for _, record := range zone.records {
+				if (query.Get("recordName") == "" || record.Name == query.Get("recordName")) && record.Type == query.Get("recordType") {
+					records = append(records, record)
+				}
+			}
+
+			_ = json.NewEncoder(rw).Encode(internal.CustomerZone{ID: "azone01", Name: "example.com", Records: records})
+
+		case http.MethodPatch:
+			var records []internal.Record
+			err := json.NewDecoder(req.Body).Decode(&records)
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			zone.patches++
+
+			// replaces all the records with the same name and type.
+			for _, record := range records {
+				zone.remove(func(r internal.Record) bool { return r.Name == record.Name && r.Type == record.Type })
+			}
+
+			for _, record := range records {
+				if record.ID == "" {
+					record.ID = strconv.Itoa(zone.nextID)
+					zone.nextID++
+				}
+
+				zone.records = append(zone.records, record)
+			}
+
+		default:
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/v1/zones/azone01/records/", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodDelete {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		zone.mu.Lock()
+		defer zone.mu.Unlock()
+
+		if zone.failedRemovals > 0 {
+			zone.failedRemovals--
+			http.Error(rw, "oops", http.StatusInternalServerError)
+			return
+		}
+
+		id := path.Base(req.URL.Path)
+		zone.remove(func(r internal.Record) bool { return r.ID == id })
+	})
+
+	return zone
+}
+
+func (f *fakeZone) remove(match func(internal.Record) bool) {
+	var records []internal.Record
+	for _, record := range f.records {
+		if !match(record) {
+			records = append(records, record)
+		}
+	}
+
+	f.records = records
+}
+
+func (f *fakeZone) contents() []string {
+	var contents []string
+	for _, record := range f.records {
+		contents = append(contents, record.Content)
+	}
+
+	return contents
+}
+
+type stubResolver struct {
+	nameservers []string
+	// visibleAfter the number of lookups before the value is visible on a nameserver.
+	visibleAfter map[string]int
+	value        string
+
+	zone  string
+	calls map[string]int
+}
+
+func (s *stubResolver) LookupNS(_ context.Context, zone string) ([]string, error) {
+	s.zone = zone
+
+	return s.nameservers, nil
+}
+
+func (s *stubResolver) LookupTXT(_ context.Context, nameserver, _ string) ([]string, error) {
+	if s.calls == nil {
+		s.calls = map[string]int{}
+	}
+
+	s.calls[nameserver]++
+
+	if s.calls[nameserver] < s.visibleAfter[nameserver] {
+		return nil, nil
+	}
+
+	return []string{s.value}, nil
+}
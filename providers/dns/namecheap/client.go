@@ -21,12 +21,32 @@ type Record struct {
 	TTL     string `xml:",attr"`
 }
 
+// errCodeInvalidRequestIP is the error returned by the API when the client IP is not whitelisted.
+const errCodeInvalidRequestIP = 1011150
+
 // apiError describes an error record in a namecheap API response.
 type apiError struct {
 	Number      int    `xml:",attr"`
 	Description string `xml:",innerxml"`
 }
 
+func (e apiError) Error() string {
+	if e.Number == errCodeInvalidRequestIP {
+		return fmt.Sprintf("%s [%d]: the IP address must be whitelisted in the API access settings of the Namecheap account"+
+			" (https://ap.www.namecheap.com/settings/tools/apiaccess/), the sandbox has its own whitelist", e.Description, e.Number)
+	}
+
+	return fmt.Sprintf("%s [%d]", e.Description, e.Number)
+}
+
+// domainHosts describes the DNS host records of a domain.
+type domainHosts struct {
+	// EmailType the mail settings of the domain (MXE, MX, FWD, OX, GMAIL).
+	// It must be sent back when writing the records, otherwise the mail settings are reset.
+	EmailType string
+	Records   []Record
+}
+
 type setHostsResponse struct {
 	XMLName xml.Name   `xml:"ApiResponse"`
 	Status  string     `xml:"Status,attr"`
@@ -40,12 +60,15 @@ type getHostsResponse struct {
 	XMLName xml.Name   `xml:"ApiResponse"`
 	Status  string     `xml:"Status,attr"`
 	Errors  []apiError `xml:"Errors>Error"`
-	Hosts   []Record   `xml:"CommandResponse>DomainDNSGetHostsResult>host"`
+	Result  struct {
+		EmailType string   `xml:",attr"`
+		Hosts     []Record `xml:"host"`
+	} `xml:"CommandResponse>DomainDNSGetHostsResult"`
 }
 
 // getHosts reads the full list of DNS host records.
 // https://www.namecheap.com/support/api/methods/domains-dns/get-hosts.aspx
-func (d *DNSProvider) getHosts(sld, tld string) ([]Record, error) {
+func (d *DNSProvider) getHosts(sld, tld string) (*domainHosts, error) {
 	request, err := d.newRequestGet("namecheap.domains.dns.getHosts",
 		addParam("SLD", sld),
 		addParam("TLD", tld),
@@ -61,20 +84,24 @@ func (d *DNSProvider) getHosts(sld, tld string) ([]Record, error) {
 	}
 
 	if len(ghr.Errors) > 0 {
-		return nil, fmt.Errorf("%s [%d]", ghr.Errors[0].Description, ghr.Errors[0].Number)
+		return nil, ghr.Errors[0]
 	}
 
-	return ghr.Hosts, nil
+	return &domainHosts{EmailType: ghr.Result.EmailType, Records: ghr.Result.Hosts}, nil
 }
 
 // setHosts writes the full list of DNS host records .
 // https://www.namecheap.com/support/api/methods/domains-dns/set-hosts.aspx
-func (d *DNSProvider) setHosts(sld, tld string, hosts []Record) error {
+func (d *DNSProvider) setHosts(sld, tld string, hosts *domainHosts) error {
 	req, err := d.newRequestPost("namecheap.domains.dns.setHosts",
 		addParam("SLD", sld),
 		addParam("TLD", tld),
 		func(values url.Values) {
-			for i, h := range hosts {
+			if hosts.EmailType != "" {
+				values.Set("EmailType", hosts.EmailType)
+			}
+
+			for i, h := range hosts.Records {
 				ind := fmt.Sprintf("%d", i+1)
 				values.Add("HostName"+ind, h.Name)
 				values.Add("RecordType"+ind, h.Type)
@@ -95,7 +122,7 @@ func (d *DNSProvider) setHosts(sld, tld string, hosts []Record) error {
 	}
 
 	if len(shr.Errors) > 0 {
-		return fmt.Errorf("%s [%d]", shr.Errors[0].Description, shr.Errors[0].Number)
+		return shr.Errors[0]
 	}
 	if shr.Result.IsSuccess != "true" {
 		return errors.New("setHosts failed")
@@ -150,7 +150,7 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		return fmt.Errorf("namecheap: %w", err)
 	}
 
-	records, err := d.getHosts(ch.sld, ch.tld)
+	hosts, err := d.getHosts(ch.sld, ch.tld)
 	if err != nil {
 		return fmt.Errorf("namecheap: %w", err)
 	}
@@ -163,15 +163,15 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		TTL:     strconv.Itoa(d.config.TTL),
 	}
 
-	records = append(records, record)
+	hosts.Records = append(hosts.Records, record)
 
 	if d.config.Debug {
-		for _, h := range records {
+		for _, h := range hosts.Records {
 			log.Printf("%-5.5s %-30.30s %-6s %-70.70s", h.Type, h.Name, h.TTL, h.Address)
 		}
 	}
 
-	err = d.setHosts(ch.sld, ch.tld, records)
+	err = d.setHosts(ch.sld, ch.tld, hosts)
 	if err != nil {
 		return fmt.Errorf("namecheap: %w", err)
 	}
@@ -186,16 +186,17 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 		return fmt.Errorf("namecheap: %w", err)
 	}
 
-	records, err := d.getHosts(ch.sld, ch.tld)
+	hosts, err := d.getHosts(ch.sld, ch.tld)
 	if err != nil {
 		return fmt.Errorf("namecheap: %w", err)
 	}
 
 	// Find the challenge TXT record and remove it if found.
+	// The other records, including the TXT records of other challenges for the same name (e.g. wildcard), are kept.
 	var found bool
 	var newRecords []Record
-	for _, h := range records {
-		if h.Name == ch.key && h.Type == "TXT" {
+	for _, h := range hosts.Records {
+		if h.Name == ch.key && h.Type == "TXT" && h.Address == ch.keyValue {
 			found = true
 		} else {
 			newRecords = append(newRecords, h)
@@ -206,7 +207,9 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 		return nil
 	}
 
-	err = d.setHosts(ch.sld, ch.tld, newRecords)
+	hosts.Records = newRecords
+
+	err = d.setHosts(ch.sld, ch.tld, hosts)
 	if err != nil {
 		return fmt.Errorf("namecheap: %w", err)
 	}
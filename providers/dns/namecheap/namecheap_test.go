@@ -5,9 +5,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -29,12 +32,13 @@ func TestDNSProvider_getHosts(t *testing.T) {
 			hosts, err := p.getHosts(ch.sld, ch.tld)
 			if test.errString != "" {
 				assert.EqualError(t, err, test.errString)
-			} else {
-				assert.NoError(t, err)
+				return
 			}
 
+			require.NoError(t, err)
+
 		next1:
-			for _, h := range hosts {
+			for _, h := range hosts.Records {
 				for _, th := range test.hosts {
 					if h == th {
 						continue next1
@@ -45,7 +49,7 @@ func TestDNSProvider_getHosts(t *testing.T) {
 
 		next2:
 			for _, th := range test.hosts {
-				for _, h := range hosts {
+				for _, h := range hosts.Records {
 					if h == th {
 						continue next2
 					}
@@ -110,6 +114,56 @@ func TestDNSProvider_CleanUp(t *testing.T) {
 	}
 }
 
+func TestDNSProvider_Present_preserveRecords(t *testing.T) {
+	var values url.Values
+	p := setupRecordsTest(t, &values, responseGetHostsChallenge)
+
+	err := p.Present("example.com", "", "dummyKey")
+	require.NoError(t, err)
+
+	_, keyValue := dns01.GetRecord("example.com", "dummyKey")
+
+	assert.Equal(t, "MXE", values.Get("EmailType"))
+	assertRecords(t, values, []Record{
+		{Type: "A", Name: "@", Address: "10.0.0.2", MXPref: "10", TTL: "1200"},
+		{Type: "MXE", Name: "mail", Address: "10.0.0.5", MXPref: "10", TTL: "1800"},
+		{Type: "TXT", Name: "_acme-challenge.", Address: "wildcard", MXPref: "10", TTL: "120"},
+		{Type: "TXT", Name: "_acme-challenge.", Address: keyValue, MXPref: "10", TTL: "120"},
+	})
+}
+
+func TestDNSProvider_CleanUp_preserveRecords(t *testing.T) {
+	_, keyValue := dns01.GetRecord("example.com", "dummyKey")
+
+	var values url.Values
+	p := setupRecordsTest(t, &values, strings.ReplaceAll(responseGetHostsChallenge, "</DomainDNSGetHostsResult>",
+		`  <host HostId="217080" Name="_acme-challenge." Type="TXT" Address="`+keyValue+`" MXPref="10" TTL="120" IsActive="true" />
+    </DomainDNSGetHostsResult>`))
+
+	err := p.CleanUp("example.com", "", "dummyKey")
+	require.NoError(t, err)
+
+	assert.Equal(t, "MXE", values.Get("EmailType"))
+	assertRecords(t, values, []Record{
+		{Type: "A", Name: "@", Address: "10.0.0.2", MXPref: "10", TTL: "1200"},
+		{Type: "MXE", Name: "mail", Address: "10.0.0.5", MXPref: "10", TTL: "1800"},
+		{Type: "TXT", Name: "_acme-challenge.", Address: "wildcard", MXPref: "10", TTL: "120"},
+	})
+}
+
+func TestDNSProvider_Present_invalidRequestIP(t *testing.T) {
+	test := testCase{
+		domain:           "example.com",
+		getHostsResponse: responseGetHostsErrorInvalidIP,
+	}
+
+	p := setupTest(t, &test)
+
+	err := p.Present(test.domain, "", "dummyKey")
+	require.EqualError(t, err, "namecheap: Invalid request IP: 10.0.0.1 [1011150]: the IP address must be whitelisted"+
+		" in the API access settings of the Namecheap account (https://ap.www.namecheap.com/settings/tools/apiaccess/), the sandbox has its own whitelist")
+}
+
 func TestDomainSplit(t *testing.T) {
 	tests := []struct {
 		domain string
@@ -218,6 +272,61 @@ func setupTest(t *testing.T, tc *testCase) *DNSProvider {
 	return mockDNSProvider(t, server.URL)
 }
 
+// setupRecordsTest creates a provider with an API returning the getHosts response,
+// the form values of the setHosts call are stored in values.
+func setupRecordsTest(t *testing.T, values *url.Values, getHostsResponse string) *DNSProvider {
+	t.Helper()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("Command") {
+		case "namecheap.domains.dns.getHosts":
+			fmt.Fprint(w, getHostsResponse)
+			return
+		}
+
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if r.Form.Get("Command") != "namecheap.domains.dns.setHosts" {
+			t.Errorf("Unexpected command: %s", r.Form.Get("Command"))
+			return
+		}
+
+		*values = r.Form
+		fmt.Fprint(w, responseSetHostsSuccess2)
+	})
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	p := mockDNSProvider(t, server.URL)
+	p.config.TTL = 120
+
+	return p
+}
+
+// assertRecords checks the records sent to the setHosts command.
+func assertRecords(t *testing.T, values url.Values, expected []Record) {
+	t.Helper()
+
+	var records []Record
+	for i := 1; values.Has("HostName" + strconv.Itoa(i)); i++ {
+		ind := strconv.Itoa(i)
+		records = append(records, Record{
+			Type:    values.Get("RecordType" + ind),
+			Name:    values.Get("HostName" + ind),
+			Address: values.Get("Address" + ind),
+			MXPref:  values.Get("MXPref" + ind),
+			TTL:     values.Get("TTL" + ind),
+		})
+	}
+
+	assert.Equal(t, expected, records)
+}
+
 func mockDNSProvider(t *testing.T, baseURL string) *DNSProvider {
 	t.Helper()
 
@@ -353,3 +462,32 @@ const responseGetHostsErrorBadAPIKey1 = `<?xml version="1.0" encoding="utf-8"?>
   <GMTTimeDifference>--5:00</GMTTimeDifference>
   <ExecutionTime>0</ExecutionTime>
 </ApiResponse>`
+
+const responseGetHostsChallenge = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <Warnings />
+  <RequestedCommand>namecheap.domains.dns.getHosts</RequestedCommand>
+  <CommandResponse Type="namecheap.domains.dns.getHosts">
+    <DomainDNSGetHostsResult Domain="example.com" EmailType="MXE" IsUsingOurDNS="true">
+      <host HostId="217076" Name="@" Type="A" Address="10.0.0.2" MXPref="10" TTL="1200" IsActive="true" />
+      <host HostId="217073" Name="mail" Type="MXE" Address="10.0.0.5" MXPref="10" TTL="1800" IsActive="true" />
+      <host HostId="217079" Name="_acme-challenge." Type="TXT" Address="wildcard" MXPref="10" TTL="120" IsActive="true" />
+    </DomainDNSGetHostsResult>
+  </CommandResponse>
+  <Server>PHX01SBAPI01</Server>
+  <GMTTimeDifference>--5:00</GMTTimeDifference>
+  <ExecutionTime>3.338</ExecutionTime>
+</ApiResponse>`
+
+const responseGetHostsErrorInvalidIP = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors>
+    <Error Number="1011150">Invalid request IP: 10.0.0.1</Error>
+  </Errors>
+  <Warnings />
+  <RequestedCommand />
+  <Server>PHX01SBAPI01</Server>
+  <GMTTimeDifference>--5:00</GMTTimeDifference>
+  <ExecutionTime>0</ExecutionTime>
+</ApiResponse>`
@@ -62,7 +62,7 @@ type apiVersion struct {
 }
 
 func (d *DNSProvider) getHostedZone(fqdn string) (*hostedZone, error) {
-	authZone, err := dns01.FindZoneByFqdn(fqdn)
+	authZone, err := d.findZone(fqdn)
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +96,43 @@ func (d *DNSProvider) getHostedZone(fqdn string) (*hostedZone, error) {
 	return &zone, nil
 }
 
+// findZone returns the name of the zone of the FQDN (with a trailing dot),
+// from the list of the zones of the server or from DNS lookups.
+func (d *DNSProvider) findZone(fqdn string) (string, error) {
+	if !d.config.ListZones {
+		return dns01.FindZoneByFqdn(fqdn)
+	}
+
+	result, err := d.sendRequest(http.MethodGet, path.Join("/servers", d.config.ServerName, "/zones"), nil)
+	if err != nil {
+		return "", err
+	}
+
+	var zones []hostedZone
+	err = json.Unmarshal(result, &zones)
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.ToLower(dns.Fqdn(fqdn))
+
+	var authZone string
+	for _, zone := range zones {
+		// PowerDNS uses canonical names: the name of a zone ends with a dot.
+		zoneName := strings.ToLower(dns.Fqdn(zone.Name))
+
+		if (name == zoneName || strings.HasSuffix(name, "."+zoneName)) && len(zoneName) > len(authZone) {
+			authZone = zoneName
+		}
+	}
+
+	if authZone == "" {
+		return "", fmt.Errorf("no zone found for %s", fqdn)
+	}
+
+	return authZone, nil
+}
+
 func (d *DNSProvider) findTxtRecord(fqdn string) (*rrSet, error) {
 	zone, err := d.getHostedZone(fqdn)
 	if err != nil {
@@ -108,7 +145,8 @@ func (d *DNSProvider) findTxtRecord(fqdn string) (*rrSet, error) {
 	}
 
 	for _, set := range zone.RRSets {
-		if set.Type == "TXT" && (set.Name == dns01.UnFqdn(fqdn) || set.Name == fqdn) {
+		// the names can be canonical (with a trailing dot) or not, depending on the API version.
+		if set.Type == "TXT" && strings.EqualFold(dns.Fqdn(set.Name), dns.Fqdn(fqdn)) {
 			return &set, nil
 		}
 	}
@@ -157,7 +195,7 @@ func (d *DNSProvider) sendRequest(method, uri string, body io.Reader) (json.RawM
 		return nil, err
 	}
 
-	resp, err := d.config.HTTPClient.Do(req)
+	resp, err := d.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error talking to PDNS API: %w", err)
 	}
@@ -3,6 +3,7 @@ package pdns
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/log"
 	"github.com/go-acme/lego/v4/platform/config/env"
+	"github.com/go-acme/lego/v4/providers/dns/internal/tlsconfig"
 )
 
 // Environment variables names.
@@ -27,6 +29,9 @@ const (
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
 	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
 	EnvServerName         = envNamespace + "SERVER_NAME"
+	EnvListZones          = envNamespace + "LIST_ZONES"
+
+	EnvTLSInsecureSkipVerify = envNamespace + "TLS_INSECURE_SKIP_VERIFY"
 )
 
 // Config is used to configure the creation of the DNSProvider.
@@ -38,6 +43,15 @@ type Config struct {
 	PollingInterval    time.Duration
 	TTL                int
 	HTTPClient         *http.Client
+
+	// ListZones resolves the zone of a domain with the list of the zones of the server (`/zones`)
+	// instead of DNS lookups (e.g. hosted PowerDNS-compatible APIs, or zones not delegated yet).
+	ListZones bool
+
+	// TLSConfig is the TLS configuration used to reach the API (e.g. a self-signed certificate).
+	// It's applied to the transport of HTTPClient, which must be nil or an *http.Transport.
+	// Optional.
+	TLSConfig *tls.Config
 }
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
@@ -47,9 +61,11 @@ func NewDefaultConfig() *Config {
 		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
 		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
 		ServerName:         env.GetOrDefaultString(EnvServerName, "localhost"),
+		ListZones:          env.GetOrDefaultBool(EnvListZones, false),
 		HTTPClient: &http.Client{
 			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
+		TLSConfig: tlsconfig.InsecureSkipVerify(env.GetOrDefaultBool(EnvTLSInsecureSkipVerify, false)),
 	}
 }
 
@@ -57,6 +73,10 @@ func NewDefaultConfig() *Config {
 type DNSProvider struct {
 	apiVersion int
 	config     *Config
+
+	// httpClient is the HTTP client of the configuration with the TLS configuration applied,
+	// the configuration is not modified.
+	httpClient *http.Client
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for pdns.
@@ -94,7 +114,12 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		return nil, errors.New("pdns: API URL missing")
 	}
 
-	d := &DNSProvider{config: config}
+	httpClient, err := tlsconfig.Wrap(config.HTTPClient, config.TLSConfig)
+	if err != nil {
+		return nil, fmt.Errorf("pdns: %w", err)
+	}
+
+	d := &DNSProvider{config: config, httpClient: httpClient}
 
 	apiVersion, err := d.getAPIVersion()
 	if err != nil {
@@ -1,10 +1,17 @@
 package pdns
 
 import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
 
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -128,6 +135,81 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func TestNewDNSProviderConfig_tlsConfig(t *testing.T) {
+	api := newFakeAPI(t)
+
+	httpClient := &http.Client{}
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+	config.Host = api.url
+	config.HTTPClient = httpClient
+	config.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	// the TLS configuration is applied to a copy of the HTTP client of the configuration.
+	assert.Same(t, httpClient, config.HTTPClient)
+	assert.Nil(t, httpClient.Transport)
+
+	assert.NotSame(t, httpClient, provider.httpClient)
+	assert.NotNil(t, provider.httpClient.Transport)
+}
+
+func TestDNSProvider_listZones(t *testing.T) {
+	api := newFakeAPI(t)
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+	config.Host = api.url
+	config.ServerName = "srv1"
+	config.ListZones = true
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, provider.apiVersion)
+
+	err = provider.Present("sub.example.com", "", "123d==")
+	require.NoError(t, err)
+
+	expected := rrSet{
+		Name:       "_acme-challenge.sub.example.com.",
+		Type:       "TXT",
+		Kind:       "Master",
+		ChangeType: "REPLACE",
+		TTL:        config.TTL,
+		Records:    []Record{{Content: `"ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY"`, Name: "_acme-challenge.sub.example.com.", Type: "TXT", TTL: config.TTL}},
+	}
+
+	assert.Equal(t, []rrSet{expected}, api.changes())
+
+	api.zone.RRSets = []rrSet{{Name: expected.Name, Type: "TXT", Records: expected.Records}}
+
+	err = provider.CleanUp("sub.EXAMPLE.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.Equal(t, []rrSet{expected, {Name: expected.Name, Type: "TXT", ChangeType: "DELETE"}}, api.changes())
+	assert.Equal(t, 2, api.notifications)
+}
+
+func TestDNSProvider_listZones_notFound(t *testing.T) {
+	api := newFakeAPI(t)
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+	config.Host = api.url
+	config.ServerName = "srv1"
+	config.ListZones = true
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = provider.Present("example.org", "", "123d==")
+	require.EqualError(t, err, "pdns: no zone found for _acme-challenge.example.org.")
+}
+
 func TestLivePresentAndCleanup(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
@@ -143,3 +225,110 @@ func TestLivePresentAndCleanup(t *testing.T) {
 	err = provider.CleanUp(envTest.GetDomain(), "", "123d==")
 	require.NoError(t, err)
 }
+
+// fakeAPI is a minimal PowerDNS API server hosting the zones "example.com." and "sub.example.com.".
+type fakeAPI struct {
+	url  *url.URL
+	zone hostedZone
+
+	mu            sync.Mutex
+	patches       []rrSet
+	notifications int
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	t.Helper()
+
+	api := &fakeAPI{
+		zone: hostedZone{
+			ID:   "sub.example.com.",
+			Name: "sub.example.com.",
+			URL:  "/api/v1/servers/srv1/zones/sub.example.com.",
+			Kind: "Master",
+		},
+	}
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-API-Key") != "secret" {
+			http.Error(rw, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+
+		mux.ServeHTTP(rw, req)
+	}))
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/api", func(rw http.ResponseWriter, _ *http.Request) {
+		writeJSON(rw, []apiVersion{{URL: "/api/v1", Version: 1}})
+	})
+
+	mux.HandleFunc("/api/v1/servers/srv1/zones", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		writeJSON(rw, []hostedZone{
+			{ID: "example.com.", Name: "example.com.", URL: "/api/v1/servers/srv1/zones/example.com.", Kind: "Native"},
+			{ID: api.zone.ID, Name: api.zone.Name, URL: api.zone.URL, Kind: api.zone.Kind},
+		})
+	})
+
+	mux.HandleFunc("/api/v1/servers/srv1/zones/sub.example.com.", func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			writeJSON(rw, api.zone)
+
+		case http.MethodPatch:
+			var sets rrSets
+			err := json.NewDecoder(req.Body).Decode(&sets)
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			api.mu.Lock()
+			api.patches = append(api.patches, sets.RRSets...)
+			api.mu.Unlock()
+
+			rw.WriteHeader(http.StatusNoContent)
+
+		default:
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/v1/servers/srv1/zones/sub.example.com./notify", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPut {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		api.mu.Lock()
+		api.notifications++
+		api.mu.Unlock()
+
+		writeJSON(rw, map[string]string{"result": "Notification queued"})
+	})
+
+	api.url, _ = url.Parse(server.URL)
+
+	return api
+}
+
+func (f *fakeAPI) changes() []rrSet {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]rrSet(nil), f.patches...)
+}
+
+func writeJSON(rw http.ResponseWriter, data interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(rw).Encode(data)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -7,11 +7,13 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/platform/config/env"
+	"github.com/go-acme/lego/v4/providers/dns/internal/retry"
 	"github.com/nrdcg/porkbun"
 )
 
@@ -26,6 +28,8 @@ const (
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
 	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
+
+	EnvMaxRetries = envNamespace + "MAX_RETRIES"
 )
 
 const minTTL = 300
@@ -38,6 +42,9 @@ type Config struct {
 	PollingInterval    time.Duration
 	TTL                int
 	HTTPClient         *http.Client
+
+	// MaxRetries is the maximum number of retries of a request rejected by the rate limits of the API (0 disables the retries).
+	MaxRetries int
 }
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
@@ -49,6 +56,7 @@ func NewDefaultConfig() *Config {
 		HTTPClient: &http.Client{
 			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
+		MaxRetries: env.GetOrDefaultInt(EnvMaxRetries, 5),
 	}
 }
 
@@ -59,6 +67,8 @@ type DNSProvider struct {
 
 	recordIDs   map[string]int
 	recordIDsMu sync.Mutex
+
+	findZone func(fqdn string) (string, error)
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for Porkbun.
@@ -97,10 +107,13 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		client.HTTPClient = config.HTTPClient
 	}
 
+	client.HTTPClient = retry.Wrap(client.HTTPClient, retryPolicy(config.MaxRetries))
+
 	return &DNSProvider{
 		config:    config,
 		client:    client,
 		recordIDs: make(map[string]int),
+		findZone:  dns01.FindZoneByFqdn,
 	}, nil
 }
 
@@ -114,7 +127,7 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	fqdn, value := dns01.GetRecord(domain, keyAuth)
 
-	zoneName, hostName, err := splitDomain(fqdn)
+	zoneName, hostName, err := d.splitDomain(fqdn)
 	if err != nil {
 		return fmt.Errorf("porkbun: %w", err)
 	}
@@ -152,7 +165,7 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 		return fmt.Errorf("porkbun: unknown record ID for '%s' '%s'", fqdn, token)
 	}
 
-	zoneName, _, err := splitDomain(fqdn)
+	zoneName, _, err := d.splitDomain(fqdn)
 	if err != nil {
 		return fmt.Errorf("porkbun: %w", err)
 	}
@@ -168,8 +181,8 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 }
 
 // splitDomain splits the hostname from the authoritative zone, and returns both parts.
-func splitDomain(fqdn string) (string, string, error) {
-	zone, err := dns01.FindZoneByFqdn(fqdn)
+func (d *DNSProvider) splitDomain(fqdn string) (string, string, error) {
+	zone, err := d.findZone(fqdn)
 	if err != nil {
 		return "", "", err
 	}
@@ -181,3 +194,18 @@ func splitDomain(fqdn string) (string, string, error) {
 
 	return zone, subDomain, nil
 }
+
+// retryPolicy returns the policy retrying, at most maxRetries times, the requests rejected by the rate limits of the API (429 and 503 responses).
+// All the requests of the API are POST requests: the creations of records are only retried after a 429,
+// a creation failing with a 503 may have been applied.
+func retryPolicy(maxRetries int) retry.Policy {
+	return retry.Policy{
+		MaxAttempts: maxRetries + 1,
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Minute,
+		StatusCodes: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+		Replayable: func(req *http.Request) bool {
+			return !strings.Contains(req.URL.Path, "/dns/create/")
+		},
+	}
+}
@@ -1,10 +1,18 @@
 package porkbun
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-acme/lego/v4/providers/dns/internal/retry"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -145,6 +153,176 @@ func TestLiveCleanUp(t *testing.T) {
 	require.NoError(t, err)
 }
 
-func TestName(t *testing.T) {
-	fmt.Println(splitDomain("_acme-challenge.example.com."))
+func TestDNSProvider_splitDomain(t *testing.T) {
+	provider, _ := setupTest(t)
+
+	zone, subDomain, err := provider.splitDomain("_acme-challenge.sub.example.com.")
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com.", zone)
+	assert.Equal(t, "_acme-challenge.sub", subDomain)
+}
+
+func TestDNSProvider_Present(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	var record map[string]string
+
+	mux.HandleFunc("/dns/create/example.com", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		err := json.NewDecoder(req.Body).Decode(&record)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		_, _ = rw.Write([]byte(`{"status":"SUCCESS","id":123}`))
+	})
+
+	err := provider.Present("example.com", "abc", "123d==")
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"apikey":       "key",
+		"secretapikey": "secret",
+		"name":         "_acme-challenge",
+		"type":         "TXT",
+		"content":      "ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY",
+		"ttl":          "300",
+	}
+
+	assert.Equal(t, expected, record)
+	assert.Equal(t, map[string]int{"abc": 123}, provider.recordIDs)
+}
+
+func TestDNSProvider_CleanUp(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	var deleted bool
+
+	mux.HandleFunc("/dns/delete/example.com/123", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		deleted = true
+
+		_, _ = rw.Write([]byte(`{"status":"SUCCESS"}`))
+	})
+
+	provider.recordIDs["abc"] = 123
+
+	err := provider.CleanUp("example.com", "abc", "123d==")
+	require.NoError(t, err)
+
+	assert.True(t, deleted)
+}
+
+func TestDNSProvider_CleanUp_unknownRecord(t *testing.T) {
+	provider, _ := setupTest(t)
+
+	err := provider.CleanUp("example.com", "abc", "123d==")
+	require.EqualError(t, err, "porkbun: unknown record ID for '_acme-challenge.example.com.' 'abc'")
+}
+
+func TestDNSProvider_Present_rateLimit(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		statusCode    int
+		rejections    int
+		expectedCalls int
+		expectedErr   string
+	}{
+		{
+			desc:          "retried",
+			statusCode:    http.StatusTooManyRequests,
+			rejections:    2,
+			expectedCalls: 3,
+		},
+		{
+			desc:          "too many retries",
+			statusCode:    http.StatusTooManyRequests,
+			rejections:    10,
+			expectedCalls: 4,
+			expectedErr:   "porkbun: failed to create record: 429: rate limit exceeded",
+		},
+		{
+			desc:          "creation not replayed",
+			statusCode:    http.StatusServiceUnavailable,
+			rejections:    2,
+			expectedCalls: 1,
+			expectedErr:   "porkbun: failed to create record: 503: rate limit exceeded",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			provider, mux := setupTest(t)
+
+			var calls int
+
+			mux.HandleFunc("/dns/create/example.com", func(rw http.ResponseWriter, req *http.Request) {
+				calls++
+
+				body, err := io.ReadAll(req.Body)
+				if err != nil || len(body) == 0 {
+					http.Error(rw, "missing body", http.StatusBadRequest)
+					return
+				}
+
+				if calls <= test.rejections {
+					rw.WriteHeader(test.statusCode)
+					_, _ = rw.Write([]byte("rate limit exceeded"))
+					return
+				}
+
+				_, _ = rw.Write([]byte(`{"status":"SUCCESS","id":123}`))
+			})
+
+			err := provider.Present("example.com", "abc", "123d==")
+
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedCalls, calls)
+		})
+	}
+}
+
+func setupTest(t *testing.T) (*DNSProvider, *http.ServeMux) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	config := NewDefaultConfig()
+	config.SecretAPIKey = "secret"
+	config.APIKey = "key"
+	config.HTTPClient = server.Client()
+	config.MaxRetries = 3
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client.BaseURL, _ = url.Parse(server.URL)
+
+	policy := retryPolicy(config.MaxRetries)
+	policy.BaseDelay = 10 * time.Millisecond
+	provider.client.HTTPClient = retry.Wrap(server.Client(), policy)
+
+	provider.findZone = func(fqdn string) (string, error) {
+		return "example.com.", nil
+	}
+
+	return provider, mux
 }
@@ -21,6 +21,7 @@ const (
 	EnvTSIGSecret    = envNamespace + "TSIG_SECRET"
 	EnvTSIGAlgorithm = envNamespace + "TSIG_ALGORITHM"
 	EnvNameserver    = envNamespace + "NAMESERVER"
+	EnvSecondaries   = envNamespace + "SECONDARIES"
 	EnvDNSTimeout    = envNamespace + "DNS_TIMEOUT"
 
 	EnvTTL                = envNamespace + "TTL"
@@ -31,7 +32,11 @@ const (
 
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
-	Nameserver         string
+	Nameserver string
+	// Secondaries are the nameservers queried by the propagation check,
+	// when Nameserver is a hidden primary which is not queried by the CA.
+	// The nameservers of the zone are used if empty.
+	Secondaries        []string
 	TSIGAlgorithm      string
 	TSIGKey            string
 	TSIGSecret         string
@@ -62,6 +67,7 @@ type DNSProvider struct {
 // NewDNSProvider returns a DNSProvider instance configured for rfc2136
 // dynamic update. Configured with environment variables:
 // RFC2136_NAMESERVER: Network address in the form "host" or "host:port".
+// RFC2136_SECONDARIES: Comma-separated network addresses of the secondaries checked for the propagation (hidden primary).
 // RFC2136_TSIG_ALGORITHM: Defaults to hmac-md5.sig-alg.reg.int. (HMAC-MD5).
 // See https://github.com/miekg/dns/blob/master/tsig.go for supported values.
 // RFC2136_TSIG_KEY: Name of the secret key as defined in DNS server configuration.
@@ -76,6 +82,7 @@ func NewDNSProvider() (*DNSProvider, error) {
 
 	config := NewDefaultConfig()
 	config.Nameserver = values[EnvNameserver]
+	config.Secondaries = parseSecondaries(env.GetOrFile(EnvSecondaries))
 	config.TSIGKey = env.GetOrFile(EnvTSIGKey)
 	config.TSIGSecret = env.GetOrFile(EnvTSIGSecret)
 
@@ -96,13 +103,20 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		config.TSIGAlgorithm = dns.HmacSHA1
 	}
 
-	// Append the default DNS port if none is specified.
-	if _, _, err := net.SplitHostPort(config.Nameserver); err != nil {
-		if strings.Contains(err.Error(), "missing port") {
-			config.Nameserver = net.JoinHostPort(config.Nameserver, "53")
-		} else {
-			return nil, fmt.Errorf("rfc2136: %w", err)
+	nameserver, err := withDefaultPort(config.Nameserver)
+	if err != nil {
+		return nil, fmt.Errorf("rfc2136: %w", err)
+	}
+
+	config.Nameserver = nameserver
+
+	for i, ns := range config.Secondaries {
+		secondary, err := withDefaultPort(ns)
+		if err != nil {
+			return nil, fmt.Errorf("rfc2136: secondary %q: %w", ns, err)
 		}
+
+		config.Secondaries[i] = secondary
 	}
 
 	if config.TSIGKey == "" || config.TSIGSecret == "" {
@@ -125,6 +139,12 @@ func (d *DNSProvider) Sequential() time.Duration {
 	return d.config.SequenceInterval
 }
 
+// AuthoritativeNameservers returns the nameservers queried by the propagation check:
+// the secondaries when the records are written to a hidden primary.
+func (d *DNSProvider) AuthoritativeNameservers(_ string) ([]string, error) {
+	return d.config.Secondaries, nil
+}
+
 // Present creates a TXT record using the specified parameters.
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	fqdn, value := dns01.GetRecord(domain, keyAuth)
@@ -200,3 +220,27 @@ func (d *DNSProvider) changeRecord(action, fqdn, value string, ttl int) error {
 
 	return nil
 }
+
+// withDefaultPort appends the default DNS port if none is specified.
+func withDefaultPort(nameserver string) (string, error) {
+	if _, _, err := net.SplitHostPort(nameserver); err != nil {
+		if strings.Contains(err.Error(), "missing port") {
+			return net.JoinHostPort(nameserver, "53"), nil
+		}
+
+		return "", err
+	}
+
+	return nameserver, nil
+}
+
+func parseSecondaries(raw string) []string {
+	var secondaries []string
+	for _, ns := range strings.Split(raw, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			secondaries = append(secondaries, ns)
+		}
+	}
+
+	return secondaries
+}
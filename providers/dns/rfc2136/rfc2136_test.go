@@ -6,6 +6,7 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -157,6 +158,65 @@ func TestValidUpdatePacket(t *testing.T) {
 	}
 }
 
+func TestHiddenPrimary(t *testing.T) {
+	reqChan := make(chan *dns.Msg, 10)
+
+	dns01.ClearFqdnCache()
+	dns.HandleFunc(fakeZone, serverHandlerPassBackRequest(reqChan))
+	defer dns.HandleRemove(fakeZone)
+
+	primary, primaryAddr, err := runLocalDNSTestServer(false)
+	require.NoError(t, err, "Failed to start test server")
+	defer func() { _ = primary.Shutdown() }()
+
+	var secondaryQueries int32
+
+	secondaryConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	secondary := &dns.Server{
+		PacketConn: secondaryConn,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			atomic.AddInt32(&secondaryQueries, 1)
+
+			m := new(dns.Msg)
+			m.SetRcode(req, dns.RcodeRefused)
+			_ = w.WriteMsg(m)
+		}),
+	}
+
+	go func() { _ = secondary.ActivateAndServe() }()
+	defer func() { _ = secondary.Shutdown() }()
+
+	config := NewDefaultConfig()
+	config.Nameserver = primaryAddr
+	config.Secondaries = []string{secondaryConn.LocalAddr().String(), "ns2.example.com"}
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = provider.Present(fakeDomain, "", fakeKeyAuth)
+	require.NoError(t, err)
+
+	select {
+	case req := <-reqChan:
+		assert.Equal(t, dns.OpcodeUpdate, req.Opcode)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the primary did not receive the update")
+	}
+
+	nss, err := provider.AuthoritativeNameservers(fakeFqdn)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{secondaryConn.LocalAddr().String(), "ns2.example.com:53"}, nss)
+	assert.Zero(t, atomic.LoadInt32(&secondaryQueries))
+}
+
+func Test_parseSecondaries(t *testing.T) {
+	assert.Nil(t, parseSecondaries(""))
+	assert.Equal(t, []string{"ns1.example.com", "10.0.0.2:5353"}, parseSecondaries(" ns1.example.com, ,10.0.0.2:5353 "))
+}
+
 func runLocalDNSTestServer(tsig bool) (*dns.Server, string, error) {
 	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
 	if err != nil {
@@ -151,7 +151,7 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		return fmt.Errorf("route53: %w", err)
 	}
 
-	realValue := `"` + value + `"`
+	realValue := dns01.FormatTXTValue(value)
 
 	var found bool
 	for _, record := range records {
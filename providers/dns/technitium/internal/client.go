@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/providers/dns/internal/useragent"
+)
+
+// Statuses of the API responses.
+const (
+	StatusOK           = "ok"
+	StatusError        = "error"
+	StatusInvalidToken = "invalid-token"
+)
+
+// Client the Technitium DNS Server API client.
+type Client struct {
+	HTTPClient *http.Client
+
+	baseURL *url.URL
+	token   string
+}
+
+// NewClient creates a new Client.
+func NewClient(baseURL, token string) (*Client, error) {
+	endpoint, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: the scheme must be http or https", baseURL)
+	}
+
+	return &Client{
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    endpoint,
+		token:      token,
+	}, nil
+}
+
+// AddRecord adds a record.
+// The record is added to the closest authoritative zone of the domain.
+// https://github.com/TechnitiumSoftware/DnsServer/blob/master/APIDOCS.md#add-record
+func (c *Client) AddRecord(ctx context.Context, record Record) error {
+	data := url.Values{}
+	data.Set("domain", dns01.UnFqdn(record.Domain))
+	data.Set("type", record.Type)
+	data.Set("text", record.Value)
+
+	if record.TTL > 0 {
+		data.Set("ttl", strconv.Itoa(record.TTL))
+	}
+
+	return c.do(ctx, "/api/zones/records/add", data)
+}
+
+// DeleteRecord deletes a record.
+// Only the record matching the value is deleted, the other records of the same name are kept.
+// https://github.com/TechnitiumSoftware/DnsServer/blob/master/APIDOCS.md#delete-record
+func (c *Client) DeleteRecord(ctx context.Context, record Record) error {
+	data := url.Values{}
+	data.Set("domain", dns01.UnFqdn(record.Domain))
+	data.Set("type", record.Type)
+	data.Set("text", record.Value)
+
+	return c.do(ctx, "/api/zones/records/delete", data)
+}
+
+func (c *Client) do(ctx context.Context, path string, data url.Values) error {
+	endpoint := c.baseURL.JoinPath(path)
+
+	// the token is sent in the body, instead of the query, to avoid leaking it in the logs of the server.
+	data.Set("token", c.token)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), strings.NewReader(data.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	useragent.SetHeader(req.Header)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to perform API request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%d: failed to read response body: %w", resp.StatusCode, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, string(raw))
+	}
+
+	var result APIResponse
+	err = json.Unmarshal(raw, &result)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal the response body: %s: %w", string(raw), err)
+	}
+
+	if result.Status != StatusOK {
+		return &APIError{
+			Status:            result.Status,
+			ErrorMessage:      result.ErrorMessage,
+			InnerErrorMessage: result.InnerErrorMessage,
+		}
+	}
+
+	return nil
+}
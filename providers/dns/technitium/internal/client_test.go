@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const apiToken = "secret"
+
+func setupTest(t *testing.T) (*Client, *http.ServeMux) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL, apiToken)
+	require.NoError(t, err)
+
+	client.HTTPClient = server.Client()
+
+	return client, mux
+}
+
+func testHandler(filename string, expected url.Values) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		err := req.ParseForm()
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if req.URL.Query().Has("token") {
+			http.Error(rw, "the token must not be in the query", http.StatusBadRequest)
+			return
+		}
+
+		if req.PostForm.Get("token") != apiToken {
+			_, _ = fmt.Fprint(rw, `{"status":"invalid-token","errorMessage":"Invalid token or session expired."}`)
+			return
+		}
+
+		for k := range expected {
+			if req.PostForm.Get(k) != expected.Get(k) {
+				http.Error(rw, fmt.Sprintf("%s: got %q, want %q", k, req.PostForm.Get(k), expected.Get(k)), http.StatusBadRequest)
+				return
+			}
+		}
+
+		file, err := os.Open(filepath.Join("fixtures", filename))
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		defer func() { _ = file.Close() }()
+
+		_, err = io.Copy(rw, file)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient("ftp://dns.example.com", apiToken)
+	require.EqualError(t, err, `invalid base URL "ftp://dns.example.com": the scheme must be http or https`)
+
+	client, err := NewClient("https://dns.example.com:5380/", apiToken)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://dns.example.com:5380/api/zones/records/add", client.baseURL.JoinPath("/api/zones/records/add").String())
+}
+
+func TestClient_AddRecord(t *testing.T) {
+	client, mux := setupTest(t)
+
+	mux.HandleFunc("/api/zones/records/add", testHandler("add_record.json", url.Values{
+		"domain": {"_acme-challenge.example.com"},
+		"type":   {"TXT"},
+		"text":   {"txtTXTtxt"},
+		"ttl":    {"120"},
+	}))
+
+	record := Record{
+		Domain: "_acme-challenge.example.com.",
+		Type:   "TXT",
+		Value:  "txtTXTtxt",
+		TTL:    120,
+	}
+
+	err := client.AddRecord(context.Background(), record)
+	require.NoError(t, err)
+}
+
+func TestClient_AddRecord_error(t *testing.T) {
+	client, mux := setupTest(t)
+
+	mux.HandleFunc("/api/zones/records/add", testHandler("error.json", nil))
+
+	record := Record{
+		Domain: "_acme-challenge.example.org.",
+		Type:   "TXT",
+		Value:  "txtTXTtxt",
+		TTL:    120,
+	}
+
+	err := client.AddRecord(context.Background(), record)
+	require.EqualError(t, err, "status: error, message: No authoritative zone was found for the domain: _acme-challenge.example.org, inner message: Zone does not exist.")
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, StatusError, apiErr.Status)
+}
+
+func TestClient_AddRecord_invalidToken(t *testing.T) {
+	client, mux := setupTest(t)
+
+	mux.HandleFunc("/api/zones/records/add", testHandler("add_record.json", nil))
+
+	client.token = "invalid"
+
+	err := client.AddRecord(context.Background(), Record{Domain: "_acme-challenge.example.com", Type: "TXT", Value: "txtTXTtxt"})
+	require.EqualError(t, err, "status: invalid-token, message: Invalid token or session expired.")
+}
+
+func TestClient_DeleteRecord(t *testing.T) {
+	client, mux := setupTest(t)
+
+	mux.HandleFunc("/api/zones/records/delete", testHandler("delete_record.json", url.Values{
+		"domain": {"_acme-challenge.example.com"},
+		"type":   {"TXT"},
+		"text":   {"txtTXTtxt"},
+	}))
+
+	record := Record{
+		Domain: "_acme-challenge.example.com.",
+		Type:   "TXT",
+		Value:  "txtTXTtxt",
+	}
+
+	err := client.DeleteRecord(context.Background(), record)
+	require.NoError(t, err)
+}
+
+func TestClient_DeleteRecord_httpError(t *testing.T) {
+	client, mux := setupTest(t)
+
+	mux.HandleFunc("/api/zones/records/delete", func(rw http.ResponseWriter, _ *http.Request) {
+		http.Error(rw, "oops", http.StatusBadGateway)
+	})
+
+	err := client.DeleteRecord(context.Background(), Record{Domain: "_acme-challenge.example.com", Type: "TXT", Value: "txtTXTtxt"})
+	require.EqualError(t, err, "unexpected status code: 502: oops\n")
+}
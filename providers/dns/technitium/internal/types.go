@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Record a DNS record.
+type Record struct {
+	Domain string
+	Type   string
+	Value  string
+	TTL    int
+}
+
+// APIResponse the response of the API.
+// The status and the error are at the top level of the response,
+// the HTTP status code is 200 even when the request fails.
+type APIResponse struct {
+	Status   string          `json:"status"`
+	Response json.RawMessage `json:"response,omitempty"`
+
+	ErrorMessage      string `json:"errorMessage,omitempty"`
+	InnerErrorMessage string `json:"innerErrorMessage,omitempty"`
+	StackTrace        string `json:"stackTrace,omitempty"`
+}
+
+// APIError an error returned by the API.
+type APIError struct {
+	Status            string
+	ErrorMessage      string
+	InnerErrorMessage string
+}
+
+func (a *APIError) Error() string {
+	msg := fmt.Sprintf("status: %s", a.Status)
+
+	if a.ErrorMessage != "" {
+		msg += ", message: " + a.ErrorMessage
+	}
+
+	if a.InnerErrorMessage != "" {
+		msg += ", inner message: " + a.InnerErrorMessage
+	}
+
+	return msg
+}
@@ -0,0 +1,211 @@
+package technitium
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envDomain = envNamespace + "DOMAIN"
+
+var envTest = tester.NewEnvTest(
+	EnvServerBaseURL,
+	EnvAPIToken).
+	WithDomain(envDomain)
+
+func TestNewDNSProvider(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		envVars  map[string]string
+		expected string
+	}{
+		{
+			desc: "success",
+			envVars: map[string]string{
+				EnvServerBaseURL: "https://localhost:5380",
+				EnvAPIToken:      "secret",
+			},
+		},
+		{
+			desc: "missing server base URL",
+			envVars: map[string]string{
+				EnvServerBaseURL: "",
+				EnvAPIToken:      "secret",
+			},
+			expected: "technitium: some credentials information are missing: TECHNITIUM_SERVER_BASE_URL",
+		},
+		{
+			desc: "missing API token",
+			envVars: map[string]string{
+				EnvServerBaseURL: "https://localhost:5380",
+				EnvAPIToken:      "",
+			},
+			expected: "technitium: some credentials information are missing: TECHNITIUM_API_TOKEN",
+		},
+		{
+			desc:     "missing credentials",
+			envVars:  map[string]string{},
+			expected: "technitium: some credentials information are missing: TECHNITIUM_SERVER_BASE_URL,TECHNITIUM_API_TOKEN",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			defer envTest.RestoreEnv()
+			envTest.ClearEnv()
+
+			envTest.Apply(test.envVars)
+
+			p, err := NewDNSProvider()
+
+			if test.expected == "" {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+				require.NotNil(t, p.client)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewDNSProviderConfig(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		baseURL  string
+		apiToken string
+		expected string
+	}{
+		{
+			desc:     "success",
+			baseURL:  "https://localhost:5380",
+			apiToken: "secret",
+		},
+		{
+			desc:     "missing server base URL",
+			apiToken: "secret",
+			expected: "technitium: missing server base URL",
+		},
+		{
+			desc:     "missing API token",
+			baseURL:  "https://localhost:5380",
+			expected: "technitium: missing API token",
+		},
+		{
+			desc:     "invalid server base URL",
+			baseURL:  "localhost:5380",
+			apiToken: "secret",
+			expected: `technitium: invalid base URL "localhost:5380": the scheme must be http or https`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.BaseURL = test.baseURL
+			config.APIToken = test.apiToken
+
+			p, err := NewDNSProviderConfig(config)
+
+			if test.expected == "" {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+				require.NotNil(t, p.client)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_PresentCleanUp(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	var calls []string
+
+	handler := func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.PostFormValue("token") != "secret" {
+			_, _ = rw.Write([]byte(`{"status":"invalid-token","errorMessage":"Invalid token or session expired."}`))
+			return
+		}
+
+		calls = append(calls, fmt.Sprintf("%s %s %s %s %s", req.URL.Path,
+			req.PostFormValue("domain"), req.PostFormValue("type"), req.PostFormValue("text"), req.PostFormValue("ttl")))
+
+		_, _ = rw.Write([]byte(`{"status":"ok","response":{}}`))
+	}
+
+	mux.HandleFunc("/api/zones/records/add", handler)
+	mux.HandleFunc("/api/zones/records/delete", handler)
+
+	config := NewDefaultConfig()
+	config.BaseURL = server.URL
+	config.APIToken = "secret"
+	config.TTL = 300
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = provider.Present("www.example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	err = provider.CleanUp("www.example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	expected := []string{
+		"/api/zones/records/add _acme-challenge.www.example.com TXT ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY 300",
+		"/api/zones/records/delete _acme-challenge.www.example.com TXT ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY ",
+	}
+	assert.Equal(t, expected, calls)
+
+	config.APIToken = "invalid"
+
+	provider, err = NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = provider.Present("www.example.com", "token", "123d==")
+	require.EqualError(t, err, "technitium: add record: status: invalid-token, message: Invalid token or session expired.")
+}
+
+func TestLivePresent(t *testing.T) {
+	if !envTest.IsLiveTest() {
+		t.Skip("skipping live test")
+	}
+
+	envTest.RestoreEnv()
+	provider, err := NewDNSProvider()
+	require.NoError(t, err)
+
+	err = provider.Present(envTest.GetDomain(), "", "123d==")
+	require.NoError(t, err)
+}
+
+func TestLiveCleanUp(t *testing.T) {
+	if !envTest.IsLiveTest() {
+		t.Skip("skipping live test")
+	}
+
+	envTest.RestoreEnv()
+	provider, err := NewDNSProvider()
+	require.NoError(t, err)
+
+	time.Sleep(1 * time.Second)
+
+	err = provider.CleanUp(envTest.GetDomain(), "", "123d==")
+	require.NoError(t, err)
+}
@@ -129,10 +129,10 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	ctx := context.Background()
 
-	fqdn, _ := dns01.GetRecord(domain, keyAuth)
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
 
 	// TODO(ldez) replace domain by FQDN to follow CNAME.
-	zoneDomain, records, err := d.findTxtRecords(ctx, domain, fqdn)
+	zoneDomain, records, err := d.findTxtRecords(ctx, domain, fqdn, value)
 	if err != nil {
 		return fmt.Errorf("vultr: %w", err)
 	}
@@ -170,7 +170,10 @@ func (d *DNSProvider) getHostedZone(ctx context.Context, domain string) (string,
 		}
 
 		for _, dom := range domains {
-			if strings.HasSuffix(domain, dom.Domain) && len(dom.Domain) > len(hostedDomain.Domain) {
+			// the zone must be the domain or one of its parents (e.g. example.com is not a parent of myexample.com).
+			isParent := domain == dom.Domain || strings.HasSuffix(domain, "."+dom.Domain)
+
+			if isParent && len(dom.Domain) > len(hostedDomain.Domain) {
 				hostedDomain = dom
 			}
 		}
@@ -193,7 +196,9 @@ func (d *DNSProvider) getHostedZone(ctx context.Context, domain string) (string,
 	return hostedDomain.Domain, nil
 }
 
-func (d *DNSProvider) findTxtRecords(ctx context.Context, domain, fqdn string) (string, []govultr.DomainRecord, error) {
+// findTxtRecords returns the TXT records of the FQDN with the value,
+// the records of the other challenges for the same FQDN (e.g. wildcard and apex) are kept.
+func (d *DNSProvider) findTxtRecords(ctx context.Context, domain, fqdn, value string) (string, []govultr.DomainRecord, error) {
 	zoneDomain, err := d.getHostedZone(ctx, domain)
 	if err != nil {
 		return "", nil, err
@@ -214,7 +219,7 @@ func (d *DNSProvider) findTxtRecords(ctx context.Context, domain, fqdn string) (
 		}
 
 		for _, record := range result {
-			if record.Type == "TXT" && record.Name == subDomain {
+			if record.Type == "TXT" && record.Name == subDomain && strings.Trim(record.Data, `"`) == value {
 				records = append(records, record)
 			}
 		}
@@ -7,9 +7,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/platform/tester"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -219,6 +221,134 @@ func TestDNSProvider_getHostedZone(t *testing.T) {
 	}
 }
 
+func TestDNSProvider_getHostedZone_notAParent(t *testing.T) {
+	p, mux := setupTest(t)
+
+	mux.HandleFunc("/v2/domains", func(rw http.ResponseWriter, _ *http.Request) {
+		writeJSON(rw, map[string]interface{}{
+			"domains": []govultr.Domain{{Domain: "example.com"}},
+			"meta":    govultr.Meta{Total: 1, Links: &govultr.Links{}},
+		})
+	})
+
+	_, err := p.getHostedZone(context.Background(), "www.myexample.com")
+	require.EqualError(t, err, "no matching domain found for domain www.myexample.com")
+}
+
+func TestDNSProvider_Present(t *testing.T) {
+	p, mux := setupTest(t)
+
+	handleDomains(mux)
+
+	var created govultr.DomainRecordReq
+
+	mux.HandleFunc("/v2/domains/example.com/records", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, "unexpected method: "+req.Method, http.StatusMethodNotAllowed)
+			return
+		}
+
+		err := json.NewDecoder(req.Body).Decode(&created)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rw.WriteHeader(http.StatusCreated)
+		writeJSON(rw, map[string]interface{}{"record": govultr.DomainRecord{ID: "1", Type: "TXT", Name: created.Name, Data: created.Data}})
+	})
+
+	err := p.Present("www.example.com", "", "keyAuth")
+	require.NoError(t, err)
+
+	_, value := dns01.GetRecord("www.example.com", "keyAuth")
+
+	assert.Equal(t, "_acme-challenge.www", created.Name)
+	assert.Equal(t, "TXT", created.Type)
+	assert.Equal(t, `"`+value+`"`, created.Data)
+}
+
+func TestDNSProvider_CleanUp(t *testing.T) {
+	p, mux := setupTest(t)
+
+	handleDomains(mux)
+
+	_, value := dns01.GetRecord("example.com", "keyAuth")
+
+	// the records are split into 2 pages.
+	pages := map[string][]govultr.DomainRecord{
+		"": {
+			{ID: "1", Type: "A", Name: "www", Data: "192.0.2.1"},
+			{ID: "2", Type: "TXT", Name: "_acme-challenge", Data: `"wildcard"`},
+		},
+		"next": {
+			{ID: "3", Type: "TXT", Name: "_acme-challenge", Data: `"` + value + `"`},
+		},
+	}
+
+	mux.HandleFunc("/v2/domains/example.com/records", func(rw http.ResponseWriter, req *http.Request) {
+		cursor := req.URL.Query().Get("cursor")
+
+		var next string
+		if cursor == "" {
+			next = "next"
+		}
+
+		writeJSON(rw, map[string]interface{}{
+			"records": pages[cursor],
+			"meta":    govultr.Meta{Total: 3, Links: &govultr.Links{Next: next}},
+		})
+	})
+
+	var deleted []string
+
+	mux.HandleFunc("/v2/domains/example.com/records/", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodDelete {
+			http.Error(rw, "unexpected method: "+req.Method, http.StatusMethodNotAllowed)
+			return
+		}
+
+		deleted = append(deleted, strings.TrimPrefix(req.URL.Path, "/v2/domains/example.com/records/"))
+
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	err := p.CleanUp("example.com", "", "keyAuth")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"3"}, deleted)
+}
+
+func setupTest(t *testing.T) (*DNSProvider, *http.ServeMux) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := govultr.NewClient(nil)
+	err := client.SetBaseURL(server.URL)
+	require.NoError(t, err)
+
+	return &DNSProvider{client: client, config: NewDefaultConfig()}, mux
+}
+
+func handleDomains(mux *http.ServeMux) {
+	mux.HandleFunc("/v2/domains", func(rw http.ResponseWriter, _ *http.Request) {
+		writeJSON(rw, map[string]interface{}{
+			"domains": []govultr.Domain{{Domain: "example.org"}, {Domain: "example.com"}},
+			"meta":    govultr.Meta{Total: 2, Links: &govultr.Links{}},
+		})
+	})
+}
+
+func writeJSON(rw http.ResponseWriter, data interface{}) {
+	err := json.NewEncoder(rw).Encode(data)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
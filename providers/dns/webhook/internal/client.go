@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-acme/lego/v4/providers/dns/internal/useragent"
+)
+
+// SignatureHeader is the header containing the HMAC-SHA256 signature of the request body.
+const SignatureHeader = "X-Lego-Signature"
+
+// Client the webhook client.
+type Client struct {
+	HTTPClient *http.Client
+
+	endpoint *url.URL
+	secret   string
+}
+
+// NewClient creates a new Client.
+// If secret is empty, the requests are not signed.
+func NewClient(endpoint *url.URL, secret string) *Client {
+	return &Client{
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		endpoint:   endpoint,
+		secret:     secret,
+	}
+}
+
+// SendEvent sends an event to the webhook.
+func (c *Client) SendEvent(ctx context.Context, event Event) (*EventResponse, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	useragent.SetHeader(req.Header)
+
+	if c.secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(c.secret, body))
+	}
+
+	raw, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EventResponse{}
+
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return result, nil
+	}
+
+	err = json.Unmarshal(raw, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %s: %w", string(raw), err)
+	}
+
+	return result, nil
+}
+
+// GetStatus gets the status of a change.
+// A relative status URL is resolved against the webhook URL.
+func (c *Client) GetStatus(ctx context.Context, statusURL string) (*Status, error) {
+	endpoint, err := c.endpoint.Parse(statusURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status URL %q: %w", statusURL, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	useragent.SetHeader(req.Header)
+
+	if c.secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(c.secret, nil))
+	}
+
+	raw, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{}
+	err = json.Unmarshal(raw, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %s: %w", string(raw), err)
+	}
+
+	return status, nil
+}
+
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call webhook: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%d: failed to read response body: %w", resp.StatusCode, err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(raw)}
+	}
+
+	return raw, nil
+}
+
+// Sign computes the hex-encoded HMAC-SHA256 of the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest(t *testing.T, secret string, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	endpoint, err := url.Parse(server.URL + "/hook")
+	require.NoError(t, err)
+
+	client := NewClient(endpoint, secret)
+	client.HTTPClient = server.Client()
+
+	return client
+}
+
+func TestClient_SendEvent(t *testing.T) {
+	event := Event{
+		Action:    ActionPresent,
+		Domain:    "example.com",
+		FQDN:      "_acme-challenge.example.com.",
+		Value:     "value",
+		Token:     "token",
+		KeyAuth:   "keyAuth",
+		Timestamp: 1700000000,
+	}
+
+	client := setupTest(t, "secret", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.URL.Path != "/hook" {
+			http.Error(rw, "unexpected request", http.StatusNotFound)
+			return
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if req.Header.Get(SignatureHeader) != "sha256="+Sign("secret", body) {
+			http.Error(rw, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		expected := `{"action":"present","domain":"example.com","fqdn":"_acme-challenge.example.com.","value":"value","token":"token","keyAuth":"keyAuth","timestamp":1700000000}`
+		if string(body) != expected {
+			http.Error(rw, "unexpected body: "+string(body), http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(rw).Encode(EventResponse{StatusURL: "/status/1"})
+	})
+
+	resp, err := client.SendEvent(context.Background(), event)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/status/1", resp.StatusURL)
+}
+
+func TestClient_SendEvent_emptyResponse(t *testing.T) {
+	client := setupTest(t, "", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get(SignatureHeader) != "" {
+			http.Error(rw, "unexpected signature", http.StatusBadRequest)
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := client.SendEvent(context.Background(), Event{Action: ActionCleanUp})
+	require.NoError(t, err)
+
+	assert.Empty(t, resp.StatusURL)
+}
+
+func TestClient_SendEvent_error(t *testing.T) {
+	client := setupTest(t, "", func(rw http.ResponseWriter, _ *http.Request) {
+		http.Error(rw, "boom", http.StatusBadGateway)
+	})
+
+	_, err := client.SendEvent(context.Background(), Event{Action: ActionPresent})
+	require.EqualError(t, err, "unexpected status code: 502: boom\n")
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+}
+
+func TestClient_GetStatus(t *testing.T) {
+	client := setupTest(t, "secret", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet || req.URL.Path != "/status/1" {
+			http.Error(rw, "unexpected request", http.StatusNotFound)
+			return
+		}
+
+		if req.Header.Get(SignatureHeader) != "sha256="+Sign("secret", nil) {
+			http.Error(rw, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(rw).Encode(Status{Status: StatusFailed, Message: "zone locked"})
+	})
+
+	status, err := client.GetStatus(context.Background(), "/status/1")
+	require.NoError(t, err)
+
+	assert.Equal(t, &Status{Status: StatusFailed, Message: "zone locked"}, status)
+}
+
+func TestSign(t *testing.T) {
+	// echo -n 'hello' | openssl dgst -sha256 -hmac 'secret'
+	assert.Equal(t, "88aab3ede8d3adf94d26ab90d3bafd4a2083070c3bcce9c014ee04a443847c0b", Sign("secret", []byte("hello")))
+}
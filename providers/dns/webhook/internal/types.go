@@ -0,0 +1,50 @@
+package internal
+
+import "fmt"
+
+// Actions of an Event.
+const (
+	ActionPresent = "present"
+	ActionCleanUp = "cleanup"
+)
+
+// Status values reported by the status URL.
+const (
+	StatusPending = "pending"
+	StatusReady   = "ready"
+	StatusFailed  = "failed"
+)
+
+// Event is the payload sent to the webhook.
+type Event struct {
+	Action    string `json:"action"`
+	Domain    string `json:"domain"`
+	FQDN      string `json:"fqdn"`
+	Value     string `json:"value"`
+	Token     string `json:"token"`
+	KeyAuth   string `json:"keyAuth"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// EventResponse is the optional response body of the webhook.
+type EventResponse struct {
+	// StatusURL is the URL to poll until the change is ready.
+	// An empty value means that the change is already applied.
+	StatusURL string `json:"statusUrl,omitempty"`
+}
+
+// Status is the response body of the status URL.
+type Status struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// APIError is returned when the webhook responds with a non-2xx status code.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (a *APIError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d: %s", a.StatusCode, a.Body)
+}
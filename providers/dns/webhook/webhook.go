@@ -0,0 +1,194 @@
+// Package webhook implements a DNS provider for solving the DNS-01 challenge by calling a webhook.
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/platform/config/env"
+	"github.com/go-acme/lego/v4/platform/wait"
+	"github.com/go-acme/lego/v4/providers/dns/webhook/internal"
+)
+
+// Environment variables names.
+const (
+	envNamespace = "WEBHOOK_"
+
+	EnvURL    = envNamespace + "URL"
+	EnvSecret = envNamespace + "SECRET"
+
+	EnvStatusTimeout      = envNamespace + "STATUS_TIMEOUT"
+	EnvStatusInterval     = envNamespace + "STATUS_INTERVAL"
+	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
+	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
+	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
+)
+
+// Config is used to configure the creation of the DNSProvider.
+type Config struct {
+	URL *url.URL
+
+	// Secret is the key used to sign the requests (HMAC-SHA256), no signature if empty.
+	Secret string
+
+	// StatusTimeout is the maximum waiting time for a change to be ready, when the webhook returns a status URL.
+	StatusTimeout time.Duration
+	// StatusInterval is the time between two requests to the status URL.
+	StatusInterval time.Duration
+
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+	HTTPClient         *http.Client
+}
+
+// NewDefaultConfig returns a default configuration for the DNSProvider.
+func NewDefaultConfig() *Config {
+	return &Config{
+		StatusTimeout:      env.GetOrDefaultSecond(EnvStatusTimeout, 2*time.Minute),
+		StatusInterval:     env.GetOrDefaultSecond(EnvStatusInterval, 5*time.Second),
+		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		HTTPClient: &http.Client{
+			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+		},
+	}
+}
+
+// DNSProvider implements the challenge.Provider interface.
+type DNSProvider struct {
+	config *Config
+	client *internal.Client
+}
+
+// NewDNSProvider returns a DNSProvider instance configured for the webhook.
+// Credentials must be passed in the environment variable: WEBHOOK_URL.
+func NewDNSProvider() (*DNSProvider, error) {
+	values, err := env.Get(EnvURL)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+
+	endpoint, err := url.Parse(values[EnvURL])
+	if err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+
+	config := NewDefaultConfig()
+	config.URL = endpoint
+	config.Secret = env.GetOrFile(EnvSecret)
+
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig return a DNSProvider instance configured for the webhook.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("webhook: the configuration of the DNS provider is nil")
+	}
+
+	if config.URL == nil {
+		return nil, errors.New("webhook: the URL is missing")
+	}
+
+	if config.URL.Scheme != "http" && config.URL.Scheme != "https" {
+		return nil, fmt.Errorf("webhook: invalid URL %q: the scheme must be http or https", config.URL)
+	}
+
+	client := internal.NewClient(config.URL, config.Secret)
+
+	if config.HTTPClient != nil {
+		client.HTTPClient = config.HTTPClient
+	}
+
+	return &DNSProvider{config: config, client: client}, nil
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation.
+// Adjusting here to cope with spikes in propagation times.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.config.PropagationTimeout, d.config.PollingInterval
+}
+
+// Present creates a TXT record to fulfill the dns-01 challenge.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	err := d.send(internal.ActionPresent, domain, token, keyAuth)
+	if err != nil {
+		return fmt.Errorf("webhook: present: %w", err)
+	}
+
+	return nil
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	err := d.send(internal.ActionCleanUp, domain, token, keyAuth)
+	if err != nil {
+		return fmt.Errorf("webhook: cleanup: %w", err)
+	}
+
+	return nil
+}
+
+// send sends the event to the webhook, and waits for the change to be ready if the webhook returns a status URL.
+func (d *DNSProvider) send(action, domain, token, keyAuth string) error {
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+
+	event := internal.Event{
+		Action:    action,
+		Domain:    domain,
+		FQDN:      fqdn,
+		Value:     value,
+		Token:     token,
+		KeyAuth:   keyAuth,
+		Timestamp: time.Now().Unix(),
+	}
+
+	ctx := context.Background()
+
+	resp, err := d.client.SendEvent(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusURL == "" {
+		return nil
+	}
+
+	return d.waitStatus(ctx, fqdn, resp.StatusURL)
+}
+
+// waitStatus polls the status URL until the change is ready.
+func (d *DNSProvider) waitStatus(ctx context.Context, fqdn, statusURL string) error {
+	var failure error
+
+	msg := fmt.Sprintf("webhook: change of %s to be ready", fqdn)
+
+	err := wait.For(msg, d.config.StatusTimeout, d.config.StatusInterval, func() (bool, error) {
+		status, err := d.client.GetStatus(ctx, statusURL)
+		if err != nil {
+			return false, err
+		}
+
+		switch status.Status {
+		case internal.StatusReady:
+			return true, nil
+
+		case internal.StatusFailed:
+			failure = fmt.Errorf("change failed: %s", status.Message)
+			return true, nil
+
+		default:
+			return false, fmt.Errorf("status %q", status.Status)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return failure
+}
@@ -0,0 +1,310 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-acme/lego/v4/providers/dns/webhook/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envDomain = envNamespace + "DOMAIN"
+
+var envTest = tester.NewEnvTest(EnvURL, EnvSecret).WithDomain(envDomain)
+
+func TestNewDNSProvider(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		envVars  map[string]string
+		expected string
+	}{
+		{
+			desc: "success",
+			envVars: map[string]string{
+				EnvURL: "https://example.com/hook",
+			},
+		},
+		{
+			desc: "success with secret",
+			envVars: map[string]string{
+				EnvURL:    "https://example.com/hook",
+				EnvSecret: "secret",
+			},
+		},
+		{
+			desc: "invalid URL",
+			envVars: map[string]string{
+				EnvURL: ":",
+			},
+			expected: `webhook: parse ":": missing protocol scheme`,
+		},
+		{
+			desc: "missing URL",
+			envVars: map[string]string{
+				EnvURL: "",
+			},
+			expected: "webhook: some credentials information are missing: WEBHOOK_URL",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			defer envTest.RestoreEnv()
+			envTest.ClearEnv()
+
+			envTest.Apply(test.envVars)
+
+			p, err := NewDNSProvider()
+
+			if test.expected == "" {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+				require.NotNil(t, p.client)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewDNSProviderConfig(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		endpoint string
+		expected string
+	}{
+		{
+			desc:     "success",
+			endpoint: "http://localhost:8090/hook",
+		},
+		{
+			desc:     "missing URL",
+			expected: "webhook: the URL is missing",
+		},
+		{
+			desc:     "invalid scheme",
+			endpoint: "ftp://localhost/hook",
+			expected: `webhook: invalid URL "ftp://localhost/hook": the scheme must be http or https`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			config := NewDefaultConfig()
+
+			if test.endpoint != "" {
+				endpoint, err := url.Parse(test.endpoint)
+				require.NoError(t, err)
+
+				config.URL = endpoint
+			}
+
+			p, err := NewDNSProviderConfig(config)
+
+			if test.expected == "" {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+				require.NotNil(t, p.client)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_Present(t *testing.T) {
+	hook := &fakeWebhook{secret: "secret", pending: 2}
+
+	provider := setupTest(t, hook)
+
+	err := provider.Present("example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	require.Len(t, hook.events, 1)
+
+	event := hook.events[0]
+	assert.Equal(t, internal.ActionPresent, event.Action)
+	assert.Equal(t, "example.com", event.Domain)
+	assert.Equal(t, "_acme-challenge.example.com.", event.FQDN)
+	assert.Equal(t, "ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY", event.Value)
+	assert.Equal(t, "token", event.Token)
+	assert.Equal(t, "123d==", event.KeyAuth)
+	assert.NotZero(t, event.Timestamp)
+
+	assert.Equal(t, 3, hook.statusCalls)
+}
+
+func TestDNSProvider_Present_failedStatus(t *testing.T) {
+	hook := &fakeWebhook{secret: "secret", failed: true}
+
+	provider := setupTest(t, hook)
+
+	err := provider.Present("example.com", "token", "123d==")
+	require.EqualError(t, err, "webhook: present: change failed: zone locked")
+}
+
+func TestDNSProvider_Present_invalidSignature(t *testing.T) {
+	hook := &fakeWebhook{secret: "other"}
+
+	provider := setupTest(t, hook)
+
+	err := provider.Present("example.com", "token", "123d==")
+	require.EqualError(t, err, "webhook: present: unexpected status code: 401: invalid signature\n")
+}
+
+func TestDNSProvider_CleanUp(t *testing.T) {
+	hook := &fakeWebhook{secret: "secret", noStatus: true}
+
+	provider := setupTest(t, hook)
+
+	err := provider.CleanUp("example.com", "token", "123d==")
+	require.NoError(t, err)
+
+	require.Len(t, hook.events, 1)
+	assert.Equal(t, internal.ActionCleanUp, hook.events[0].Action)
+	assert.Equal(t, 0, hook.statusCalls)
+}
+
+func TestLivePresent(t *testing.T) {
+	if !envTest.IsLiveTest() {
+		t.Skip("skipping live test")
+	}
+
+	envTest.RestoreEnv()
+	provider, err := NewDNSProvider()
+	require.NoError(t, err)
+
+	err = provider.Present(envTest.GetDomain(), "", "123d==")
+	require.NoError(t, err)
+}
+
+func TestLiveCleanUp(t *testing.T) {
+	if !envTest.IsLiveTest() {
+		t.Skip("skipping live test")
+	}
+
+	envTest.RestoreEnv()
+	provider, err := NewDNSProvider()
+	require.NoError(t, err)
+
+	err = provider.CleanUp(envTest.GetDomain(), "", "123d==")
+	require.NoError(t, err)
+}
+
+func setupTest(t *testing.T, hook *fakeWebhook) *DNSProvider {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/hook", hook.handleEvent)
+	mux.HandleFunc("/status/1", hook.handleStatus)
+
+	endpoint, err := url.Parse(server.URL + "/hook")
+	require.NoError(t, err)
+
+	config := NewDefaultConfig()
+	config.URL = endpoint
+	config.Secret = "secret"
+	config.StatusTimeout = 5 * time.Second
+	config.StatusInterval = 10 * time.Millisecond
+	config.HTTPClient = server.Client()
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	return provider
+}
+
+// fakeWebhook a webhook verifying the signature of the requests.
+type fakeWebhook struct {
+	mu sync.Mutex
+
+	secret string
+
+	// noStatus the webhook doesn't return a status URL.
+	noStatus bool
+	// pending the number of status calls returning a pending status.
+	pending int
+	// failed the status reports a failure.
+	failed bool
+
+	events      []internal.Event
+	statusCalls int
+}
+
+func (f *fakeWebhook) handleEvent(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(rw, "unsupported method: "+req.Method, http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if req.Header.Get(internal.SignatureHeader) != "sha256="+internal.Sign(f.secret, body) {
+		http.Error(rw, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var event internal.Event
+	err = json.Unmarshal(body, &event)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	f.events = append(f.events, event)
+	f.mu.Unlock()
+
+	if f.noStatus {
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	rw.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(rw).Encode(internal.EventResponse{StatusURL: "/status/1"})
+}
+
+func (f *fakeWebhook) handleStatus(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(rw, "unsupported method: "+req.Method, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if req.Header.Get(internal.SignatureHeader) != "sha256="+internal.Sign(f.secret, nil) {
+		http.Error(rw, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	f.mu.Lock()
+	f.statusCalls++
+	calls := f.statusCalls
+	f.mu.Unlock()
+
+	status := internal.Status{Status: internal.StatusReady}
+
+	switch {
+	case f.failed:
+		status = internal.Status{Status: internal.StatusFailed, Message: "zone locked"}
+	case calls <= f.pending:
+		status = internal.Status{Status: internal.StatusPending}
+	}
+
+	_ = json.NewEncoder(rw).Encode(status)
+}
@@ -0,0 +1,42 @@
+// Package callback implements a HTTP provider for solving the HTTP-01 challenge by calling functions,
+// e.g. to serve the challenges from the routes of a running web server, without another server or a webroot.
+package callback
+
+import (
+	"errors"
+)
+
+// Func is called with the domain, the token, and the key authorization of a challenge.
+// To solve the challenge, the response of the web server of the domain to `http01.ChallengePath(token)`
+// must be the key authorization.
+type Func func(domain, token, keyAuth string) error
+
+// HTTPProvider implements ChallengeProvider for `http-01` challenge.
+type HTTPProvider struct {
+	present Func
+	cleanUp Func
+}
+
+// NewHTTPProvider returns a HTTPProvider instance calling the functions to present and to clean up the challenges.
+// The clean up function is optional.
+func NewHTTPProvider(present, cleanUp Func) (*HTTPProvider, error) {
+	if present == nil {
+		return nil, errors.New("the present function is required")
+	}
+
+	return &HTTPProvider{present: present, cleanUp: cleanUp}, nil
+}
+
+// Present calls the present function: the key authorization must be served at `http01.ChallengePath(token)`.
+func (p *HTTPProvider) Present(domain, token, keyAuth string) error {
+	return p.present(domain, token, keyAuth)
+}
+
+// CleanUp calls the clean up function, if any.
+func (p *HTTPProvider) CleanUp(domain, token, keyAuth string) error {
+	if p.cleanUp == nil {
+		return nil
+	}
+
+	return p.cleanUp(domain, token, keyAuth)
+}
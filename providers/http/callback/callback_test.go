@@ -0,0 +1,105 @@
+package callback
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-acme/lego/v4/challenge/http01"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPProvider(t *testing.T) {
+	// the routes of the application.
+	var mu sync.Mutex
+	routes := map[string]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		keyAuth, ok := routes[req.URL.Path]
+		if !ok {
+			http.NotFound(rw, req)
+			return
+		}
+
+		_, _ = rw.Write([]byte(keyAuth))
+	}))
+	t.Cleanup(server.Close)
+
+	var calls []string
+
+	present := func(domain, token, keyAuth string) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls = append(calls, "present "+domain+" "+token+" "+keyAuth)
+		routes[http01.ChallengePath(token)] = keyAuth
+
+		return nil
+	}
+
+	cleanUp := func(domain, token, keyAuth string) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls = append(calls, "cleanup "+domain+" "+token+" "+keyAuth)
+		delete(routes, http01.ChallengePath(token))
+
+		return nil
+	}
+
+	provider, err := NewHTTPProvider(present, cleanUp)
+	require.NoError(t, err)
+
+	err = provider.Present("example.com", "token", "token.thumbprint")
+	require.NoError(t, err)
+
+	assert.Equal(t, "token.thumbprint", get(t, server.URL+http01.ChallengePath("token")))
+
+	err = provider.CleanUp("example.com", "token", "token.thumbprint")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"present example.com token token.thumbprint",
+		"cleanup example.com token token.thumbprint",
+	}, calls)
+
+	assert.Empty(t, routes)
+}
+
+func TestHTTPProvider_errors(t *testing.T) {
+	_, err := NewHTTPProvider(nil, nil)
+	require.EqualError(t, err, "the present function is required")
+
+	provider, err := NewHTTPProvider(func(_, _, _ string) error { return errors.New("route conflict") }, nil)
+	require.NoError(t, err)
+
+	err = provider.Present("example.com", "token", "token.thumbprint")
+	require.EqualError(t, err, "route conflict")
+
+	// without clean up function.
+	err = provider.CleanUp("example.com", "token", "token.thumbprint")
+	require.NoError(t, err)
+}
+
+func get(t *testing.T, uri string) string {
+	t.Helper()
+
+	resp, err := http.Get(uri)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	return string(body)
+}
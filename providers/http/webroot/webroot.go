@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 
 	"github.com/go-acme/lego/v4/challenge/http01"
+	"github.com/go-acme/lego/v4/internal/atomicfile"
 )
 
 // HTTPProvider implements ChallengeProvider for `http-01` challenge.
@@ -25,6 +26,7 @@ func NewHTTPProvider(path string) (*HTTPProvider, error) {
 }
 
 // Present makes the token available at `HTTP01ChallengePath(token)` by creating a file in the given webroot path.
+// The file is written atomically (a temporary file is renamed), to avoid the web server serving a partial file.
 func (w *HTTPProvider) Present(domain, token, keyAuth string) error {
 	var err error
 
@@ -34,7 +36,7 @@ func (w *HTTPProvider) Present(domain, token, keyAuth string) error {
 		return fmt.Errorf("could not create required directories in webroot for HTTP challenge: %w", err)
 	}
 
-	err = os.WriteFile(challengeFilePath, []byte(keyAuth), 0o644)
+	err = atomicfile.WriteFile(challengeFilePath, []byte(keyAuth))
 	if err != nil {
 		return fmt.Errorf("could not write file in webroot for HTTP challenge: %w", err)
 	}
@@ -43,9 +45,10 @@ func (w *HTTPProvider) Present(domain, token, keyAuth string) error {
 }
 
 // CleanUp removes the file created for the challenge.
+// A file already removed is not an error.
 func (w *HTTPProvider) CleanUp(domain, token, keyAuth string) error {
 	err := os.Remove(filepath.Join(w.path, http01.ChallengePath(token)))
-	if err != nil {
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
 		return fmt.Errorf("could not remove file in webroot after HTTP challenge: %w", err)
 	}
 
@@ -2,6 +2,8 @@ package webroot
 
 import (
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -38,3 +40,70 @@ func TestHTTPProvider(t *testing.T) {
 	err = provider.CleanUp(domain, token, keyAuth)
 	require.NoError(t, err)
 }
+
+func TestHTTPProvider_Present_atomic(t *testing.T) {
+	webroot := t.TempDir()
+	challengeDir := filepath.Join(webroot, ".well-known", "acme-challenge")
+
+	provider, err := NewHTTPProvider(webroot)
+	require.NoError(t, err)
+
+	// a previous content is replaced.
+	err = provider.Present("domain", "token", "previous")
+	require.NoError(t, err)
+
+	err = provider.Present("domain", "token", "keyAuth")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(challengeDir, "token"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "keyAuth", string(data))
+
+	entries, err := os.ReadDir(challengeDir)
+	require.NoError(t, err)
+
+	// no temporary file left.
+	require.Len(t, entries, 1)
+	assert.Equal(t, "token", entries[0].Name())
+
+	if runtime.GOOS != "windows" {
+		info, errS := os.Stat(filepath.Join(challengeDir, "token"))
+		require.NoError(t, errS)
+
+		assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+	}
+
+	err = provider.CleanUp("domain", "token", "keyAuth")
+	require.NoError(t, err)
+
+	entries, err = os.ReadDir(challengeDir)
+	require.NoError(t, err)
+
+	assert.Empty(t, entries)
+
+	// the file is already removed.
+	err = provider.CleanUp("domain", "token", "keyAuth")
+	require.NoError(t, err)
+}
+
+func TestHTTPProvider_Present_error(t *testing.T) {
+	webroot := t.TempDir()
+	challengeDir := filepath.Join(webroot, ".well-known", "acme-challenge")
+
+	// the challenge file cannot replace a directory.
+	require.NoError(t, os.MkdirAll(filepath.Join(challengeDir, "token", "sub"), 0o755))
+
+	provider, err := NewHTTPProvider(webroot)
+	require.NoError(t, err)
+
+	err = provider.Present("domain", "token", "keyAuth")
+	require.Error(t, err)
+
+	entries, err := os.ReadDir(challengeDir)
+	require.NoError(t, err)
+
+	// the temporary file is removed.
+	require.Len(t, entries, 1)
+	assert.Equal(t, "token", entries[0].Name())
+}
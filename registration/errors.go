@@ -0,0 +1,21 @@
+package registration
+
+import (
+	"fmt"
+
+	"github.com/go-acme/lego/v4/acme"
+)
+
+// EABUpdateError is returned when the ACME server rejects the update of the External Account Binding of an account.
+type EABUpdateError struct {
+	Kid string
+	Err *acme.ProblemDetails
+}
+
+func (e *EABUpdateError) Error() string {
+	return fmt.Sprintf("acme: the server rejected the external account binding (kid: %s): %v", e.Kid, e.Err)
+}
+
+func (e *EABUpdateError) Unwrap() error {
+	return e.Err
+}
@@ -1,8 +1,12 @@
 package registration
 
 import (
+	"crypto"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/mail"
+	"strings"
 
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/acme/api"
@@ -40,11 +44,17 @@ func NewRegistrar(core *api.Core, user User) *Registrar {
 }
 
 // Register the current account to the ACME server.
+// If the ACME server requires an External Account Binding (directory meta `externalAccountRequired`),
+// the account is not registered: RegisterWithExternalAccountBinding must be used.
 func (r *Registrar) Register(options RegisterOptions) (*Resource, error) {
 	if r == nil || r.user == nil {
 		return nil, errors.New("acme: cannot register a nil client or user")
 	}
 
+	if r.core.GetDirectory().Meta.ExternalAccountRequired {
+		return nil, errors.New("acme: the ACME server requires an External Account Binding (EAB): a key identifier and an HMAC key must be provided")
+	}
+
 	accMsg := acme.Account{
 		TermsOfServiceAgreed: options.TermsOfServiceAgreed,
 		Contact:              []string{},
@@ -69,6 +79,14 @@ func (r *Registrar) Register(options RegisterOptions) (*Resource, error) {
 
 // RegisterWithExternalAccountBinding Register the current account to the ACME server.
 func (r *Registrar) RegisterWithExternalAccountBinding(options RegisterEABOptions) (*Resource, error) {
+	if r == nil || r.user == nil {
+		return nil, errors.New("acme: cannot register a nil client or user")
+	}
+
+	if options.Kid == "" || options.HmacEncoded == "" {
+		return nil, errors.New("acme: the External Account Binding (EAB) requires a key identifier and an HMAC key")
+	}
+
 	accMsg := acme.Account{
 		TermsOfServiceAgreed: options.TermsOfServiceAgreed,
 		Contact:              []string{},
@@ -141,6 +159,66 @@ func (r *Registrar) UpdateRegistration(options RegisterOptions) (*Resource, erro
 	return &Resource{URI: accountURL, Body: account}, nil
 }
 
+// UpdateAccount replaces the contacts of the existing account on the ACME server,
+// and returns the updated registration.
+//
+// The contacts must be "mailto:" URLs (e.g. "mailto:admin@example.com"), at least one contact is required.
+func (r *Registrar) UpdateAccount(contacts []string) (*Resource, error) {
+	if r == nil || r.user == nil || r.user.GetRegistration() == nil {
+		return nil, errors.New("acme: cannot update the account of a nil client or user")
+	}
+
+	err := validateContacts(contacts)
+	if err != nil {
+		return nil, fmt.Errorf("acme: %w", err)
+	}
+
+	accountURL := r.user.GetRegistration().URI
+
+	log.Infof("acme: Updating contacts of account %s", accountURL)
+
+	account, err := r.core.Accounts.Update(accountURL, acme.Account{Contact: contacts})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Resource{URI: accountURL, Body: account}, nil
+}
+
+// UpdateExternalAccountBinding binds the existing account to new External Account Binding credentials.
+//
+// The ACME server may reject the update (e.g. the CA does not support the rebinding of an account, or the credentials are invalid):
+// in this case, an *EABUpdateError wrapping the problem details returned by the server is returned.
+func (r *Registrar) UpdateExternalAccountBinding(options RegisterEABOptions) (*Resource, error) {
+	if r == nil || r.user == nil || r.user.GetRegistration() == nil {
+		return nil, errors.New("acme: cannot update the external account binding of a nil client or user")
+	}
+
+	accMsg := acme.Account{
+		TermsOfServiceAgreed: options.TermsOfServiceAgreed,
+		Contact:              []string{},
+	}
+
+	if r.user.GetEmail() != "" {
+		log.Infof("acme: Updating external account binding for %s", r.user.GetEmail())
+		accMsg.Contact = []string{"mailto:" + r.user.GetEmail()}
+	}
+
+	accountURL := r.user.GetRegistration().URI
+
+	account, err := r.core.Accounts.UpdateEAB(accountURL, accMsg, options.Kid, options.HmacEncoded)
+	if err != nil {
+		var problem *acme.ProblemDetails
+		if errors.As(err, &problem) {
+			return nil, &EABUpdateError{Kid: options.Kid, Err: problem}
+		}
+
+		return nil, err
+	}
+
+	return &Resource{URI: accountURL, Body: account}, nil
+}
+
 // DeleteRegistration deletes the client's user registration from the ACME server.
 func (r *Registrar) DeleteRegistration() error {
 	if r == nil || r.user == nil {
@@ -152,6 +230,30 @@ func (r *Registrar) DeleteRegistration() error {
 	return r.core.Accounts.Deactivate(r.user.GetRegistration().URI)
 }
 
+// RolloverAccountKey changes the private key of the account, without changing the account and its authorizations.
+// On success, the new key is used by the client to sign the following requests,
+// and it's given to the user if the user implements UserKeyUpdater:
+// the new key must be stored in place of the old one.
+// If the new key is already used by another account, the error is an *acme.KeyInUseError.
+func (r *Registrar) RolloverAccountKey(newKey crypto.Signer) error {
+	if r == nil || r.user == nil || r.user.GetRegistration() == nil {
+		return errors.New("acme: cannot rollover the key of a nil client or user")
+	}
+
+	log.Infof("acme: Changing the key of the account %s", r.user.GetRegistration().URI)
+
+	err := r.core.Accounts.KeyChange(newKey)
+	if err != nil {
+		return err
+	}
+
+	if u, ok := r.user.(UserKeyUpdater); ok {
+		u.SetPrivateKey(newKey)
+	}
+
+	return nil
+}
+
 // ResolveAccountByKey will attempt to look up an account using the given account key
 // and return its registration resource.
 func (r *Registrar) ResolveAccountByKey() (*Resource, error) {
@@ -165,3 +267,25 @@ func (r *Registrar) ResolveAccountByKey() (*Resource, error) {
 
 	return &Resource{URI: account.Location, Body: account.Account}, nil
 }
+
+// validateContacts checks that the contacts are well-formed "mailto:" URLs.
+func validateContacts(contacts []string) error {
+	if len(contacts) == 0 {
+		return errors.New("no contacts")
+	}
+
+	for _, contact := range contacts {
+		if !strings.HasPrefix(contact, "mailto:") {
+			return fmt.Errorf("invalid contact %q: only mailto: contacts are supported", contact)
+		}
+
+		email := strings.TrimPrefix(contact, "mailto:")
+
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Name != "" || addr.Address != email {
+			return fmt.Errorf("invalid contact %q: malformed email address", contact)
+		}
+	}
+
+	return nil
+}
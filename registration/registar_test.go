@@ -1,14 +1,23 @@
 package registration
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/acme/api"
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-jose/go-jose/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -46,3 +55,468 @@ func TestRegistrar_ResolveAccountByKey(t *testing.T) {
 
 	assert.Equal(t, "valid", res.Body.Status, "Unexpected account status")
 }
+
+func TestRegistrar_Register_externalAccountRequired(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/dir", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Directory{
+			NewNonceURL:   server.URL + "/nonce",
+			NewAccountURL: server.URL + "/account",
+			NewOrderURL:   server.URL + "/newOrder",
+			Meta:          acme.Meta{ExternalAccountRequired: true},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	var registered bool
+
+	mux.HandleFunc("/account", func(w http.ResponseWriter, _ *http.Request) {
+		registered = true
+
+		http.Error(w, "unexpected account request", http.StatusBadRequest)
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", server.URL+"/dir", "", key)
+	require.NoError(t, err)
+
+	registrar := NewRegistrar(core, mockUser{email: "test@test.com", privatekey: key})
+
+	_, err = registrar.Register(RegisterOptions{TermsOfServiceAgreed: true})
+	require.EqualError(t, err, "acme: the ACME server requires an External Account Binding (EAB): a key identifier and an HMAC key must be provided")
+
+	_, err = registrar.RegisterWithExternalAccountBinding(RegisterEABOptions{TermsOfServiceAgreed: true, Kid: "kid"})
+	require.EqualError(t, err, "acme: the External Account Binding (EAB) requires a key identifier and an HMAC key")
+
+	assert.False(t, registered, "the account must not be registered")
+}
+
+func TestRegistrar_UpdateExternalAccountBinding(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	hmacKey := []byte("0123456789abcdef0123456789abcdef")
+
+	mux.HandleFunc("/account", func(w http.ResponseWriter, r *http.Request) {
+		body, err := readSignedBody(r, key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var account acme.Account
+		err = json.Unmarshal(body, &account)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		eab, err := jose.ParseSigned(string(account.ExternalAccountBinding))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		payload, err := eab.Verify(hmacKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		header := eab.Signatures[0].Protected
+		if header.KeyID != "kid-2" || header.ExtraHeaders["url"] != apiURL+"/account" {
+			http.Error(w, "invalid EAB protected header", http.StatusBadRequest)
+			return
+		}
+
+		var jwk jose.JSONWebKey
+		err = json.Unmarshal(payload, &jwk)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		pub, ok := jwk.Key.(*rsa.PublicKey)
+		if !ok || !pub.Equal(key.Public()) {
+			http.Error(w, "EAB payload is not the account key", http.StatusBadRequest)
+			return
+		}
+
+		err = tester.WriteJSONResponse(w, acme.Account{
+			Status:                 "valid",
+			Contact:                account.Contact,
+			ExternalAccountBinding: account.ExternalAccountBinding,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	user := mockUser{
+		email:      "test@test.com",
+		regres:     &Resource{URI: apiURL + "/account"},
+		privatekey: key,
+	}
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", apiURL+"/account", key)
+	require.NoError(t, err)
+
+	registrar := NewRegistrar(core, user)
+
+	res, err := registrar.UpdateExternalAccountBinding(RegisterEABOptions{
+		TermsOfServiceAgreed: true,
+		Kid:                  "kid-2",
+		HmacEncoded:          base64.RawURLEncoding.EncodeToString(hmacKey),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, apiURL+"/account", res.URI)
+	assert.Equal(t, "valid", res.Body.Status)
+	assert.Equal(t, []string{"mailto:test@test.com"}, res.Body.Contact)
+}
+
+func TestRegistrar_UpdateExternalAccountBinding_rejected(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	mux.HandleFunc("/account", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(acme.ProblemDetails{
+			Type:   "urn:ietf:params:acme:error:unauthorized",
+			Detail: "account rebinding is not supported",
+		})
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	user := mockUser{
+		email:      "test@test.com",
+		regres:     &Resource{URI: apiURL + "/account"},
+		privatekey: key,
+	}
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", apiURL+"/account", key)
+	require.NoError(t, err)
+
+	registrar := NewRegistrar(core, user)
+
+	_, err = registrar.UpdateExternalAccountBinding(RegisterEABOptions{
+		TermsOfServiceAgreed: true,
+		Kid:                  "kid-2",
+		HmacEncoded:          base64.RawURLEncoding.EncodeToString([]byte("secret")),
+	})
+	require.Error(t, err)
+
+	var eabErr *EABUpdateError
+	require.True(t, errors.As(err, &eabErr))
+	assert.Equal(t, "kid-2", eabErr.Kid)
+	assert.Equal(t, "urn:ietf:params:acme:error:unauthorized", eabErr.Err.Type)
+
+	var problem *acme.ProblemDetails
+	assert.True(t, errors.As(err, &problem))
+}
+
+func TestRegistrar_UpdateAccount(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	mux.HandleFunc("/account", func(w http.ResponseWriter, r *http.Request) {
+		body, err := readSignedBody(r, key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var account acme.Account
+		err = json.Unmarshal(body, &account)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = tester.WriteJSONResponse(w, acme.Account{
+			Status:  "valid",
+			Contact: account.Contact,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	user := mockUser{
+		email:      "test@test.com",
+		regres:     &Resource{URI: apiURL + "/account"},
+		privatekey: key,
+	}
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", apiURL+"/account", key)
+	require.NoError(t, err)
+
+	registrar := NewRegistrar(core, user)
+
+	contacts := []string{"mailto:admin@example.com", "mailto:security@example.com"}
+
+	res, err := registrar.UpdateAccount(contacts)
+	require.NoError(t, err)
+
+	assert.Equal(t, apiURL+"/account", res.URI)
+	assert.Equal(t, "valid", res.Body.Status)
+	assert.Equal(t, contacts, res.Body.Contact)
+}
+
+func TestRegistrar_UpdateAccount_invalidContacts(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		contacts []string
+		expected string
+	}{
+		{
+			desc:     "no contacts",
+			expected: "acme: no contacts",
+		},
+		{
+			desc:     "missing mailto",
+			contacts: []string{"admin@example.com"},
+			expected: `acme: invalid contact "admin@example.com": only mailto: contacts are supported`,
+		},
+		{
+			desc:     "other scheme",
+			contacts: []string{"mailto:admin@example.com", "tel:+33123456789"},
+			expected: `acme: invalid contact "tel:+33123456789": only mailto: contacts are supported`,
+		},
+		{
+			desc:     "malformed email",
+			contacts: []string{"mailto:admin"},
+			expected: `acme: invalid contact "mailto:admin": malformed email address`,
+		},
+		{
+			desc:     "email with a name",
+			contacts: []string{"mailto:Admin <admin@example.com>"},
+			expected: `acme: invalid contact "mailto:Admin <admin@example.com>": malformed email address`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			user := mockUser{
+				email:  "test@test.com",
+				regres: &Resource{URI: "https://example.com/account"},
+			}
+
+			registrar := NewRegistrar(nil, user)
+
+			_, err := registrar.UpdateAccount(test.contacts)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestRegistrar_RolloverAccountKey(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err, "Could not generate test key")
+
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "Could not generate test key")
+
+	accountURL := apiURL + "/account"
+
+	mux.HandleFunc("/keyChange", func(w http.ResponseWriter, r *http.Request) {
+		outer, err := readSignedJWS(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// the outer JWS is signed by the old key, and identifies the account.
+		outerHeader := outer.Signatures[0].Protected
+		if outerHeader.KeyID != accountURL || outerHeader.Nonce == "" || outerHeader.ExtraHeaders["url"] != apiURL+"/keyChange" {
+			http.Error(w, "invalid outer protected header", http.StatusBadRequest)
+			return
+		}
+
+		outerPayload, err := outer.Verify(oldKey.Public())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		// the inner JWS is signed by the new key, embeds the new key, and has no nonce.
+		inner, err := jose.ParseSigned(string(outerPayload))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		innerHeader := inner.Signatures[0].Protected
+		if innerHeader.JSONWebKey == nil || innerHeader.KeyID != "" || innerHeader.Nonce != "" || innerHeader.ExtraHeaders["url"] != apiURL+"/keyChange" {
+			http.Error(w, "invalid inner protected header", http.StatusBadRequest)
+			return
+		}
+
+		jwk, ok := innerHeader.JSONWebKey.Key.(*ecdsa.PublicKey)
+		if !ok || !jwk.Equal(newKey.Public()) {
+			http.Error(w, "the inner JWK is not the new key", http.StatusBadRequest)
+			return
+		}
+
+		innerPayload, err := inner.Verify(newKey.Public())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var keyChange struct {
+			Account string          `json:"account"`
+			OldKey  jose.JSONWebKey `json:"oldKey"`
+		}
+
+		err = json.Unmarshal(innerPayload, &keyChange)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		pub, ok := keyChange.OldKey.Key.(*rsa.PublicKey)
+		if keyChange.Account != accountURL || !ok || !pub.Equal(oldKey.Public()) {
+			http.Error(w, "invalid key change payload", http.StatusBadRequest)
+			return
+		}
+	})
+
+	// the requests following the key change are signed by the new key.
+	mux.HandleFunc("/account", func(w http.ResponseWriter, r *http.Request) {
+		jws, err := readSignedJWS(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		_, err = jws.Verify(newKey.Public())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		err = tester.WriteJSONResponse(w, acme.Account{Status: "valid"})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	user := &keyUpdaterUser{mockUser: mockUser{
+		email:      "test@test.com",
+		regres:     &Resource{URI: accountURL},
+		privatekey: oldKey,
+	}}
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", accountURL, oldKey)
+	require.NoError(t, err)
+
+	registrar := NewRegistrar(core, user)
+
+	err = registrar.RolloverAccountKey(newKey)
+	require.NoError(t, err)
+
+	assert.Equal(t, newKey, user.newKey)
+
+	res, err := registrar.QueryRegistration()
+	require.NoError(t, err)
+
+	assert.Equal(t, "valid", res.Body.Status)
+}
+
+func TestRegistrar_RolloverAccountKey_keyInUse(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	mux.HandleFunc("/keyChange", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", apiURL+"/account/other")
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusConflict)
+
+		_, _ = w.Write([]byte(`{"type":"urn:ietf:params:acme:error:malformed","detail":"New key is already in use for a different account"}`))
+	})
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err, "Could not generate test key")
+
+	newKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err, "Could not generate test key")
+
+	user := &keyUpdaterUser{mockUser: mockUser{
+		email:      "test@test.com",
+		regres:     &Resource{URI: apiURL + "/account"},
+		privatekey: oldKey,
+	}}
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", apiURL+"/account", oldKey)
+	require.NoError(t, err)
+
+	registrar := NewRegistrar(core, user)
+
+	err = registrar.RolloverAccountKey(newKey)
+	require.Error(t, err)
+
+	var keyInUseErr *acme.KeyInUseError
+	require.True(t, errors.As(err, &keyInUseErr))
+
+	assert.Equal(t, apiURL+"/account/other", keyInUseErr.AccountURL)
+	assert.Nil(t, user.newKey)
+}
+
+// keyUpdaterUser is a user storing the new key of a key rollover.
+type keyUpdaterUser struct {
+	mockUser
+
+	newKey crypto.PrivateKey
+}
+
+func (u *keyUpdaterUser) SetPrivateKey(key crypto.PrivateKey) { u.newKey = key }
+
+func readSignedJWS(r *http.Request) (*jose.JSONWebSignature, error) {
+	reqBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return jose.ParseSigned(string(reqBody))
+}
+
+func readSignedBody(r *http.Request, privateKey *rsa.PrivateKey) ([]byte, error) {
+	reqBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	jws, err := jose.ParseSigned(string(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	return jws.Verify(&jose.JSONWebKey{
+		Key:       privateKey.Public(),
+		Algorithm: "RSA",
+	})
+}
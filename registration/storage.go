@@ -0,0 +1,137 @@
+package registration
+
+import (
+	"crypto"
+	"errors"
+	"sync"
+)
+
+// ErrAccountNotFound is returned by a Storage when there is no account for an email.
+var ErrAccountNotFound = errors.New("account not found")
+
+// Storage is used to persist the ACME accounts (the private key and the registration).
+type Storage interface {
+	// Save stores the account of a user, an existing account with the same email is replaced.
+	Save(user User) error
+
+	// Load returns the account associated to an email.
+	// It returns ErrAccountNotFound if there is no account for this email.
+	Load(email string) (User, error)
+}
+
+// MemoryStorage is an in-memory implementation of Storage.
+// The accounts are only kept during the lifetime of the process: nothing is written to the filesystem.
+type MemoryStorage struct {
+	mu       sync.RWMutex
+	accounts map[string]*account
+}
+
+// NewMemoryStorage creates a new MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{accounts: map[string]*account{}}
+}
+
+// Save stores a copy of the account of a user.
+func (s *MemoryStorage) Save(user User) error {
+	if user == nil {
+		return errors.New("user is nil")
+	}
+
+	if user.GetPrivateKey() == nil {
+		return errors.New("private key is nil")
+	}
+
+	acc := &account{
+		email: user.GetEmail(),
+		key:   user.GetPrivateKey(),
+	}
+
+	if reg := user.GetRegistration(); reg != nil {
+		r := *reg
+		r.Body.Contact = append([]string(nil), reg.Body.Contact...)
+		acc.registration = &r
+	}
+
+	s.mu.Lock()
+	s.accounts[acc.email] = acc
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Load returns the account associated to an email.
+func (s *MemoryStorage) Load(email string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	acc, ok := s.accounts[email]
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+
+	return acc, nil
+}
+
+// account is the User returned by MemoryStorage.
+type account struct {
+	email        string
+	registration *Resource
+	key          crypto.PrivateKey
+}
+
+func (a *account) GetEmail() string                 { return a.email }
+func (a *account) GetRegistration() *Resource       { return a.registration }
+func (a *account) GetPrivateKey() crypto.PrivateKey { return a.key }
+
+// AccountLoader loads the credentials of the account of an email, e.g. from the environment or from a secret manager.
+// It returns the private key of the account, and the URL of the registration (empty if the account is not registered yet).
+// It returns ErrAccountNotFound if there is no account for this email.
+type AccountLoader func(email string) (key crypto.PrivateKey, registrationURL string, err error)
+
+// LoaderStorage is a Storage reading the accounts with an AccountLoader instead of the filesystem.
+// The saved accounts (e.g. after a registration) are only kept in memory,
+// and take precedence over the accounts provided by the loader.
+type LoaderStorage struct {
+	loader AccountLoader
+	saved  *MemoryStorage
+}
+
+// NewLoaderStorage creates a new LoaderStorage.
+func NewLoaderStorage(loader AccountLoader) *LoaderStorage {
+	return &LoaderStorage{loader: loader, saved: NewMemoryStorage()}
+}
+
+// Save stores a copy of the account of a user in memory.
+func (s *LoaderStorage) Save(user User) error {
+	return s.saved.Save(user)
+}
+
+// Load returns the account associated to an email:
+// the saved account, or the account provided by the loader.
+func (s *LoaderStorage) Load(email string) (User, error) {
+	user, err := s.saved.Load(email)
+	if err == nil {
+		return user, nil
+	}
+
+	if s.loader == nil {
+		return nil, ErrAccountNotFound
+	}
+
+	key, registrationURL, err := s.loader(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil {
+		return nil, errors.New("private key is nil")
+	}
+
+	acc := &account{email: email, key: key}
+
+	if registrationURL != "" {
+		acc.registration = &Resource{URI: registrationURL}
+	}
+
+	return acc, nil
+}
@@ -0,0 +1,180 @@
+package registration
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	mux.HandleFunc("/account", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", apiURL+"/account/1")
+		err := tester.WriteJSONResponse(w, acme.Account{
+			Status:  "valid",
+			Contact: []string{"mailto:test@test.com"},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	mux.HandleFunc("/account/1", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Account{
+			Status:  "valid",
+			Contact: []string{"mailto:test@test.com"},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	storage := NewMemoryStorage()
+
+	_, err = storage.Load("test@test.com")
+	require.ErrorIs(t, err, ErrAccountNotFound)
+
+	// register
+
+	user := &mockUser{
+		email:      "test@test.com",
+		privatekey: key,
+	}
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	reg, err := NewRegistrar(core, user).Register(RegisterOptions{TermsOfServiceAgreed: true})
+	require.NoError(t, err)
+
+	user.regres = reg
+
+	// persist
+
+	err = storage.Save(user)
+	require.NoError(t, err)
+
+	// the stored account must not be modified by the changes of the user.
+	user.regres.Body.Contact[0] = "mailto:other@test.com"
+
+	// reload
+
+	loaded, err := storage.Load("test@test.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, "test@test.com", loaded.GetEmail())
+	assert.Equal(t, key, loaded.GetPrivateKey())
+	require.NotNil(t, loaded.GetRegistration())
+	assert.Equal(t, apiURL+"/account/1", loaded.GetRegistration().URI)
+	assert.Equal(t, []string{"mailto:test@test.com"}, loaded.GetRegistration().Body.Contact)
+
+	res, err := NewRegistrar(core, loaded).QueryRegistration()
+	require.NoError(t, err)
+
+	assert.Equal(t, "valid", res.Body.Status)
+}
+
+func TestMemoryStorage_Save_nil(t *testing.T) {
+	storage := NewMemoryStorage()
+
+	err := storage.Save(nil)
+	require.EqualError(t, err, "user is nil")
+}
+
+func TestLoaderStorage(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	mux.HandleFunc("/account/1", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Account{
+			Status:  "valid",
+			Contact: []string{"mailto:test@test.com"},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	var calls int
+
+	storage := NewLoaderStorage(func(email string) (crypto.PrivateKey, string, error) {
+		calls++
+
+		if email != "test@test.com" {
+			return nil, "", ErrAccountNotFound
+		}
+
+		return key, apiURL + "/account/1", nil
+	})
+
+	_, err = storage.Load("other@test.com")
+	require.ErrorIs(t, err, ErrAccountNotFound)
+
+	loaded, err := storage.Load("test@test.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, "test@test.com", loaded.GetEmail())
+	assert.Equal(t, key, loaded.GetPrivateKey())
+	require.NotNil(t, loaded.GetRegistration())
+	assert.Equal(t, apiURL+"/account/1", loaded.GetRegistration().URI)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", loaded.GetRegistration().URI, key)
+	require.NoError(t, err)
+
+	res, err := NewRegistrar(core, loaded).QueryRegistration()
+	require.NoError(t, err)
+
+	assert.Equal(t, "valid", res.Body.Status)
+
+	// the saved account takes precedence over the loader.
+
+	err = storage.Save(&mockUser{email: "test@test.com", privatekey: key, regres: res})
+	require.NoError(t, err)
+
+	loaded, err = storage.Load("test@test.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"mailto:test@test.com"}, loaded.GetRegistration().Body.Contact)
+	assert.Equal(t, 2, calls)
+}
+
+func TestLoaderStorage_Load_notRegistered(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	storage := NewLoaderStorage(func(_ string) (crypto.PrivateKey, string, error) {
+		return key, "", nil
+	})
+
+	loaded, err := storage.Load("test@test.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, key, loaded.GetPrivateKey())
+	assert.Nil(t, loaded.GetRegistration())
+}
+
+func TestLoaderStorage_Load_noKey(t *testing.T) {
+	storage := NewLoaderStorage(func(_ string) (crypto.PrivateKey, string, error) {
+		return nil, "", nil
+	})
+
+	_, err := storage.Load("test@test.com")
+	require.EqualError(t, err, "private key is nil")
+}
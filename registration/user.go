@@ -11,3 +11,9 @@ type User interface {
 	GetRegistration() *Resource
 	GetPrivateKey() crypto.PrivateKey
 }
+
+// UserKeyUpdater can be implemented by a User to update the stored private key of the account
+// after a key rollover (see Registrar.RolloverAccountKey).
+type UserKeyUpdater interface {
+	SetPrivateKey(key crypto.PrivateKey)
+}